@@ -17,6 +17,8 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -24,7 +26,17 @@ var ClusterExtensionKind = "ClusterExtension"
 
 type (
 	UpgradeConstraintPolicy     string
+	UpgradeApproval             string
+	PermissionEscalationPolicy  string
+	ApplyMode                   string
+	AutoUpgradePolicy           string
+	RollbackPolicy              string
 	CRDUpgradeSafetyEnforcement string
+	ServiceAccountEnforcement   string
+	KubeVersionEnforcement      string
+	UninstallPolicy             string
+	DependencyPolicy            string
+	DependencyState             string
 )
 
 const (
@@ -41,6 +53,90 @@ const (
 	UpgradeConstraintPolicySelfCertified UpgradeConstraintPolicy = "SelfCertified"
 )
 
+const (
+	// Upgrades are performed whatever the permissions they grant.
+	PermissionEscalationAllow PermissionEscalationPolicy = "Allow"
+
+	// Upgrades that grant permissions the installed bundle does not grant
+	// are only performed once their version has been explicitly approved.
+	PermissionEscalationRequireApproval PermissionEscalationPolicy = "RequireApproval"
+)
+
+const (
+	// The content of the resolved bundle is applied to the cluster.
+	ApplyModeApply ApplyMode = "Apply"
+
+	// The changes applying the content of the resolved bundle would make are
+	// computed with a dry run and reported, without changing the cluster.
+	ApplyModePlan ApplyMode = "Plan"
+)
+
+const (
+	// Upgrades found in a catalog are applied as soon as they are found.
+	UpgradeApprovalAutomatic UpgradeApproval = "Automatic"
+
+	// Upgrades found in a catalog are only applied once
+	// their version has been explicitly approved.
+	UpgradeApprovalManual UpgradeApproval = "Manual"
+)
+
+const (
+	// Only upgrades to a new patch version of the installed major and minor version are allowed.
+	AutoUpgradePolicyPatchOnly AutoUpgradePolicy = "PatchOnly"
+
+	// Only upgrades to a new minor or patch version of the installed major version are allowed.
+	AutoUpgradePolicyMinorAndPatch AutoUpgradePolicy = "MinorAndPatch"
+
+	// Upgrades to any new version are allowed.
+	AutoUpgradePolicyAll AutoUpgradePolicy = "All"
+
+	// No upgrades are allowed.
+	AutoUpgradePolicyNone AutoUpgradePolicy = "None"
+)
+
+const (
+	// A failed upgrade leaves the release in a failed state.
+	RollbackPolicyNone RollbackPolicy = "None"
+
+	// A failed upgrade is rolled back to the last successfully deployed revision.
+	RollbackPolicyAutomatic RollbackPolicy = "Automatic"
+)
+
+const (
+	// All installed objects are kept when the extension is deleted.
+	UninstallPolicyOrphan UninstallPolicy = "Orphan"
+
+	// Installed objects are deleted when the extension is deleted, except for
+	// CustomResourceDefinitions, which are kept along with their custom resources.
+	UninstallPolicyKeepCRDs UninstallPolicy = "KeepCRDs"
+
+	// All installed objects are deleted when the extension is deleted, including
+	// CustomResourceDefinitions once all of their custom resources are deleted.
+	UninstallPolicyCascade UninstallPolicy = "Cascade"
+)
+
+const (
+	// Missing dependencies are reported in the status and block the installation
+	// until they are satisfied by other means.
+	DependencyPolicyReport DependencyPolicy = "Report"
+
+	// A ClusterExtension is created for each missing dependency that can be
+	// satisfied by a bundle from the catalogs.
+	DependencyPolicyInstall DependencyPolicy = "Install"
+)
+
+const (
+	// The dependency is satisfied.
+	DependencyStateSatisfied DependencyState = "Satisfied"
+
+	// The dependency is not satisfied yet, but can be satisfied by installing a
+	// bundle from the catalogs or by the ClusterExtension being installed for it.
+	DependencyStateMissing DependencyState = "Missing"
+
+	// The dependency cannot be satisfied by any bundle from the catalogs.
+	DependencyStateUnsatisfiable DependencyState = "Unsatisfiable"
+)
+
 // ClusterExtensionSpec defines the desired state of ClusterExtension
 type ClusterExtensionSpec struct {
 	// namespace is a reference to a Kubernetes namespace.
@@ -74,8 +170,8 @@ type ClusterExtensionSpec struct {
 	// source is a required field which selects the installation source of content
 	// for this ClusterExtension. Selection is performed by setting the sourceType.
 	//
-	// Catalog is currently the only implemented sourceType, and setting the
-	// sourcetype to "Catalog" requires the catalog field to also be defined.
+	// Setting the sourceType to "Catalog" requires the catalog field to also be defined.
+	// Setting the sourceType to "Image" requires the image field to also be defined.
 	//
 	// Below is a minimal example of a source definition (in yaml):
 	//
@@ -92,26 +188,173 @@ type ClusterExtensionSpec struct {
 	//
 	// +optional
 	Install *ClusterExtensionInstallConfig `json:"install,omitempty"`
+
+	// health is an optional field used to configure extension-specific rules
+	// that determine whether the extension is healthy, in addition to the
+	// readiness of the Deployments and CustomResourceDefinitions it installs.
+	//
+	// The results of the rules are reported in the Healthy condition.
+	//
+	// +optional
+	Health *HealthConfig `json:"health,omitempty"`
+
+	// uninstall is an optional field used to configure what happens to the
+	// objects installed for the ClusterExtension when it is deleted.
+	//
+	// The progress of the uninstall is reported in the Terminating condition.
+	//
+	// +optional
+	Uninstall *ClusterExtensionUninstallConfig `json:"uninstall,omitempty"`
 }
 
-const SourceTypeCatalog = "Catalog"
+// ClusterExtensionUninstallConfig configures the uninstall of a ClusterExtension.
+type ClusterExtensionUninstallConfig struct {
+	// policy is an optional field that defines what happens to the installed
+	// objects when the ClusterExtension is deleted.
+	//
+	// Allowed values are: "Orphan", "KeepCRDs" and "Cascade".
+	//
+	// When set to "Orphan", all installed objects are kept. The labels and owner
+	// references that tie them to the ClusterExtension are removed, so that they
+	// are no longer managed by operator-controller.
+	//
+	// When set to "KeepCRDs", the installed objects are deleted, except for
+	// CustomResourceDefinitions, which are orphaned so that they and their
+	// custom resources are kept.
+	//
+	// When set to "Cascade", the custom resources of the installed
+	// CustomResourceDefinitions are deleted first. Once they are all gone,
+	// including any finalizers they have, the CustomResourceDefinitions and the
+	// other installed objects are deleted. The ServiceAccount must be permitted
+	// to list and delete the custom resources.
+	//
+	// When omitted, the default value is "KeepCRDs".
+	//
+	// +kubebuilder:validation:Enum:=Orphan;KeepCRDs;Cascade
+	// +optional
+	Policy UninstallPolicy `json:"policy,omitempty"`
+}
+
+// HealthConfig configures the health rules of a ClusterExtension.
+type HealthConfig struct {
+	// rules is a required list of health rules. The extension is only
+	// healthy when every rule is satisfied.
+	//
+	// No more than 16 rules can be specified.
+	//
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MinItems:=1
+	// +kubebuilder:validation:MaxItems:=16
+	// +kubebuilder:validation:Required
+	Rules []HealthRule `json:"rules"`
+}
+
+// HealthRule is a CEL expression evaluated against an object on the cluster.
+type HealthRule struct {
+	// name is a required, unique name of the rule, used to identify the rule
+	// in the Healthy condition message.
+	//
+	// It must follow the DNS label standard as defined in [RFC 1123].
+	//
+	// [RFC 1123]: https://tools.ietf.org/html/rfc1123
+	//
+	// +kubebuilder:validation:MaxLength:=63
+	// +kubebuilder:validation:XValidation:rule="self.matches(\"^[a-z0-9]([-a-z0-9]*[a-z0-9])?$\")",message="name must be a valid DNS1123 label"
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// object is a required reference to the object the rule is evaluated against.
+	// The object does not need to be installed by the extension, so the
+	// ServiceAccount referenced in the spec must be allowed to list and watch
+	// it. Only the object itself is watched, so that permission may be
+	// restricted to its name.
+	//
+	// +kubebuilder:validation:Required
+	Object HealthRuleObjectReference `json:"object"`
+
+	// expression is a required CEL expression that must evaluate to true for
+	// the rule to be satisfied. The object is available as the variable self.
+	//
+	// An example of a valid value is:
+	//   has(self.status.phase) && self.status.phase == 'Ready'
+	//
+	// A rule whose object does not exist, or whose expression does not evaluate
+	// to true, is not satisfied. An expression that cannot be compiled is
+	// reported in the Healthy condition.
+	//
+	// +kubebuilder:validation:MinLength:=1
+	// +kubebuilder:validation:MaxLength:=1024
+	// +kubebuilder:validation:Required
+	Expression string `json:"expression"`
+
+	// message is an optional message reported in the Healthy condition when the
+	// rule is not satisfied.
+	//
+	// When not specified, the message reports that the expression evaluated to false.
+	//
+	// +kubebuilder:validation:MaxLength:=256
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// HealthRuleObjectReference identifies the object a health rule is evaluated against.
+type HealthRuleObjectReference struct {
+	// apiVersion is the required API version of the object, for example "example.com/v1".
+	//
+	// +kubebuilder:validation:MinLength:=1
+	// +kubebuilder:validation:MaxLength:=253
+	// +kubebuilder:validation:Required
+	APIVersion string `json:"apiVersion"`
+
+	// kind is the required kind of the object, for example "Foo".
+	//
+	// +kubebuilder:validation:MinLength:=1
+	// +kubebuilder:validation:MaxLength:=63
+	// +kubebuilder:validation:Required
+	Kind string `json:"kind"`
+
+	// name is the required name of the object.
+	//
+	// +kubebuilder:validation:MinLength:=1
+	// +kubebuilder:validation:MaxLength:=253
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// namespace is the optional namespace of the object.
+	// It must be specified for namespaced objects and omitted for cluster-scoped objects.
+	//
+	// +kubebuilder:validation:MaxLength:=63
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+const (
+	SourceTypeCatalog = "Catalog"
+	SourceTypeImage   = "Image"
+)
 
 // SourceConfig is a discriminated union which selects the installation source.
 //
 // +union
 // +kubebuilder:validation:XValidation:rule="has(self.sourceType) && self.sourceType == 'Catalog' ? has(self.catalog) : !has(self.catalog)",message="catalog is required when sourceType is Catalog, and forbidden otherwise"
+// +kubebuilder:validation:XValidation:rule="has(self.sourceType) && self.sourceType == 'Image' ? has(self.image) : !has(self.image)",message="image is required when sourceType is Image, and forbidden otherwise"
 type SourceConfig struct {
 	// sourceType is a required reference to the type of install source.
 	//
-	// Allowed values are "Catalog"
+	// Allowed values are "Catalog" or "Image"
 	//
 	// When this field is set to "Catalog", information for determining the
 	// appropriate bundle of content to install will be fetched from
 	// ClusterCatalog resources existing on the cluster.
 	// When using the Catalog sourceType, the catalog field must also be set.
 	//
+	// When this field is set to "Image", the bundle image referenced in the
+	// image field is installed directly, without consulting any ClusterCatalogs.
+	// When using the Image sourceType, the image field must also be set.
+	//
 	// +unionDiscriminator
-	// +kubebuilder:validation:Enum:="Catalog"
+	// +kubebuilder:validation:Enum:="Catalog";"Image"
 	// +kubebuilder:validation:Required
 	SourceType string `json:"sourceType"`
 
@@ -120,12 +363,18 @@ type SourceConfig struct {
 	//
 	// +optional
 	Catalog *CatalogSource `json:"catalog,omitempty"`
+
+	// image is used to configure a bundle image to install directly.
+	// This field is required when sourceType is "Image", and forbidden otherwise.
+	//
+	// +optional
+	Image *ImageSource `json:"image,omitempty"`
 }
 
 // ClusterExtensionInstallConfig is a union which selects the clusterExtension installation config.
 // ClusterExtensionInstallConfig requires the namespace and serviceAccount which should be used for the installation of packages.
 //
-// +kubebuilder:validation:XValidation:rule="has(self.preflight)",message="at least one of [preflight] are required when install is specified"
+// +kubebuilder:validation:XValidation:rule="has(self.preflight) || has(self.watchNamespaces) || has(self.config) || has(self.maintenanceWindows) || has(self.rollback) || has(self.rollbackTo) || has(self.dependencyPolicy) || has(self.permissionEscalation) || has(self.approvedPermissionEscalationVersion) || has(self.mode)",message="at least one of [preflight, watchNamespaces, config, maintenanceWindows, rollback, rollbackTo, dependencyPolicy, permissionEscalation, approvedPermissionEscalationVersion, mode] are required when install is specified"
 // +union
 type ClusterExtensionInstallConfig struct {
 	// preflight is an optional field that can be used to configure the checks that are
@@ -136,6 +385,337 @@ type ClusterExtensionInstallConfig struct {
 	//
 	// +optional
 	Preflight *PreflightConfig `json:"preflight,omitempty"`
+
+	// watchNamespaces is an optional list of namespaces the installed extension
+	// is configured to watch. It selects the install mode of registry+v1 bundles:
+	//   - When unspecified, the extension watches all namespaces if the bundle supports
+	//     the AllNamespaces install mode, and otherwise only the namespace referenced in
+	//     the spec if the bundle supports the OwnNamespace install mode.
+	//   - When set to only the namespace referenced in the spec, the bundle must
+	//     support the OwnNamespace or SingleNamespace install mode.
+	//   - When set to a single other namespace, the bundle must support the
+	//     SingleNamespace install mode.
+	//   - When set to more than one namespace, the bundle must support the
+	//     MultiNamespace install mode.
+	//
+	// When the validating webhook of operator-controller is deployed, a
+	// watchNamespaces configuration that the bundle resolved from a catalog
+	// does not support is rejected on admission. The install modes of bundle
+	// images are only known once they are pulled, so an unsupported
+	// configuration is otherwise reported in the Progressing condition.
+	//
+	// Each namespace in the list must follow the DNS label standard
+	// as defined in [RFC 1123]. It must contain only lowercase alphanumeric characters or hyphens (-),
+	// start and end with an alphanumeric character, and be no longer than 63 characters.
+	// No more than 64 namespaces can be specified.
+	//
+	// [RFC 1123]: https://tools.ietf.org/html/rfc1123
+	//
+	// +listType=set
+	// +kubebuilder:validation:MinItems:=1
+	// +kubebuilder:validation:MaxItems:=64
+	// +kubebuilder:validation:items:MaxLength:=63
+	// +kubebuilder:validation:items:XValidation:rule="self.matches(\"^[a-z0-9]([-a-z0-9]*[a-z0-9])?$\")",message="watchNamespaces entries must be valid DNS1123 labels"
+	// +optional
+	WatchNamespaces []string `json:"watchNamespaces,omitempty"`
+
+	// config is an optional field used to customize the Deployments
+	// of the extension, for example to set proxy environment variables or to
+	// place the extension on specific nodes.
+	//
+	// The configuration is applied to every Deployment defined by the bundle,
+	// and to every container of those Deployments where applicable. Init
+	// containers are left unchanged.
+	// Changing the configuration upgrades the installed content in place.
+	//
+	// +optional
+	Config *DeploymentConfig `json:"config,omitempty"`
+
+	// maintenanceWindows is an optional list of recurring time windows during
+	// which upgrades of the installed bundle may be performed.
+	//
+	// When specified, an upgrade found during resolution is deferred until one
+	// of the windows is open, and the deferral is reported in the Progressing
+	// condition. The initial installation and re-applying the installed bundle
+	// are never deferred.
+	// When not specified, upgrades are performed as soon as they are found.
+	//
+	// No more than 16 maintenance windows can be specified.
+	//
+	// +kubebuilder:validation:MinItems:=1
+	// +kubebuilder:validation:MaxItems:=16
+	// +optional
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+
+	// rollback is an optional field that configures what happens when an
+	// upgrade of the installed bundle fails.
+	//
+	// When not specified, a failed upgrade leaves the release in a failed state
+	// and the upgrade is retried.
+	//
+	// +optional
+	Rollback *RollbackConfig `json:"rollback,omitempty"`
+
+	// rollbackTo is an optional field that rolls the installed content back to
+	// a revision that was previously deployed.
+	//
+	// When specified, bundle resolution is skipped and the content of the
+	// selected revision is re-applied, after checking that doing so does not
+	// break the CustomResourceDefinitions it contains. The installed bundle
+	// reported in the status is the bundle of that revision.
+	// When removed, bundle resolution resumes and the installed content is
+	// upgraded according to the rest of the spec.
+	//
+	// +optional
+	RollbackTo *RollbackTarget `json:"rollbackTo,omitempty"`
+
+	// dependencyPolicy is an optional field that defines how the dependencies
+	// declared by the resolved bundle through olm.package.required and
+	// olm.gvk.required properties are satisfied.
+	//
+	// A package dependency is satisfied by another ClusterExtension that has a
+	// version of the required package in the required range installed.
+	// An API dependency is satisfied when the API is served by the cluster.
+	// The state of each dependency is reported in status.dependencies, and the
+	// resolved bundle is only installed or upgraded to once all of them are satisfied.
+	//
+	// Allowed values are: "Report" and "Install".
+	//
+	// When set to "Report", missing dependencies are only reported, along with
+	// the bundle from the catalogs that would satisfy them.
+	//
+	// When set to "Install", a ClusterExtension is created for each missing
+	// dependency that can be satisfied by a bundle from the catalogs. It is named
+	// after the package of that bundle, uses the same namespace and serviceAccount
+	// as this ClusterExtension, and also has its dependencyPolicy set to "Install".
+	// It is pinned to the version of that bundle and is not upgraded automatically,
+	// since the ClusterExtensions depending on it may require different ranges of
+	// versions. It is owned by every ClusterExtension depending on it, and is
+	// deleted once all of them are deleted.
+	//
+	// When omitted, the default value is "Report".
+	//
+	// +kubebuilder:validation:Enum:=Report;Install
+	// +optional
+	DependencyPolicy DependencyPolicy `json:"dependencyPolicy,omitempty"`
+
+	// permissionEscalation is an optional field that defines whether upgrades
+	// that grant additional permissions are performed.
+	//
+	// An upgrade grants additional permissions when the Roles and ClusterRoles
+	// of the bundle it upgrades to have rules that those of the installed bundle
+	// do not allow, when its RoleBindings and ClusterRoleBindings grant a subject
+	// rules that the installed bundle does not grant it, or when they bind a
+	// subject to a role outside of the bundle that the installed bundle does not
+	// bind it to. Rules that are narrowed, split or merged do not grant
+	// additional permissions.
+	//
+	// Allowed values are: "Allow" and "RequireApproval".
+	//
+	// When set to "Allow", upgrades are performed whatever the permissions they grant.
+	//
+	// When set to "RequireApproval", an upgrade that grants additional permissions
+	// is not performed. Instead, the Progressing condition is set to False with the
+	// reason PermissionEscalation, the permission changes of the upgrade are reported
+	// in status.install.pendingPermissionChanges, and the installed bundle is kept
+	// until the upgrade is approved by setting approvedPermissionEscalationVersion
+	// to its version. The initial installation does not require approval.
+	//
+	// When omitted, the default value is "Allow".
+	//
+	// +kubebuilder:validation:Enum:=Allow;RequireApproval
+	// +optional
+	PermissionEscalation PermissionEscalationPolicy `json:"permissionEscalation,omitempty"`
+
+	// approvedPermissionEscalationVersion is an optional field that approves the
+	// permissions granted by the upgrade to the bundle with this version when
+	// permissionEscalation is set to "RequireApproval".
+	//
+	// Any other upgrade that grants additional permissions requires approval again.
+	//
+	// approvedPermissionEscalationVersion follows the semantic versioning standard
+	// as defined in https://semver.org/ and can be no longer than 64 characters.
+	//
+	// +kubebuilder:validation:MaxLength:=64
+	// +kubebuilder:validation:XValidation:rule="self.matches(\"^([0-9]+)(\\\\.[0-9]+)?(\\\\.[0-9]+)?(-([-0-9A-Za-z]+(\\\\.[-0-9A-Za-z]+)*))?(\\\\+([-0-9A-Za-z]+(-\\\\.[-0-9A-Za-z]+)*))?$\")",message="approvedPermissionEscalationVersion must be well-formed semver"
+	// +optional
+	ApprovedPermissionEscalationVersion string `json:"approvedPermissionEscalationVersion,omitempty"`
+
+	// mode is an optional field that defines whether the content of the
+	// resolved bundle is applied to the cluster.
+	//
+	// Allowed values are: "Apply" and "Plan".
+	//
+	// When set to "Apply", the content of the resolved bundle is installed or
+	// upgraded to.
+	//
+	// When set to "Plan", the bundle is resolved and unpacked, and its content
+	// is rendered with a server-side dry run, but nothing is installed or
+	// upgraded. Instead, the objects that applying it would add, change or
+	// remove are reported in status.plan, and the Progressing condition is set
+	// to False with the reason Planned. This allows reviewing what a change of
+	// the spec would do before applying it. Rollbacks requested with
+	// rollbackTo are performed regardless of the mode.
+	//
+	// When omitted, the default value is "Apply".
+	//
+	// +kubebuilder:validation:Enum:=Apply;Plan
+	// +optional
+	Mode ApplyMode `json:"mode,omitempty"`
+}
+
+// RollbackTarget selects a previously deployed revision to roll back to.
+type RollbackTarget struct {
+	// revision is an optional number of the revision to roll back to.
+	// Revisions are numbered from 1 and incremented every time the installed
+	// content changes. The revision must have been successfully deployed and
+	// must still be part of the retained revision history.
+	//
+	// When not specified, the content is rolled back to the revision that was
+	// deployed before the current one.
+	//
+	// +kubebuilder:validation:Minimum:=1
+	// +optional
+	Revision int32 `json:"revision,omitempty"`
+}
+
+// RollbackConfig configures the rollback of failed upgrades.
+//
+// +kubebuilder:validation:XValidation:rule="!has(self.healthCheckTimeout) || self.policy == 'Automatic'",message="healthCheckTimeout is only allowed when policy is Automatic"
+type RollbackConfig struct {
+	// policy is a required field that defines whether failed upgrades are rolled back.
+	//
+	// Allowed values are: "None" and "Automatic".
+	//
+	// When set to "None", a failed upgrade leaves the release in a failed state
+	// and the upgrade is retried.
+	//
+	// When set to "Automatic", a failed upgrade is rolled back to the last
+	// successfully deployed revision and the Progressing condition is set to False
+	// with the reason RolledBack. The same upgrade is not attempted again until
+	// either the resolved bundle or the ClusterExtension configuration changes.
+	// A rollback is refused when the content being rolled back to does not serve
+	// a version of a CustomResourceDefinition that is stored in the cluster, since
+	// objects stored with that version would become inaccessible.
+	//
+	// +kubebuilder:validation:Enum:=None;Automatic
+	// +kubebuilder:validation:Required
+	Policy RollbackPolicy `json:"policy"`
+
+	// healthCheckTimeout is an optional field that defines how long to wait,
+	// after an upgrade has been applied, for its workloads to become ready.
+	// An upgrade whose workloads are not ready within this duration is considered
+	// failed and is rolled back.
+	//
+	// When not specified, an upgrade is considered successful once its content
+	// has been applied.
+	//
+	// healthCheckTimeout must be between 10s and 10m.
+	//
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('10s') && duration(self) <= duration('10m')",message="healthCheckTimeout must be between 10s and 10m"
+	// +optional
+	HealthCheckTimeout *metav1.Duration `json:"healthCheckTimeout,omitempty"`
+}
+
+// MaintenanceWindow is a recurring time window during which upgrades may be performed.
+type MaintenanceWindow struct {
+	// schedule is a required cron expression that defines when the window opens.
+	//
+	// The expression is made of five space-separated fields: minute, hour,
+	// day of month, month and day of week. Each field accepts "*", single values,
+	// ranges (1-5), lists (1,3,5) and steps (*/15 or 1-30/5). Days of the week
+	// range from 0 (Sunday) to 6 (Saturday). Months and days of the week may
+	// also be given by their three-letter English names.
+	// The descriptors @yearly, @monthly, @weekly, @daily and @hourly are also accepted.
+	//
+	// Some examples of valid values are:
+	//   - "0 2 * * *" opens the window every day at 02:00
+	//   - "30 22 * * SAT" opens the window every Saturday at 22:30
+	//   - "0 */6 * * 1-5" opens the window every six hours on weekdays
+	//
+	// An invalid schedule is reported in the Progressing condition.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength:=1
+	// +kubebuilder:validation:MaxLength:=100
+	Schedule string `json:"schedule"`
+
+	// duration is a required field that defines how long the window stays open
+	// once it has opened, for example "2h" or "90m". It must be at least one minute.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('1m')",message="duration must be at least 1m"
+	Duration metav1.Duration `json:"duration"`
+
+	// timeZone is an optional name of the time zone in which the schedule is
+	// interpreted, as defined in the IANA time zone database, for example
+	// "Europe/Berlin" or "America/New_York".
+	//
+	// When not specified, the schedule is interpreted in UTC.
+	// An unknown time zone is reported in the Progressing condition.
+	//
+	// +kubebuilder:validation:MaxLength:=64
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// DeploymentConfig is a set of overrides applied to the Deployments of an
+// extension. It behaves like the config field of an OLM v0 Subscription.
+type DeploymentConfig struct {
+	// env is an optional list of environment variables to set in every container.
+	// A variable that is already defined by a container is replaced.
+	//
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// envFrom is an optional list of sources to populate environment variables
+	// in every container. The sources are appended to those already defined by
+	// a container.
+	//
+	// +optional
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+
+	// resources is an optional field that replaces the compute resource
+	// requirements of every container.
+	//
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// tolerations is an optional list of tolerations added to the pods.
+	// Tolerations already defined by the bundle are kept.
+	//
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// nodeSelector is an optional field that replaces the node selector of the pods.
+	//
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// affinity is an optional field that replaces the scheduling constraints of the pods.
+	// Each of nodeAffinity, podAffinity and podAntiAffinity replaces the corresponding
+	// constraint defined by the bundle only when it is specified.
+	//
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// volumes is an optional list of volumes added to the pods.
+	// A volume that is already defined by the bundle is replaced.
+	//
+	// +optional
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+
+	// volumeMounts is an optional list of volume mounts added to every container.
+	// A volume mount that is already defined by a container is replaced.
+	//
+	// +optional
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+
+	// annotations is an optional set of annotations added to the Deployments
+	// and their pod templates. Annotations already defined by the bundle are kept.
+	//
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 // CatalogSource defines the attributes used to identify and filter content from a catalog.
@@ -325,6 +905,114 @@ type CatalogSource struct {
 	// +kubebuilder:default:=CatalogProvided
 	// +optional
 	UpgradeConstraintPolicy UpgradeConstraintPolicy `json:"upgradeConstraintPolicy,omitempty"`
+
+	// upgradeApproval is an optional field that controls whether upgrades to
+	// the installed bundle are applied automatically.
+	//
+	// Allowed values are: "Automatic" or "Manual", or omitted.
+	//
+	// When this field is set to "Automatic", an upgrade is applied as soon as it
+	// is found in a catalog.
+	//
+	// When this field is set to "Manual", an upgrade that is found in a catalog is
+	// not applied. Instead, the upgrade is reported in the availableUpgrade
+	// status field and in the UpgradeAvailable condition, and the installed bundle
+	// is kept until the upgrade is approved by setting the approvedVersion field
+	// to the version of the available upgrade.
+	// The initial installation does not require approval.
+	//
+	// When this field is omitted, the default value is "Automatic".
+	//
+	// +kubebuilder:validation:Enum:=Automatic;Manual
+	// +kubebuilder:default:=Automatic
+	// +optional
+	UpgradeApproval UpgradeApproval `json:"upgradeApproval,omitempty"`
+
+	// autoUpgrade is an optional field that restricts the upgrades of the installed
+	// bundle to a class of semantic version changes. It is applied in addition to
+	// the upgrade path(s) enforced by upgradeConstraintPolicy, so an upgrade must be
+	// allowed by both fields to be selected. Downgrades are not restricted by this field.
+	//
+	// Allowed values are: "PatchOnly", "MinorAndPatch", "All", "None", or omitted.
+	//
+	// When this field is set to "PatchOnly", only upgrades to a version with the
+	// same major and minor version as the installed bundle are allowed.
+	// For example, 1.2.3 may be upgraded to 1.2.4 but not to 1.3.0.
+	//
+	// When this field is set to "MinorAndPatch", only upgrades to a version with the
+	// same major version as the installed bundle are allowed.
+	// For example, 1.2.3 may be upgraded to 1.3.0 but not to 2.0.0.
+	//
+	// When this field is set to "All", upgrades to any version are allowed.
+	//
+	// When this field is set to "None", the installed bundle is never upgraded.
+	//
+	// The initial installation is not restricted by this field.
+	//
+	// When this field is omitted, the default value is "All".
+	//
+	// +kubebuilder:validation:Enum:=PatchOnly;MinorAndPatch;All;None
+	// +kubebuilder:default:=All
+	// +optional
+	AutoUpgrade AutoUpgradePolicy `json:"autoUpgrade,omitempty"`
+
+	// approvedVersion is an optional field used to approve an upgrade when
+	// upgradeApproval is set to "Manual".
+	//
+	// The upgrade reported in the availableUpgrade status field is applied when
+	// approvedVersion is set to exactly its version. Any other upgrade that is
+	// found in a catalog requires approval again.
+	//
+	// approvedVersion follows the semantic versioning standard as defined in https://semver.org/
+	// and can be no longer than 64 characters.
+	//
+	// +kubebuilder:validation:MaxLength:=64
+	// +kubebuilder:validation:XValidation:rule="self.matches(\"^([0-9]+)(\\\\.[0-9]+)?(\\\\.[0-9]+)?(-([-0-9A-Za-z]+(\\\\.[-0-9A-Za-z]+)*))?(\\\\+([-0-9A-Za-z]+(-\\\\.[-0-9A-Za-z]+)*))?$\")",message="approvedVersion must be well-formed semver"
+	// +optional
+	ApprovedVersion string `json:"approvedVersion,omitempty"`
+}
+
+// ImageSource defines a bundle image that is installed without being resolved from a catalog.
+type ImageSource struct {
+	// ref is a required reference to a registry+v1 bundle image.
+	//
+	// The reference may use either a tag or a digest. When a tag is used, the
+	// tag is resolved to a digest whenever the ClusterExtension is reconciled,
+	// so the installed content may change if the tag is moved to a different image.
+	// Using a digest is recommended. The digest that was installed is reported
+	// in the status.
+	//
+	// Some examples of valid values are:
+	//   - quay.io/example/example-operator-bundle:v1.2.3
+	//   - quay.io/example/example-operator-bundle@sha256:9bc8b2e0c3e7d6f9d8d2a4c4e2f7b4c0f6c8e9b0d1a2c3e4f5a6b7c8d9e0f1a2
+	//
+	// +kubebuilder:validation:MaxLength:=1000
+	// +kubebuilder:validation:MinLength:=1
+	// +kubebuilder:validation:Required
+	Ref string `json:"ref"`
+
+	// pullSecret is an optional reference to the name of a Secret of type
+	// "kubernetes.io/dockerconfigjson" containing the credentials needed to
+	// pull the bundle image referenced in the ref field.
+	//
+	// The Secret must exist in the namespace referenced in the spec, and
+	// it is read using the ServiceAccount referenced in the spec. That
+	// ServiceAccount must therefore be permitted to get the Secret.
+	//
+	// When unspecified, the image is pulled using the global pull secret
+	// configured for operator-controller, if any.
+	//
+	// pullSecret follows the DNS subdomain standard as defined in [RFC 1123].
+	// It must contain only lowercase alphanumeric characters,
+	// hyphens (-) or periods (.), start and end with an alphanumeric character,
+	// and be no longer than 253 characters.
+	//
+	// [RFC 1123]: https://tools.ietf.org/html/rfc1123
+	//
+	// +kubebuilder:validation:MaxLength:=253
+	// +kubebuilder:validation:XValidation:rule="self.matches(\"^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$\")",message="pullSecret must be a valid DNS1123 subdomain. It must contain only lowercase alphanumeric characters, hyphens (-) or periods (.), start and end with an alphanumeric character, and be no longer than 253 characters"
+	// +optional
+	PullSecret string `json:"pullSecret,omitempty"`
 }
 
 // ServiceAccountReference identifies the serviceAccount used fo install a ClusterExtension.
@@ -362,14 +1050,35 @@ type ServiceAccountReference struct {
 
 // PreflightConfig holds the configuration for the preflight checks.  If used, at least one preflight check must be non-nil.
 //
-// +kubebuilder:validation:XValidation:rule="has(self.crdUpgradeSafety)",message="at least one of [crdUpgradeSafety] are required when preflight is specified"
+// +kubebuilder:validation:XValidation:rule="has(self.crdUpgradeSafety) || has(self.serviceAccount) || has(self.kubeVersion)",message="at least one of [crdUpgradeSafety, serviceAccount, kubeVersion] are required when preflight is specified"
 type PreflightConfig struct {
 	// crdUpgradeSafety is used to configure the CRD Upgrade Safety pre-flight
 	// checks that run prior to upgrades of installed content.
 	//
 	// The CRD Upgrade Safety pre-flight check safeguards from unintended
 	// consequences of upgrading a CRD, such as data loss.
-	CRDUpgradeSafety *CRDUpgradeSafetyPreflightConfig `json:"crdUpgradeSafety"`
+	//
+	// +optional
+	CRDUpgradeSafety *CRDUpgradeSafetyPreflightConfig `json:"crdUpgradeSafety,omitempty"`
+
+	// serviceAccount is used to configure the Service Account pre-flight check
+	// that runs prior to installs and upgrades.
+	//
+	// The Service Account pre-flight check reports every permission that the
+	// service account referenced in the spec is missing to manage the content,
+	// instead of failing on the first request that is forbidden.
+	//
+	// +optional
+	ServiceAccount *ServiceAccountPreflightConfig `json:"serviceAccount,omitempty"`
+
+	// kubeVersion is used to configure the Kubernetes Version pre-flight check
+	// that runs prior to installs and upgrades.
+	//
+	// The Kubernetes Version pre-flight check blocks content whose minKubeVersion
+	// or olm.maxKubeVersion excludes the version of Kubernetes the cluster runs.
+	//
+	// +optional
+	KubeVersion *KubeVersionPreflightConfig `json:"kubeVersion,omitempty"`
 }
 
 // CRDUpgradeSafetyPreflightConfig is the configuration for CRD upgrade safety preflight check.
@@ -390,6 +1099,43 @@ type CRDUpgradeSafetyPreflightConfig struct {
 	Enforcement CRDUpgradeSafetyEnforcement `json:"enforcement"`
 }
 
+// ServiceAccountPreflightConfig is the configuration for the Service Account preflight check.
+type ServiceAccountPreflightConfig struct {
+	// enforcement is a required field, used to configure the state of the Service Account pre-flight check.
+	//
+	// Allowed values are "None" or "Strict". The default value is "Strict".
+	//
+	// When set to "None", the Service Account pre-flight check will be skipped,
+	// for example when the permissions of the service account are granted by an
+	// authorizer that SubjectAccessReviews do not reflect. Missing permissions are
+	// then reported by the first request that is forbidden.
+	//
+	// When set to "Strict", the Service Account pre-flight check will be run when
+	// performing an install or upgrade operation.
+	//
+	// +kubebuilder:validation:Enum:="None";"Strict"
+	// +kubebuilder:validation:Required
+	Enforcement ServiceAccountEnforcement `json:"enforcement"`
+}
+
+// KubeVersionPreflightConfig is the configuration for the Kubernetes Version preflight check.
+type KubeVersionPreflightConfig struct {
+	// enforcement is a required field, used to configure the state of the Kubernetes Version pre-flight check.
+	//
+	// Allowed values are "None" or "Strict". The default value is "Strict".
+	//
+	// When set to "None", the Kubernetes Version pre-flight check will be skipped
+	// when performing an install or upgrade operation. This should be used with
+	// caution as the content may not work on the version of Kubernetes the cluster runs.
+	//
+	// When set to "Strict", the Kubernetes Version pre-flight check will be run when
+	// performing an install or upgrade operation.
+	//
+	// +kubebuilder:validation:Enum:="None";"Strict"
+	// +kubebuilder:validation:Required
+	Enforcement KubeVersionEnforcement `json:"enforcement"`
+}
+
 const (
 	TypeInstalled   = "Installed"
 	TypeProgressing = "Progressing"
@@ -401,16 +1147,85 @@ const (
 	TypeChannelDeprecated = "ChannelDeprecated"
 	TypeBundleDeprecated  = "BundleDeprecated"
 
+	// TypeUpgradeAvailable is True when an upgrade is waiting for approval.
+	TypeUpgradeAvailable = "UpgradeAvailable"
+
+	// TypeHealthy is True when the workloads of the installed bundle are ready.
+	TypeHealthy = "Healthy"
+
+	// TypeTerminating is True when the ClusterExtension is being deleted.
+	TypeTerminating = "Terminating"
+
 	ReasonSucceeded  = "Succeeded"
 	ReasonDeprecated = "Deprecated"
 	ReasonFailed     = "Failed"
 	ReasonBlocked    = "Blocked"
 	ReasonRetrying   = "Retrying"
 
+	ReasonApprovalRequired = "ApprovalRequired"
+	ReasonUpToDate         = "UpToDate"
+
+	// ReasonUpgradeDeferred is set on the Progressing condition while an upgrade
+	// waits for the next maintenance window to open.
+	ReasonUpgradeDeferred = "UpgradeDeferred"
+
+	// ReasonPermissionEscalation is set on the Progressing condition while an
+	// upgrade that grants additional permissions waits for approval.
+	ReasonPermissionEscalation = "PermissionEscalation"
+
+	// ReasonPlanned is set on the Progressing condition when the changes that
+	// applying the resolved bundle would make are reported instead of applied.
+	ReasonPlanned = "Planned"
+
+	// ReasonRolledBack is set on the Progressing condition when a failed upgrade
+	// has been rolled back to the last successfully deployed revision.
+	ReasonRolledBack = "RolledBack"
+
+	ReasonHealthy   = "Healthy"
+	ReasonUnhealthy = "Unhealthy"
+
+	// ReasonActive is set on the Terminating condition when the ClusterExtension is not being deleted.
+	ReasonActive = "Active"
+	// ReasonUninstalling is set on the Terminating condition while the installed
+	// objects are being uninstalled according to the uninstall policy.
+	ReasonUninstalling = "Uninstalling"
+
+	// ReasonResolutionFailed is set on the Progressing condition when no bundle could be resolved.
+	ReasonResolutionFailed = "ResolutionFailed"
+	// ReasonMissingDependencies is set on the Progressing condition when the resolved
+	// bundle has dependencies that are not satisfied.
+	ReasonMissingDependencies = "MissingDependencies"
+	// ReasonUnpackFailed is set on the Progressing condition when the resolved bundle could not be unpacked.
+	ReasonUnpackFailed = "UnpackFailed"
+	// ReasonPreflightFailed is set on the Progressing condition when a preflight check,
+	// such as the CRD upgrade safety check or the Kubernetes version check, prevented
+	// installing or upgrading the bundle.
+	ReasonPreflightFailed = "PreflightFailed"
+	// ReasonApplyFailed is set on the Progressing condition when the bundle could not be installed or upgraded.
+	ReasonApplyFailed = "ApplyFailed"
+	// ReasonWatchFailed is set on the Progressing condition when the installed objects could not be watched.
+	ReasonWatchFailed = "WatchFailed"
+	// ReasonInstallConflict is set on the Progressing condition when an object of the bundle
+	// already exists on the cluster and is not managed by the ClusterExtension.
+	ReasonInstallConflict = "InstallConflict"
+	// ReasonPermissionDenied is set on the Progressing condition when a request was forbidden,
+	// usually because the service account is missing permissions required by the bundle.
+	ReasonPermissionDenied = "PermissionDenied"
+
 	// None will not perform CRD upgrade safety checks.
 	CRDUpgradeSafetyEnforcementNone CRDUpgradeSafetyEnforcement = "None"
 	// Strict will enforce the CRD upgrade safety check and block the upgrade if the CRD would not pass the check.
 	CRDUpgradeSafetyEnforcementStrict CRDUpgradeSafetyEnforcement = "Strict"
+
+	// None will not check the permissions of the service account before installs and upgrades.
+	ServiceAccountEnforcementNone ServiceAccountEnforcement = "None"
+	// Strict will check the permissions of the service account and block the install or upgrade if any is missing.
+	ServiceAccountEnforcementStrict ServiceAccountEnforcement = "Strict"
+
+	// None will not check the Kubernetes version supported by the content before installs and upgrades.
+	KubeVersionEnforcementNone KubeVersionEnforcement = "None"
+	// Strict will check the Kubernetes version supported by the content and block the install or upgrade if it is not supported.
+	KubeVersionEnforcementStrict KubeVersionEnforcement = "Strict"
 )
 
 // BundleMetadata is a representation of the identifying attributes of a bundle.
@@ -444,6 +1259,19 @@ type ClusterExtensionStatus struct {
 	// When Progressing is True and the Reason is Succeeded, the ClusterExtension is making progress towards a new state.
 	// When Progressing is True and the Reason is Retrying, the ClusterExtension has encountered an error that could be resolved on subsequent reconciliation attempts.
 	// When Progressing is False and the Reason is Blocked, the ClusterExtension has encountered an error that requires manual intervention for recovery.
+	// When Progressing is True and the Reason is UpgradeDeferred, an upgrade was found that is waiting for the next maintenance window to open.
+	// When Progressing is False and the Reason is RolledBack, an upgrade failed and was rolled back to the last successfully deployed revision.
+	// When Progressing is False and the Reason is PermissionEscalation, an upgrade that grants additional permissions is waiting for approval.
+	// When Progressing is False and the Reason is Planned, the changes that applying the resolved bundle would make are reported in status.plan instead of being applied.
+	//
+	// When an error occurs at a given stage, the Reason of the Progressing condition identifies the stage instead of
+	// being Retrying or Blocked: ResolutionFailed, UnpackFailed, PreflightFailed, ApplyFailed or WatchFailed.
+	// When Progressing is True and the Reason is MissingDependencies, the resolved bundle is waiting for
+	// the dependencies reported in status.dependencies to be satisfied.
+	// The Reason is InstallConflict when an object of the bundle already exists and is not managed by the ClusterExtension,
+	// and PermissionDenied when a request was forbidden, regardless of the stage.
+	// The status is True when the error could be resolved on subsequent reconciliation attempts and False when it
+	// requires manual intervention for recovery.
 	//
 	// When the ClusterExtension is sourced from a catalog, if may also communicate a deprecation condition.
 	// These are indications from a package owner to guide users away from a particular package, channel, or bundle.
@@ -452,6 +1280,27 @@ type ClusterExtensionStatus struct {
 	// PackageDeprecated is set if the requested package is marked deprecated in the catalog.
 	// Deprecated is a rollup condition that is present when any of the deprecated conditions are present.
 	//
+	// The UpgradeAvailable condition represents whether or not an upgrade is waiting for approval,
+	// which can only be the case when upgradeApproval is set to "Manual".
+	// When UpgradeAvailable is True and the Reason is ApprovalRequired, an upgrade was found that is waiting for approval.
+	// When UpgradeAvailable is False and the Reason is UpToDate, no upgrade is waiting for approval.
+	//
+	// The Healthy condition represents whether or not the workloads of the installed bundle are ready.
+	// It is computed from the Deployments and CustomResourceDefinitions that were installed, and from
+	// the rules configured in spec.health.
+	// When Healthy is True and the Reason is Healthy, every Deployment is available with all of its
+	// replicas updated, every CustomResourceDefinition is established and every health rule is satisfied.
+	// When Healthy is False and the Reason is Unhealthy, at least one of them is not, and the message
+	// describes which ones.
+	// When Healthy is Unknown and the Reason is Failed, the health could not be determined, for example
+	// because no bundle is installed.
+	//
+	// The Terminating condition represents whether or not the ClusterExtension is being deleted.
+	// When Terminating is False and the Reason is Active, the ClusterExtension is not being deleted.
+	// When Terminating is True and the Reason is Uninstalling, the installed objects are being
+	// uninstalled according to spec.uninstall.policy, and the message describes what is being waited for.
+	// When Terminating is True and the Reason is Failed, the uninstall encountered an error that is retried.
+	//
 	// +patchMergeKey=type
 	// +patchStrategy=merge
 	// +listType=map
@@ -463,6 +1312,107 @@ type ClusterExtensionStatus struct {
 	//
 	// +optional
 	Install *ClusterExtensionInstallStatus `json:"install,omitempty"`
+
+	// availableUpgrade represents the identifying attributes of the bundle that
+	// the installed bundle would be upgraded to once approved. It is only set
+	// when upgradeApproval is "Manual" and an upgrade is waiting for approval.
+	//
+	// +optional
+	AvailableUpgrade *BundleMetadata `json:"availableUpgrade,omitempty"`
+
+	// dependencies is the list of dependencies declared by the resolved bundle,
+	// sorted by requirement, along with whether they are satisfied.
+	// It is omitted when the resolved bundle declares no dependencies.
+	//
+	// +listType=atomic
+	// +optional
+	Dependencies []DependencyStatus `json:"dependencies,omitempty"`
+
+	// plan describes the objects that applying the content of the resolved
+	// bundle would add, change or remove. It is only set when spec.install.mode
+	// is "Plan".
+	//
+	// +optional
+	Plan *ReleasePlan `json:"plan,omitempty"`
+}
+
+// ReleasePlan describes the objects that applying the content of a bundle would
+// add, change or remove compared to the installed content.
+type ReleasePlan struct {
+	// bundle is the bundle the plan applies.
+	Bundle BundleMetadata `json:"bundle"`
+
+	// added is the list of objects that would be created, sorted by
+	// apiVersion, kind, namespace and name.
+	//
+	// +listType=atomic
+	// +optional
+	Added []ManagedObjectReference `json:"added,omitempty"`
+
+	// changed is the list of installed objects that would be modified, sorted
+	// by apiVersion, kind, namespace and name.
+	//
+	// +listType=atomic
+	// +optional
+	Changed []ObjectChange `json:"changed,omitempty"`
+
+	// removed is the list of installed objects that would be deleted, sorted
+	// by apiVersion, kind, namespace and name.
+	//
+	// +listType=atomic
+	// +optional
+	Removed []ManagedObjectReference `json:"removed,omitempty"`
+}
+
+// ObjectChange describes the modification of an installed object.
+type ObjectChange struct {
+	ManagedObjectReference `json:",inline"`
+
+	// fields is the sorted list of the paths of the fields that would be
+	// modified, for example ".spec.template.spec.containers".
+	// Lists are compared as a whole.
+	//
+	// +listType=atomic
+	// +optional
+	Fields []string `json:"fields,omitempty"`
+}
+
+// DependencyStatus describes a dependency declared by the resolved bundle.
+type DependencyStatus struct {
+	// requirement describes the dependency, for example
+	// `package "foo" in range ">=1.0.0 <2.0.0"` or `API example.com/v1, Kind=Foo`.
+	Requirement string `json:"requirement"`
+
+	// state is whether the dependency is satisfied.
+	// It is one of "Satisfied", "Missing" or "Unsatisfiable".
+	State DependencyState `json:"state"`
+
+	// clusterExtensionName is the name of the ClusterExtension that satisfies
+	// the dependency, or that is being installed to satisfy it.
+	//
+	// +optional
+	ClusterExtensionName string `json:"clusterExtensionName,omitempty"`
+
+	// candidate is the bundle from the catalogs that would satisfy a missing
+	// dependency once installed.
+	//
+	// +optional
+	Candidate *DependencyCandidate `json:"candidate,omitempty"`
+
+	// message is a human-readable explanation of the state of the dependency.
+	Message string `json:"message"`
+}
+
+// DependencyCandidate identifies a bundle from the catalogs that satisfies a dependency.
+type DependencyCandidate struct {
+	// packageName is the name of the package of the bundle.
+	PackageName string `json:"packageName"`
+
+	// bundle represents the identifying attributes of the bundle.
+	Bundle BundleMetadata `json:"bundle"`
+
+	// catalog is the name of the ClusterCatalog the bundle was found in.
+	Catalog string `json:"catalog"`
 }
 
 // ClusterExtensionInstallStatus is a representation of the status of the identified bundle.
@@ -474,6 +1424,125 @@ type ClusterExtensionInstallStatus struct {
 	//
 	// +kubebuilder:validation:Required
 	Bundle BundleMetadata `json:"bundle"`
+
+	// resolvedImageRef is the canonical, digest-based reference of the
+	// bundle image from which the installed content was unpacked.
+	//
+	// For example: quay.io/example/example-operator-bundle@sha256:9bc8b2e0c3e7d6f9d8d2a4c4e2f7b4c0f6c8e9b0d1a2c3e4f5a6b7c8d9e0f1a2
+	//
+	// +optional
+	ResolvedImageRef string `json:"resolvedImageRef,omitempty"`
+
+	// catalog is the name of the ClusterCatalog the installed bundle was resolved from.
+	// It is omitted when the bundle was not resolved from a catalog.
+	//
+	// +optional
+	Catalog string `json:"catalog,omitempty"`
+
+	// channels is the list of channels of the catalog that contain the installed bundle.
+	// When spec.source.catalog.channels is set, only the channels listed there are included.
+	// It is omitted when the bundle was not resolved from a catalog.
+	//
+	// +listType=set
+	// +optional
+	Channels []string `json:"channels,omitempty"`
+
+	// managedObjects is the inventory of the objects installed from the bundle
+	// and managed by the ClusterExtension, sorted by apiVersion, kind, namespace and name.
+	//
+	// +listType=atomic
+	// +optional
+	ManagedObjects []ManagedObjectReference `json:"managedObjects,omitempty"`
+
+	// pendingPermissionChanges describes how the Roles, ClusterRoles, RoleBindings and
+	// ClusterRoleBindings installed from the bundle change when the pending upgrade is performed.
+	// It is set when an upgrade is waiting for approval, including the approval of the
+	// permissions it grants, or for the next maintenance window, and omitted otherwise.
+	//
+	// +optional
+	PendingPermissionChanges *PermissionChanges `json:"pendingPermissionChanges,omitempty"`
+}
+
+// PermissionChanges describes the rules of the Roles and ClusterRoles of a bundle,
+// and the bindings of its RoleBindings and ClusterRoleBindings, that are added or
+// removed compared to the installed bundle.
+type PermissionChanges struct {
+	// bundle is the bundle the changes are computed for.
+	Bundle BundleMetadata `json:"bundle"`
+
+	// added is the list of rules granted by the bundle that are not granted by
+	// the installed bundle, sorted by namespace.
+	//
+	// +listType=atomic
+	// +optional
+	Added []PermissionRule `json:"added,omitempty"`
+
+	// removed is the list of rules granted by the installed bundle that are not
+	// granted by the bundle, sorted by namespace.
+	//
+	// +listType=atomic
+	// +optional
+	Removed []PermissionRule `json:"removed,omitempty"`
+
+	// addedBindings is the list of bindings made by the bundle that are not
+	// made by the installed bundle, sorted by namespace.
+	//
+	// +listType=atomic
+	// +optional
+	AddedBindings []PermissionBinding `json:"addedBindings,omitempty"`
+
+	// removedBindings is the list of bindings made by the installed bundle that
+	// are not made by the bundle, sorted by namespace.
+	//
+	// +listType=atomic
+	// +optional
+	RemovedBindings []PermissionBinding `json:"removedBindings,omitempty"`
+}
+
+// PermissionRule is a rule of a Role or ClusterRole of a bundle.
+type PermissionRule struct {
+	// namespace is the namespace of the Role the rule belongs to.
+	// It is omitted for the rules of ClusterRoles.
+	//
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// rule is the policy rule.
+	Rule rbacv1.PolicyRule `json:"rule"`
+}
+
+// PermissionBinding is the binding of a role to a subject by a RoleBinding or
+// ClusterRoleBinding of a bundle.
+type PermissionBinding struct {
+	// namespace is the namespace of the RoleBinding the binding belongs to.
+	// It is omitted for the bindings of ClusterRoleBindings.
+	//
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// roleRef is the role that is bound.
+	RoleRef rbacv1.RoleRef `json:"roleRef"`
+
+	// subject is the subject the role is bound to.
+	Subject rbacv1.Subject `json:"subject"`
+}
+
+// ManagedObjectReference identifies an object managed by a ClusterExtension.
+type ManagedObjectReference struct {
+	// apiVersion is the API version of the object, for example "apps/v1".
+	APIVersion string `json:"apiVersion"`
+
+	// kind is the kind of the object, for example "Deployment".
+	Kind string `json:"kind"`
+
+	// namespace is the namespace of the object.
+	// It is omitted for cluster-scoped objects.
+	//
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// name is the name of the object.
+	Name string `json:"name"`
 }
 
 // +kubebuilder:object:root=true
@@ -18,6 +18,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"net/http"
@@ -31,10 +32,14 @@ import (
 	"github.com/spf13/pflag"
 	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1client "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	k8slabels "k8s.io/apimachinery/pkg/labels"
 	k8stypes "k8s.io/apimachinery/pkg/types"
 	apimachineryrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/discovery"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/klog/v2"
@@ -45,9 +50,11 @@ import (
 	crfinalizer "sigs.k8s.io/controller-runtime/pkg/finalizer"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	crwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	catalogd "github.com/operator-framework/catalogd/api/v1"
 	helmclient "github.com/operator-framework/helm-operator-plugins/pkg/client"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
 
 	ocv1 "github.com/operator-framework/operator-controller/api/v1"
 	"github.com/operator-framework/operator-controller/internal/action"
@@ -61,10 +68,16 @@ import (
 	"github.com/operator-framework/operator-controller/internal/finalizers"
 	"github.com/operator-framework/operator-controller/internal/httputil"
 	"github.com/operator-framework/operator-controller/internal/resolve"
+	"github.com/operator-framework/operator-controller/internal/rukpak/certprovider"
+	"github.com/operator-framework/operator-controller/internal/rukpak/convert"
 	"github.com/operator-framework/operator-controller/internal/rukpak/preflights/crdupgradesafety"
+	"github.com/operator-framework/operator-controller/internal/rukpak/preflights/kubeversion"
+	"github.com/operator-framework/operator-controller/internal/rukpak/preflights/serviceaccount"
 	"github.com/operator-framework/operator-controller/internal/rukpak/source"
 	"github.com/operator-framework/operator-controller/internal/scheme"
+	"github.com/operator-framework/operator-controller/internal/uninstall"
 	"github.com/operator-framework/operator-controller/internal/version"
+	"github.com/operator-framework/operator-controller/internal/webhook"
 )
 
 var (
@@ -74,6 +87,13 @@ var (
 
 const authFilePrefix = "operator-controller-global-pull-secrets"
 
+// The providers of the serving certificates of bundle webhooks and APIServices.
+const (
+	webhookCertProviderCertManager = "cert-manager"
+	webhookCertProviderSelfSigned  = "self-signed"
+	webhookCertProviderNone        = "none"
+)
+
 // podNamespace checks whether the controller is running in a Pod vs.
 // being run locally by inspecting the namespace file that gets mounted
 // automatically for Pods at runtime. If that file doesn't exist, then
@@ -96,6 +116,8 @@ func main() {
 		systemNamespace           string
 		caCertDir                 string
 		globalPullSecret          string
+		webhookCertProvider       string
+		webhookServerCertDir      string
 	)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -107,6 +129,12 @@ func main() {
 	flag.BoolVar(&operatorControllerVersion, "version", false, "Prints operator-controller version information")
 	flag.StringVar(&systemNamespace, "system-namespace", "", "Configures the namespace that gets used to deploy system resources.")
 	flag.StringVar(&globalPullSecret, "global-pull-secret", "", "The <namespace>/<name> of the global pull secret that is going to be used to pull bundle images.")
+	flag.StringVar(&webhookCertProvider, "webhook-cert-provider", webhookCertProviderCertManager,
+		fmt.Sprintf("The provider of the serving certificates of bundle webhooks and APIServices: %q, %q or %q to reject bundles with them.",
+			webhookCertProviderCertManager, webhookCertProviderSelfSigned, webhookCertProviderNone))
+
+	flag.StringVar(&webhookServerCertDir, "webhook-server-cert-dir", "",
+		"The directory of the tls.crt and tls.key serving certificate of the webhook validating ClusterExtensions on admission. The webhook is not served when empty.")
 
 	klog.InitFlags(flag.CommandLine)
 
@@ -168,6 +196,7 @@ func main() {
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "9c4404e7.operatorframework.io",
 		Cache:                  cacheOptions,
+		WebhookServer:          crwebhook.NewServer(crwebhook.Options{CertDir: webhookServerCertDir}),
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -190,6 +219,23 @@ func main() {
 		setupLog.Error(err, "unable to create core client")
 		os.Exit(1)
 	}
+
+	var (
+		certProvider       convert.CertificateProvider
+		selfSignedProvider *certprovider.SelfSigned
+	)
+	switch webhookCertProvider {
+	case webhookCertProviderCertManager:
+		certProvider = certprovider.CertManager{}
+	case webhookCertProviderSelfSigned:
+		selfSignedProvider = certprovider.NewSelfSigned(certprovider.WithSecretStore(coreClient.Secrets(systemNamespace)))
+		certProvider = selfSignedProvider
+	case webhookCertProviderNone:
+	default:
+		setupLog.Error(fmt.Errorf("unknown provider %q", webhookCertProvider), "invalid value of webhook-cert-provider")
+		os.Exit(1)
+	}
+
 	tokenGetter := authentication.NewTokenGetter(coreClient, authentication.WithExpirationDuration(1*time.Hour))
 	clientRestConfigMapper := action.ServiceAccountRestConfigMapper(tokenGetter)
 
@@ -240,11 +286,23 @@ func main() {
 
 	clusterExtensionFinalizers := crfinalizer.NewFinalizers()
 	if err := clusterExtensionFinalizers.Register(controllers.ClusterExtensionCleanupUnpackCacheFinalizer, finalizers.FinalizerFunc(func(ctx context.Context, obj client.Object) (crfinalizer.Result, error) {
-		return crfinalizer.Result{}, unpacker.Cleanup(ctx, &source.BundleSource{Name: obj.GetName()})
+		return crfinalizer.Result{}, errors.Join(
+			unpacker.Cleanup(ctx, &source.BundleSource{Name: obj.GetName()}),
+			unpacker.Cleanup(ctx, &source.BundleSource{Name: controllers.PendingUpgradeUnpackName(obj.GetName())}),
+			unpacker.Cleanup(ctx, &source.BundleSource{Name: resolve.ResolveUnpackName(obj.GetName())}),
+		)
 	})); err != nil {
 		setupLog.Error(err, "unable to register finalizer", "finalizerKey", controllers.ClusterExtensionCleanupUnpackCacheFinalizer)
 		os.Exit(1)
 	}
+	if selfSignedProvider != nil {
+		if err := clusterExtensionFinalizers.Register(controllers.ClusterExtensionCleanupCertificatesFinalizer, finalizers.FinalizerFunc(func(ctx context.Context, obj client.Object) (crfinalizer.Result, error) {
+			return crfinalizer.Result{}, selfSignedProvider.Delete(ctx, obj.GetName())
+		})); err != nil {
+			setupLog.Error(err, "unable to register finalizer", "finalizerKey", controllers.ClusterExtensionCleanupCertificatesFinalizer)
+			os.Exit(1)
+		}
+	}
 
 	cl := mgr.GetClient()
 
@@ -258,19 +316,72 @@ func main() {
 		return httputil.BuildHTTPClient(certPoolWatcher)
 	})
 
-	resolver := &resolve.CatalogResolver{
-		WalkCatalogsFunc: resolve.CatalogWalker(
-			func(ctx context.Context, option ...client.ListOption) ([]catalogd.ClusterCatalog, error) {
-				var catalogs catalogd.ClusterCatalogList
-				if err := cl.List(ctx, &catalogs, option...); err != nil {
-					return nil, err
-				}
-				return catalogs.Items, nil
-			},
-			catalogClient.GetPackage,
-		),
-		Validations: []resolve.ValidationFunc{
-			resolve.NoDependencyValidation,
+	listCatalogs := func(ctx context.Context, option ...client.ListOption) ([]catalogd.ClusterCatalog, error) {
+		var catalogs catalogd.ClusterCatalogList
+		if err := cl.List(ctx, &catalogs, option...); err != nil {
+			return nil, err
+		}
+		return catalogs.Items, nil
+	}
+	walkCatalogs := resolve.CatalogWalker(listCatalogs, catalogClient.GetPackage)
+	walkAllCatalogs := resolve.CatalogWalker(listCatalogs, func(ctx context.Context, catalog *catalogd.ClusterCatalog, _ string) (*declcfg.DeclarativeConfig, error) {
+		return catalogClient.GetCatalog(ctx, catalog)
+	})
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create discovery client")
+		os.Exit(1)
+	}
+	clusterFacts := &resolve.ClusterFacts{
+		Client:        cl,
+		RESTMapper:    mgr.GetRESTMapper(),
+		ServerVersion: discoveryClient.ServerVersion,
+	}
+
+	catalogResolver := &resolve.CatalogResolver{
+		WalkCatalogsFunc: walkCatalogs,
+		FactsFunc:        clusterFacts.Facts,
+	}
+
+	pullSecretGetter := func(ctx context.Context, ext *ocv1.ClusterExtension) ([]byte, error) {
+		// The pull secret is read with the ServiceAccount of the ClusterExtension
+		// so that only secrets it is permitted to read can be used.
+		cfg, err := clientRestConfigMapper(ctx, ext, mgr.GetConfig())
+		if err != nil {
+			return nil, err
+		}
+		saCoreClient, err := corev1client.NewForConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		secret, err := saCoreClient.Secrets(ext.Spec.Namespace).Get(ctx, ext.Spec.Source.Image.PullSecret, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if secret.Type != corev1.SecretTypeDockerConfigJson {
+			return nil, fmt.Errorf("secret %q has type %q, expected %q", secret.Name, secret.Type, corev1.SecretTypeDockerConfigJson)
+		}
+		return secret.Data[corev1.DockerConfigJsonKey], nil
+	}
+
+	imageResolver := &resolve.ImageResolver{
+		Unpacker:         unpacker,
+		PullSecretGetter: pullSecretGetter,
+		FactsFunc:        clusterFacts.Facts,
+	}
+
+	resolver := resolve.MultiResolver{
+		ocv1.SourceTypeCatalog: catalogResolver,
+		ocv1.SourceTypeImage:   imageResolver,
+	}
+
+	dependencyResolver := &resolve.CatalogDependencyResolver{
+		Client:           cl,
+		RESTMapper:       mgr.GetRESTMapper(),
+		WalkCatalogsFunc: walkCatalogs,
+		WalkAllCatalogsFunc: func(ctx context.Context, f resolve.CatalogWalkFunc, option ...client.ListOption) error {
+			return walkAllCatalogs(ctx, "", f, option...)
 		},
 	}
 
@@ -280,13 +391,26 @@ func main() {
 		os.Exit(1)
 	}
 
+	authorizationClient, err := authorizationv1client.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create authorization client")
+		os.Exit(1)
+	}
+
 	preflights := []applier.Preflight{
 		crdupgradesafety.NewPreflight(aeClient.CustomResourceDefinitions()),
+		kubeversion.NewPreflight(discoveryClient),
+		serviceaccount.NewPreflight(cl, mgr.GetRESTMapper(), authorizationClient.SubjectAccessReviews()),
 	}
 
 	applier := &applier.Helm{
 		ActionClientGetter: acg,
 		Preflights:         preflights,
+		RollbackPreflights: []applier.Preflight{
+			crdupgradesafety.NewStoredVersionPreflight(aeClient.CustomResourceDefinitions()),
+		},
+		CertificateProvider: certProvider,
+		HealthChecker:       &applier.ActionConfigHealthChecker{ActionConfigGetter: cfgGetter},
 	}
 
 	cm := contentmanager.NewManager(clientRestConfigMapper, mgr.GetConfig(), mgr.GetRESTMapper())
@@ -300,19 +424,66 @@ func main() {
 		os.Exit(1)
 	}
 
+	installedBundleGetter := &controllers.DefaultInstalledBundleGetter{ActionClientGetter: acg}
+	uninstaller := &uninstall.Uninstaller{
+		ClientFor: func(ctx context.Context, ext *ocv1.ClusterExtension) (client.Client, error) {
+			saKey := k8stypes.NamespacedName{Namespace: ext.Spec.Namespace, Name: ext.Spec.ServiceAccount.Name}
+			if _, err := tokenGetter.Get(ctx, saKey); err != nil {
+				if apierrors.IsNotFound(err) {
+					return nil, fmt.Errorf("%w: %v", uninstall.ErrServiceAccountNotFound, err)
+				}
+				return nil, err
+			}
+			cfg, err := clientRestConfigMapper(ctx, ext, mgr.GetConfig())
+			if err != nil {
+				return nil, err
+			}
+			return client.New(cfg, client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+		},
+		ManagedObjectsFor: func(ctx context.Context, ext *ocv1.ClusterExtension) ([]ocv1.ManagedObjectReference, error) {
+			installedBundle, err := installedBundleGetter.GetInstalledBundle(ctx, ext)
+			if err != nil || installedBundle == nil {
+				return nil, err
+			}
+			return installedBundle.ManagedObjects, nil
+		},
+		Recorder: mgr.GetEventRecorderFor("operator-controller"),
+	}
+	err = clusterExtensionFinalizers.Register(controllers.ClusterExtensionUninstallFinalizer, finalizers.FinalizerFunc(func(ctx context.Context, obj client.Object) (crfinalizer.Result, error) {
+		return crfinalizer.Result{}, uninstaller.Uninstall(ctx, obj.(*ocv1.ClusterExtension))
+	}))
+	if err != nil {
+		setupLog.Error(err, "unable to register uninstall finalizer")
+		os.Exit(1)
+	}
+
 	if err = (&controllers.ClusterExtensionReconciler{
 		Client:                cl,
 		Resolver:              resolver,
+		DependencyResolver:    dependencyResolver,
 		Unpacker:              unpacker,
 		Applier:               applier,
-		InstalledBundleGetter: &controllers.DefaultInstalledBundleGetter{ActionClientGetter: acg},
+		InstalledBundleGetter: installedBundleGetter,
 		Finalizers:            clusterExtensionFinalizers,
 		Manager:               cm,
+		Recorder:              mgr.GetEventRecorderFor("operator-controller"),
+		PermissionPreviewer:   applier,
+		Planner:               applier,
+		PullSecretGetter:      pullSecretGetter,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ClusterExtension")
 		os.Exit(1)
 	}
 
+	if webhookServerCertDir != "" {
+		if err = (&webhook.ClusterExtension{
+			Resolver: resolver,
+		}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ClusterExtension")
+			os.Exit(1)
+		}
+	}
+
 	if err = (&controllers.ClusterCatalogReconciler{
 		Client:                cl,
 		CatalogCache:          catalogClientBackend,
@@ -0,0 +1,163 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// olmv1-rbac prints the ClusterRole, Roles and bindings that the installer
+// ServiceAccount of a ClusterExtension needs to install and manage a
+// registry+v1 bundle, given as an unpacked bundle directory or a bundle image.
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/containers/image/v5/types"
+	"github.com/go-logr/logr"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-controller/internal/rukpak/certprovider"
+	"github.com/operator-framework/operator-controller/internal/rukpak/convert"
+	"github.com/operator-framework/operator-controller/internal/rukpak/source"
+)
+
+// The providers of the serving certificates of bundle webhooks and APIServices.
+const (
+	webhookCertProviderCertManager = "cert-manager"
+	webhookCertProviderSelfSigned  = "self-signed"
+	webhookCertProviderNone        = "none"
+)
+
+func main() {
+	var (
+		namespace           string
+		watchNamespaces     []string
+		extensionName       string
+		serviceAccountName  string
+		webhookCertProvider string
+	)
+	flags := pflag.NewFlagSet("olmv1-rbac", pflag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: olmv1-rbac [flags] <bundle directory or image>\n\n")
+		fmt.Fprintf(os.Stderr, "Prints the RBAC the installer ServiceAccount of a ClusterExtension needs to install and manage a registry+v1 bundle.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.StringVar(&namespace, "namespace", "", "The namespace the bundle is installed into, as spec.namespace of the ClusterExtension. Defaults to the namespace suggested by the bundle.")
+	flags.StringSliceVar(&watchNamespaces, "watch-namespaces", nil,
+		"The namespaces the extension watches, as spec.install.watchNamespaces of the ClusterExtension, which select the install mode. Defaults to all namespaces, or to the install namespace if the bundle only supports the OwnNamespace install mode.")
+	flags.StringVar(&extensionName, "cluster-extension-name", "", "The name of the ClusterExtension. Defaults to the package name of the bundle.")
+	flags.StringVar(&serviceAccountName, "service-account", "", "The name of the installer ServiceAccount, in the install namespace. Defaults to <cluster-extension-name>-installer.")
+	flags.StringVar(&webhookCertProvider, "webhook-cert-provider", webhookCertProviderCertManager,
+		fmt.Sprintf("The provider of the serving certificates of bundle webhooks and APIServices configured on operator-controller: %q, %q or %q.",
+			webhookCertProviderCertManager, webhookCertProviderSelfSigned, webhookCertProviderNone))
+	_ = flags.Parse(os.Args[1:])
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(context.Background(), flags.Arg(0), namespace, watchNamespaces, extensionName, serviceAccountName, webhookCertProvider); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, bundleRef, namespace string, watchNamespaces []string, extensionName, serviceAccountName, webhookCertProvider string) error {
+	var opts []convert.Option
+	switch webhookCertProvider {
+	case webhookCertProviderCertManager:
+		opts = append(opts, convert.WithCertificateProvider(certprovider.CertManager{}))
+	case webhookCertProviderSelfSigned:
+		opts = append(opts, convert.WithCertificateProvider(certprovider.NewSelfSigned()))
+	case webhookCertProviderNone:
+	default:
+		return fmt.Errorf("unknown webhook certificate provider %q", webhookCertProvider)
+	}
+
+	bundleFS, cleanup, err := openBundle(ctx, bundleRef)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	reg, err := convert.ParseFS(ctx, bundleFS)
+	if err != nil {
+		return err
+	}
+	if extensionName == "" {
+		extensionName = reg.PackageName
+	}
+	if serviceAccountName == "" {
+		serviceAccountName = extensionName + "-installer"
+	}
+	objs, err := convert.InstallerRBAC(reg, namespace, watchNamespaces, convert.Installer{
+		ClusterExtensionName: extensionName,
+		ServiceAccountName:   serviceAccountName,
+	}, opts...)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objs {
+		out, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(os.Stdout, "---\n"+string(out)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openBundle returns the content of the bundle at ref, which is either the
+// path of an unpacked bundle directory or the reference of a bundle image,
+// which is pulled with the registry configuration of the host. The returned
+// function removes what was pulled.
+func openBundle(ctx context.Context, ref string) (fs.FS, func(), error) {
+	if info, err := os.Stat(ref); err == nil && info.IsDir() {
+		return os.DirFS(ref), func() {}, nil
+	}
+
+	cachePath, err := os.MkdirTemp("", "olmv1-rbac-")
+	if err != nil {
+		return nil, nil, err
+	}
+	unpacker := &source.ContainersImageRegistry{
+		BaseCachePath: cachePath,
+		SourceContextFunc: func(logr.Logger) (*types.SystemContext, error) {
+			return &types.SystemContext{}, nil
+		},
+	}
+	bundleSource := &source.BundleSource{
+		Name:  "bundle",
+		Type:  source.SourceTypeImage,
+		Image: &source.ImageSource{Ref: ref},
+	}
+	cleanup := func() {
+		// Unpacked bundles are read-only, which the unpacker takes care of.
+		_ = unpacker.Cleanup(ctx, bundleSource)
+		_ = os.RemoveAll(cachePath)
+	}
+	result, err := unpacker.Unpack(ctx, bundleSource)
+	if err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("error pulling bundle image %q: %w", ref, err)
+	}
+	return result.Bundle, cleanup, nil
+}
@@ -22,11 +22,10 @@ func (o Olmv1Err) Cause() error {
 	return o.originalErr
 }
 
-func newOlmv1Err(originalErr error, message string) error {
-	return &Olmv1Err{
-		originalErr: originalErr,
-		message:     message,
-	}
+// InstallConflictError is returned when an object of a bundle already exists
+// and cannot be managed by operator-controller.
+type InstallConflictError struct {
+	Olmv1Err
 }
 
 func AsOlmErr(originalErr error) error {
@@ -65,7 +64,10 @@ func helmInstallConflictErr(originalErr error) error {
 	kind := matches[1]
 	name := matches[2]
 	namespace := matches[3]
-	return newOlmv1Err(originalErr, fmt.Sprintf("%s '%s' already exists in namespace '%s' and cannot be managed by operator-controller", kind, name, namespace))
+	return &InstallConflictError{Olmv1Err{
+		originalErr: originalErr,
+		message:     fmt.Sprintf("%s '%s' already exists in namespace '%s' and cannot be managed by operator-controller", kind, name, namespace),
+	}}
 }
 
 func defaultErrTranslator(originalErr error) error {
@@ -7,14 +7,16 @@ import (
 
 func TestAsOlmErr(t *testing.T) {
 	tests := []struct {
-		name     string
-		err      error
-		expected error
+		name            string
+		err             error
+		expected        error
+		installConflict bool
 	}{
 		{
-			name:     "Install conflict error (match)",
-			err:      errors.New("Unable to continue with install: Deployment \"my-deploy\" in namespace \"my-namespace\" exists and cannot be imported into the current release"),
-			expected: errors.New("Deployment 'my-deploy' already exists in namespace 'my-namespace' and cannot be managed by operator-controller"),
+			name:            "Install conflict error (match)",
+			err:             errors.New("Unable to continue with install: Deployment \"my-deploy\" in namespace \"my-namespace\" exists and cannot be imported into the current release"),
+			expected:        errors.New("Deployment 'my-deploy' already exists in namespace 'my-namespace' and cannot be managed by operator-controller"),
+			installConflict: true,
 		},
 		{
 			name:     "Install conflict error (no match)",
@@ -39,6 +41,10 @@ func TestAsOlmErr(t *testing.T) {
 			if result != nil && result.Error() != tt.expected.Error() {
 				t.Errorf("Expected: %v, got: %v", tt.expected, result)
 			}
+			var installConflict *InstallConflictError
+			if errors.As(result, &installConflict) != tt.installConflict {
+				t.Errorf("Expected install conflict: %v, got: %v", tt.installConflict, result)
+			}
 		})
 	}
 }
@@ -0,0 +1,106 @@
+package applier
+
+import (
+	"slices"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+	"github.com/operator-framework/operator-controller/internal/rukpak/util"
+)
+
+// applyDeploymentConfigToObject applies cfg to obj when obj is a Deployment.
+// Other objects are left untouched.
+func applyDeploymentConfigToObject(obj *unstructured.Unstructured, cfg *ocv1.DeploymentConfig) error {
+	if cfg == nil || obj.GroupVersionKind().GroupKind() != appsv1.SchemeGroupVersion.WithKind("Deployment").GroupKind() {
+		return nil
+	}
+	dep := appsv1.Deployment{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &dep); err != nil {
+		return err
+	}
+	applyDeploymentConfig(&dep, cfg)
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&dep)
+	if err != nil {
+		return err
+	}
+	obj.Object = u
+	return nil
+}
+
+// applyDeploymentConfig overrides the pod template of dep with cfg, following
+// the semantics of the config field of an OLM v0 Subscription.
+func applyDeploymentConfig(dep *appsv1.Deployment, cfg *ocv1.DeploymentConfig) {
+	podSpec := &dep.Spec.Template.Spec
+	// Like OLM v0, only the containers are configured, not the init containers.
+	for i := range podSpec.Containers {
+		applyContainerConfig(&podSpec.Containers[i], cfg)
+	}
+
+	for _, toleration := range cfg.Tolerations {
+		if !slices.ContainsFunc(podSpec.Tolerations, func(t corev1.Toleration) bool { return equality.Semantic.DeepEqual(t, toleration) }) {
+			podSpec.Tolerations = append(podSpec.Tolerations, toleration)
+		}
+	}
+
+	if cfg.NodeSelector != nil {
+		podSpec.NodeSelector = cfg.NodeSelector
+	}
+
+	if cfg.Affinity != nil {
+		if podSpec.Affinity == nil {
+			podSpec.Affinity = &corev1.Affinity{}
+		}
+		if cfg.Affinity.NodeAffinity != nil {
+			podSpec.Affinity.NodeAffinity = cfg.Affinity.NodeAffinity
+		}
+		if cfg.Affinity.PodAffinity != nil {
+			podSpec.Affinity.PodAffinity = cfg.Affinity.PodAffinity
+		}
+		if cfg.Affinity.PodAntiAffinity != nil {
+			podSpec.Affinity.PodAntiAffinity = cfg.Affinity.PodAntiAffinity
+		}
+	}
+
+	for _, volume := range cfg.Volumes {
+		if i := slices.IndexFunc(podSpec.Volumes, func(v corev1.Volume) bool { return v.Name == volume.Name }); i >= 0 {
+			podSpec.Volumes[i] = volume
+			continue
+		}
+		podSpec.Volumes = append(podSpec.Volumes, volume)
+	}
+
+	if len(cfg.Annotations) > 0 {
+		// Annotations defined by the bundle take precedence over the configured ones.
+		dep.SetAnnotations(util.MergeMaps(cfg.Annotations, dep.GetAnnotations()))
+		dep.Spec.Template.SetAnnotations(util.MergeMaps(cfg.Annotations, dep.Spec.Template.GetAnnotations()))
+	}
+}
+
+func applyContainerConfig(container *corev1.Container, cfg *ocv1.DeploymentConfig) {
+	for _, env := range cfg.Env {
+		if i := slices.IndexFunc(container.Env, func(e corev1.EnvVar) bool { return e.Name == env.Name }); i >= 0 {
+			container.Env[i] = env
+			continue
+		}
+		container.Env = append(container.Env, env)
+	}
+
+	container.EnvFrom = append(container.EnvFrom, cfg.EnvFrom...)
+
+	if cfg.Resources != nil {
+		container.Resources = *cfg.Resources
+	}
+
+	for _, volumeMount := range cfg.VolumeMounts {
+		if i := slices.IndexFunc(container.VolumeMounts, func(m corev1.VolumeMount) bool { return m.Name == volumeMount.Name }); i >= 0 {
+			container.VolumeMounts[i] = volumeMount
+			continue
+		}
+		container.VolumeMounts = append(container.VolumeMounts, volumeMount)
+	}
+}
@@ -0,0 +1,237 @@
+package applier
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	apimachyaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+)
+
+func testDeployment() *appsv1.Deployment {
+	return &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+			Kind:       "Deployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-operator",
+			Namespace:   "test-ns",
+			Annotations: map[string]string{"bundle": "value"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{"olm.targetNamespaces": ""},
+				},
+				Spec: corev1.PodSpec{
+					InitContainers: []corev1.Container{{
+						Name: "setup",
+						Env:  []corev1.EnvVar{{Name: "KEEP", Value: "bundle"}},
+					}},
+					Containers: []corev1.Container{{
+						Name: "manager",
+						Env: []corev1.EnvVar{
+							{Name: "KEEP", Value: "bundle"},
+							{Name: "HTTP_PROXY", Value: "bundle"},
+						},
+						VolumeMounts: []corev1.VolumeMount{{Name: "certs", MountPath: "/bundle"}},
+						Resources: corev1.ResourceRequirements{
+							Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+						},
+					}},
+					Volumes:      []corev1.Volume{{Name: "certs", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}},
+					Tolerations:  []corev1.Toleration{{Key: "bundle", Operator: corev1.TolerationOpExists}},
+					NodeSelector: map[string]string{"kubernetes.io/os": "linux"},
+					Affinity: &corev1.Affinity{
+						PodAntiAffinity: &corev1.PodAntiAffinity{},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestApplyDeploymentConfig(t *testing.T) {
+	nodeAffinity := &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "node-role.kubernetes.io/infra", Operator: corev1.NodeSelectorOpExists}},
+			}},
+		},
+	}
+	resources := &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")},
+	}
+
+	for _, tc := range []struct {
+		name   string
+		config *ocv1.DeploymentConfig
+		verify func(*testing.T, *appsv1.Deployment)
+	}{
+		{
+			name:   "empty config leaves the deployment unchanged",
+			config: &ocv1.DeploymentConfig{},
+			verify: func(t *testing.T, dep *appsv1.Deployment) {
+				assert.Equal(t, testDeployment(), dep)
+			},
+		},
+		{
+			name: "env replaces variables with the same name and appends others",
+			config: &ocv1.DeploymentConfig{
+				Env: []corev1.EnvVar{
+					{Name: "HTTP_PROXY", Value: "config"},
+					{Name: "NO_PROXY", Value: "config"},
+				},
+			},
+			verify: func(t *testing.T, dep *appsv1.Deployment) {
+				assert.Equal(t, []corev1.EnvVar{
+					{Name: "KEEP", Value: "bundle"},
+					{Name: "HTTP_PROXY", Value: "config"},
+					{Name: "NO_PROXY", Value: "config"},
+				}, dep.Spec.Template.Spec.Containers[0].Env)
+			},
+		},
+		{
+			name: "envFrom is appended",
+			config: &ocv1.DeploymentConfig{
+				EnvFrom: []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "proxy"}}}},
+			},
+			verify: func(t *testing.T, dep *appsv1.Deployment) {
+				assert.Equal(t, []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "proxy"}}}}, dep.Spec.Template.Spec.Containers[0].EnvFrom)
+			},
+		},
+		{
+			name:   "resources replace the container resources",
+			config: &ocv1.DeploymentConfig{Resources: resources},
+			verify: func(t *testing.T, dep *appsv1.Deployment) {
+				assert.Equal(t, *resources, dep.Spec.Template.Spec.Containers[0].Resources)
+			},
+		},
+		{
+			name: "tolerations are appended without duplicates",
+			config: &ocv1.DeploymentConfig{
+				Tolerations: []corev1.Toleration{
+					{Key: "bundle", Operator: corev1.TolerationOpExists},
+					{Key: "node-role.kubernetes.io/infra", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+				},
+			},
+			verify: func(t *testing.T, dep *appsv1.Deployment) {
+				assert.Equal(t, []corev1.Toleration{
+					{Key: "bundle", Operator: corev1.TolerationOpExists},
+					{Key: "node-role.kubernetes.io/infra", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+				}, dep.Spec.Template.Spec.Tolerations)
+			},
+		},
+		{
+			name:   "nodeSelector replaces the node selector",
+			config: &ocv1.DeploymentConfig{NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""}},
+			verify: func(t *testing.T, dep *appsv1.Deployment) {
+				assert.Equal(t, map[string]string{"node-role.kubernetes.io/infra": ""}, dep.Spec.Template.Spec.NodeSelector)
+			},
+		},
+		{
+			name:   "affinity replaces only the specified constraints",
+			config: &ocv1.DeploymentConfig{Affinity: &corev1.Affinity{NodeAffinity: nodeAffinity}},
+			verify: func(t *testing.T, dep *appsv1.Deployment) {
+				assert.Equal(t, &corev1.Affinity{
+					NodeAffinity:    nodeAffinity,
+					PodAntiAffinity: &corev1.PodAntiAffinity{},
+				}, dep.Spec.Template.Spec.Affinity)
+			},
+		},
+		{
+			name: "volumes and volumeMounts replace entries with the same name and append others",
+			config: &ocv1.DeploymentConfig{
+				Volumes: []corev1.Volume{
+					{Name: "certs", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "certs"}}},
+					{Name: "trusted-ca", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "trusted-ca"}}}},
+				},
+				VolumeMounts: []corev1.VolumeMount{
+					{Name: "certs", MountPath: "/config"},
+					{Name: "trusted-ca", MountPath: "/etc/pki/ca-trust"},
+				},
+			},
+			verify: func(t *testing.T, dep *appsv1.Deployment) {
+				assert.Equal(t, []corev1.Volume{
+					{Name: "certs", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "certs"}}},
+					{Name: "trusted-ca", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "trusted-ca"}}}},
+				}, dep.Spec.Template.Spec.Volumes)
+				assert.Equal(t, []corev1.VolumeMount{
+					{Name: "certs", MountPath: "/config"},
+					{Name: "trusted-ca", MountPath: "/etc/pki/ca-trust"},
+				}, dep.Spec.Template.Spec.Containers[0].VolumeMounts)
+			},
+		},
+		{
+			name: "init containers are left unchanged",
+			config: &ocv1.DeploymentConfig{
+				Env:          []corev1.EnvVar{{Name: "HTTP_PROXY", Value: "config"}},
+				EnvFrom:      []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "proxy"}}}},
+				Resources:    resources,
+				VolumeMounts: []corev1.VolumeMount{{Name: "certs", MountPath: "/config"}},
+			},
+			verify: func(t *testing.T, dep *appsv1.Deployment) {
+				assert.Equal(t, testDeployment().Spec.Template.Spec.InitContainers, dep.Spec.Template.Spec.InitContainers)
+			},
+		},
+		{
+			name:   "annotations are added without overriding the bundle",
+			config: &ocv1.DeploymentConfig{Annotations: map[string]string{"bundle": "config", "config": "value"}},
+			verify: func(t *testing.T, dep *appsv1.Deployment) {
+				assert.Equal(t, map[string]string{"bundle": "value", "config": "value"}, dep.GetAnnotations())
+				assert.Equal(t, map[string]string{"olm.targetNamespaces": "", "bundle": "config", "config": "value"}, dep.Spec.Template.GetAnnotations())
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dep := testDeployment()
+			applyDeploymentConfig(dep, tc.config)
+			tc.verify(t, dep)
+		})
+	}
+}
+
+func TestPostRendererAppliesDeploymentConfig(t *testing.T) {
+	dep, err := runtime.DefaultUnstructuredConverter.ToUnstructured(testDeployment())
+	require.NoError(t, err)
+	svc, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&corev1.Service{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test-service", Namespace: "test-ns"},
+	})
+	require.NoError(t, err)
+
+	var in bytes.Buffer
+	for _, obj := range []map[string]interface{}{dep, svc} {
+		b, err := json.Marshal(obj)
+		require.NoError(t, err)
+		in.Write(b)
+	}
+
+	p := &postrenderer{
+		labels:           map[string]string{"owner": "test"},
+		deploymentConfig: &ocv1.DeploymentConfig{NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""}},
+	}
+	out, err := p.Run(&in)
+	require.NoError(t, err)
+
+	dec := apimachyaml.NewYAMLOrJSONDecoder(out, 1024)
+	gotDep := appsv1.Deployment{}
+	require.NoError(t, dec.Decode(&gotDep))
+	assert.Equal(t, map[string]string{"node-role.kubernetes.io/infra": ""}, gotDep.Spec.Template.Spec.NodeSelector)
+	assert.Equal(t, map[string]string{"owner": "test"}, gotDep.GetLabels())
+
+	gotSvc := corev1.Service{}
+	require.NoError(t, dec.Decode(&gotSvc))
+	assert.Equal(t, "test-service", gotSvc.GetName())
+	assert.Equal(t, map[string]string{"owner": "test"}, gotSvc.GetLabels())
+}
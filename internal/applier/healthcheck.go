@@ -0,0 +1,124 @@
+package applier
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	helmclient "github.com/operator-framework/helm-operator-plugins/pkg/client"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+)
+
+// healthCheckDeadlineKey is the storage label set on a release upgraded with a
+// health check timeout until its workloads are ready. Its value is the time, in
+// RFC 3339 format, after which the upgrade is rolled back if they are not.
+const healthCheckDeadlineKey = "olm.operatorframework.io/health-check-deadline"
+
+// HealthChecker checks whether the workloads of an upgraded release are ready
+// and records the outcome of the check in the release.
+type HealthChecker interface {
+	// Ready reports whether the objects of rel are ready.
+	Ready(ctx context.Context, ext *ocv1.ClusterExtension, rel *release.Release) (bool, error)
+	// Update stores rel in place of the stored release with the same revision.
+	Update(ctx context.Context, ext *ocv1.ClusterExtension, rel *release.Release) error
+}
+
+// HealthCheckPendingError is returned by Apply, along with the objects of the
+// release, while the workloads of an upgraded release are not ready and its
+// health check deadline has not passed yet.
+type HealthCheckPendingError struct {
+	Deadline time.Time
+}
+
+func (e *HealthCheckPendingError) Error() string {
+	return fmt.Sprintf("waiting until %s for the upgraded workloads to become ready", e.Deadline.UTC().Format(time.RFC3339))
+}
+
+// healthCheckTimeout returns the health check timeout of the upgrades of ext,
+// or 0 if they are not health checked.
+func (h *Helm) healthCheckTimeout(ext *ocv1.ClusterExtension) time.Duration {
+	if h.HealthChecker == nil || !rollbackOnFailure(ext) || ext.Spec.Install.Rollback.HealthCheckTimeout == nil {
+		return 0
+	}
+	return ext.Spec.Install.Rollback.HealthCheckTimeout.Duration
+}
+
+// checkUpgradeHealth checks the workloads of rel, which was upgraded with a
+// health check deadline. The deadline is removed once they are ready, and the
+// release is rolled back if they are still not ready when it passes.
+func (h *Helm) checkUpgradeHealth(ctx context.Context, ac helmclient.ActionInterface, ext *ocv1.ClusterExtension, rel *release.Release) error {
+	deadline, err := time.Parse(time.RFC3339, rel.Labels[healthCheckDeadlineKey])
+	if err != nil {
+		return fmt.Errorf("parsing health check deadline of revision %d: %w", rel.Version, err)
+	}
+	ready, err := h.HealthChecker.Ready(ctx, ext, rel)
+	if err != nil {
+		return fmt.Errorf("checking health of revision %d: %w", rel.Version, err)
+	}
+	if !ready && time.Now().Before(deadline) {
+		return &HealthCheckPendingError{Deadline: deadline}
+	}
+
+	delete(rel.Labels, healthCheckDeadlineKey)
+	if ready {
+		return h.HealthChecker.Update(ctx, ext, rel)
+	}
+
+	log.FromContext(ctx).Info("upgraded workloads not ready by the health check deadline", "revision", rel.Version, "deadline", deadline)
+	rel.Info.Status = release.StatusFailed
+	rel.Info.Description = fmt.Sprintf("Upgrade %q failed: workloads not ready by the health check deadline %s", rel.Name, deadline.UTC().Format(time.RFC3339))
+	if err := h.HealthChecker.Update(ctx, ext, rel); err != nil {
+		return fmt.Errorf("recording failed health check of revision %d: %w", rel.Version, err)
+	}
+	_, err = h.rollback(ctx, ac, ext, rel, errors.New(rel.Info.Description))
+	return err
+}
+
+// ActionConfigHealthChecker checks the readiness of the objects of a release the
+// same way Helm does when it waits for an upgrade, as the ServiceAccount of the
+// ClusterExtension, but without waiting.
+type ActionConfigHealthChecker struct {
+	ActionConfigGetter helmclient.ActionConfigGetter
+}
+
+func (c *ActionConfigHealthChecker) Ready(ctx context.Context, ext *ocv1.ClusterExtension, rel *release.Release) (bool, error) {
+	cfg, err := c.ActionConfigGetter.ActionConfigFor(ctx, ext)
+	if err != nil {
+		return false, err
+	}
+	kubeClient, ok := cfg.KubeClient.(*kube.Client)
+	if !ok {
+		return false, fmt.Errorf("unexpected kube client type %T", cfg.KubeClient)
+	}
+	clientset, err := kubeClient.Factory.KubernetesClientSet()
+	if err != nil {
+		return false, err
+	}
+	resources, err := kubeClient.Build(bytes.NewBufferString(rel.Manifest), false)
+	if err != nil {
+		return false, err
+	}
+	checker := kube.NewReadyChecker(clientset, func(string, ...interface{}) {}, kube.PausedAsReady(true))
+	for _, info := range resources {
+		ready, err := checker.IsReady(ctx, info)
+		if err != nil || !ready {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (c *ActionConfigHealthChecker) Update(ctx context.Context, ext *ocv1.ClusterExtension, rel *release.Release) error {
+	cfg, err := c.ActionConfigGetter.ActionConfigFor(ctx, ext)
+	if err != nil {
+		return err
+	}
+	return cfg.Releases.Update(rel)
+}
@@ -0,0 +1,112 @@
+package applier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+)
+
+type fakeHealthChecker struct {
+	ready   bool
+	updated *release.Release
+}
+
+func (f *fakeHealthChecker) Ready(context.Context, *ocv1.ClusterExtension, *release.Release) (bool, error) {
+	return f.ready, nil
+}
+
+func (f *fakeHealthChecker) Update(_ context.Context, _ *ocv1.ClusterExtension, rel *release.Release) error {
+	f.updated = rel
+	return nil
+}
+
+// testHealthCheckHistory returns a history whose latest revision was upgraded
+// with the given health check deadline.
+func testHealthCheckHistory(deadline time.Time) []*release.Release {
+	return []*release.Release{
+		{
+			Name:     "test",
+			Version:  2,
+			Manifest: "upgraded-manifest",
+			Labels:   map[string]string{"bundle": "v2", healthCheckDeadlineKey: deadline.UTC().Format(time.RFC3339)},
+			Info:     &release.Info{Status: release.StatusDeployed},
+		},
+		{
+			Name:     "test",
+			Version:  1,
+			Chart:    &chart.Chart{Metadata: &chart.Metadata{Name: "test"}},
+			Manifest: "superseded-manifest",
+			Labels:   map[string]string{"bundle": "v1"},
+			Info:     &release.Info{Status: release.StatusSuperseded},
+		},
+	}
+}
+
+func TestCheckUpgradeHealthReady(t *testing.T) {
+	history := testHealthCheckHistory(time.Now().Add(time.Minute))
+	ac := &fakeActionClient{history: history}
+	hc := &fakeHealthChecker{ready: true}
+	h := &Helm{HealthChecker: hc}
+
+	require.NoError(t, h.checkUpgradeHealth(context.Background(), ac, testRollbackClusterExtension(), history[0]))
+	require.NotNil(t, hc.updated)
+	assert.Equal(t, map[string]string{"bundle": "v2"}, hc.updated.Labels)
+	assert.Equal(t, release.StatusDeployed, hc.updated.Info.Status)
+	assert.Nil(t, ac.upgraded)
+}
+
+func TestCheckUpgradeHealthPending(t *testing.T) {
+	deadline := time.Now().Add(time.Minute).Truncate(time.Second)
+	history := testHealthCheckHistory(deadline)
+	ac := &fakeActionClient{history: history}
+	hc := &fakeHealthChecker{}
+	h := &Helm{HealthChecker: hc}
+
+	err := h.checkUpgradeHealth(context.Background(), ac, testRollbackClusterExtension(), history[0])
+	var pending *HealthCheckPendingError
+	require.ErrorAs(t, err, &pending)
+	assert.True(t, deadline.Equal(pending.Deadline))
+	assert.Nil(t, hc.updated)
+	assert.Nil(t, ac.upgraded)
+}
+
+func TestCheckUpgradeHealthDeadlinePassed(t *testing.T) {
+	deadline := time.Now().Add(-time.Minute)
+	history := testHealthCheckHistory(deadline)
+	ac := &fakeActionClient{history: history}
+	hc := &fakeHealthChecker{}
+	h := &Helm{HealthChecker: hc}
+
+	err := h.checkUpgradeHealth(context.Background(), ac, testRollbackClusterExtension(), history[0])
+	var rolledBack *RolledBackError
+	require.ErrorAs(t, err, &rolledBack)
+	assert.Equal(t, 2, rolledBack.FailedRevision)
+
+	t.Log("It records the failed health check in the upgraded release")
+	require.NotNil(t, hc.updated)
+	assert.Equal(t, release.StatusFailed, hc.updated.Info.Status)
+	assert.Equal(t, `Upgrade "test" failed: workloads not ready by the health check deadline `+deadline.UTC().Format(time.RFC3339), hc.updated.Info.Description)
+	assert.Equal(t, map[string]string{"bundle": "v2"}, hc.updated.Labels)
+
+	t.Log("It rolls back to the superseded revision")
+	require.NotNil(t, ac.upgraded)
+	assert.Equal(t, "superseded-manifest", ac.upgraded.Manifest)
+	assert.Equal(t, map[string]string{"bundle": "v1", rolledBackFromRevisionKey: "2"}, ac.upgraded.Labels)
+}
+
+func TestCheckUpgradeHealthInvalidDeadline(t *testing.T) {
+	history := testHealthCheckHistory(time.Now())
+	history[0].Labels[healthCheckDeadlineKey] = "soon"
+	h := &Helm{HealthChecker: &fakeHealthChecker{}}
+
+	err := h.checkUpgradeHealth(context.Background(), &fakeActionClient{history: history}, testRollbackClusterExtension(), history[0])
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "parsing health check deadline of revision 2")
+}
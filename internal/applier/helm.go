@@ -3,11 +3,15 @@ package applier
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"strings"
+	"sync"
+	"time"
 
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
@@ -15,7 +19,6 @@ import (
 	"helm.sh/helm/v3/pkg/postrender"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/storage/driver"
-	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	apimachyaml "k8s.io/apimachinery/pkg/util/yaml"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -26,6 +29,8 @@ import (
 	ocv1 "github.com/operator-framework/operator-controller/api/v1"
 	"github.com/operator-framework/operator-controller/internal/rukpak/convert"
 	"github.com/operator-framework/operator-controller/internal/rukpak/preflights/crdupgradesafety"
+	"github.com/operator-framework/operator-controller/internal/rukpak/preflights/kubeversion"
+	"github.com/operator-framework/operator-controller/internal/rukpak/preflights/serviceaccount"
 	"github.com/operator-framework/operator-controller/internal/rukpak/util"
 )
 
@@ -52,33 +57,81 @@ type Preflight interface {
 	Upgrade(context.Context, *release.Release) error
 }
 
+// PreflightError is returned when a preflight check fails. No changes are
+// made to the cluster when a preflight check fails.
+type PreflightError struct {
+	Err error
+}
+
+func (e *PreflightError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PreflightError) Unwrap() error {
+	return e.Err
+}
+
 type Helm struct {
 	ActionClientGetter helmclient.ActionClientGetter
 	Preflights         []Preflight
+	// RollbackPreflights are run against the revision a failed upgrade is
+	// rolled back to. A rollback is refused if any of them fails.
+	RollbackPreflights []Preflight
+	// CertificateProvider provisions the serving certificates of the webhooks
+	// and APIServices of registry+v1 bundles. Bundles with webhooks or
+	// APIServices are rejected when it is nil.
+	CertificateProvider convert.CertificateProvider
+	// HealthChecker checks the workloads of the upgrades of ClusterExtensions
+	// with an automatic rollback policy and a health check timeout. Upgrades
+	// are not health checked when it is nil.
+	HealthChecker HealthChecker
+
+	// checkedReleases holds, per ClusterExtension, the digest of the last
+	// release that passed the client-only preflights, so that they are not
+	// run again on every reconcile of an unchanged release.
+	checkedReleases   map[string]string
+	checkedReleasesMu sync.Mutex
 }
 
-// shouldSkipPreflight is a helper to determine if the preflight check is CRDUpgradeSafety AND
-// if it is set to enforcement None.
+// shouldSkipPreflight is a helper to determine if the preflight check is CRDUpgradeSafety,
+// ServiceAccount or KubeVersion AND if it is set to enforcement None.
 func shouldSkipPreflight(ctx context.Context, preflight Preflight, ext *ocv1.ClusterExtension, state string) bool {
 	l := log.FromContext(ctx)
-	hasCRDUpgradeSafety := ext.Spec.Install != nil && ext.Spec.Install.Preflight != nil && ext.Spec.Install.Preflight.CRDUpgradeSafety != nil
-	_, isCRDUpgradeSafetyInstance := preflight.(*crdupgradesafety.Preflight)
+	if ext.Spec.Install == nil || ext.Spec.Install.Preflight == nil {
+		return false
+	}
+	config := ext.Spec.Install.Preflight
 
-	if hasCRDUpgradeSafety && isCRDUpgradeSafetyInstance {
-		if state == StateNeedsInstall || state == StateNeedsUpgrade {
-			l.Info("crdUpgradeSafety ", "policy", ext.Spec.Install.Preflight.CRDUpgradeSafety.Enforcement)
+	switch preflight.(type) {
+	case *crdupgradesafety.Preflight:
+		if config.CRDUpgradeSafety == nil {
+			return false
 		}
-		if ext.Spec.Install.Preflight.CRDUpgradeSafety.Enforcement == ocv1.CRDUpgradeSafetyEnforcementNone {
-			// Skip this preflight check because it is of type *crdupgradesafety.Preflight and the CRD Upgrade Safety
-			// policy is set to None
-			return true
+		if state == StateNeedsInstall || state == StateNeedsUpgrade {
+			l.Info("crdUpgradeSafety ", "policy", config.CRDUpgradeSafety.Enforcement)
 		}
+		// Skip this preflight check because it is of type *crdupgradesafety.Preflight and the CRD Upgrade Safety
+		// policy is set to None
+		return config.CRDUpgradeSafety.Enforcement == ocv1.CRDUpgradeSafetyEnforcementNone
+	case *serviceaccount.Preflight:
+		return config.ServiceAccount != nil && config.ServiceAccount.Enforcement == ocv1.ServiceAccountEnforcementNone
+	case *kubeversion.Preflight:
+		return config.KubeVersion != nil && config.KubeVersion.Enforcement == ocv1.KubeVersionEnforcementNone
 	}
 	return false
 }
 
+// isClientOnlyPreflight reports whether preflight only needs the objects of a
+// release, so that it runs against a release rendered without contacting the
+// cluster, before the release is dry-run as the service account of the
+// ClusterExtension.
+func isClientOnlyPreflight(preflight Preflight) bool {
+	_, ok := preflight.(*serviceaccount.Preflight)
+	return ok
+}
+
 func (h *Helm) Apply(ctx context.Context, contentFS fs.FS, ext *ocv1.ClusterExtension, objectLabels map[string]string, storageLabels map[string]string) ([]client.Object, string, error) {
-	chrt, err := convert.RegistryV1ToHelmChart(ctx, contentFS, ext.Spec.Namespace, []string{corev1.NamespaceAll})
+	chrt, err := convert.RegistryV1ToHelmChart(ctx, contentFS, ext.Spec.Namespace, watchNamespaces(ext), h.convertOptions(ext)...)
 	if err != nil {
 		return nil, "", err
 	}
@@ -89,8 +142,13 @@ func (h *Helm) Apply(ctx context.Context, contentFS fs.FS, ext *ocv1.ClusterExte
 		return nil, "", err
 	}
 
-	post := &postrenderer{
-		labels: objectLabels,
+	post := newPostrenderer(ext, objectLabels)
+
+	// The release is dry-run as the service account of ext, which fails on the
+	// first request that is forbidden, so the permissions of the service account
+	// are checked beforehand.
+	if err := h.runClientOnlyPreflights(ctx, ext, chrt, values, post); err != nil {
+		return nil, "", err
 	}
 
 	rel, desiredRel, state, err := h.getReleaseState(ac, ext, chrt, values, post)
@@ -98,20 +156,30 @@ func (h *Helm) Apply(ctx context.Context, contentFS fs.FS, ext *ocv1.ClusterExte
 		return nil, "", err
 	}
 
+	if state == StateNeedsUpgrade && rollbackOnFailure(ext) {
+		rolledBack, err := previousRollback(ac, rel, desiredRel)
+		if err != nil {
+			return nil, state, err
+		}
+		if rolledBack != nil {
+			return nil, state, rolledBack
+		}
+	}
+
 	for _, preflight := range h.Preflights {
-		if shouldSkipPreflight(ctx, preflight, ext, state) {
+		if isClientOnlyPreflight(preflight) || shouldSkipPreflight(ctx, preflight, ext, state) {
 			continue
 		}
 		switch state {
 		case StateNeedsInstall:
 			err := preflight.Install(ctx, desiredRel)
 			if err != nil {
-				return nil, state, err
+				return nil, state, &PreflightError{Err: err}
 			}
 		case StateNeedsUpgrade:
 			err := preflight.Upgrade(ctx, desiredRel)
 			if err != nil {
-				return nil, state, err
+				return nil, state, &PreflightError{Err: err}
 			}
 		}
 	}
@@ -127,14 +195,28 @@ func (h *Helm) Apply(ctx context.Context, contentFS fs.FS, ext *ocv1.ClusterExte
 			return nil, state, err
 		}
 	case StateNeedsUpgrade:
-		rel, err = ac.Upgrade(ext.GetName(), ext.Spec.Namespace, chrt, values, func(upgrade *action.Upgrade) error {
+		upgradedRel, err := ac.Upgrade(ext.GetName(), ext.Spec.Namespace, chrt, values, func(upgrade *action.Upgrade) error {
 			upgrade.MaxHistory = maxHelmReleaseHistory
 			upgrade.Labels = storageLabels
+			// The upgrade does not wait for the workloads to be ready, so that
+			// reconciles are not blocked: they are checked on later reconciles
+			// until the deadline recorded in the release passes.
+			if timeout := h.healthCheckTimeout(ext); timeout > 0 {
+				upgrade.Labels = util.MergeMaps(storageLabels, map[string]string{
+					healthCheckDeadlineKey: time.Now().Add(timeout).UTC().Format(time.RFC3339),
+				})
+			}
 			return nil
 		}, helmclient.AppendUpgradePostRenderer(post))
 		if err != nil {
+			// A non-nil release means that the failed upgrade was recorded
+			// and that there is something to roll back.
+			if rollbackOnFailure(ext) && upgradedRel != nil {
+				_, err = h.rollback(ctx, ac, ext, upgradedRel, err)
+			}
 			return nil, state, err
 		}
+		rel = upgradedRel
 	case StateUnchanged:
 		if err := ac.Reconcile(rel); err != nil {
 			return nil, state, err
@@ -148,18 +230,99 @@ func (h *Helm) Apply(ctx context.Context, contentFS fs.FS, ext *ocv1.ClusterExte
 		return nil, state, err
 	}
 
+	if _, ok := rel.Labels[healthCheckDeadlineKey]; ok && h.HealthChecker != nil {
+		if err := h.checkUpgradeHealth(ctx, ac, ext, rel); err != nil {
+			return relObjects, state, err
+		}
+	}
+
 	return relObjects, state, nil
 }
 
+// runClientOnlyPreflights runs the client-only preflights against the release
+// of ext rendered without contacting the cluster. They are not run again for a
+// release that already passed them with the same service account.
+func (h *Helm) runClientOnlyPreflights(ctx context.Context, ext *ocv1.ClusterExtension, chrt *chart.Chart, values chartutil.Values, post postrender.PostRenderer) error {
+	var preflights []Preflight
+	for _, preflight := range h.Preflights {
+		if isClientOnlyPreflight(preflight) && !shouldSkipPreflight(ctx, preflight, ext, StateUnchanged) {
+			preflights = append(preflights, preflight)
+		}
+	}
+	if len(preflights) == 0 {
+		return nil
+	}
+
+	rel, err := renderRelease(ext, chrt, values, post)
+	if err != nil {
+		return err
+	}
+	digest := releaseDigest(ext, rel)
+	h.checkedReleasesMu.Lock()
+	checked := h.checkedReleases[ext.GetName()] == digest
+	h.checkedReleasesMu.Unlock()
+	if checked {
+		return nil
+	}
+
+	for _, preflight := range preflights {
+		// Whether the release is installed or upgraded is only known once it
+		// is dry-run, and both need the same objects to be checked.
+		if err := preflight.Install(ctx, rel); err != nil {
+			return &PreflightError{Err: err}
+		}
+	}
+
+	h.checkedReleasesMu.Lock()
+	defer h.checkedReleasesMu.Unlock()
+	if h.checkedReleases == nil {
+		h.checkedReleases = map[string]string{}
+	}
+	h.checkedReleases[ext.GetName()] = digest
+	return nil
+}
+
+// releaseDigest returns the digest of the manifest of rel, as applied by the
+// service account of ext.
+func releaseDigest(ext *ocv1.ClusterExtension, rel *release.Release) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s/%s\n%s", ext.Spec.Namespace, ext.Spec.ServiceAccount.Name, rel.Manifest)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// renderRelease renders the release of ext as an install without contacting
+// the cluster, so that it does not need any permission.
+func renderRelease(ext *ocv1.ClusterExtension, chrt *chart.Chart, values chartutil.Values, post postrender.PostRenderer) (*release.Release, error) {
+	install := action.NewInstall(&action.Configuration{Log: func(string, ...interface{}) {}})
+	install.ClientOnly = true
+	install.DryRun = true
+	install.ReleaseName = ext.GetName()
+	install.Namespace = ext.Spec.Namespace
+	install.PostRenderer = post
+	return install.Run(chrt, values)
+}
+
+func watchNamespaces(ext *ocv1.ClusterExtension) []string {
+	if ext.Spec.Install == nil {
+		return nil
+	}
+	return ext.Spec.Install.WatchNamespaces
+}
+
+func (h *Helm) convertOptions(ext *ocv1.ClusterExtension) []convert.Option {
+	var opts []convert.Option
+	if h.CertificateProvider != nil {
+		opts = append(opts, convert.WithCertificateProvider(h.CertificateProvider), convert.WithCertificateOwner(ext.GetName()))
+	}
+	return opts
+}
+
 func (h *Helm) getReleaseState(cl helmclient.ActionInterface, ext *ocv1.ClusterExtension, chrt *chart.Chart, values chartutil.Values, post postrender.PostRenderer) (*release.Release, *release.Release, string, error) {
 	currentRelease, err := cl.Get(ext.GetName())
 	if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
 		return nil, nil, StateError, err
 	}
-	if errors.Is(err, driver.ErrReleaseNotFound) {
-		return nil, nil, StateNeedsInstall, nil
-	}
-
+	// The desired release of an install is rendered too, for the preflights to check it.
 	if errors.Is(err, driver.ErrReleaseNotFound) {
 		desiredRelease, err := cl.Install(ext.GetName(), ext.Spec.Namespace, chrt, values, func(i *action.Install) error {
 			i.DryRun = true
@@ -190,8 +353,21 @@ func (h *Helm) getReleaseState(cl helmclient.ActionInterface, ext *ocv1.ClusterE
 }
 
 type postrenderer struct {
-	labels  map[string]string
-	cascade postrender.PostRenderer
+	labels           map[string]string
+	deploymentConfig *ocv1.DeploymentConfig
+	cascade          postrender.PostRenderer
+}
+
+// newPostrenderer returns the postrenderer adding objectLabels to the objects
+// of the release of ext and applying its deployment configuration.
+func newPostrenderer(ext *ocv1.ClusterExtension, objectLabels map[string]string) *postrenderer {
+	post := &postrenderer{
+		labels: objectLabels,
+	}
+	if ext.Spec.Install != nil {
+		post.deploymentConfig = ext.Spec.Install.Config
+	}
+	return post
 }
 
 func (p *postrenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
@@ -206,6 +382,9 @@ func (p *postrenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, erro
 		if err != nil {
 			return nil, err
 		}
+		if err := applyDeploymentConfigToObject(&obj, p.deploymentConfig); err != nil {
+			return nil, err
+		}
 		obj.SetLabels(util.MergeMaps(obj.GetLabels(), p.labels))
 		b, err := obj.MarshalJSON()
 		if err != nil {
@@ -0,0 +1,70 @@
+package applier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+	"github.com/operator-framework/operator-controller/internal/rukpak/preflights/serviceaccount"
+	"github.com/operator-framework/operator-controller/internal/scheme"
+)
+
+type countingSARClient struct {
+	reviews int
+}
+
+func (c *countingSARClient) Create(_ context.Context, sar *authorizationv1.SubjectAccessReview, _ metav1.CreateOptions) (*authorizationv1.SubjectAccessReview, error) {
+	c.reviews++
+	sar.Status.Allowed = true
+	return sar, nil
+}
+
+func TestRunClientOnlyPreflightsOncePerRelease(t *testing.T) {
+	ext := &ocv1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: ocv1.ClusterExtensionSpec{
+			Namespace:      "test-ns",
+			ServiceAccount: ocv1.ServiceAccountReference{Name: "test-installer"},
+		},
+	}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+	sarClient := &countingSARClient{}
+	h := &Helm{Preflights: []Preflight{
+		serviceaccount.NewPreflight(fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(ext).Build(), mapper, sarClient),
+	}}
+	chrt := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "test", Version: "1.0.0"},
+		Templates: []*chart.File{{
+			Name: "templates/configmap.yaml",
+			Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: test\n  namespace: test-ns\n"),
+		}},
+	}
+
+	require.NoError(t, h.runClientOnlyPreflights(context.Background(), ext, chrt, chartutil.Values{}, newPostrenderer(ext, nil)))
+	reviews := sarClient.reviews
+	require.Positive(t, reviews)
+
+	t.Log("It does not check an unchanged release again")
+	require.NoError(t, h.runClientOnlyPreflights(context.Background(), ext, chrt, chartutil.Values{}, newPostrenderer(ext, nil)))
+	assert.Equal(t, reviews, sarClient.reviews)
+
+	t.Log("It checks the release again when the objects change")
+	require.NoError(t, h.runClientOnlyPreflights(context.Background(), ext, chrt, chartutil.Values{}, newPostrenderer(ext, map[string]string{"label": "value"})))
+	assert.Equal(t, 2*reviews, sarClient.reviews)
+
+	t.Log("It checks the release again when the service account changes")
+	ext.Spec.ServiceAccount.Name = "other-installer"
+	require.NoError(t, h.runClientOnlyPreflights(context.Background(), ext, chrt, chartutil.Values{}, newPostrenderer(ext, map[string]string{"label": "value"})))
+	assert.Equal(t, 3*reviews, sarClient.reviews)
+}
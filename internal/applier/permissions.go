@@ -0,0 +1,368 @@
+package applier
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/storage/driver"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+	"github.com/operator-framework/operator-controller/internal/rukpak/convert"
+	"github.com/operator-framework/operator-controller/internal/rukpak/util"
+)
+
+// PermissionChanges returns the rules of the Roles and ClusterRoles, and the
+// bindings of the RoleBindings and ClusterRoleBindings, of the bundle in
+// contentFS that are added or removed compared to the installed release of
+// ext. Everything in the bundle is added when nothing is installed. It also
+// reports whether the bundle grants any permission that the installed release
+// does not, which a rule or binding being added does not imply.
+func (h *Helm) PermissionChanges(ctx context.Context, contentFS fs.FS, ext *ocv1.ClusterExtension) (*ocv1.PermissionChanges, bool, error) {
+	reg, err := convert.ParseFS(ctx, contentFS)
+	if err != nil {
+		return nil, false, err
+	}
+	plain, err := convert.Convert(reg, ext.Spec.Namespace, watchNamespaces(ext), h.convertOptions(ext)...)
+	if err != nil {
+		return nil, false, err
+	}
+
+	ac, err := h.ActionClientGetter.ActionClientFor(ctx, ext)
+	if err != nil {
+		return nil, false, err
+	}
+	var installedObjs []client.Object
+	rel, err := ac.Get(ext.GetName())
+	switch {
+	case errors.Is(err, driver.ErrReleaseNotFound):
+	case err != nil:
+		return nil, false, err
+	default:
+		installedObjs, err = util.ManifestObjects(strings.NewReader(rel.Manifest), fmt.Sprintf("%s-release-manifest", rel.Name))
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	changes, err := diffPermissions(installedObjs, plain.Objects)
+	if err != nil {
+		return nil, false, err
+	}
+	escalates, err := escalatesPermissions(installedObjs, plain.Objects)
+	if err != nil {
+		return nil, false, err
+	}
+	return changes, escalates, nil
+}
+
+// diffPermissions returns the rules and bindings of desired that are not
+// rules and bindings of installed, and the other way around.
+func diffPermissions(installed, desired []client.Object) (*ocv1.PermissionChanges, error) {
+	installedPermissions, err := permissionsOf(installed)
+	if err != nil {
+		return nil, err
+	}
+	desiredPermissions, err := permissionsOf(desired)
+	if err != nil {
+		return nil, err
+	}
+	return &ocv1.PermissionChanges{
+		Added:           missing(desiredPermissions.rules, installedPermissions.rules, func(r ocv1.PermissionRule) string { return r.Namespace }),
+		Removed:         missing(installedPermissions.rules, desiredPermissions.rules, func(r ocv1.PermissionRule) string { return r.Namespace }),
+		AddedBindings:   missing(desiredPermissions.bindings, installedPermissions.bindings, func(b ocv1.PermissionBinding) string { return b.Namespace }),
+		RemovedBindings: missing(installedPermissions.bindings, desiredPermissions.bindings, func(b ocv1.PermissionBinding) string { return b.Namespace }),
+	}, nil
+}
+
+// escalatesPermissions reports whether desired grants any permission that
+// installed does not. That is the case when a rule of a role of desired is not
+// allowed by the roles of installed in the same namespace, when a rule granted
+// to a subject by desired is not granted to it by installed in the same
+// namespace or cluster-wide, or when desired binds a subject to a role outside
+// of desired that installed does not bind it to. Narrowing, splitting or
+// merging rules does not grant any permission.
+func escalatesPermissions(installed, desired []client.Object) (bool, error) {
+	installedPermissions, err := permissionsOf(installed)
+	if err != nil {
+		return false, err
+	}
+	desiredPermissions, err := permissionsOf(desired)
+	if err != nil {
+		return false, err
+	}
+	for namespace, rules := range desiredPermissions.roleRules {
+		for _, rule := range rules {
+			if !rulesAllow(installedPermissions.roleRules[namespace], rule) {
+				return true, nil
+			}
+		}
+	}
+	for key, rules := range desiredPermissions.grants {
+		installedRules := slices.Concat(installedPermissions.grants[key], installedPermissions.grants[grant{subject: key.subject}])
+		for _, rule := range rules {
+			if !rulesAllow(installedRules, rule) {
+				return true, nil
+			}
+		}
+	}
+	return !installedPermissions.externalBindings.IsSuperset(desiredPermissions.externalBindings), nil
+}
+
+// rulesAllow reports whether rules allow everything rule allows. rule is
+// broken down into a single verb on a single API group, resource and resource
+// name, or on a single non-resource URL, each of which must be allowed by one
+// of rules. A wildcard in rule is only allowed by a wildcard in rules.
+func rulesAllow(rules []rbacv1.PolicyRule, rule rbacv1.PolicyRule) bool {
+	resourceNames := rule.ResourceNames
+	if len(resourceNames) == 0 {
+		// A rule without resource names is only allowed by rules without resource names.
+		resourceNames = []string{""}
+	}
+	for _, verb := range rule.Verbs {
+		for _, url := range rule.NonResourceURLs {
+			if !slices.ContainsFunc(rules, func(r rbacv1.PolicyRule) bool {
+				return matches(r.Verbs, verb) && nonResourceURLMatches(r.NonResourceURLs, url)
+			}) {
+				return false
+			}
+		}
+		for _, group := range rule.APIGroups {
+			for _, resource := range rule.Resources {
+				for _, name := range resourceNames {
+					if !slices.ContainsFunc(rules, func(r rbacv1.PolicyRule) bool {
+						return matches(r.Verbs, verb) && matches(r.APIGroups, group) && resourceMatches(r.Resources, resource) &&
+							(len(r.ResourceNames) == 0 || name != "" && slices.Contains(r.ResourceNames, name))
+					}) {
+						return false
+					}
+				}
+			}
+		}
+	}
+	return true
+}
+
+// matches reports whether values contain value or the "*" wildcard.
+func matches(values []string, value string) bool {
+	return slices.Contains(values, "*") || slices.Contains(values, value)
+}
+
+// resourceMatches reports whether resources contain resource, the "*" wildcard
+// or the "*/<subresource>" wildcard for the subresource of resource.
+func resourceMatches(resources []string, resource string) bool {
+	if matches(resources, resource) {
+		return true
+	}
+	_, subresource, ok := strings.Cut(resource, "/")
+	return ok && slices.Contains(resources, "*/"+subresource)
+}
+
+// nonResourceURLMatches reports whether urls contain url or a prefix of url
+// followed by the "*" wildcard.
+func nonResourceURLMatches(urls []string, url string) bool {
+	return slices.ContainsFunc(urls, func(u string) bool {
+		return u == url || strings.HasSuffix(u, "*") && strings.HasPrefix(url, strings.TrimSuffix(u, "*"))
+	})
+}
+
+// missing returns the values of a whose keys are not keys of b, sorted by
+// namespace and key.
+func missing[T any](a, b map[string]T, namespace func(T) string) []T {
+	keys := make([]string, 0, len(a))
+	for key := range a {
+		if _, ok := b[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	slices.SortFunc(keys, func(x, y string) int {
+		return cmp.Or(cmp.Compare(namespace(a[x]), namespace(a[y])), cmp.Compare(x, y))
+	})
+	values := make([]T, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, a[key])
+	}
+	return values
+}
+
+// permissions are the rules and bindings of a set of objects, keyed so that
+// equivalent rules and bindings have the same key.
+type permissions struct {
+	rules    map[string]ocv1.PermissionRule
+	bindings map[string]ocv1.PermissionBinding
+
+	// roleRules are the rules of the roles, by namespace
+	roleRules map[string][]rbacv1.PolicyRule
+	// grants are the rules that the roles among the objects grant to each
+	// subject, by the namespace they are granted in
+	grants map[grant][]rbacv1.PolicyRule
+	// externalBindings are the keys of the bindings to roles that are not
+	// among the objects
+	externalBindings sets.Set[string]
+}
+
+// grant identifies the rules granted to a subject in a namespace, or
+// cluster-wide when the namespace is empty.
+type grant struct {
+	namespace string
+	subject   string
+}
+
+// permissionsOf returns the rules of the Roles and ClusterRoles among objs,
+// keyed by their namespace and normalized rule, and the bindings of the
+// RoleBindings and ClusterRoleBindings among objs, keyed by their namespace,
+// role and subject. The names of the roles and bindings among objs are left
+// out, since they change with their rules: a role among objs is identified
+// by its kind, namespace and normalized rules instead.
+func permissionsOf(objs []client.Object) (*permissions, error) {
+	p := &permissions{
+		rules:            map[string]ocv1.PermissionRule{},
+		bindings:         map[string]ocv1.PermissionBinding{},
+		roleRules:        map[string][]rbacv1.PolicyRule{},
+		grants:           map[grant][]rbacv1.PolicyRule{},
+		externalBindings: sets.New[string](),
+	}
+	// The keys and rules of the roles among objs, by kind, namespace and name
+	type roleName struct{ kind, namespace, name string }
+	roleKeys := map[roleName]string{}
+	rolesRules := map[roleName][]rbacv1.PolicyRule{}
+	var bindings []client.Object
+	for _, obj := range objs {
+		var (
+			kind        = obj.GetObjectKind().GroupVersionKind().Kind
+			namespace   string
+			policyRules []rbacv1.PolicyRule
+		)
+		switch obj.GetObjectKind().GroupVersionKind().GroupKind() {
+		case rbacv1.SchemeGroupVersion.WithKind("Role").GroupKind():
+			role := &rbacv1.Role{}
+			if err := toTyped(obj, role); err != nil {
+				return nil, err
+			}
+			namespace, policyRules = role.Namespace, role.Rules
+		case rbacv1.SchemeGroupVersion.WithKind("ClusterRole").GroupKind():
+			clusterRole := &rbacv1.ClusterRole{}
+			if err := toTyped(obj, clusterRole); err != nil {
+				return nil, err
+			}
+			policyRules = clusterRole.Rules
+		case rbacv1.SchemeGroupVersion.WithKind("RoleBinding").GroupKind(),
+			rbacv1.SchemeGroupVersion.WithKind("ClusterRoleBinding").GroupKind():
+			// Bindings are keyed once the keys of all the roles are known
+			bindings = append(bindings, obj)
+			continue
+		default:
+			continue
+		}
+		ruleKeys := make([]string, 0, len(policyRules))
+		for _, rule := range policyRules {
+			rule = normalizeRule(rule)
+			key, err := json.Marshal(rule)
+			if err != nil {
+				return nil, err
+			}
+			p.rules[namespace+"/"+string(key)] = ocv1.PermissionRule{Namespace: namespace, Rule: rule}
+			ruleKeys = append(ruleKeys, string(key))
+		}
+		slices.Sort(ruleKeys)
+		roleKeys[roleName{kind, namespace, obj.GetName()}] = fmt.Sprintf("%s/%s/[%s]", kind, namespace, strings.Join(slices.Compact(ruleKeys), ","))
+		rolesRules[roleName{kind, namespace, obj.GetName()}] = policyRules
+		p.roleRules[namespace] = append(p.roleRules[namespace], policyRules...)
+	}
+
+	for _, obj := range bindings {
+		var (
+			namespace string
+			roleRef   rbacv1.RoleRef
+			subjects  []rbacv1.Subject
+		)
+		if obj.GetObjectKind().GroupVersionKind().Kind == "RoleBinding" {
+			roleBinding := &rbacv1.RoleBinding{}
+			if err := toTyped(obj, roleBinding); err != nil {
+				return nil, err
+			}
+			namespace, roleRef, subjects = roleBinding.Namespace, roleBinding.RoleRef, roleBinding.Subjects
+		} else {
+			clusterRoleBinding := &rbacv1.ClusterRoleBinding{}
+			if err := toTyped(obj, clusterRoleBinding); err != nil {
+				return nil, err
+			}
+			roleRef, subjects = clusterRoleBinding.RoleRef, clusterRoleBinding.Subjects
+		}
+
+		// A Role is in the namespace of its RoleBinding, a ClusterRole is not namespaced.
+		roleNamespace := namespace
+		if roleRef.Kind == "ClusterRole" {
+			roleNamespace = ""
+		}
+		key, ok := roleKeys[roleName{roleRef.Kind, roleNamespace, roleRef.Name}]
+		external := !ok || roleRef.APIGroup != rbacv1.GroupName
+		if external {
+			// The role does not belong to objs, so it is identified by its name.
+			ref, err := json.Marshal(roleRef)
+			if err != nil {
+				return nil, err
+			}
+			key = string(ref)
+		}
+		for _, subject := range subjects {
+			subjectKey, err := json.Marshal(subject)
+			if err != nil {
+				return nil, err
+			}
+			bindingKey := namespace + "/" + key + "/" + string(subjectKey)
+			p.bindings[bindingKey] = ocv1.PermissionBinding{Namespace: namespace, RoleRef: roleRef, Subject: subject}
+			if external {
+				p.externalBindings.Insert(bindingKey)
+			} else {
+				granted := grant{namespace: namespace, subject: string(subjectKey)}
+				p.grants[granted] = append(p.grants[granted], rolesRules[roleName{roleRef.Kind, roleNamespace, roleRef.Name}]...)
+			}
+		}
+	}
+	return p, nil
+}
+
+// toTyped converts obj, which is either typed or unstructured, into out.
+func toTyped(obj client.Object, out runtime.Object) error {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return fmt.Errorf("error converting %s %q: %w", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName(), err)
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u, out); err != nil {
+		return fmt.Errorf("error converting %s %q: %w", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName(), err)
+	}
+	return nil
+}
+
+// normalizeRule returns a copy of rule with sorted and deduplicated lists, so
+// that equivalent rules compare equal.
+func normalizeRule(rule rbacv1.PolicyRule) rbacv1.PolicyRule {
+	normalize := func(s []string) []string {
+		if len(s) == 0 {
+			return nil
+		}
+		s = slices.Clone(s)
+		slices.Sort(s)
+		return slices.Compact(s)
+	}
+	return rbacv1.PolicyRule{
+		Verbs:           normalize(rule.Verbs),
+		APIGroups:       normalize(rule.APIGroups),
+		Resources:       normalize(rule.Resources),
+		ResourceNames:   normalize(rule.ResourceNames),
+		NonResourceURLs: normalize(rule.NonResourceURLs),
+	}
+}
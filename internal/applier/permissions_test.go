@@ -0,0 +1,280 @@
+package applier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+)
+
+func TestDiffPermissions(t *testing.T) {
+	clusterRole := func(name string, rules ...rbacv1.PolicyRule) *rbacv1.ClusterRole {
+		return &rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{Kind: "ClusterRole", APIVersion: rbacv1.SchemeGroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Rules:      rules,
+		}
+	}
+	role := func(namespace, name string, rules ...rbacv1.PolicyRule) *rbacv1.Role {
+		return &rbacv1.Role{
+			TypeMeta:   metav1.TypeMeta{Kind: "Role", APIVersion: rbacv1.SchemeGroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Rules:      rules,
+		}
+	}
+	// Installed objects are read from the release manifest, so they are unstructured.
+	unstructuredObj := func(obj client.Object) client.Object {
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		require.NoError(t, err)
+		return &unstructured.Unstructured{Object: u}
+	}
+	readPods := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list", "watch"}}
+	readSecrets := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}}
+	writeDeployments := rbacv1.PolicyRule{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"create", "update"}}
+
+	installed := []client.Object{
+		unstructuredObj(clusterRole("foo.v1.0.0-manager-abc", readPods, readSecrets)),
+		unstructuredObj(role("ns-a", "foo.v1.0.0-manager-def", readSecrets)),
+		unstructuredObj(&rbacv1.ClusterRoleBinding{
+			TypeMeta:   metav1.TypeMeta{Kind: "ClusterRoleBinding", APIVersion: rbacv1.SchemeGroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Name: "foo.v1.0.0-manager-abc"},
+		}),
+	}
+	desired := []client.Object{
+		// Renamed roles with equivalent rules are unchanged.
+		clusterRole("foo.v1.1.0-manager-123",
+			rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"watch", "list", "get", "get"}},
+			writeDeployments,
+		),
+		role("ns-a", "foo.v1.1.0-manager-456", readSecrets),
+		role("ns-b", "foo.v1.1.0-manager-456", readSecrets),
+	}
+
+	changes, err := diffPermissions(installed, desired)
+	require.NoError(t, err)
+	assert.Equal(t, []ocv1.PermissionRule{
+		{Rule: writeDeployments},
+		{Namespace: "ns-b", Rule: readSecrets},
+	}, changes.Added)
+	assert.Equal(t, []ocv1.PermissionRule{
+		{Rule: readSecrets},
+	}, changes.Removed)
+
+	t.Log("Nothing changes when the rules are the same")
+	changes, err = diffPermissions(installed, installed)
+	require.NoError(t, err)
+	assert.Equal(t, &ocv1.PermissionChanges{}, changes)
+
+	t.Log("Every rule is added when nothing is installed")
+	changes, err = diffPermissions(nil, desired)
+	require.NoError(t, err)
+	assert.Len(t, changes.Added, 4)
+	assert.Nil(t, changes.Removed)
+}
+
+func TestDiffPermissionsBindings(t *testing.T) {
+	clusterRole := func(name string, rules ...rbacv1.PolicyRule) *rbacv1.ClusterRole {
+		return &rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{Kind: "ClusterRole", APIVersion: rbacv1.SchemeGroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Rules:      rules,
+		}
+	}
+	clusterRoleBinding := func(name, roleName string, subjects ...rbacv1.Subject) *rbacv1.ClusterRoleBinding {
+		return &rbacv1.ClusterRoleBinding{
+			TypeMeta:   metav1.TypeMeta{Kind: "ClusterRoleBinding", APIVersion: rbacv1.SchemeGroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: roleName},
+			Subjects:   subjects,
+		}
+	}
+	roleBinding := func(namespace, name string, roleRef rbacv1.RoleRef, subjects ...rbacv1.Subject) *rbacv1.RoleBinding {
+		return &rbacv1.RoleBinding{
+			TypeMeta:   metav1.TypeMeta{Kind: "RoleBinding", APIVersion: rbacv1.SchemeGroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			RoleRef:    roleRef,
+			Subjects:   subjects,
+		}
+	}
+	serviceAccount := func(name string) rbacv1.Subject {
+		return rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: "foo-system", Name: name}
+	}
+	readPods := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}
+	view := rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "view"}
+	admin := rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "admin"}
+
+	installed := []client.Object{
+		clusterRole("foo.v1.0.0-manager-abc", readPods),
+		clusterRoleBinding("foo.v1.0.0-manager-abc", "foo.v1.0.0-manager-abc", serviceAccount("manager")),
+		roleBinding("ns-a", "foo-view", view, serviceAccount("manager")),
+	}
+
+	t.Log("Renamed roles with equivalent rules bound to the same subjects are unchanged")
+	changes, err := diffPermissions(installed, []client.Object{
+		clusterRole("foo.v1.1.0-manager-123", readPods),
+		clusterRoleBinding("foo.v1.1.0-manager-123", "foo.v1.1.0-manager-123", serviceAccount("manager")),
+		roleBinding("ns-a", "foo-view", view, serviceAccount("manager")),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &ocv1.PermissionChanges{}, changes)
+
+	t.Log("Binding roles to other subjects, or other roles outside of the bundle, is reported")
+	changes, err = diffPermissions(installed, []client.Object{
+		clusterRole("foo.v1.1.0-manager-123", readPods),
+		clusterRoleBinding("foo.v1.1.0-manager-123", "foo.v1.1.0-manager-123", serviceAccount("manager"), serviceAccount("helper")),
+		roleBinding("ns-a", "foo-view", admin, serviceAccount("manager")),
+	})
+	require.NoError(t, err)
+	assert.Nil(t, changes.Added)
+	assert.Nil(t, changes.Removed)
+	assert.Equal(t, []ocv1.PermissionBinding{
+		{
+			RoleRef: rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "foo.v1.1.0-manager-123"},
+			Subject: serviceAccount("helper"),
+		},
+		{Namespace: "ns-a", RoleRef: admin, Subject: serviceAccount("manager")},
+	}, changes.AddedBindings)
+	assert.Equal(t, []ocv1.PermissionBinding{
+		{Namespace: "ns-a", RoleRef: view, Subject: serviceAccount("manager")},
+	}, changes.RemovedBindings)
+
+	t.Log("Bindings are reported for roles whose rules change")
+	changes, err = diffPermissions(installed, []client.Object{
+		clusterRole("foo.v1.1.0-manager-123", readPods, rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}}),
+		clusterRoleBinding("foo.v1.1.0-manager-123", "foo.v1.1.0-manager-123", serviceAccount("manager")),
+		roleBinding("ns-a", "foo-view", view, serviceAccount("manager")),
+	})
+	require.NoError(t, err)
+	assert.Len(t, changes.Added, 1)
+	assert.Len(t, changes.AddedBindings, 1)
+	assert.Len(t, changes.RemovedBindings, 1)
+}
+
+func TestEscalatesPermissions(t *testing.T) {
+	clusterRole := func(name string, rules ...rbacv1.PolicyRule) *rbacv1.ClusterRole {
+		return &rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{Kind: "ClusterRole", APIVersion: rbacv1.SchemeGroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Rules:      rules,
+		}
+	}
+	role := func(namespace, name string, rules ...rbacv1.PolicyRule) *rbacv1.Role {
+		return &rbacv1.Role{
+			TypeMeta:   metav1.TypeMeta{Kind: "Role", APIVersion: rbacv1.SchemeGroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Rules:      rules,
+		}
+	}
+	clusterRoleBinding := func(name, roleName string, subjects ...rbacv1.Subject) *rbacv1.ClusterRoleBinding {
+		return &rbacv1.ClusterRoleBinding{
+			TypeMeta:   metav1.TypeMeta{Kind: "ClusterRoleBinding", APIVersion: rbacv1.SchemeGroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: roleName},
+			Subjects:   subjects,
+		}
+	}
+	roleBinding := func(namespace, name string, roleRef rbacv1.RoleRef, subjects ...rbacv1.Subject) *rbacv1.RoleBinding {
+		return &rbacv1.RoleBinding{
+			TypeMeta:   metav1.TypeMeta{Kind: "RoleBinding", APIVersion: rbacv1.SchemeGroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			RoleRef:    roleRef,
+			Subjects:   subjects,
+		}
+	}
+	manager := rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: "foo-system", Name: "manager"}
+	helper := rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: "foo-system", Name: "helper"}
+	rule := func(verbs []string, group, resource string, resourceNames ...string) rbacv1.PolicyRule {
+		return rbacv1.PolicyRule{APIGroups: []string{group}, Resources: []string{resource}, Verbs: verbs, ResourceNames: resourceNames}
+	}
+	readPods := rule([]string{"get", "list", "watch"}, "", "pods")
+
+	installed := []client.Object{
+		clusterRole("foo.v1.0.0-manager-abc", readPods, rule([]string{"*"}, "apps", "*")),
+		clusterRoleBinding("foo.v1.0.0-manager-abc", "foo.v1.0.0-manager-abc", manager),
+		role("ns-a", "foo.v1.0.0-manager-def", rule([]string{"get"}, "", "secrets")),
+		roleBinding("ns-a", "foo.v1.0.0-manager-def", rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "foo.v1.0.0-manager-def"}, manager),
+		roleBinding("ns-a", "foo-view", rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "view"}, manager),
+	}
+
+	for _, tc := range []struct {
+		name      string
+		desired   []client.Object
+		escalates bool
+	}{
+		{
+			name:    "nothing changes",
+			desired: installed,
+		},
+		{
+			name: "rules are narrowed, split and covered by wildcards",
+			desired: []client.Object{
+				clusterRole("foo.v1.1.0-manager-123",
+					rule([]string{"get", "list"}, "", "pods"),
+					rule([]string{"watch"}, "", "pods", "foo"),
+					rule([]string{"create", "delete"}, "apps", "deployments"),
+					rule([]string{"update"}, "apps", "deployments/scale"),
+				),
+				clusterRoleBinding("foo.v1.1.0-manager-123", "foo.v1.1.0-manager-123", manager),
+				role("ns-a", "foo.v1.1.0-manager-456", rule([]string{"get"}, "", "secrets", "foo")),
+				roleBinding("ns-a", "foo.v1.1.0-manager-456", rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "foo.v1.1.0-manager-456"}, manager),
+			},
+		},
+		{
+			name: "a rule gains a verb",
+			desired: []client.Object{
+				role("ns-a", "foo.v1.1.0-manager-456", rule([]string{"get", "list"}, "", "secrets")),
+			},
+			escalates: true,
+		},
+		{
+			name: "a rule loses its resource names",
+			desired: []client.Object{
+				clusterRole("foo.v1.1.0-manager-123", rule([]string{"get"}, "", "pods"), rule([]string{"get"}, "", "configmaps")),
+			},
+			escalates: true,
+		},
+		{
+			name: "a rule is granted in another namespace",
+			desired: []client.Object{
+				role("ns-b", "foo.v1.1.0-manager-456", rule([]string{"get"}, "", "secrets")),
+			},
+			escalates: true,
+		},
+		{
+			name: "a namespaced grant is covered by a cluster-wide grant",
+			desired: []client.Object{
+				clusterRole("foo.v1.0.0-manager-abc", readPods, rule([]string{"*"}, "apps", "*")),
+				clusterRoleBinding("foo.v1.0.0-manager-abc", "foo.v1.0.0-manager-abc", manager),
+				roleBinding("ns-b", "foo.v1.1.0-manager-abc", rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "foo.v1.0.0-manager-abc"}, manager),
+			},
+		},
+		{
+			name: "a role is bound to another subject",
+			desired: []client.Object{
+				clusterRole("foo.v1.0.0-manager-abc", readPods),
+				clusterRoleBinding("foo.v1.0.0-manager-abc", "foo.v1.0.0-manager-abc", manager, helper),
+			},
+			escalates: true,
+		},
+		{
+			name: "a role outside of the bundle is bound",
+			desired: []client.Object{
+				roleBinding("ns-a", "foo-view", rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "admin"}, manager),
+			},
+			escalates: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			escalates, err := escalatesPermissions(installed, tc.desired)
+			require.NoError(t, err)
+			assert.Equal(t, tc.escalates, escalates)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package applier
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+	"github.com/operator-framework/operator-controller/internal/rukpak/convert"
+	"github.com/operator-framework/operator-controller/internal/rukpak/util"
+)
+
+// Plan returns the objects that applying the bundle in contentFS for ext would
+// add, change or remove compared to its installed release, without making any
+// changes to the cluster. The desired release is rendered with the same
+// server-side dry run Apply performs. Every object is added when nothing is
+// installed. The bundle of the returned plan is left for the caller to set.
+func (h *Helm) Plan(ctx context.Context, contentFS fs.FS, ext *ocv1.ClusterExtension, objectLabels map[string]string) (*ocv1.ReleasePlan, error) {
+	chrt, err := convert.RegistryV1ToHelmChart(ctx, contentFS, ext.Spec.Namespace, watchNamespaces(ext), h.convertOptions(ext)...)
+	if err != nil {
+		return nil, err
+	}
+
+	ac, err := h.ActionClientGetter.ActionClientFor(ctx, ext)
+	if err != nil {
+		return nil, err
+	}
+
+	rel, desiredRel, _, err := h.getReleaseState(ac, ext, chrt, chartutil.Values{}, newPostrenderer(ext, objectLabels))
+	if err != nil {
+		return nil, err
+	}
+
+	var installedObjs []client.Object
+	if rel != nil {
+		installedObjs, err = util.ManifestObjects(strings.NewReader(rel.Manifest), fmt.Sprintf("%s-release-manifest", rel.Name))
+		if err != nil {
+			return nil, err
+		}
+	}
+	desiredObjs, err := util.ManifestObjects(strings.NewReader(desiredRel.Manifest), fmt.Sprintf("%s-release-manifest", desiredRel.Name))
+	if err != nil {
+		return nil, err
+	}
+	return diffObjects(installedObjs, desiredObjs)
+}
+
+// diffObjects returns the plan turning the installed objects into the desired
+// ones. Objects are matched by group, kind, namespace and name.
+func diffObjects(installed, desired []client.Object) (*ocv1.ReleasePlan, error) {
+	installedByKey, err := objectsByKey(installed)
+	if err != nil {
+		return nil, err
+	}
+	desiredByKey, err := objectsByKey(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &ocv1.ReleasePlan{}
+	for key, desiredObj := range desiredByKey {
+		installedObj, ok := installedByKey[key]
+		if !ok {
+			plan.Added = append(plan.Added, objectReference(desiredObj))
+			continue
+		}
+		if fields := changedFields("", installedObj, desiredObj); len(fields) > 0 {
+			plan.Changed = append(plan.Changed, ocv1.ObjectChange{
+				ManagedObjectReference: objectReference(desiredObj),
+				Fields:                 fields,
+			})
+		}
+	}
+	for key, installedObj := range installedByKey {
+		if _, ok := desiredByKey[key]; !ok {
+			plan.Removed = append(plan.Removed, objectReference(installedObj))
+		}
+	}
+
+	slices.SortFunc(plan.Added, compareObjectReferences)
+	slices.SortFunc(plan.Removed, compareObjectReferences)
+	slices.SortFunc(plan.Changed, func(a, b ocv1.ObjectChange) int {
+		return compareObjectReferences(a.ManagedObjectReference, b.ManagedObjectReference)
+	})
+	return plan, nil
+}
+
+// objectsByKey returns the content of objs keyed by their group, kind,
+// namespace and name. The API version is left out of the key, since it may
+// change between releases for the same object.
+func objectsByKey(objs []client.Object) (map[string]map[string]interface{}, error) {
+	byKey := make(map[string]map[string]interface{}, len(objs))
+	for _, obj := range objs {
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, fmt.Errorf("error converting %s %q: %w", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName(), err)
+		}
+		gk := obj.GetObjectKind().GroupVersionKind().GroupKind()
+		byKey[objectKey(gk, obj.GetNamespace(), obj.GetName())] = u
+	}
+	return byKey, nil
+}
+
+func objectKey(gk schema.GroupKind, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s", gk, namespace, name)
+}
+
+func objectReference(u map[string]interface{}) ocv1.ManagedObjectReference {
+	ref := ocv1.ManagedObjectReference{}
+	ref.APIVersion, _ = u["apiVersion"].(string)
+	ref.Kind, _ = u["kind"].(string)
+	if metadata, ok := u["metadata"].(map[string]interface{}); ok {
+		ref.Namespace, _ = metadata["namespace"].(string)
+		ref.Name, _ = metadata["name"].(string)
+	}
+	return ref
+}
+
+func compareObjectReferences(a, b ocv1.ManagedObjectReference) int {
+	return cmp.Or(
+		cmp.Compare(a.APIVersion, b.APIVersion),
+		cmp.Compare(a.Kind, b.Kind),
+		cmp.Compare(a.Namespace, b.Namespace),
+		cmp.Compare(a.Name, b.Name),
+	)
+}
+
+// identifierRegexp matches the field names that are written as is in paths.
+var identifierRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// changedFields returns the sorted paths, below path, of the fields that differ
+// between the unstructured values a and b. Maps are compared field by field,
+// other values, including lists, as a whole.
+func changedFields(path string, a, b interface{}) []string {
+	aMap, aIsMap := a.(map[string]interface{})
+	bMap, bIsMap := b.(map[string]interface{})
+	if !aIsMap || !bIsMap {
+		if reflect.DeepEqual(a, b) {
+			return nil
+		}
+		return []string{path}
+	}
+
+	var fields []string
+	for key, aValue := range aMap {
+		bValue, ok := bMap[key]
+		if !ok {
+			fields = append(fields, fieldPath(path, key))
+			continue
+		}
+		fields = append(fields, changedFields(fieldPath(path, key), aValue, bValue)...)
+	}
+	for key := range bMap {
+		if _, ok := aMap[key]; !ok {
+			fields = append(fields, fieldPath(path, key))
+		}
+	}
+	slices.Sort(fields)
+	return fields
+}
+
+// fieldPath returns the path of the field key of the map at path, for example
+// ".spec.replicas" or `.metadata.labels["app.kubernetes.io/name"]`.
+func fieldPath(path, key string) string {
+	if identifierRegexp.MatchString(key) {
+		return path + "." + key
+	}
+	return fmt.Sprintf("%s[%q]", path, key)
+}
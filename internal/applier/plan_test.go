@@ -0,0 +1,82 @@
+package applier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+)
+
+func TestDiffObjects(t *testing.T) {
+	deployment := func(replicas int32, image string, labels map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: appsv1.SchemeGroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "manager", Labels: labels},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: ptr.To(replicas),
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "manager", Image: image}}},
+				},
+			},
+		}
+	}
+	configMap := func(name string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name},
+		}
+	}
+	// Objects are read from release manifests, so they are unstructured.
+	unstructuredObj := func(obj client.Object) client.Object {
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		require.NoError(t, err)
+		return &unstructured.Unstructured{Object: u}
+	}
+
+	installed := []client.Object{
+		unstructuredObj(deployment(1, "manager:v1.0.0", map[string]string{"app": "manager"})),
+		unstructuredObj(configMap("unchanged")),
+		unstructuredObj(configMap("removed")),
+	}
+	desired := []client.Object{
+		unstructuredObj(deployment(2, "manager:v1.1.0", map[string]string{"app": "manager", "app.kubernetes.io/version": "v1.1.0"})),
+		unstructuredObj(configMap("unchanged")),
+		unstructuredObj(configMap("added")),
+	}
+
+	plan, err := diffObjects(installed, desired)
+	require.NoError(t, err)
+	assert.Equal(t, &ocv1.ReleasePlan{
+		Added: []ocv1.ManagedObjectReference{{APIVersion: "v1", Kind: "ConfigMap", Namespace: "ns", Name: "added"}},
+		Changed: []ocv1.ObjectChange{{
+			ManagedObjectReference: ocv1.ManagedObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "ns", Name: "manager"},
+			Fields: []string{
+				`.metadata.labels["app.kubernetes.io/version"]`,
+				".spec.replicas",
+				".spec.template.spec.containers",
+			},
+		}},
+		Removed: []ocv1.ManagedObjectReference{{APIVersion: "v1", Kind: "ConfigMap", Namespace: "ns", Name: "removed"}},
+	}, plan)
+
+	t.Log("Every object is added when nothing is installed")
+	plan, err = diffObjects(nil, desired)
+	require.NoError(t, err)
+	assert.Len(t, plan.Added, 3)
+	assert.Empty(t, plan.Changed)
+	assert.Empty(t, plan.Removed)
+
+	t.Log("Nothing changes when the objects are the same")
+	plan, err = diffObjects(installed, installed)
+	require.NoError(t, err)
+	assert.Equal(t, &ocv1.ReleasePlan{}, plan)
+}
@@ -0,0 +1,254 @@
+package applier
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	helmclient "github.com/operator-framework/helm-operator-plugins/pkg/client"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+	"github.com/operator-framework/operator-controller/internal/rukpak/util"
+)
+
+const (
+	// rolledBackFromRevisionKey is the storage label set on a release created by
+	// rolling back a failed upgrade. Its value is the revision of the failed release.
+	rolledBackFromRevisionKey = "olm.operatorframework.io/rolled-back-from-revision"
+
+	// rolledBackToRevisionKey is the storage label set on a release created by
+	// an explicit rollback. Its value is the revision that was rolled back to.
+	rolledBackToRevisionKey = "olm.operatorframework.io/rolled-back-to-revision"
+)
+
+// RolledBackError is returned by Apply when an upgrade failed and the release
+// was rolled back to its last successfully deployed revision.
+type RolledBackError struct {
+	// FailedRevision is the revision of the release created by the failed upgrade.
+	FailedRevision int
+	// Err describes why the upgrade failed.
+	Err error
+}
+
+func (e *RolledBackError) Error() string {
+	return fmt.Sprintf("upgrade to revision %d failed and was rolled back: %v", e.FailedRevision, e.Err)
+}
+
+func (e *RolledBackError) Unwrap() error {
+	return e.Err
+}
+
+func rollbackOnFailure(ext *ocv1.ClusterExtension) bool {
+	return ext.Spec.Install != nil && ext.Spec.Install.Rollback != nil &&
+		ext.Spec.Install.Rollback.Policy == ocv1.RollbackPolicyAutomatic
+}
+
+// previousRollback returns the error of a previous upgrade that was rolled back,
+// if currentRel is the result of such a rollback and the failed release has the
+// same manifest as desiredRel. This prevents attempting the same failing upgrade
+// over and over again.
+func previousRollback(ac helmclient.ActionInterface, currentRel, desiredRel *release.Release) (*RolledBackError, error) {
+	if currentRel == nil || desiredRel == nil {
+		return nil, nil
+	}
+	failedRevision, err := strconv.Atoi(currentRel.Labels[rolledBackFromRevisionKey])
+	if err != nil {
+		return nil, nil
+	}
+	history, err := ac.History(currentRel.Name)
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range history {
+		if rel.Version == failedRevision && rel.Manifest == desiredRel.Manifest {
+			return &RolledBackError{FailedRevision: failedRevision, Err: errors.New(releaseDescription(rel))}, nil
+		}
+	}
+	return nil, nil
+}
+
+// rollback re-applies the last successfully deployed revision of the release
+// of ext after the upgrade that created failedRel failed with upgradeErr, or
+// after its workloads failed their health check.
+func (h *Helm) rollback(ctx context.Context, ac helmclient.ActionInterface, ext *ocv1.ClusterExtension, failedRel *release.Release, upgradeErr error) (*release.Release, error) {
+	l := log.FromContext(ctx)
+
+	history, err := ac.History(ext.GetName())
+	if err != nil {
+		return nil, fmt.Errorf("getting release history to roll back failed upgrade: %v: original upgrade error: %w", err, upgradeErr)
+	}
+	// The history is sorted from the latest revision, and the revision
+	// deployed before failedRel is superseded when failedRel was deployed
+	// before failing its health check.
+	var targetRel *release.Release
+	for _, rel := range history {
+		if rel.Version < failedRel.Version && rel.Info != nil &&
+			(rel.Info.Status == release.StatusDeployed || rel.Info.Status == release.StatusSuperseded) {
+			targetRel = rel
+			break
+		}
+	}
+	if targetRel == nil {
+		return nil, fmt.Errorf("no deployed revision to roll back to: original upgrade error: %w", upgradeErr)
+	}
+
+	for _, preflight := range h.RollbackPreflights {
+		if err := preflight.Upgrade(ctx, targetRel); err != nil {
+			return nil, fmt.Errorf("refusing to roll back to revision %d: %v: original upgrade error: %w", targetRel.Version, err, upgradeErr)
+		}
+	}
+
+	l.Info("rolling back failed upgrade", "failedRevision", failedRel.Version, "targetRevision", targetRel.Version)
+	rel, err := reapplyRevision(ac, ext, targetRel, map[string]string{
+		rolledBackFromRevisionKey: strconv.Itoa(failedRel.Version),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("rolling back to revision %d: %v: original upgrade error: %w", targetRel.Version, err, upgradeErr)
+	}
+
+	// Report the description recorded in the failed release rather than upgradeErr,
+	// so that the error is the same when it is reported again by previousRollback.
+	return rel, &RolledBackError{FailedRevision: failedRel.Version, Err: errors.New(releaseDescription(failedRel))}
+}
+
+func releaseDescription(rel *release.Release) string {
+	if rel.Info == nil || rel.Info.Description == "" {
+		return "unknown error"
+	}
+	return rel.Info.Description
+}
+
+// RollbackTo re-applies the given revision of the release of ext, or the
+// revision deployed before the current one when revision is 0. It returns
+// the objects of the resulting release and its storage labels, which are
+// those of the revision that was rolled back to.
+//
+// Rolling back to a revision is idempotent: once the current release is the
+// result of rolling back to the requested revision, it is only reconciled.
+func (h *Helm) RollbackTo(ctx context.Context, ext *ocv1.ClusterExtension, revision int) ([]client.Object, map[string]string, error) {
+	l := log.FromContext(ctx)
+
+	ac, err := h.ActionClientGetter.ActionClientFor(ctx, ext)
+	if err != nil {
+		return nil, nil, err
+	}
+	history, err := ac.History(ext.GetName())
+	if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+		return nil, nil, err
+	}
+
+	var currentRel *release.Release
+	for _, rel := range history {
+		if rel.Info != nil && rel.Info.Status == release.StatusDeployed {
+			currentRel = rel
+			break
+		}
+	}
+	if currentRel == nil {
+		return nil, nil, reconcile.TerminalError(errors.New("no deployed revision to roll back from"))
+	}
+
+	if revision == 0 {
+		revision = previousRevision(history, currentRel)
+		if revision == 0 {
+			return nil, nil, reconcile.TerminalError(fmt.Errorf("no revision was deployed before revision %d", currentRel.Version))
+		}
+	}
+
+	rel := currentRel
+	if currentRel.Version != revision && currentRel.Labels[rolledBackToRevisionKey] != strconv.Itoa(revision) {
+		targetRel, err := rollbackTarget(history, revision)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, preflight := range h.Preflights {
+			if shouldSkipPreflight(ctx, preflight, ext, StateNeedsUpgrade) {
+				continue
+			}
+			if err := preflight.Upgrade(ctx, targetRel); err != nil {
+				return nil, nil, &PreflightError{Err: err}
+			}
+		}
+
+		l.Info("rolling back", "currentRevision", currentRel.Version, "targetRevision", targetRel.Version)
+		rel, err = reapplyRevision(ac, ext, targetRel, map[string]string{
+			rolledBackToRevisionKey: strconv.Itoa(targetRel.Version),
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("rolling back to revision %d: %w", targetRel.Version, err)
+		}
+	} else if err := ac.Reconcile(rel); err != nil {
+		return nil, nil, err
+	}
+
+	relObjects, err := util.ManifestObjects(strings.NewReader(rel.Manifest), fmt.Sprintf("%s-release-manifest", rel.Name))
+	if err != nil {
+		return nil, nil, err
+	}
+	return relObjects, rel.Labels, nil
+}
+
+// previousRevision returns the revision that was deployed before currentRel,
+// or 0 if there is none. When currentRel is itself the result of rolling back,
+// the revision it was rolled back to is returned, so that rolling back to the
+// previous revision is stable across reconciliations.
+func previousRevision(history []*release.Release, currentRel *release.Release) int {
+	if revision, err := strconv.Atoi(currentRel.Labels[rolledBackToRevisionKey]); err == nil {
+		return revision
+	}
+	for _, rel := range history {
+		if rel.Version < currentRel.Version && rel.Info != nil && rel.Info.Status == release.StatusSuperseded {
+			return rel.Version
+		}
+	}
+	return 0
+}
+
+// rollbackTarget returns the given revision from history if it was successfully deployed.
+func rollbackTarget(history []*release.Release, revision int) (*release.Release, error) {
+	for _, rel := range history {
+		if rel.Version != revision {
+			continue
+		}
+		if rel.Info == nil || (rel.Info.Status != release.StatusSuperseded && rel.Info.Status != release.StatusDeployed) {
+			return nil, reconcile.TerminalError(fmt.Errorf("revision %d was not successfully deployed", revision))
+		}
+		return rel, nil
+	}
+	return nil, reconcile.TerminalError(fmt.Errorf("revision %d not found in the release history", revision))
+}
+
+// reapplyRevision upgrades the release of ext to the content of targetRel,
+// recording the given labels in addition to the storage labels of targetRel.
+func reapplyRevision(ac helmclient.ActionInterface, ext *ocv1.ClusterExtension, targetRel *release.Release, rollbackLabels map[string]string) (*release.Release, error) {
+	storageLabels := map[string]string{}
+	for k, v := range targetRel.Labels {
+		if k != rolledBackFromRevisionKey && k != rolledBackToRevisionKey && k != healthCheckDeadlineKey {
+			storageLabels[k] = v
+		}
+	}
+	return ac.Upgrade(ext.GetName(), ext.Spec.Namespace, targetRel.Chart, targetRel.Config, func(upgrade *action.Upgrade) error {
+		upgrade.MaxHistory = maxHelmReleaseHistory
+		upgrade.Labels = util.MergeMaps(storageLabels, rollbackLabels)
+		return nil
+	}, helmclient.AppendUpgradePostRenderer(manifestPostRenderer(targetRel.Manifest)))
+}
+
+// manifestPostRenderer replaces the rendered manifests with a previously
+// rendered manifest, so that a rollback restores exactly the objects of the
+// revision being rolled back to.
+type manifestPostRenderer string
+
+func (m manifestPostRenderer) Run(*bytes.Buffer) (*bytes.Buffer, error) {
+	return bytes.NewBufferString(string(m)), nil
+}
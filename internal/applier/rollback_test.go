@@ -0,0 +1,334 @@
+package applier
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	helmclient "github.com/operator-framework/helm-operator-plugins/pkg/client"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+)
+
+type fakeActionClient struct {
+	helmclient.ActionInterface
+	history    []*release.Release
+	upgradeErr error
+
+	upgraded   *release.Release
+	reconciled *release.Release
+}
+
+func (f *fakeActionClient) History(string, ...helmclient.HistoryOption) ([]*release.Release, error) {
+	return f.history, nil
+}
+
+func (f *fakeActionClient) Upgrade(name, _ string, chrt *chart.Chart, vals map[string]interface{}, opts ...helmclient.UpgradeOption) (*release.Release, error) {
+	upgrade := &action.Upgrade{}
+	for _, o := range opts {
+		if err := o(upgrade); err != nil {
+			return nil, err
+		}
+	}
+	manifest, err := upgrade.PostRenderer.Run(&bytes.Buffer{})
+	if err != nil {
+		return nil, err
+	}
+	f.upgraded = &release.Release{
+		Name:     name,
+		Version:  f.history[0].Version + 1,
+		Chart:    chrt,
+		Config:   vals,
+		Manifest: manifest.String(),
+		Labels:   upgrade.Labels,
+		Info:     &release.Info{Status: release.StatusDeployed},
+	}
+	return f.upgraded, f.upgradeErr
+}
+
+func (f *fakeActionClient) Reconcile(rel *release.Release) error {
+	f.reconciled = rel
+	return nil
+}
+
+type fakePreflight struct {
+	err error
+}
+
+func (f fakePreflight) Install(context.Context, *release.Release) error { return f.err }
+func (f fakePreflight) Upgrade(context.Context, *release.Release) error { return f.err }
+
+func testRollbackClusterExtension() *ocv1.ClusterExtension {
+	return &ocv1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: ocv1.ClusterExtensionSpec{
+			Namespace: "test-ns",
+			Install: &ocv1.ClusterExtensionInstallConfig{
+				Rollback: &ocv1.RollbackConfig{Policy: ocv1.RollbackPolicyAutomatic},
+			},
+		},
+	}
+}
+
+func testReleaseHistory() []*release.Release {
+	return []*release.Release{
+		{
+			Name:     "test",
+			Version:  3,
+			Manifest: "failed-manifest",
+			Labels:   map[string]string{"bundle": "v2"},
+			Info:     &release.Info{Status: release.StatusFailed, Description: `Upgrade "test" failed: boom`},
+		},
+		{
+			Name:     "test",
+			Version:  2,
+			Chart:    &chart.Chart{Metadata: &chart.Metadata{Name: "test"}},
+			Config:   map[string]interface{}{"key": "value"},
+			Manifest: "deployed-manifest",
+			Labels:   map[string]string{"bundle": "v1"},
+			Info:     &release.Info{Status: release.StatusDeployed},
+		},
+		{
+			Name:     "test",
+			Version:  1,
+			Manifest: "superseded-manifest",
+			Labels:   map[string]string{"bundle": "v0"},
+			Info:     &release.Info{Status: release.StatusSuperseded},
+		},
+	}
+}
+
+func TestRollback(t *testing.T) {
+	history := testReleaseHistory()
+	ac := &fakeActionClient{history: history}
+	h := &Helm{RollbackPreflights: []Preflight{fakePreflight{}}}
+
+	rel, err := h.rollback(context.Background(), ac, testRollbackClusterExtension(), history[0], errors.New("boom"))
+	require.Equal(t, &RolledBackError{FailedRevision: 3, Err: errors.New(`Upgrade "test" failed: boom`)}, err)
+	assert.EqualError(t, err, `upgrade to revision 3 failed and was rolled back: Upgrade "test" failed: boom`)
+
+	require.NotNil(t, rel)
+	assert.Equal(t, history[1].Chart, rel.Chart)
+	assert.Equal(t, history[1].Config, rel.Config)
+	assert.Equal(t, "deployed-manifest", rel.Manifest)
+	assert.Equal(t, map[string]string{"bundle": "v1", rolledBackFromRevisionKey: "3"}, rel.Labels)
+}
+
+func TestRollbackRefusedByPreflight(t *testing.T) {
+	history := testReleaseHistory()
+	ac := &fakeActionClient{history: history}
+	h := &Helm{RollbackPreflights: []Preflight{fakePreflight{err: errors.New("stored version removed")}}}
+
+	_, err := h.rollback(context.Background(), ac, testRollbackClusterExtension(), history[0], errors.New("boom"))
+	require.EqualError(t, err, "refusing to roll back to revision 2: stored version removed: original upgrade error: boom")
+	assert.Nil(t, ac.upgraded)
+}
+
+func TestRollbackNoDeployedRevision(t *testing.T) {
+	history := testReleaseHistory()[:1]
+	ac := &fakeActionClient{history: history}
+	h := &Helm{}
+
+	_, err := h.rollback(context.Background(), ac, testRollbackClusterExtension(), history[0], errors.New("boom"))
+	require.EqualError(t, err, "no deployed revision to roll back to: original upgrade error: boom")
+	assert.Nil(t, ac.upgraded)
+}
+
+func TestRollbackUpgradeError(t *testing.T) {
+	history := testReleaseHistory()
+	ac := &fakeActionClient{history: history, upgradeErr: errors.New("fake error")}
+	h := &Helm{}
+
+	_, err := h.rollback(context.Background(), ac, testRollbackClusterExtension(), history[0], errors.New("boom"))
+	require.EqualError(t, err, "rolling back to revision 2: fake error: original upgrade error: boom")
+}
+
+func TestPreviousRollback(t *testing.T) {
+	history := testReleaseHistory()
+	rolledBackRel := &release.Release{
+		Name:   "test",
+		Labels: map[string]string{"bundle": "v1", rolledBackFromRevisionKey: "3"},
+		Info:   &release.Info{Status: release.StatusDeployed},
+	}
+	ac := &fakeActionClient{history: append([]*release.Release{rolledBackRel}, history...)}
+
+	t.Run("reports the rollback of the same upgrade", func(t *testing.T) {
+		rolledBack, err := previousRollback(ac, rolledBackRel, &release.Release{Manifest: "failed-manifest"})
+		require.NoError(t, err)
+		assert.Equal(t, &RolledBackError{FailedRevision: 3, Err: errors.New(`Upgrade "test" failed: boom`)}, rolledBack)
+	})
+
+	t.Run("ignores a different upgrade", func(t *testing.T) {
+		rolledBack, err := previousRollback(ac, rolledBackRel, &release.Release{Manifest: "other-manifest"})
+		require.NoError(t, err)
+		assert.Nil(t, rolledBack)
+	})
+
+	t.Run("ignores a release that is not a rollback", func(t *testing.T) {
+		rolledBack, err := previousRollback(ac, history[1], &release.Release{Manifest: "failed-manifest"})
+		require.NoError(t, err)
+		assert.Nil(t, rolledBack)
+	})
+}
+
+func testRollbackToHelm(ac *fakeActionClient, preflights ...Preflight) *Helm {
+	return &Helm{
+		ActionClientGetter: helmclient.ActionClientGetterFunc(func(context.Context, client.Object) (helmclient.ActionInterface, error) {
+			return ac, nil
+		}),
+		Preflights: preflights,
+	}
+}
+
+func testRollbackToHistory() []*release.Release {
+	manifest := func(name string) string {
+		return "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + name + "\n  namespace: test-ns\n"
+	}
+	return []*release.Release{
+		{
+			Name:     "test",
+			Version:  4,
+			Manifest: manifest("v3"),
+			Labels:   map[string]string{"bundle": "v3"},
+			Info:     &release.Info{Status: release.StatusDeployed},
+		},
+		{
+			Name:     "test",
+			Version:  3,
+			Manifest: manifest("v2"),
+			Labels:   map[string]string{"bundle": "v2"},
+			Info:     &release.Info{Status: release.StatusFailed},
+		},
+		{
+			Name:     "test",
+			Version:  2,
+			Chart:    &chart.Chart{Metadata: &chart.Metadata{Name: "test"}},
+			Config:   map[string]interface{}{"key": "value"},
+			Manifest: manifest("v1"),
+			Labels:   map[string]string{"bundle": "v1", rolledBackFromRevisionKey: "1"},
+			Info:     &release.Info{Status: release.StatusSuperseded},
+		},
+		{
+			Name:     "test",
+			Version:  1,
+			Manifest: manifest("v0"),
+			Labels:   map[string]string{"bundle": "v0"},
+			Info:     &release.Info{Status: release.StatusSuperseded},
+		},
+	}
+}
+
+func TestRollbackTo(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		revision int
+	}{
+		{name: "previous revision", revision: 0},
+		{name: "explicit revision", revision: 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			history := testRollbackToHistory()
+			ac := &fakeActionClient{history: history}
+			h := testRollbackToHelm(ac, fakePreflight{})
+
+			objs, lbls, err := h.RollbackTo(context.Background(), testRollbackClusterExtension(), tc.revision)
+			require.NoError(t, err)
+			require.Len(t, objs, 1)
+			assert.Equal(t, "v1", objs[0].GetName())
+			assert.Equal(t, map[string]string{"bundle": "v1", rolledBackToRevisionKey: "2"}, lbls)
+
+			require.NotNil(t, ac.upgraded)
+			assert.Equal(t, history[2].Chart, ac.upgraded.Chart)
+			assert.Equal(t, history[2].Config, ac.upgraded.Config)
+			assert.Equal(t, history[2].Manifest, ac.upgraded.Manifest)
+		})
+	}
+}
+
+func TestRollbackToAlreadyRolledBack(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		revision int
+	}{
+		{name: "previous revision", revision: 0},
+		{name: "explicit revision", revision: 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			history := testRollbackToHistory()
+			history[0].Labels = map[string]string{"bundle": "v1", rolledBackToRevisionKey: "2"}
+			ac := &fakeActionClient{history: history}
+			h := testRollbackToHelm(ac, fakePreflight{err: errors.New("preflights must not run")})
+
+			_, lbls, err := h.RollbackTo(context.Background(), testRollbackClusterExtension(), tc.revision)
+			require.NoError(t, err)
+			assert.Equal(t, history[0].Labels, lbls)
+			assert.Nil(t, ac.upgraded)
+			assert.Equal(t, history[0], ac.reconciled)
+		})
+	}
+}
+
+func TestRollbackToErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		history    []*release.Release
+		revision   int
+		preflight  fakePreflight
+		upgradeErr error
+		expectErr  string
+	}{
+		{
+			name:      "nothing deployed",
+			history:   testRollbackToHistory()[1:2],
+			expectErr: "terminal error: no deployed revision to roll back from",
+		},
+		{
+			name:      "no previous revision",
+			history:   testRollbackToHistory()[:2],
+			expectErr: "terminal error: no revision was deployed before revision 4",
+		},
+		{
+			name:      "unknown revision",
+			history:   testRollbackToHistory(),
+			revision:  7,
+			expectErr: "terminal error: revision 7 not found in the release history",
+		},
+		{
+			name:      "failed revision",
+			history:   testRollbackToHistory(),
+			revision:  3,
+			expectErr: "terminal error: revision 3 was not successfully deployed",
+		},
+		{
+			name:      "preflight failure",
+			history:   testRollbackToHistory(),
+			preflight: fakePreflight{err: errors.New("stored version removed")},
+			expectErr: "stored version removed",
+		},
+		{
+			name:       "upgrade failure",
+			history:    testRollbackToHistory(),
+			upgradeErr: errors.New("fake error"),
+			expectErr:  "rolling back to revision 2: fake error",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ac := &fakeActionClient{history: tc.history, upgradeErr: tc.upgradeErr}
+			h := testRollbackToHelm(ac, tc.preflight)
+
+			_, _, err := h.RollbackTo(context.Background(), testRollbackClusterExtension(), tc.revision)
+			require.EqualError(t, err, tc.expectErr)
+			var preflightErr *PreflightError
+			assert.Equal(t, tc.preflight.err != nil, errors.As(err, &preflightErr))
+		})
+	}
+}
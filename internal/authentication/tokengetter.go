@@ -10,6 +10,8 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/utils/ptr"
+
+	"github.com/operator-framework/operator-controller/internal/metrics"
 )
 
 type TokenGetter struct {
@@ -85,6 +87,7 @@ func (t *TokenGetter) getToken(ctx context.Context, key types.NamespacedName) (*
 		&authenticationv1.TokenRequest{
 			Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: ptr.To(int64(t.expirationDuration / time.Second))},
 		}, metav1.CreateOptions{})
+	metrics.IncTokenRequests(err)
 	if err != nil {
 		return nil, err
 	}
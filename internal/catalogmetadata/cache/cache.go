@@ -11,6 +11,7 @@ import (
 	"github.com/operator-framework/operator-registry/alpha/declcfg"
 
 	"github.com/operator-framework/operator-controller/internal/catalogmetadata/client"
+	"github.com/operator-framework/operator-controller/internal/metrics"
 )
 
 var _ client.Cache = &filesystemCache{}
@@ -64,6 +65,9 @@ func (fsc *filesystemCache) Put(catalogName, resolvedRef string, source io.Reade
 	if errToCache == nil {
 		cacheFS, errToCache = fsc.writeFS(catalogName, source)
 	}
+	if errToCache != nil {
+		metrics.IncCatalogCachePopulationErrors(catalogName)
+	}
 	fsc.cacheDataByCatalogName[catalogName] = cacheData{
 		Ref:   resolvedRef,
 		Error: errToCache,
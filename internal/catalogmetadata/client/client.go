@@ -59,16 +59,9 @@ type Client struct {
 }
 
 func (c *Client) GetPackage(ctx context.Context, catalog *catalogd.ClusterCatalog, pkgName string) (*declcfg.DeclarativeConfig, error) {
-	if err := validateCatalog(catalog); err != nil {
-		return nil, err
-	}
-
-	catalogFsys, err := c.cache.Get(catalog.Name, catalog.Status.ResolvedSource.Image.Ref)
+	catalogFsys, err := c.getFS(catalog)
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving cache for catalog %q: %v", catalog.Name, err)
-	}
-	if catalogFsys == nil {
-		return nil, fmt.Errorf("cache for catalog %q not found", catalog.Name)
+		return nil, err
 	}
 
 	pkgFsys, err := fs.Sub(catalogFsys, pkgName)
@@ -89,6 +82,37 @@ func (c *Client) GetPackage(ctx context.Context, catalog *catalogd.ClusterCatalo
 	return pkgFBC, nil
 }
 
+// GetCatalog returns the full contents of the catalog. Unlike GetPackage, it
+// loads every package of the catalog, so it should only be used when the
+// packages of interest are not known in advance.
+func (c *Client) GetCatalog(ctx context.Context, catalog *catalogd.ClusterCatalog) (*declcfg.DeclarativeConfig, error) {
+	catalogFsys, err := c.getFS(catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	fbc, err := declcfg.LoadFS(ctx, catalogFsys)
+	if err != nil {
+		return nil, fmt.Errorf("error loading catalog %q: %v", catalog.Name, err)
+	}
+	return fbc, nil
+}
+
+func (c *Client) getFS(catalog *catalogd.ClusterCatalog) (fs.FS, error) {
+	if err := validateCatalog(catalog); err != nil {
+		return nil, err
+	}
+
+	catalogFsys, err := c.cache.Get(catalog.Name, catalog.Status.ResolvedSource.Image.Ref)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving cache for catalog %q: %v", catalog.Name, err)
+	}
+	if catalogFsys == nil {
+		return nil, fmt.Errorf("cache for catalog %q not found", catalog.Name)
+	}
+	return catalogFsys, nil
+}
+
 func (c *Client) PopulateCache(ctx context.Context, catalog *catalogd.ClusterCatalog) (fs.FS, error) {
 	if err := validateCatalog(catalog); err != nil {
 		return nil, err
@@ -136,6 +136,30 @@ func TestClientGetPackage(t *testing.T) {
 	}
 }
 
+func TestClientGetCatalog(t *testing.T) {
+	ctx := context.Background()
+	c := catalogClient.New(&fakeCache{getFS: fstest.MapFS{
+		"pkg-1/olm.package/pkg-1.json": &fstest.MapFile{Data: []byte(`{"schema": "olm.package","name": "pkg-1"}`)},
+		"pkg-2/olm.package/pkg-2.json": &fstest.MapFile{Data: []byte(`{"schema": "olm.package","name": "pkg-2"}`)},
+	}}, nil)
+
+	fbc, err := c.GetCatalog(ctx, defaultCatalog())
+	require.NoError(t, err)
+	assert.Equal(t, &declcfg.DeclarativeConfig{Packages: []declcfg.Package{
+		{Schema: declcfg.SchemaPackage, Name: "pkg-1"},
+		{Schema: declcfg.SchemaPackage, Name: "pkg-2"},
+	}}, fbc)
+
+	c = catalogClient.New(&fakeCache{getFS: fstest.MapFS{
+		"pkg-1/olm.package/pkg-1.json": &fstest.MapFile{Data: []byte(`{"schema": "olm.package","name": 12345}`)},
+	}}, nil)
+	_, err = c.GetCatalog(ctx, defaultCatalog())
+	require.ErrorContains(t, err, `error loading catalog "catalog-1"`)
+
+	_, err = c.GetCatalog(ctx, &catalogd.ClusterCatalog{ObjectMeta: metav1.ObjectMeta{Name: "catalog-1"}})
+	require.ErrorContains(t, err, `catalog "catalog-1" is not being served`)
+}
+
 func TestClientPopulateCache(t *testing.T) {
 	testFS := fstest.MapFS{
 		"pkg-present/olm.package/pkg-present.json": &fstest.MapFile{Data: []byte(`{"schema": "olm.package","name": "pkg-present"}`)},
@@ -0,0 +1,50 @@
+package filter
+
+import (
+	"fmt"
+
+	bsemver "github.com/blang/semver/v4"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+	"github.com/operator-framework/operator-controller/internal/bundleutil"
+)
+
+// InAutoUpgradeClass returns a predicate that keeps bundles whose version is
+// not an upgrade of the installed bundle, or is an upgrade allowed by policy.
+func InAutoUpgradeClass(installedBundle ocv1.BundleMetadata, policy ocv1.AutoUpgradePolicy) (Predicate[declcfg.Bundle], error) {
+	installedBundleVersion, err := bsemver.Parse(installedBundle.Version)
+	if err != nil {
+		return nil, fmt.Errorf("parsing installed bundle %q version %q: %w", installedBundle.Name, installedBundle.Version, err)
+	}
+
+	var isAllowedUpgrade func(candidate bsemver.Version) bool
+	switch policy {
+	case ocv1.AutoUpgradePolicyAll, "":
+		isAllowedUpgrade = func(bsemver.Version) bool { return true }
+	case ocv1.AutoUpgradePolicyMinorAndPatch:
+		isAllowedUpgrade = func(candidate bsemver.Version) bool {
+			return candidate.Major == installedBundleVersion.Major
+		}
+	case ocv1.AutoUpgradePolicyPatchOnly:
+		isAllowedUpgrade = func(candidate bsemver.Version) bool {
+			return candidate.Major == installedBundleVersion.Major && candidate.Minor == installedBundleVersion.Minor
+		}
+	case ocv1.AutoUpgradePolicyNone:
+		isAllowedUpgrade = func(bsemver.Version) bool { return false }
+	default:
+		return nil, fmt.Errorf("unknown auto upgrade policy %q", policy)
+	}
+
+	return func(b declcfg.Bundle) bool {
+		bVersion, err := bundleutil.GetVersion(b)
+		if err != nil {
+			return false
+		}
+		if bVersion.LTE(installedBundleVersion) {
+			return true
+		}
+		return isAllowedUpgrade(*bVersion)
+	}, nil
+}
@@ -0,0 +1,89 @@
+package filter_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata/filter"
+)
+
+func TestInAutoUpgradeClass(t *testing.T) {
+	bundleWithVersion := func(version string) declcfg.Bundle {
+		return declcfg.Bundle{
+			Name: fmt.Sprintf("package1.v%s", version),
+			Properties: []property.Property{
+				{
+					Type:  property.TypePackage,
+					Value: json.RawMessage(fmt.Sprintf(`{"packageName": "package1", "version": %q}`, version)),
+				},
+			},
+		}
+	}
+	installedBundle := ocv1.BundleMetadata{Name: "package1.v1.2.3", Version: "1.2.3"}
+
+	for _, tc := range []struct {
+		name     string
+		policy   ocv1.AutoUpgradePolicy
+		expected map[string]bool
+	}{
+		{
+			name:   "All",
+			policy: ocv1.AutoUpgradePolicyAll,
+			expected: map[string]bool{
+				"1.2.2": true, "1.2.3": true, "1.2.4": true, "1.3.0": true, "2.0.0": true, "broken": false,
+			},
+		},
+		{
+			name:   "omitted",
+			policy: "",
+			expected: map[string]bool{
+				"1.2.2": true, "1.2.3": true, "1.2.4": true, "1.3.0": true, "2.0.0": true, "broken": false,
+			},
+		},
+		{
+			name:   "MinorAndPatch",
+			policy: ocv1.AutoUpgradePolicyMinorAndPatch,
+			expected: map[string]bool{
+				"1.2.2": true, "1.2.3": true, "1.2.4": true, "1.3.0": true, "2.0.0": false, "broken": false,
+			},
+		},
+		{
+			name:   "PatchOnly",
+			policy: ocv1.AutoUpgradePolicyPatchOnly,
+			expected: map[string]bool{
+				"1.2.2": true, "1.2.3": true, "1.2.4": true, "1.2.4-rc.1": true, "1.3.0": false, "2.0.0": false, "broken": false,
+			},
+		},
+		{
+			name:   "None",
+			policy: ocv1.AutoUpgradePolicyNone,
+			expected: map[string]bool{
+				"1.2.2": true, "1.2.3": true, "1.2.4": false, "1.3.0": false, "2.0.0": false, "broken": false,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := filter.InAutoUpgradeClass(installedBundle, tc.policy)
+			require.NoError(t, err)
+			for version, expected := range tc.expected {
+				assert.Equal(t, expected, f(bundleWithVersion(version)), "version %q", version)
+			}
+		})
+	}
+}
+
+func TestInAutoUpgradeClassErrors(t *testing.T) {
+	_, err := filter.InAutoUpgradeClass(ocv1.BundleMetadata{Name: "package1.broken", Version: "broken"}, ocv1.AutoUpgradePolicyAll)
+	require.Error(t, err)
+
+	_, err = filter.InAutoUpgradeClass(ocv1.BundleMetadata{Name: "package1.v1.2.3", Version: "1.2.3"}, "Sometimes")
+	require.EqualError(t, err, `unknown auto upgrade policy "Sometimes"`)
+}
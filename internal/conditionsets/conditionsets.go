@@ -31,6 +31,9 @@ var ConditionTypes = []string{
 	ocv1.TypeChannelDeprecated,
 	ocv1.TypeBundleDeprecated,
 	ocv1.TypeProgressing,
+	ocv1.TypeUpgradeAvailable,
+	ocv1.TypeHealthy,
+	ocv1.TypeTerminating,
 }
 
 var ConditionReasons = []string{
@@ -39,4 +42,22 @@ var ConditionReasons = []string{
 	ocv1.ReasonFailed,
 	ocv1.ReasonBlocked,
 	ocv1.ReasonRetrying,
+	ocv1.ReasonApprovalRequired,
+	ocv1.ReasonUpToDate,
+	ocv1.ReasonUpgradeDeferred,
+	ocv1.ReasonPermissionEscalation,
+	ocv1.ReasonPlanned,
+	ocv1.ReasonRolledBack,
+	ocv1.ReasonHealthy,
+	ocv1.ReasonUnhealthy,
+	ocv1.ReasonResolutionFailed,
+	ocv1.ReasonMissingDependencies,
+	ocv1.ReasonUnpackFailed,
+	ocv1.ReasonPreflightFailed,
+	ocv1.ReasonApplyFailed,
+	ocv1.ReasonWatchFailed,
+	ocv1.ReasonInstallConflict,
+	ocv1.ReasonPermissionDenied,
+	ocv1.ReasonActive,
+	ocv1.ReasonUninstalling,
 }
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -30,14 +31,21 @@ type Cache interface {
 	// watches being stopped and removed and new watches being
 	// created
 	Watch(context.Context, Watcher, ...client.Object) error
+	// Get returns the state of the provided client.Object as
+	// last observed by the watch for its GroupVersionKind.
+	// It returns false if the object has not been observed,
+	// and an error if its GroupVersionKind is not watched
+	Get(client.Object) (*unstructured.Unstructured, bool, error)
 }
 
 // CloserSyncingSource is a wrapper of the controller-runtime
 // source.SyncingSource that includes methods for:
 //   - Closing the source, stopping it's interaction with the Kubernetes API server and reaction to events
+//   - Getting the last observed state of an object the source reacts to events for
 type CloserSyncingSource interface {
 	source.SyncingSource
 	io.Closer
+	Get(namespace, name string) (*unstructured.Unstructured, bool, error)
 }
 
 type sourcerer interface {
@@ -81,6 +89,17 @@ func (c *cache) Watch(ctx context.Context, watcher Watcher, objs ...client.Objec
 	return c.startNewSources(ctx, gvkSet, watcher)
 }
 
+func (c *cache) Get(obj client.Object) (*unstructured.Unstructured, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	source, ok := c.sources[gvk]
+	if !ok {
+		return nil, false, fmt.Errorf("GVK %q is not watched", gvk)
+	}
+	return source.Get(obj.GetNamespace(), obj.GetName())
+}
+
 func (c *cache) Close() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
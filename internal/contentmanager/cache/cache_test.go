@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -42,7 +43,8 @@ func (ms *mockSourcerer) Source(_ schema.GroupVersionKind, _ client.Object, _ fu
 }
 
 type mockSource struct {
-	err error
+	err  error
+	objs map[string]*unstructured.Unstructured
 }
 
 var _ CloserSyncingSource = (*mockSource)(nil)
@@ -59,6 +61,14 @@ func (ms *mockSource) Close() error {
 	return ms.err
 }
 
+func (ms *mockSource) Get(namespace, name string) (*unstructured.Unstructured, bool, error) {
+	if ms.err != nil {
+		return nil, false, ms.err
+	}
+	obj, ok := ms.objs[namespace+"/"+name]
+	return obj, ok, nil
+}
+
 func TestCacheWatch(t *testing.T) {
 	c := NewCache(
 		&mockSourcerer{
@@ -200,3 +210,38 @@ func TestCacheWatchRemovingStaleSourcesError(t *testing.T) {
 	secret.SetGroupVersionKind(secretGvk)
 	require.Error(t, c.Watch(context.Background(), &mockWatcher{}, secret))
 }
+
+func TestCacheGet(t *testing.T) {
+	observedPod := &unstructured.Unstructured{}
+	observedPod.SetName("observed")
+	c := NewCache(
+		&mockSourcerer{
+			source: &mockSource{
+				objs: map[string]*unstructured.Unstructured{"test-ns/observed": observedPod},
+			},
+		},
+		&ocv1.ClusterExtension{},
+		time.Second,
+	)
+
+	pod := &corev1.Pod{}
+	podGvk := corev1.SchemeGroupVersion.WithKind("Pod")
+	pod.SetGroupVersionKind(podGvk)
+	pod.SetNamespace("test-ns")
+
+	_, _, err := c.Get(pod)
+	require.Error(t, err, "should fail when the GVK is not watched")
+
+	require.NoError(t, c.Watch(context.Background(), &mockWatcher{}, pod))
+
+	pod.SetName("observed")
+	obj, found, err := c.Get(pod)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, observedPod, obj)
+
+	pod.SetName("unobserved")
+	_, found, err = c.Get(pod)
+	require.NoError(t, err)
+	require.False(t, found)
+}
@@ -8,6 +8,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
@@ -26,8 +27,14 @@ type Manager interface {
 	// ClusterExtension if one exists. If one does not exist,
 	// a new Cache is created and returned
 	Get(context.Context, *v1.ClusterExtension) (cmcache.Cache, error)
-	// Delete will stop and remove a managed content cache
-	// for the provided ClusterExtension if one exists.
+	// GetReferenced returns a cache of the objects referenced
+	// by the provided ClusterExtension that it does not manage,
+	// such as the objects its health rules are evaluated against.
+	// If one does not exist, a new Cache is created and returned
+	GetReferenced(context.Context, *v1.ClusterExtension) (cmcache.Cache, error)
+	// Delete will stop and remove the managed content cache and
+	// the referenced objects cache for the provided ClusterExtension
+	// if they exist.
 	Delete(*v1.ClusterExtension) error
 }
 
@@ -38,6 +45,7 @@ type managerImpl struct {
 	rcm          RestConfigMapper
 	baseCfg      *rest.Config
 	caches       map[string]cmcache.Cache
+	refCaches    map[string]cmcache.Cache
 	mapper       meta.RESTMapper
 	mu           *sync.Mutex
 	syncTimeout  time.Duration
@@ -68,6 +76,7 @@ func NewManager(rcm RestConfigMapper, cfg *rest.Config, mapper meta.RESTMapper,
 		rcm:          rcm,
 		baseCfg:      cfg,
 		caches:       make(map[string]cmcache.Cache),
+		refCaches:    make(map[string]cmcache.Cache),
 		mapper:       mapper,
 		mu:           &sync.Mutex{},
 		syncTimeout:  time.Second * 10,
@@ -96,14 +105,9 @@ func (i *managerImpl) Get(ctx context.Context, ce *v1.ClusterExtension) (cmcache
 		return cache, nil
 	}
 
-	cfg, err := i.rcm(ctx, ce, i.baseCfg)
-	if err != nil {
-		return nil, fmt.Errorf("getting rest.Config: %w", err)
-	}
-
-	dynamicClient, err := dynamic.NewForConfig(cfg)
+	dynamicClient, err := i.dynamicClient(ctx, ce)
 	if err != nil {
-		return nil, fmt.Errorf("getting dynamic client: %w", err)
+		return nil, err
 	}
 
 	tgtLabels := labels.Set{
@@ -128,7 +132,60 @@ func (i *managerImpl) Get(ctx context.Context, ce *v1.ClusterExtension) (cmcache
 	return cache, nil
 }
 
-// Delete stops and removes the Cache for the provided ClusterExtension
+// GetReferenced returns a Cache of the objects referenced by the
+// provided ClusterExtension. If a cache does not already exist, a
+// new one will be created.
+// If a nil ClusterExtension is provided this function will panic.
+func (i *managerImpl) GetReferenced(ctx context.Context, ce *v1.ClusterExtension) (cmcache.Cache, error) {
+	if ce == nil {
+		panic("nil ClusterExtension provided")
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	cache, ok := i.refCaches[ce.Name]
+	if ok {
+		return cache, nil
+	}
+
+	dynamicClient, err := i.dynamicClient(ctx, ce)
+	if err != nil {
+		return nil, err
+	}
+
+	// Referenced objects are not labeled as belonging to the ClusterExtension,
+	// so each of them is watched on its own, by namespace and name, rather
+	// than every object of its kind being watched.
+	cache = newReferencedCache(func(namespace, name string) cmcache.Cache {
+		dynamicSourcerer := &dynamicSourcerer{
+			informerFactoryCreateFunc: func() dynamicinformer.DynamicSharedInformerFactory {
+				return dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicClient, time.Hour*10, namespace, func(lo *metav1.ListOptions) {
+					lo.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+				})
+			},
+			mapper:     i.mapper,
+			referenced: true,
+		}
+		return cmcache.NewCache(dynamicSourcerer, ce, i.syncTimeout)
+	})
+	i.refCaches[ce.Name] = cache
+	return cache, nil
+}
+
+func (i *managerImpl) dynamicClient(ctx context.Context, ce *v1.ClusterExtension) (dynamic.Interface, error) {
+	cfg, err := i.rcm(ctx, ce, i.baseCfg)
+	if err != nil {
+		return nil, fmt.Errorf("getting rest.Config: %w", err)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("getting dynamic client: %w", err)
+	}
+	return dynamicClient, nil
+}
+
+// Delete stops and removes the Caches for the provided ClusterExtension
 func (i *managerImpl) Delete(ce *v1.ClusterExtension) error {
 	if ce == nil {
 		panic("nil ClusterExtension provided")
@@ -143,5 +200,12 @@ func (i *managerImpl) Delete(ce *v1.ClusterExtension) error {
 		}
 		delete(i.caches, ce.Name)
 	}
+	if cache, ok := i.refCaches[ce.Name]; ok {
+		err := cache.Close()
+		if err != nil {
+			return fmt.Errorf("closing referenced objects cache: %w", err)
+		}
+		delete(i.refCaches, ce.Name)
+	}
 	return nil
 }
@@ -0,0 +1,122 @@
+package contentmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cmcache "github.com/operator-framework/operator-controller/internal/contentmanager/cache"
+)
+
+// referencedObjectKey identifies an object watched by a referencedCache.
+type referencedObjectKey struct {
+	gvk       schema.GroupVersionKind
+	namespace string
+	name      string
+}
+
+func referencedObjectKeyFor(obj client.Object) referencedObjectKey {
+	return referencedObjectKey{
+		gvk:       obj.GetObjectKind().GroupVersionKind(),
+		namespace: obj.GetNamespace(),
+		name:      obj.GetName(),
+	}
+}
+
+// referencedCache is a Cache that watches every object with its own
+// Cache, so that only the referenced objects are watched rather than
+// every object of their kinds.
+type referencedCache struct {
+	// newCache returns a Cache that watches only
+	// the object with the given namespace and name
+	newCache func(namespace, name string) cmcache.Cache
+	caches   map[referencedObjectKey]cmcache.Cache
+	mu       sync.Mutex
+}
+
+func newReferencedCache(newCache func(namespace, name string) cmcache.Cache) cmcache.Cache {
+	return &referencedCache{
+		newCache: newCache,
+		caches:   make(map[referencedObjectKey]cmcache.Cache),
+	}
+}
+
+var _ cmcache.Cache = (*referencedCache)(nil)
+
+func (c *referencedCache) Watch(ctx context.Context, watcher cmcache.Watcher, objs ...client.Object) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys := sets.New[referencedObjectKey]()
+	for _, obj := range objs {
+		keys.Insert(referencedObjectKeyFor(obj))
+	}
+
+	errs := []error{}
+	for key, cache := range c.caches {
+		if keys.Has(key) {
+			continue
+		}
+		if err := cache.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("closing cache of %s %s/%s: %w", key.gvk.Kind, key.namespace, key.name, err))
+		}
+		delete(c.caches, key)
+	}
+
+	for _, obj := range objs {
+		key := referencedObjectKeyFor(obj)
+		cache, ok := c.caches[key]
+		if !ok {
+			cache = c.newCache(key.namespace, key.name)
+			c.caches[key] = cache
+		}
+		// Watching an already watched object restarts
+		// its watch if it stopped after an error
+		if err := cache.Watch(ctx, watcher, obj); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	slices.SortFunc(errs, func(a, b error) int {
+		return strings.Compare(a.Error(), b.Error())
+	})
+
+	return errors.Join(errs...)
+}
+
+func (c *referencedCache) Get(obj client.Object) (*unstructured.Unstructured, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := referencedObjectKeyFor(obj)
+	cache, ok := c.caches[key]
+	if !ok {
+		return nil, false, fmt.Errorf("%s %s/%s is not watched", key.gvk.Kind, key.namespace, key.name)
+	}
+	return cache.Get(obj)
+}
+
+func (c *referencedCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	errs := []error{}
+	for _, cache := range c.caches {
+		if err := cache.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	slices.SortFunc(errs, func(a, b error) int {
+		return strings.Compare(a.Error(), b.Error())
+	})
+
+	return errors.Join(errs...)
+}
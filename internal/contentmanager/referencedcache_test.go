@@ -0,0 +1,91 @@
+package contentmanager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cmcache "github.com/operator-framework/operator-controller/internal/contentmanager/cache"
+)
+
+type mockObjectCache struct {
+	namespace, name string
+	watched         []client.Object
+	closed          bool
+}
+
+var _ cmcache.Cache = (*mockObjectCache)(nil)
+
+func (m *mockObjectCache) Watch(_ context.Context, _ cmcache.Watcher, objs ...client.Object) error {
+	m.watched = objs
+	return nil
+}
+
+func (m *mockObjectCache) Get(obj client.Object) (*unstructured.Unstructured, bool, error) {
+	u := &unstructured.Unstructured{}
+	u.SetNamespace(obj.GetNamespace())
+	u.SetName(obj.GetName())
+	return u, true, nil
+}
+
+func (m *mockObjectCache) Close() error {
+	m.closed = true
+	return nil
+}
+
+func TestReferencedCacheWatchesEachObject(t *testing.T) {
+	created := []*mockObjectCache{}
+	c := newReferencedCache(func(namespace, name string) cmcache.Cache {
+		cache := &mockObjectCache{namespace: namespace, name: name}
+		created = append(created, cache)
+		return cache
+	})
+
+	deployment := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "manager"},
+	}
+	configMap := &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "settings"},
+	}
+	otherConfigMap := &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "settings"},
+	}
+
+	require.NoError(t, c.Watch(context.Background(), nil, deployment, configMap))
+	require.Len(t, created, 2)
+	assert.Equal(t, "ns", created[0].namespace)
+	assert.Equal(t, "manager", created[0].name)
+	assert.Equal(t, []client.Object{deployment}, created[0].watched)
+	assert.Equal(t, "ns", created[1].namespace)
+	assert.Equal(t, "settings", created[1].name)
+	assert.Equal(t, []client.Object{configMap}, created[1].watched)
+
+	observed, found, err := c.Get(configMap)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "settings", observed.GetName())
+
+	_, _, err = c.Get(otherConfigMap)
+	require.EqualError(t, err, "ConfigMap other/settings is not watched")
+
+	t.Log("Objects that are no longer referenced stop being watched")
+	require.NoError(t, c.Watch(context.Background(), nil, configMap, otherConfigMap))
+	require.Len(t, created, 3)
+	assert.True(t, created[0].closed)
+	assert.False(t, created[1].closed)
+	assert.Equal(t, "other", created[2].namespace)
+
+	require.NoError(t, c.Close())
+	assert.True(t, created[1].closed)
+	assert.True(t, created[2].closed)
+}
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -72,6 +73,7 @@ type dynamicInformerSource struct {
 	cfg            DynamicSourceConfig
 	informerCancel context.CancelFunc
 	informerCtx    context.Context
+	informer       cgocache.SharedIndexInformer
 	startedChan    chan struct{}
 	syncedChan     chan struct{}
 	erroredChan    chan struct{}
@@ -84,12 +86,6 @@ func (dis *dynamicInformerSource) String() string {
 }
 
 func (dis *dynamicInformerSource) Start(ctx context.Context, q workqueue.TypedRateLimitingInterface[reconcile.Request]) error {
-	// Close the startedChan to signal that this
-	// source has been started. Subsequent calls
-	// to Start will attempt to close a closed channel
-	// and panic.
-	close(dis.startedChan)
-
 	dis.informerCtx, dis.informerCancel = context.WithCancel(ctx)
 	gInf := dis.cfg.DynamicInformerFactory.ForResource(dis.cfg.GVR)
 	eventHandler := source.NewEventHandler(dis.informerCtx, q, dis.cfg.Handler, dis.cfg.Predicates)
@@ -102,6 +98,15 @@ func (dis *dynamicInformerSource) Start(ctx context.Context, q workqueue.TypedRa
 	// Only if we have successfully synced in the past should we
 	// requeue the ClusterExtension
 	sharedIndexInf := gInf.Informer()
+	dis.informer = sharedIndexInf
+
+	// Close the startedChan to signal that this
+	// source has been started, only once the informer
+	// that Get and Close use is set. Subsequent calls
+	// to Start will attempt to close a closed channel
+	// and panic.
+	close(dis.startedChan)
+
 	err := sharedIndexInf.SetWatchErrorHandler(func(r *cgocache.Reflector, err error) {
 		dis.errOnce.Do(func() {
 			dis.err = err
@@ -168,6 +173,29 @@ func (dis *dynamicInformerSource) WaitForSync(ctx context.Context) error {
 	}
 }
 
+// Get returns the object with the given namespace and name as last
+// observed by the informer of this source. It returns false if the
+// informer has not observed such an object.
+func (dis *dynamicInformerSource) Get(namespace, name string) (*unstructured.Unstructured, bool, error) {
+	if !dis.hasStarted() {
+		return nil, false, errors.New("source has not yet started")
+	}
+
+	key := name
+	if namespace != "" {
+		key = namespace + "/" + name
+	}
+	item, found, err := dis.informer.GetStore().GetByKey(key)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	obj, ok := item.(*unstructured.Unstructured)
+	if !ok {
+		return nil, false, fmt.Errorf("unexpected object type %T in informer store", item)
+	}
+	return obj, true, nil
+}
+
 func (dis *dynamicInformerSource) Close() error {
 	if !dis.hasStarted() {
 		return errors.New("source has not yet started")
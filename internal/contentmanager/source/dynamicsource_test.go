@@ -22,6 +22,12 @@ func TestDynamicInformerSourceCloseBeforeStartErrors(t *testing.T) {
 	require.Error(t, dis.Close(), "calling close before start should error")
 }
 
+func TestDynamicInformerSourceGetBeforeStartErrors(t *testing.T) {
+	dis := NewDynamicSource(DynamicSourceConfig{})
+	_, _, err := dis.Get("ns", "name")
+	require.Error(t, err, "calling get before start should error")
+}
+
 func TestDynamicInformerSourceWaitForSyncTimeout(t *testing.T) {
 	dis := NewDynamicSource(DynamicSourceConfig{})
 	close(dis.startedChan)
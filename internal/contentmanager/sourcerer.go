@@ -9,11 +9,13 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/operator-framework/operator-controller/api/v1"
 	"github.com/operator-framework/operator-controller/internal/contentmanager/cache"
@@ -23,6 +25,10 @@ import (
 type dynamicSourcerer struct {
 	informerFactoryCreateFunc func() dynamicinformer.DynamicSharedInformerFactory
 	mapper                    meta.RESTMapper
+	// referenced is set when the sources watch objects that are referenced
+	// by the owner rather than managed by it. Those objects have no owner
+	// reference, so their events are mapped to the owner directly.
+	referenced bool
 }
 
 func (ds *dynamicSourcerer) Source(gvk schema.GroupVersionKind, owner client.Object, onPostSyncError func(context.Context)) (cache.CloserSyncingSource, error) {
@@ -36,18 +42,29 @@ func (ds *dynamicSourcerer) Source(gvk schema.GroupVersionKind, owner client.Obj
 		return nil, fmt.Errorf("getting resource mapping for GVK %q: %w", gvk, err)
 	}
 
-	s := source.NewDynamicSource(source.DynamicSourceConfig{
-		GVR:     restMapping.Resource,
-		Owner:   owner,
-		Handler: handler.EnqueueRequestForOwner(scheme, ds.mapper, owner, handler.OnlyControllerOwner()),
-		Predicates: []predicate.Predicate{
-			predicate.Funcs{
-				CreateFunc:  func(tce event.TypedCreateEvent[client.Object]) bool { return false },
-				UpdateFunc:  func(tue event.TypedUpdateEvent[client.Object]) bool { return true },
-				DeleteFunc:  func(tde event.TypedDeleteEvent[client.Object]) bool { return true },
-				GenericFunc: func(tge event.TypedGenericEvent[client.Object]) bool { return true },
-			},
+	eventHandler := handler.EnqueueRequestForOwner(scheme, ds.mapper, owner, handler.OnlyControllerOwner())
+	predicates := []predicate.Predicate{
+		predicate.Funcs{
+			CreateFunc:  func(tce event.TypedCreateEvent[client.Object]) bool { return false },
+			UpdateFunc:  func(tue event.TypedUpdateEvent[client.Object]) bool { return true },
+			DeleteFunc:  func(tde event.TypedDeleteEvent[client.Object]) bool { return true },
+			GenericFunc: func(tge event.TypedGenericEvent[client.Object]) bool { return true },
 		},
+	}
+	if ds.referenced {
+		// Referenced objects may be created after the owner,
+		// so their creation is reacted to as well
+		eventHandler = handler.EnqueueRequestsFromMapFunc(func(context.Context, client.Object) []reconcile.Request {
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: owner.GetName()}}}
+		})
+		predicates = nil
+	}
+
+	s := source.NewDynamicSource(source.DynamicSourceConfig{
+		GVR:                    restMapping.Resource,
+		Owner:                  owner,
+		Handler:                eventHandler,
+		Predicates:             predicates,
 		DynamicInformerFactory: ds.informerFactoryCreateFunc(),
 		OnPostSyncError:        onPostSyncError,
 	})
@@ -2,8 +2,10 @@ package controllers_test
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -15,7 +17,7 @@ func TestClusterExtensionSourceConfig(t *testing.T) {
 	sourceTypeEmptyError := "Invalid value: \"null\""
 	sourceTypeMismatchError := "spec.source.sourceType: Unsupported value"
 	sourceConfigInvalidError := "spec.source: Invalid value"
-	// unionField represents the required Catalog or (future) Bundle field required by SourceConfig
+	// unionField represents the required Catalog or Image field required by SourceConfig
 	testCases := []struct {
 		name       string
 		sourceType string
@@ -26,6 +28,10 @@ func TestClusterExtensionSourceConfig(t *testing.T) {
 		{"sourceType is invalid", "Invalid", "Catalog", sourceTypeMismatchError},
 		{"catalog field does not exist", "Catalog", "", sourceConfigInvalidError},
 		{"sourceConfig has required fields", "Catalog", "Catalog", ""},
+		{"image field does not exist", "Image", "", sourceConfigInvalidError},
+		{"image field set with sourceType Catalog", "Catalog", "Image", sourceConfigInvalidError},
+		{"catalog field set with sourceType Image", "Image", "Catalog", sourceConfigInvalidError},
+		{"image sourceConfig has required fields", "Image", "Image", ""},
 	}
 
 	t.Parallel()
@@ -49,6 +55,20 @@ func TestClusterExtensionSourceConfig(t *testing.T) {
 					},
 				}))
 			}
+			if tc.unionField == "Image" {
+				err = cl.Create(context.Background(), buildClusterExtension(ocv1.ClusterExtensionSpec{
+					Source: ocv1.SourceConfig{
+						SourceType: tc.sourceType,
+						Image: &ocv1.ImageSource{
+							Ref: "quay.io/example/test-bundle:v1.0.0",
+						},
+					},
+					Namespace: "default",
+					ServiceAccount: ocv1.ServiceAccountReference{
+						Name: "default",
+					},
+				}))
+			}
 			if tc.unionField == "" {
 				err = cl.Create(context.Background(), buildClusterExtension(ocv1.ClusterExtensionSpec{
 					Source: ocv1.SourceConfig{
@@ -281,6 +301,155 @@ func TestClusterExtensionAdmissionChannel(t *testing.T) {
 	}
 }
 
+func TestClusterExtensionAdmissionUpgradeApproval(t *testing.T) {
+	enumError := "spec.source.catalog.upgradeApproval: Unsupported value"
+	tooLongError := "spec.source.catalog.approvedVersion: Too long: may not be longer than 64"
+	regexMismatchError := "approvedVersion must be well-formed semver"
+
+	testCases := []struct {
+		name            string
+		upgradeApproval ocv1.UpgradeApproval
+		approvedVersion string
+		errMsg          string
+	}{
+		{"default upgrade approval", "", "", ""},
+		{"automatic upgrade approval", ocv1.UpgradeApprovalAutomatic, "", ""},
+		{"manual upgrade approval", ocv1.UpgradeApprovalManual, "", ""},
+		{"manual upgrade approval with approved version", ocv1.UpgradeApprovalManual, "1.2.3", ""},
+		{"approved version with pre-release", ocv1.UpgradeApprovalManual, "1.2.3-alpha.1", ""},
+		{"invalid upgrade approval", "Sometimes", "", enumError},
+		{"approved version range", ocv1.UpgradeApprovalManual, ">=1.2.3", regexMismatchError},
+		{"invalid approved version", ocv1.UpgradeApprovalManual, "invalid-semver", regexMismatchError},
+		{"too long approved version", ocv1.UpgradeApprovalManual, strings.Repeat("1", 65), tooLongError},
+	}
+
+	t.Parallel()
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			cl := newClient(t)
+			err := cl.Create(context.Background(), buildClusterExtension(ocv1.ClusterExtensionSpec{
+				Source: ocv1.SourceConfig{
+					SourceType: "Catalog",
+					Catalog: &ocv1.CatalogSource{
+						PackageName:     "package",
+						UpgradeApproval: tc.upgradeApproval,
+						ApprovedVersion: tc.approvedVersion,
+					},
+				},
+				Namespace: "default",
+				ServiceAccount: ocv1.ServiceAccountReference{
+					Name: "default",
+				},
+			}))
+			if tc.errMsg == "" {
+				require.NoError(t, err, "unexpected error for upgradeApproval %q and approvedVersion %q: %w", tc.upgradeApproval, tc.approvedVersion, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestClusterExtensionAdmissionPermissionEscalation(t *testing.T) {
+	enumError := "spec.install.permissionEscalation: Unsupported value"
+	tooLongError := "spec.install.approvedPermissionEscalationVersion: Too long: may not be longer than 64"
+	regexMismatchError := "approvedPermissionEscalationVersion must be well-formed semver"
+
+	testCases := []struct {
+		name                 string
+		permissionEscalation ocv1.PermissionEscalationPolicy
+		approvedVersion      string
+		errMsg               string
+	}{
+		{"default permission escalation policy", "", "", ""},
+		{"allow permission escalation", ocv1.PermissionEscalationAllow, "", ""},
+		{"require approval of permission escalation", ocv1.PermissionEscalationRequireApproval, "", ""},
+		{"approved permission escalation", ocv1.PermissionEscalationRequireApproval, "1.2.3", ""},
+		{"invalid permission escalation policy", "Deny", "", enumError},
+		{"approved version range", ocv1.PermissionEscalationRequireApproval, ">=1.2.3", regexMismatchError},
+		{"too long approved version", ocv1.PermissionEscalationRequireApproval, strings.Repeat("1", 65), tooLongError},
+	}
+
+	t.Parallel()
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			cl := newClient(t)
+			err := cl.Create(context.Background(), buildClusterExtension(ocv1.ClusterExtensionSpec{
+				Source: ocv1.SourceConfig{
+					SourceType: "Catalog",
+					Catalog: &ocv1.CatalogSource{
+						PackageName: "package",
+					},
+				},
+				Namespace: "default",
+				ServiceAccount: ocv1.ServiceAccountReference{
+					Name: "default",
+				},
+				Install: &ocv1.ClusterExtensionInstallConfig{
+					PermissionEscalation:                tc.permissionEscalation,
+					ApprovedPermissionEscalationVersion: tc.approvedVersion,
+				},
+			}))
+			if tc.errMsg == "" {
+				require.NoError(t, err, "unexpected error for permissionEscalation %q and approvedPermissionEscalationVersion %q: %w", tc.permissionEscalation, tc.approvedVersion, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestClusterExtensionAdmissionAutoUpgrade(t *testing.T) {
+	enumError := "spec.source.catalog.autoUpgrade: Unsupported value"
+
+	testCases := []struct {
+		name        string
+		autoUpgrade ocv1.AutoUpgradePolicy
+		errMsg      string
+	}{
+		{"default auto upgrade policy", "", ""},
+		{"auto upgrade PatchOnly", ocv1.AutoUpgradePolicyPatchOnly, ""},
+		{"auto upgrade MinorAndPatch", ocv1.AutoUpgradePolicyMinorAndPatch, ""},
+		{"auto upgrade All", ocv1.AutoUpgradePolicyAll, ""},
+		{"auto upgrade None", ocv1.AutoUpgradePolicyNone, ""},
+		{"invalid auto upgrade policy", "MajorOnly", enumError},
+	}
+
+	t.Parallel()
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			cl := newClient(t)
+			err := cl.Create(context.Background(), buildClusterExtension(ocv1.ClusterExtensionSpec{
+				Source: ocv1.SourceConfig{
+					SourceType: "Catalog",
+					Catalog: &ocv1.CatalogSource{
+						PackageName: "package",
+						AutoUpgrade: tc.autoUpgrade,
+					},
+				},
+				Namespace: "default",
+				ServiceAccount: ocv1.ServiceAccountReference{
+					Name: "default",
+				},
+			}))
+			if tc.errMsg == "" {
+				require.NoError(t, err, "unexpected error for autoUpgrade %q: %w", tc.autoUpgrade, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			}
+		})
+	}
+}
+
 func TestClusterExtensionAdmissionInstallNamespace(t *testing.T) {
 	tooLongError := "spec.namespace: Too long: may not be longer than 63"
 	regexMismatchError := "namespace must be a valid DNS1123 label"
@@ -388,8 +557,59 @@ func TestClusterExtensionAdmissionServiceAccount(t *testing.T) {
 	}
 }
 
+func TestClusterExtensionAdmissionImage(t *testing.T) {
+	refTooShortError := "spec.source.image.ref: Invalid value: \"\": spec.source.image.ref in body should be at least 1 chars long"
+	refTooLongError := "spec.source.image.ref: Too long: may not be longer than 1000"
+	pullSecretTooLongError := "spec.source.image.pullSecret: Too long: may not be longer than 253"
+	pullSecretRegexMismatchError := "pullSecret must be a valid DNS1123 subdomain"
+
+	testCases := []struct {
+		name       string
+		ref        string
+		pullSecret string
+		errMsg     string
+	}{
+		{"ref with tag", "quay.io/example/test-bundle:v1.0.0", "", ""},
+		{"ref with digest", "quay.io/example/test-bundle@sha256:9bc8b2e0c3e7d6f9d8d2a4c4e2f7b4c0f6c8e9b0d1a2c3e4f5a6b7c8d9e0f1a2", "", ""},
+		{"empty ref", "", "", refTooShortError},
+		{"long ref", strings.Repeat("x", 1001), "", refTooLongError},
+		{"valid pull secret", "quay.io/example/test-bundle:v1.0.0", "my-pull-secret", ""},
+		{"long pull secret", "quay.io/example/test-bundle:v1.0.0", strings.Repeat("x", 254), pullSecretTooLongError},
+		{"uppercase pull secret", "quay.io/example/test-bundle:v1.0.0", "My-Pull-Secret", pullSecretRegexMismatchError},
+		{"underscore separated pull secret", "quay.io/example/test-bundle:v1.0.0", "my_pull_secret", pullSecretRegexMismatchError},
+	}
+
+	t.Parallel()
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			cl := newClient(t)
+			err := cl.Create(context.Background(), buildClusterExtension(ocv1.ClusterExtensionSpec{
+				Source: ocv1.SourceConfig{
+					SourceType: "Image",
+					Image: &ocv1.ImageSource{
+						Ref:        tc.ref,
+						PullSecret: tc.pullSecret,
+					},
+				},
+				Namespace: "default",
+				ServiceAccount: ocv1.ServiceAccountReference{
+					Name: "default",
+				},
+			}))
+			if tc.errMsg == "" {
+				require.NoError(t, err, "unexpected error for image ref %q and pull secret %q: %w", tc.ref, tc.pullSecret, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			}
+		})
+	}
+}
+
 func TestClusterExtensionAdmissionInstall(t *testing.T) {
-	oneOfErrMsg := "at least one of [preflight] are required when install is specified"
+	oneOfErrMsg := "at least one of [preflight, watchNamespaces, config, maintenanceWindows, rollback, rollbackTo, dependencyPolicy, permissionEscalation, approvedPermissionEscalationVersion, mode] are required when install is specified"
 
 	testCases := []struct {
 		name          string
@@ -412,6 +632,43 @@ func TestClusterExtensionAdmissionInstall(t *testing.T) {
 			},
 			errMsg: "",
 		},
+		{
+			name: "install specified, serviceAccount and kubeVersion preflights configured",
+			installConfig: &ocv1.ClusterExtensionInstallConfig{
+				Preflight: &ocv1.PreflightConfig{
+					ServiceAccount: &ocv1.ServiceAccountPreflightConfig{
+						Enforcement: ocv1.ServiceAccountEnforcementNone,
+					},
+					KubeVersion: &ocv1.KubeVersionPreflightConfig{
+						Enforcement: ocv1.KubeVersionEnforcementStrict,
+					},
+				},
+			},
+			errMsg: "",
+		},
+		{
+			name: "install specified, preflight without any preflight configured",
+			installConfig: &ocv1.ClusterExtensionInstallConfig{
+				Preflight: &ocv1.PreflightConfig{},
+			},
+			errMsg: "at least one of [crdUpgradeSafety, serviceAccount, kubeVersion] are required when preflight is specified",
+		},
+		{
+			name: "install specified, watchNamespaces configured",
+			installConfig: &ocv1.ClusterExtensionInstallConfig{
+				WatchNamespaces: []string{"default"},
+			},
+			errMsg: "",
+		},
+		{
+			name: "install specified, config configured",
+			installConfig: &ocv1.ClusterExtensionInstallConfig{
+				Config: &ocv1.DeploymentConfig{
+					NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+				},
+			},
+			errMsg: "",
+		},
 		{
 			name:          "install not specified",
 			installConfig: nil,
@@ -448,6 +705,294 @@ func TestClusterExtensionAdmissionInstall(t *testing.T) {
 	}
 }
 
+func TestClusterExtensionAdmissionMaintenanceWindows(t *testing.T) {
+	requiredScheduleError := "should be at least 1 chars long"
+	tooLongScheduleError := "spec.install.maintenanceWindows[0].schedule: Too long: may not be longer than 100"
+	tooShortDurationError := "duration must be at least 1m"
+	tooLongTimeZoneError := "spec.install.maintenanceWindows[0].timeZone: Too long: may not be longer than 64"
+	tooManyError := "spec.install.maintenanceWindows: Too many: 17: must have at most 16 items"
+
+	daily := ocv1.MaintenanceWindow{Schedule: "0 2 * * *", Duration: metav1.Duration{Duration: time.Hour}}
+	manyWindows := make([]ocv1.MaintenanceWindow, 0, 17)
+	for range 17 {
+		manyWindows = append(manyWindows, daily)
+	}
+
+	testCases := []struct {
+		name    string
+		windows []ocv1.MaintenanceWindow
+		errMsg  string
+	}{
+		{"daily window", []ocv1.MaintenanceWindow{daily}, ""},
+		{"window with time zone", []ocv1.MaintenanceWindow{{Schedule: "30 22 * * SAT", Duration: metav1.Duration{Duration: 90 * time.Minute}, TimeZone: "Europe/Berlin"}}, ""},
+		{"empty schedule", []ocv1.MaintenanceWindow{{Duration: metav1.Duration{Duration: time.Hour}}}, requiredScheduleError},
+		{"too long schedule", []ocv1.MaintenanceWindow{{Schedule: strings.Repeat("*", 101), Duration: metav1.Duration{Duration: time.Hour}}}, tooLongScheduleError},
+		{"too short duration", []ocv1.MaintenanceWindow{{Schedule: "@daily", Duration: metav1.Duration{Duration: time.Second}}}, tooShortDurationError},
+		{"too long time zone", []ocv1.MaintenanceWindow{{Schedule: "@daily", Duration: metav1.Duration{Duration: time.Hour}, TimeZone: strings.Repeat("x", 65)}}, tooLongTimeZoneError},
+		{"too many windows", manyWindows, tooManyError},
+	}
+
+	t.Parallel()
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			cl := newClient(t)
+			err := cl.Create(context.Background(), buildClusterExtension(ocv1.ClusterExtensionSpec{
+				Source: ocv1.SourceConfig{
+					SourceType: "Catalog",
+					Catalog: &ocv1.CatalogSource{
+						PackageName: "package",
+					},
+				},
+				Namespace: "default",
+				ServiceAccount: ocv1.ServiceAccountReference{
+					Name: "default",
+				},
+				Install: &ocv1.ClusterExtensionInstallConfig{
+					MaintenanceWindows: tc.windows,
+				},
+			}))
+			if tc.errMsg == "" {
+				require.NoError(t, err, "unexpected error for maintenanceWindows %v: %w", tc.windows, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestClusterExtensionAdmissionRollback(t *testing.T) {
+	enumError := "spec.install.rollback.policy: Unsupported value"
+	timeoutRangeError := "healthCheckTimeout must be between 10s and 10m"
+	timeoutPolicyError := "healthCheckTimeout is only allowed when policy is Automatic"
+
+	testCases := []struct {
+		name     string
+		rollback *ocv1.RollbackConfig
+		errMsg   string
+	}{
+		{"policy None", &ocv1.RollbackConfig{Policy: ocv1.RollbackPolicyNone}, ""},
+		{"policy Automatic", &ocv1.RollbackConfig{Policy: ocv1.RollbackPolicyAutomatic}, ""},
+		{"policy Automatic with health check", &ocv1.RollbackConfig{Policy: ocv1.RollbackPolicyAutomatic, HealthCheckTimeout: &metav1.Duration{Duration: 5 * time.Minute}}, ""},
+		{"invalid policy", &ocv1.RollbackConfig{Policy: "Sometimes"}, enumError},
+		{"too short health check", &ocv1.RollbackConfig{Policy: ocv1.RollbackPolicyAutomatic, HealthCheckTimeout: &metav1.Duration{Duration: time.Second}}, timeoutRangeError},
+		{"too long health check", &ocv1.RollbackConfig{Policy: ocv1.RollbackPolicyAutomatic, HealthCheckTimeout: &metav1.Duration{Duration: time.Hour}}, timeoutRangeError},
+		{"health check without rollback", &ocv1.RollbackConfig{Policy: ocv1.RollbackPolicyNone, HealthCheckTimeout: &metav1.Duration{Duration: time.Minute}}, timeoutPolicyError},
+	}
+
+	t.Parallel()
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			cl := newClient(t)
+			err := cl.Create(context.Background(), buildClusterExtension(ocv1.ClusterExtensionSpec{
+				Source: ocv1.SourceConfig{
+					SourceType: "Catalog",
+					Catalog: &ocv1.CatalogSource{
+						PackageName: "package",
+					},
+				},
+				Namespace: "default",
+				ServiceAccount: ocv1.ServiceAccountReference{
+					Name: "default",
+				},
+				Install: &ocv1.ClusterExtensionInstallConfig{
+					Rollback: tc.rollback,
+				},
+			}))
+			if tc.errMsg == "" {
+				require.NoError(t, err, "unexpected error for rollback %v: %w", tc.rollback, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestClusterExtensionAdmissionRollbackTo(t *testing.T) {
+	minimumError := "spec.install.rollbackTo.revision: Invalid value: -1: spec.install.rollbackTo.revision in body should be greater than or equal to 1"
+
+	testCases := []struct {
+		name       string
+		rollbackTo *ocv1.RollbackTarget
+		errMsg     string
+	}{
+		{"previous revision", &ocv1.RollbackTarget{}, ""},
+		{"explicit revision", &ocv1.RollbackTarget{Revision: 3}, ""},
+		{"negative revision", &ocv1.RollbackTarget{Revision: -1}, minimumError},
+	}
+
+	t.Parallel()
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			cl := newClient(t)
+			err := cl.Create(context.Background(), buildClusterExtension(ocv1.ClusterExtensionSpec{
+				Source: ocv1.SourceConfig{
+					SourceType: "Catalog",
+					Catalog: &ocv1.CatalogSource{
+						PackageName: "package",
+					},
+				},
+				Namespace: "default",
+				ServiceAccount: ocv1.ServiceAccountReference{
+					Name: "default",
+				},
+				Install: &ocv1.ClusterExtensionInstallConfig{
+					RollbackTo: tc.rollbackTo,
+				},
+			}))
+			if tc.errMsg == "" {
+				require.NoError(t, err, "unexpected error for rollbackTo %v: %w", tc.rollbackTo, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestClusterExtensionAdmissionHealthRules(t *testing.T) {
+	validRule := func() ocv1.HealthRule {
+		return ocv1.HealthRule{
+			Name: "foo-ready",
+			Object: ocv1.HealthRuleObjectReference{
+				APIVersion: "example.com/v1",
+				Kind:       "Foo",
+				Name:       "default",
+				Namespace:  "default",
+			},
+			Expression: "self.status.phase == 'Ready'",
+		}
+	}
+
+	testCases := []struct {
+		name   string
+		rules  func() []ocv1.HealthRule
+		errMsg string
+	}{
+		{"valid rule", func() []ocv1.HealthRule { return []ocv1.HealthRule{validRule()} }, ""},
+		{"cluster-scoped object", func() []ocv1.HealthRule {
+			rule := validRule()
+			rule.Object.Namespace = ""
+			return []ocv1.HealthRule{rule}
+		}, ""},
+		{"no rules", func() []ocv1.HealthRule { return []ocv1.HealthRule{} }, "spec.health.rules in body should have at least 1 items"},
+		{"invalid name", func() []ocv1.HealthRule {
+			rule := validRule()
+			rule.Name = "Foo_Ready"
+			return []ocv1.HealthRule{rule}
+		}, "name must be a valid DNS1123 label"},
+		{"duplicate name", func() []ocv1.HealthRule { return []ocv1.HealthRule{validRule(), validRule()} }, "spec.health.rules[1]: Duplicate value"},
+		{"empty expression", func() []ocv1.HealthRule {
+			rule := validRule()
+			rule.Expression = ""
+			return []ocv1.HealthRule{rule}
+		}, "spec.health.rules[0].expression: Invalid value: \"\": spec.health.rules[0].expression in body should be at least 1 chars long"},
+		{"missing kind", func() []ocv1.HealthRule {
+			rule := validRule()
+			rule.Object.Kind = ""
+			return []ocv1.HealthRule{rule}
+		}, "spec.health.rules[0].object.kind in body should be at least 1 chars long"},
+	}
+
+	t.Parallel()
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			cl := newClient(t)
+			err := cl.Create(context.Background(), buildClusterExtension(ocv1.ClusterExtensionSpec{
+				Source: ocv1.SourceConfig{
+					SourceType: "Catalog",
+					Catalog: &ocv1.CatalogSource{
+						PackageName: "package",
+					},
+				},
+				Namespace: "default",
+				ServiceAccount: ocv1.ServiceAccountReference{
+					Name: "default",
+				},
+				Health: &ocv1.HealthConfig{
+					Rules: tc.rules(),
+				},
+			}))
+			if tc.errMsg == "" {
+				require.NoError(t, err, "unexpected error for health rules: %w", err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestClusterExtensionAdmissionWatchNamespaces(t *testing.T) {
+	tooLongError := "spec.install.watchNamespaces[0]: Too long: may not be longer than 63"
+	tooManyError := "spec.install.watchNamespaces: Too many: 65: must have at most 64 items"
+	// An empty list is omitted when serialized, leaving install empty.
+	emptyError := "at least one of [preflight, watchNamespaces, config, maintenanceWindows, rollback, rollbackTo, dependencyPolicy, permissionEscalation, approvedPermissionEscalationVersion, mode] are required when install is specified"
+	duplicateError := "spec.install.watchNamespaces[1]: Duplicate value"
+	regexMismatchError := "watchNamespaces entries must be valid DNS1123 labels"
+
+	manyNamespaces := make([]string, 0, 65)
+	for i := range 65 {
+		manyNamespaces = append(manyNamespaces, fmt.Sprintf("ns-%d", i))
+	}
+
+	testCases := []struct {
+		name            string
+		watchNamespaces []string
+		errMsg          string
+	}{
+		{"single namespace", []string{"default"}, ""},
+		{"multiple namespaces", []string{"foo", "bar"}, ""},
+		{"longest valid namespace", []string{strings.Repeat("x", 63)}, ""},
+		{"no namespaces", []string{}, emptyError},
+		{"too long namespace", []string{strings.Repeat("x", 64)}, tooLongError},
+		{"too many namespaces", manyNamespaces, tooManyError},
+		{"duplicate namespaces", []string{"foo", "foo"}, duplicateError},
+		{"empty namespace", []string{""}, regexMismatchError},
+		{"dot-separated", []string{"dotted.name"}, regexMismatchError},
+		{"capitalized", []string{"Capitalized"}, regexMismatchError},
+	}
+
+	t.Parallel()
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			cl := newClient(t)
+			err := cl.Create(context.Background(), buildClusterExtension(ocv1.ClusterExtensionSpec{
+				Source: ocv1.SourceConfig{
+					SourceType: "Catalog",
+					Catalog: &ocv1.CatalogSource{
+						PackageName: "package",
+					},
+				},
+				Namespace: "default",
+				ServiceAccount: ocv1.ServiceAccountReference{
+					Name: "default",
+				},
+				Install: &ocv1.ClusterExtensionInstallConfig{
+					WatchNamespaces: tc.watchNamespaces,
+				},
+			}))
+			if tc.errMsg == "" {
+				require.NoError(t, err, "unexpected error for watchNamespaces %v: %w", tc.watchNamespaces, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			}
+		})
+	}
+}
+
 func buildClusterExtension(spec ocv1.ClusterExtensionSpec) *ocv1.ClusterExtension {
 	return &ocv1.ClusterExtension{
 		ObjectMeta: metav1.ObjectMeta{
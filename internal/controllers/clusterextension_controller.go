@@ -17,26 +17,34 @@ limitations under the License.
 package controllers
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"io/fs"
+	"slices"
 	"strings"
 	"time"
 
+	bsemver "github.com/blang/semver/v4"
 	"github.com/go-logr/logr"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/storage/driver"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	crcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	crfinalizer "sigs.k8s.io/controller-runtime/pkg/finalizer"
 	crhandler "sigs.k8s.io/controller-runtime/pkg/handler"
@@ -50,23 +58,55 @@ import (
 	"github.com/operator-framework/operator-registry/alpha/declcfg"
 
 	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+	"github.com/operator-framework/operator-controller/internal/applier"
 	"github.com/operator-framework/operator-controller/internal/bundleutil"
 	"github.com/operator-framework/operator-controller/internal/conditionsets"
 	"github.com/operator-framework/operator-controller/internal/contentmanager"
+	cmcache "github.com/operator-framework/operator-controller/internal/contentmanager/cache"
+	"github.com/operator-framework/operator-controller/internal/health"
 	"github.com/operator-framework/operator-controller/internal/labels"
+	"github.com/operator-framework/operator-controller/internal/maintenance"
+	"github.com/operator-framework/operator-controller/internal/metrics"
 	"github.com/operator-framework/operator-controller/internal/resolve"
 	rukpaksource "github.com/operator-framework/operator-controller/internal/rukpak/source"
+	"github.com/operator-framework/operator-controller/internal/rukpak/util"
 )
 
 const (
 	ClusterExtensionCleanupUnpackCacheFinalizer         = "olm.operatorframework.io/cleanup-unpack-cache"
 	ClusterExtensionCleanupContentManagerCacheFinalizer = "olm.operatorframework.io/cleanup-contentmanager-cache"
+	ClusterExtensionUninstallFinalizer                  = "olm.operatorframework.io/uninstall"
+	ClusterExtensionCleanupCertificatesFinalizer        = "olm.operatorframework.io/cleanup-certificates"
+)
+
+// Reasons of the Events recorded for a ClusterExtension at each step of its lifecycle.
+const (
+	EventReasonResolved            = "Resolved"
+	EventReasonResolutionFailed    = "ResolutionFailed"
+	EventReasonMissingDependencies = "MissingDependencies"
+	EventReasonDependencyCreated   = "DependencyCreated"
+	EventReasonUnpacking           = "Unpacking"
+	EventReasonUnpacked            = "Unpacked"
+	EventReasonUnpackFailed        = "UnpackFailed"
+	EventReasonPreflightFailed     = "PreflightFailed"
+	EventReasonInstalled           = "Installed"
+	EventReasonUpgraded            = "Upgraded"
+	EventReasonUnchanged           = "Unchanged"
+	EventReasonApplyFailed         = "ApplyFailed"
+	EventReasonPlanned             = "Planned"
+	EventReasonRolledBack          = "RolledBack"
+	EventReasonRollbackFailed      = "RollbackFailed"
+	EventReasonDeprecated          = "Deprecated"
+	EventReasonNoLongerDeprecated  = "NoLongerDeprecated"
+	EventReasonFinalized           = "Finalized"
+	EventReasonFinalizeFailed      = "FinalizeFailed"
 )
 
 // ClusterExtensionReconciler reconciles a ClusterExtension object
 type ClusterExtensionReconciler struct {
 	client.Client
 	Resolver              resolve.Resolver
+	DependencyResolver    resolve.DependencyResolver
 	Unpacker              rukpaksource.Unpacker
 	Applier               Applier
 	Manager               contentmanager.Manager
@@ -74,6 +114,18 @@ type ClusterExtensionReconciler struct {
 	cache                 cache.Cache
 	InstalledBundleGetter InstalledBundleGetter
 	Finalizers            crfinalizer.Finalizers
+	Recorder              record.EventRecorder
+	// PermissionPreviewer computes the permission changes of pending upgrades.
+	// They are not reported when it is nil.
+	PermissionPreviewer PermissionPreviewer
+	// Planner computes the changes applying a bundle would make when
+	// spec.install.mode is Plan. Planning fails when it is nil.
+	Planner Planner
+	// PullSecretGetter returns the contents of the docker config JSON to use
+	// when pulling the bundle images of a ClusterExtension with an image source
+	// and a pull secret. It may be nil, in which case the unpacker's default
+	// credentials are used.
+	PullSecretGetter func(context.Context, *ocv1.ClusterExtension) ([]byte, error)
 }
 
 type Applier interface {
@@ -81,18 +133,47 @@ type Applier interface {
 	// It also takes in a map[string]string to be applied to all applied resources as labels and another
 	// map[string]string used to create a unique identifier for a stored reference to the resources created.
 	Apply(context.Context, fs.FS, *ocv1.ClusterExtension, map[string]string, map[string]string) ([]client.Object, string, error)
+	// RollbackTo re-applies a previously deployed revision of the content of the provided ClusterExtension,
+	// or the revision deployed before the current one when the revision is 0. It returns the applied
+	// resources and the labels stored with the revision that was rolled back to.
+	RollbackTo(context.Context, *ocv1.ClusterExtension, int) ([]client.Object, map[string]string, error)
+}
+
+type PermissionPreviewer interface {
+	// PermissionChanges returns the rules of the Roles and ClusterRoles, and the bindings of the RoleBindings
+	// and ClusterRoleBindings, of the content in the provided fs.FS that are added and removed compared to
+	// the content installed for the provided ClusterExtension. The bundle of the returned changes is not set.
+	// It also returns whether the content grants any permission that the installed content does not.
+	PermissionChanges(context.Context, fs.FS, *ocv1.ClusterExtension) (*ocv1.PermissionChanges, bool, error)
+}
+
+type Planner interface {
+	// Plan returns the objects that applying the content in the provided fs.FS using the configuration of the
+	// provided ClusterExtension would add, change or remove, without making any changes to the cluster. It also
+	// takes in the map[string]string of labels that would be applied to all applied resources.
+	Plan(context.Context, fs.FS, *ocv1.ClusterExtension, map[string]string) (*ocv1.ReleasePlan, error)
+}
+
+// PendingUpgradeUnpackName returns the name the bundle of the pending upgrade
+// of the ClusterExtension with the given name is unpacked with. It is kept
+// apart from the installed bundle, which is unpacked with the name of the
+// ClusterExtension, and cannot be the name of another ClusterExtension.
+func PendingUpgradeUnpackName(extName string) string {
+	return extName + "_pending-upgrade"
 }
 
 type InstalledBundleGetter interface {
 	GetInstalledBundle(ctx context.Context, ext *ocv1.ClusterExtension) (*InstalledBundle, error)
 }
 
-//+kubebuilder:rbac:groups=olm.operatorframework.io,resources=clusterextensions,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=olm.operatorframework.io,resources=clusterextensions,verbs=get;list;watch;create;update;patch
 //+kubebuilder:rbac:groups=olm.operatorframework.io,resources=clusterextensions/status,verbs=update;patch
 //+kubebuilder:rbac:groups=olm.operatorframework.io,resources=clusterextensions/finalizers,verbs=update
 //+kubebuilder:rbac:namespace=system,groups=core,resources=secrets,verbs=create;update;patch;delete;deletecollection;get;list;watch
 //+kubebuilder:rbac:groups=core,resources=serviceaccounts/token,verbs=create
 //+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
 
 //+kubebuilder:rbac:groups=olm.operatorframework.io,resources=clustercatalogs,verbs=list;watch
 
@@ -107,6 +188,9 @@ func (r *ClusterExtensionReconciler) Reconcile(ctx context.Context, req ctrl.Req
 
 	existingExt := &ocv1.ClusterExtension{}
 	if err := r.Client.Get(ctx, req.NamespacedName, existingExt); err != nil {
+		if apierrors.IsNotFound(err) {
+			metrics.DeleteInstalledExtension(req.Name)
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
@@ -173,9 +257,8 @@ func checkForUnexpectedFieldChange(a, b ocv1.ClusterExtension) bool {
 
 // Helper function to do the actual reconcile
 //
-// Today we always return ctrl.Result{} and an error.
-// But in the future we might update this function
-// to return different results (e.g. requeue).
+// It returns a ctrl.Result requesting a requeue when an upgrade is
+// deferred until the next maintenance window, and ctrl.Result{} otherwise.
 //
 /* The reconcile functions performs the following major tasks:
 1. Resolution: Run the resolution to find the bundle from the catalog which needs to be installed.
@@ -186,13 +269,23 @@ func checkForUnexpectedFieldChange(a, b ocv1.ClusterExtension) bool {
 4.2 Generating a chart from k8s objects.
 4.3 Apply the release on cluster.
 */
-//nolint:unparam
 func (r *ClusterExtensionReconciler) reconcile(ctx context.Context, ext *ocv1.ClusterExtension) (ctrl.Result, error) {
 	l := log.FromContext(ctx)
 
 	l.Info("handling finalizers")
+	finalizers := sets.New(ext.GetFinalizers()...)
+	finalizeStart := time.Now()
 	finalizeResult, err := r.Finalizers.Finalize(ctx, ext)
+	metrics.ObserveReconcilePhase(metrics.PhaseFinalize, finalizeStart, err)
+	if ext.GetDeletionTimestamp() != nil {
+		metrics.DeleteInstalledExtension(ext.GetName())
+		for _, finalizer := range sets.List(finalizers.Delete(ext.GetFinalizers()...)) {
+			r.Recorder.Eventf(ext, corev1.EventTypeNormal, EventReasonFinalized, "Ran finalizer %q", finalizer)
+		}
+	}
+	setStatusTerminating(ext, err)
 	if err != nil {
+		r.Recorder.Eventf(ext, corev1.EventTypeWarning, EventReasonFinalizeFailed, "Error running finalizers: %v", err)
 		setStatusProgressing(ext, err)
 		return ctrl.Result{}, err
 	}
@@ -202,8 +295,22 @@ func (r *ClusterExtensionReconciler) reconcile(ctx context.Context, ext *ocv1.Cl
 		return ctrl.Result{}, nil
 	}
 
+	// The health of the managed objects is only checked once they are
+	// watched. Until then, the result of the last check is kept.
+	keepCondition(ext, metav1.Condition{
+		Type:    ocv1.TypeHealthy,
+		Status:  metav1.ConditionUnknown,
+		Reason:  ocv1.ReasonFailed,
+		Message: "health of the installed workloads has not been checked yet",
+	})
+
+	// The plan is only reported while in Plan mode, for the resolved bundle.
+	ext.Status.Plan = nil
+
 	l.Info("getting installed bundle")
+	getInstalledStart := time.Now()
 	installedBundle, err := r.InstalledBundleGetter.GetInstalledBundle(ctx, ext)
+	metrics.ObserveReconcilePhase(metrics.PhaseGetInstalled, getInstalledStart, err)
 	if err != nil {
 		setInstallStatus(ext, nil)
 		setInstalledStatusConditionUnknown(ext, err.Error())
@@ -211,21 +318,40 @@ func (r *ClusterExtensionReconciler) reconcile(ctx context.Context, ext *ocv1.Cl
 		return ctrl.Result{}, err
 	}
 
+	if ext.Spec.Install != nil && ext.Spec.Install.RollbackTo != nil {
+		return r.reconcileRollbackTo(ctx, ext, installedBundle)
+	}
+
 	// run resolution
 	l.Info("resolving bundle")
 	var bm *ocv1.BundleMetadata
 	if installedBundle != nil {
 		bm = &installedBundle.BundleMetadata
 	}
-	resolvedBundle, resolvedBundleVersion, resolvedDeprecation, err := r.Resolver.Resolve(ctx, ext, bm)
+	resolveStart := time.Now()
+	resolvedBundle, resolvedBundleVersion, resolvedDeprecation, resolvedOrigin, err := r.Resolver.Resolve(ctx, ext, bm)
+	metrics.ObserveReconcilePhase(metrics.PhaseResolve, resolveStart, err)
 	if err != nil {
-		// Note: We don't distinguish between resolution-specific errors and generic errors
-		setStatusProgressing(ext, err)
+		r.Recorder.Eventf(ext, corev1.EventTypeWarning, EventReasonResolutionFailed, "Error resolving bundle: %v", err)
+		setStatusProgressingFailed(ext, ocv1.ReasonResolutionFailed, err)
 		setInstalledStatusFromBundle(ext, installedBundle)
-		ensureAllConditionsWithReason(ext, ocv1.ReasonFailed, err.Error())
+		ensureAllConditionsWithReason(ext, ocv1.ReasonResolutionFailed, err.Error())
 		return ctrl.Result{}, err
 	}
 
+	var dependencies []ocv1.DependencyStatus
+	if r.DependencyResolver != nil {
+		dependencies, err = r.DependencyResolver.ResolveDependencies(ctx, ext, resolvedBundle)
+		if err != nil {
+			err = fmt.Errorf("error resolving dependencies of bundle %q: %w", resolvedBundle.Name, err)
+			r.Recorder.Eventf(ext, corev1.EventTypeWarning, EventReasonResolutionFailed, "Error resolving bundle: %v", err)
+			setStatusProgressingFailed(ext, ocv1.ReasonResolutionFailed, err)
+			setInstalledStatusFromBundle(ext, installedBundle)
+			ensureAllConditionsWithReason(ext, ocv1.ReasonResolutionFailed, err.Error())
+			return ctrl.Result{}, err
+		}
+	}
+
 	// set deprecation status after _successful_ resolution
 	// TODO:
 	//  1. It seems like deprecation status should reflect the currently installed bundle, not the resolved
@@ -240,24 +366,143 @@ func (r *ClusterExtensionReconciler) reconcile(ctx context.Context, ext *ocv1.Cl
 	//         Perhaps if the package shows up in multiple catalogs and deprecations don't match, we can set
 	//         the deprecation status to unknown? Or perhaps we somehow combine the deprecation information from
 	//         all catalogs?
+	resolvedBundleMetadata := bundleutil.MetadataFor(resolvedBundle.Name, *resolvedBundleVersion)
+	// The installed bundle is resolved again on every reconcile, so only
+	// the resolution of a different bundle is recorded.
+	if installedBundle == nil || installedBundle.BundleMetadata != resolvedBundleMetadata {
+		if resolvedOrigin != nil {
+			r.Recorder.Eventf(ext, corev1.EventTypeNormal, EventReasonResolved, "Resolved bundle %q with version %q from catalog %q", resolvedBundleMetadata.Name, resolvedBundleMetadata.Version, resolvedOrigin.Catalog)
+		} else {
+			r.Recorder.Eventf(ext, corev1.EventTypeNormal, EventReasonResolved, "Resolved bundle %q with version %q", resolvedBundleMetadata.Name, resolvedBundleMetadata.Version)
+		}
+	}
+	var (
+		pendingBundle         *declcfg.Bundle
+		pendingBundleMetadata ocv1.BundleMetadata
+	)
+	if upgradeRequiresApproval(ext, installedBundle, resolvedBundleMetadata) {
+		// Keep the installed bundle until the resolved upgrade is approved.
+		l.Info("upgrade requires approval", "installedVersion", installedBundle.Version, "availableVersion", resolvedBundleMetadata.Version)
+		availableUpgrade := resolvedBundleMetadata
+		setAvailableUpgrade(ext, &availableUpgrade)
+		pendingBundle, pendingBundleMetadata = resolvedBundle, resolvedBundleMetadata
+		resolvedBundle, resolvedBundleVersion, err = bundleForInstalledBundle(installedBundle, resolvedBundle.Package)
+		if err != nil {
+			setStatusProgressing(ext, err)
+			setInstalledStatusFromBundle(ext, installedBundle)
+			return ctrl.Result{}, err
+		}
+		resolvedBundleMetadata = installedBundle.BundleMetadata
+		resolvedOrigin = installedBundle.origin()
+	} else {
+		setAvailableUpgrade(ext, nil)
+	}
+
+	var permissionEscalation *ocv1.PermissionChanges
+	if installedBundle != nil && installedBundle.BundleMetadata != resolvedBundleMetadata &&
+		permissionEscalationRequiresApproval(ext, resolvedBundleMetadata) {
+		if r.PermissionPreviewer == nil {
+			err := errors.New("permission escalation cannot be checked: no permission previewer is configured")
+			setStatusProgressing(ext, err)
+			setInstalledStatusFromBundle(ext, installedBundle)
+			return ctrl.Result{}, err
+		}
+		changes, escalates, err := r.previewPermissionChanges(ctx, ext, resolvedBundle, resolvedBundleMetadata)
+		if err != nil {
+			setStatusProgressing(ext, err)
+			setInstalledStatusFromBundle(ext, installedBundle)
+			return ctrl.Result{}, err
+		}
+		if escalates {
+			// Keep the installed bundle until the permissions granted by the upgrade are approved.
+			l.Info("upgrade grants additional permissions and requires approval", "installedVersion", installedBundle.Version, "availableVersion", resolvedBundleMetadata.Version)
+			permissionEscalation = changes
+			pendingBundle, pendingBundleMetadata = resolvedBundle, resolvedBundleMetadata
+			resolvedBundle, resolvedBundleVersion, err = bundleForInstalledBundle(installedBundle, resolvedBundle.Package)
+			if err != nil {
+				setStatusProgressing(ext, err)
+				setInstalledStatusFromBundle(ext, installedBundle)
+				return ctrl.Result{}, err
+			}
+			resolvedBundleMetadata = installedBundle.BundleMetadata
+			resolvedOrigin = installedBundle.origin()
+		}
+	}
+
+	var (
+		deferredUpgrade       *ocv1.BundleMetadata
+		nextMaintenanceWindow time.Time
+	)
+	if installedBundle != nil && installedBundle.BundleMetadata != resolvedBundleMetadata &&
+		ext.Spec.Install != nil && len(ext.Spec.Install.MaintenanceWindows) > 0 {
+		windows, err := maintenance.NewWindows(ext.Spec.Install.MaintenanceWindows)
+		if err != nil {
+			err = reconcile.TerminalError(fmt.Errorf("invalid maintenance windows: %w", err))
+			setStatusProgressing(ext, err)
+			setInstalledStatusFromBundle(ext, installedBundle)
+			return ctrl.Result{}, err
+		}
+		if open, opensAt := windows.IsOpen(time.Now()); !open {
+			// Keep the installed bundle until the next maintenance window opens.
+			l.Info("upgrade deferred until the next maintenance window", "installedVersion", installedBundle.Version, "availableVersion", resolvedBundleMetadata.Version, "opensAt", opensAt)
+			deferredUpgrade = ptr.To(resolvedBundleMetadata)
+			nextMaintenanceWindow = opensAt
+			pendingBundle, pendingBundleMetadata = resolvedBundle, resolvedBundleMetadata
+			resolvedBundle, resolvedBundleVersion, err = bundleForInstalledBundle(installedBundle, resolvedBundle.Package)
+			if err != nil {
+				setStatusProgressing(ext, err)
+				setInstalledStatusFromBundle(ext, installedBundle)
+				return ctrl.Result{}, err
+			}
+			resolvedBundleMetadata = installedBundle.BundleMetadata
+			resolvedOrigin = installedBundle.origin()
+		}
+	}
+
+	previouslyDeprecated := apimeta.FindStatusCondition(ext.Status.Conditions, ocv1.TypeDeprecated).DeepCopy()
 	SetDeprecationStatus(ext, resolvedBundle.Name, resolvedDeprecation)
+	r.recordDeprecationChange(ext, previouslyDeprecated)
 
-	resolvedBundleMetadata := bundleutil.MetadataFor(resolvedBundle.Name, *resolvedBundleVersion)
-	bundleSource := &rukpaksource.BundleSource{
-		Name: ext.GetName(),
-		Type: rukpaksource.SourceTypeImage,
-		Image: &rukpaksource.ImageSource{
-			Ref: resolvedBundle.Image,
-		},
+	// The dependencies of the installed bundle do not prevent it from being re-applied,
+	// including while an upgrade waits for approval or for a maintenance window.
+	ext.Status.Dependencies = dependencies
+	// The installed bundle is unpacked and applied again on every reconcile,
+	// so the Events of those steps are only recorded for a different bundle.
+	bundleChanged := installedBundle == nil || installedBundle.BundleMetadata != resolvedBundleMetadata
+	if bundleChanged {
+		if err := r.reconcileDependencies(ctx, ext, resolvedBundle.Name); err != nil {
+			setStatusProgressingFailed(ext, ocv1.ReasonMissingDependencies, err)
+			setInstalledStatusFromBundle(ext, installedBundle)
+			return ctrl.Result{}, err
+		}
 	}
 
 	l.Info("unpacking resolved bundle")
-	unpackResult, err := r.Unpacker.Unpack(ctx, bundleSource)
+	if bundleChanged {
+		r.Recorder.Eventf(ext, corev1.EventTypeNormal, EventReasonUnpacking, "Unpacking bundle image %q", resolvedBundle.Image)
+	}
+	unpackStart := time.Now()
+	imageSource, err := r.bundleImageSource(ctx, ext, resolvedBundle.Image)
+	var unpackResult *rukpaksource.Result
+	if err == nil {
+		unpackName := ext.GetName()
+		if pendingBundle == nil {
+			// The resolved bundle is applied rather than the installed one.
+			unpackName = resolvedBundleUnpackName(ext, unpackName)
+		}
+		unpackResult, err = r.Unpacker.Unpack(ctx, &rukpaksource.BundleSource{
+			Name:  unpackName,
+			Type:  rukpaksource.SourceTypeImage,
+			Image: imageSource,
+		})
+	}
+	metrics.ObserveReconcilePhase(metrics.PhaseUnpack, unpackStart, err)
 	if err != nil {
+		r.Recorder.Eventf(ext, corev1.EventTypeWarning, EventReasonUnpackFailed, "Error unpacking bundle image %q: %v", resolvedBundle.Image, err)
 		// Wrap the error passed to this with the resolution information until we have successfully
 		// installed since we intend for the progressing condition to replace the resolved condition
 		// and will be removing the .status.resolution field from the ClusterExtension status API
-		setStatusProgressing(ext, wrapErrorWithResolutionInfo(resolvedBundleMetadata, err))
+		setStatusProgressingFailed(ext, ocv1.ReasonUnpackFailed, wrapErrorWithResolutionInfo(resolvedBundleMetadata, err))
 		setInstalledStatusFromBundle(ext, installedBundle)
 		return ctrl.Result{}, err
 	}
@@ -266,6 +511,16 @@ func (r *ClusterExtensionReconciler) reconcile(ctx context.Context, ext *ocv1.Cl
 		panic(fmt.Sprintf("unexpected unpack state %q", unpackResult.State))
 	}
 
+	// Prefer the canonical reference of the unpacked image so that the
+	// installed digest is recorded even when the bundle was referenced by tag.
+	resolvedImageRef := resolvedBundle.Image
+	if unpackResult.ResolvedSource != nil && unpackResult.ResolvedSource.Image != nil {
+		resolvedImageRef = unpackResult.ResolvedSource.Image.Ref
+	}
+	if bundleChanged {
+		r.Recorder.Eventf(ext, corev1.EventTypeNormal, EventReasonUnpacked, "Unpacked bundle image %q", resolvedImageRef)
+	}
+
 	objLbls := map[string]string{
 		labels.OwnerKindKey: ocv1.ClusterExtensionKind,
 		labels.OwnerNameKey: ext.GetName(),
@@ -275,7 +530,27 @@ func (r *ClusterExtensionReconciler) reconcile(ctx context.Context, ext *ocv1.Cl
 		labels.BundleNameKey:      resolvedBundle.Name,
 		labels.PackageNameKey:     resolvedBundle.Package,
 		labels.BundleVersionKey:   resolvedBundleVersion.String(),
-		labels.BundleReferenceKey: resolvedBundle.Image,
+		labels.BundleReferenceKey: resolvedImageRef,
+	}
+	if resolvedOrigin != nil {
+		storeLbls[labels.CatalogNameKey] = resolvedOrigin.Catalog
+		storeLbls[labels.ChannelsKey] = strings.Join(resolvedOrigin.Channels, ",")
+	}
+
+	if planMode(ext) {
+		planFS, planBundleMetadata := unpackResult.Bundle, resolvedBundleMetadata
+		if pendingBundle != nil {
+			// Approvals and maintenance windows only gate applying an upgrade,
+			// so the pending upgrade is what gets planned.
+			planFS, err = r.unpackPendingBundle(ctx, ext, pendingBundle)
+			if err != nil {
+				setStatusProgressingFailed(ext, ocv1.ReasonUnpackFailed, wrapErrorWithResolutionInfo(pendingBundleMetadata, err))
+				setInstalledStatusFromBundle(ext, installedBundle)
+				return ctrl.Result{}, err
+			}
+			planBundleMetadata = pendingBundleMetadata
+		}
+		return ctrl.Result{}, r.reconcilePlan(ctx, ext, planFS, planBundleMetadata, installedBundle, objLbls)
 	}
 
 	l.Info("applying bundle contents")
@@ -288,36 +563,78 @@ func (r *ClusterExtensionReconciler) reconcile(ctx context.Context, ext *ocv1.Cl
 	// to ensure exponential backoff can occur:
 	//   - Permission errors (it is not possible to watch changes to permissions.
 	//     The only way to eventually recover from permission errors is to keep retrying).
-	managedObjs, _, err := r.Applier.Apply(ctx, unpackResult.Bundle, ext, objLbls, storeLbls)
+	applyStart := time.Now()
+	managedObjs, state, err := r.Applier.Apply(ctx, unpackResult.Bundle, ext, objLbls, storeLbls)
+	var healthCheckPending *applier.HealthCheckPendingError
+	if errors.As(err, &healthCheckPending) {
+		// The bundle is applied, but the workloads of the upgrade are not ready
+		// yet: they are checked again on later reconciles until the deadline.
+		err = nil
+	}
+	metrics.ObserveReconcilePhase(metrics.PhaseApply, applyStart, err)
+	r.recordApplyResult(ext, resolvedBundleMetadata, state, bundleChanged, err)
+	var rolledBack *applier.RolledBackError
+	if errors.As(err, &rolledBack) {
+		// The release was rolled back to the installed bundle. The same upgrade is
+		// not attempted again until the resolved bundle or the configuration changes,
+		// so there is no point in retrying.
+		setStatusProgressingRolledBack(ext, wrapErrorWithResolutionInfo(resolvedBundleMetadata, err))
+		setInstalledStatusFromBundle(ext, installedBundle)
+		return ctrl.Result{}, reconcile.TerminalError(err)
+	}
 	if err != nil {
-		setStatusProgressing(ext, wrapErrorWithResolutionInfo(resolvedBundleMetadata, err))
+		setStatusProgressingFailed(ext, applyFailureReason(err), wrapErrorWithResolutionInfo(resolvedBundleMetadata, err))
 		// Now that we're actually trying to install, use the error
 		setInstalledStatusFromBundle(ext, installedBundle)
 		return ctrl.Result{}, err
 	}
 
-	newInstalledBundle := &InstalledBundle{
-		BundleMetadata: resolvedBundleMetadata,
-		Image:          resolvedBundle.Image,
-	}
+	newInstalledBundle := installedBundleFromLabels(storeLbls)
+	newInstalledBundle.ManagedObjects = managedObjectReferences(managedObjs)
 	// Successful install
 	setInstalledStatusFromBundle(ext, newInstalledBundle)
+	metrics.SetInstalledExtension(ext.GetName(), resolvedBundle.Package, resolvedBundleMetadata.Version,
+		apimeta.IsStatusConditionTrue(ext.Status.Conditions, ocv1.TypeDeprecated))
 
 	l.Info("watching managed objects")
-	cache, err := r.Manager.Get(ctx, ext)
+	cache, err := r.watchManagedObjects(ctx, ext, managedObjs)
 	if err != nil {
 		// No need to wrap error with resolution information here (or beyond) since the
 		// bundle was successfully installed and the information will be present in
 		// the .status.installed field
-		setStatusProgressing(ext, err)
+		setStatusProgressingFailed(ext, ocv1.ReasonWatchFailed, err)
 		return ctrl.Result{}, err
 	}
+	unhealthy, err := r.checkHealth(ctx, ext, cache, managedObjs)
+	setHealthyStatus(ext, unhealthy, err)
 
-	if err := cache.Watch(ctx, r.controller, managedObjs...); err != nil {
+	if err := r.reconcilePendingPermissionChanges(ctx, ext, pendingBundle, pendingBundleMetadata, permissionEscalation); err != nil {
 		setStatusProgressing(ext, err)
 		return ctrl.Result{}, err
 	}
 
+	if healthCheckPending != nil {
+		setStatusProgressing(ext, healthCheckPending)
+		return ctrl.Result{RequeueAfter: max(time.Until(healthCheckPending.Deadline), time.Second)}, nil
+	}
+
+	if permissionEscalation != nil {
+		// The installed bundle is in its desired state, but the upgrade is
+		// blocked until the permissions it grants are approved.
+		setStatusProgressingPermissionEscalation(ext, permissionEscalation.Bundle)
+		return ctrl.Result{}, nil
+	}
+
+	if deferredUpgrade != nil {
+		// The installed bundle is in its desired state, but the upgrade still has to
+		// be performed once the next maintenance window opens.
+		setStatusProgressingUpgradeDeferred(ext, *deferredUpgrade, nextMaintenanceWindow)
+		if nextMaintenanceWindow.IsZero() {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{RequeueAfter: time.Until(nextMaintenanceWindow)}, nil
+	}
+
 	// If we made it here, we have successfully reconciled the ClusterExtension
 	// and have reached the desired state. Since the Progressing status should reflect
 	// our progress towards the desired state, we also set it when we have reached
@@ -326,6 +643,462 @@ func (r *ClusterExtensionReconciler) reconcile(ctx context.Context, ext *ocv1.Cl
 	return ctrl.Result{}, nil
 }
 
+// reconcilePlan reports in the status of ext the changes that applying the
+// content of the resolved bundle in bundleFS would make, instead of applying it.
+func (r *ClusterExtensionReconciler) reconcilePlan(ctx context.Context, ext *ocv1.ClusterExtension, bundleFS fs.FS, resolvedBundleMetadata ocv1.BundleMetadata, installedBundle *InstalledBundle, objLbls map[string]string) error {
+	l := log.FromContext(ctx)
+	setInstalledStatusFromBundle(ext, installedBundle)
+	if r.Planner == nil {
+		err := errors.New("changes cannot be planned: no planner is configured")
+		setStatusProgressing(ext, err)
+		return err
+	}
+
+	l.Info("planning bundle contents")
+	plan, err := r.Planner.Plan(ctx, bundleFS, ext, objLbls)
+	if err != nil {
+		setStatusProgressingFailed(ext, ocv1.ReasonApplyFailed, wrapErrorWithResolutionInfo(resolvedBundleMetadata, err))
+		return err
+	}
+	plan.Bundle = resolvedBundleMetadata
+	ext.Status.Plan = plan
+	r.Recorder.Eventf(ext, corev1.EventTypeNormal, EventReasonPlanned, "Planned bundle %q with version %q: %d objects added, %d changed, %d removed",
+		resolvedBundleMetadata.Name, resolvedBundleMetadata.Version, len(plan.Added), len(plan.Changed), len(plan.Removed))
+	setStatusProgressingPlanned(ext, resolvedBundleMetadata)
+	return nil
+}
+
+// bundleImageSource returns the source of the bundle image ref of ext, which
+// is pulled with the pull secret of the image source of ext, if any.
+func (r *ClusterExtensionReconciler) bundleImageSource(ctx context.Context, ext *ocv1.ClusterExtension, ref string) (*rukpaksource.ImageSource, error) {
+	imageSource := &rukpaksource.ImageSource{Ref: ref}
+	if ext.Spec.Source.Image == nil || ext.Spec.Source.Image.PullSecret == "" || r.PullSecretGetter == nil {
+		return imageSource, nil
+	}
+	pullSecret, err := r.PullSecretGetter(ctx, ext)
+	if err != nil {
+		return nil, fmt.Errorf("error getting pull secret %q: %w", ext.Spec.Source.Image.PullSecret, err)
+	}
+	imageSource.PullSecret = pullSecret
+	return imageSource, nil
+}
+
+// reconcilePendingPermissionChanges reports the permission changes of the
+// upgrade to the pending bundle, if any, in the install status of ext. The
+// changes are computed unless they are already known.
+func (r *ClusterExtensionReconciler) reconcilePendingPermissionChanges(ctx context.Context, ext *ocv1.ClusterExtension, pendingBundle *declcfg.Bundle, pendingBundleMetadata ocv1.BundleMetadata, changes *ocv1.PermissionChanges) error {
+	if r.PermissionPreviewer == nil {
+		return nil
+	}
+	if pendingBundle == nil {
+		// The bundle of a previously pending upgrade is not needed anymore.
+		return r.Unpacker.Cleanup(ctx, &rukpaksource.BundleSource{
+			Name: PendingUpgradeUnpackName(ext.GetName()),
+			Type: rukpaksource.SourceTypeImage,
+		})
+	}
+	if changes == nil {
+		var err error
+		changes, _, err = r.previewPermissionChanges(ctx, ext, pendingBundle, pendingBundleMetadata)
+		if err != nil {
+			return err
+		}
+	}
+	ext.Status.Install.PendingPermissionChanges = changes
+	return nil
+}
+
+// previewPermissionChanges returns the permission changes of the upgrade of ext
+// to the given bundle, which is unpacked apart from the installed bundle, and
+// whether the upgrade grants any permission that is not granted yet.
+func (r *ClusterExtensionReconciler) previewPermissionChanges(ctx context.Context, ext *ocv1.ClusterExtension, bundle *declcfg.Bundle, bundleMetadata ocv1.BundleMetadata) (*ocv1.PermissionChanges, bool, error) {
+	bundleFS, err := r.unpackPendingBundle(ctx, ext, bundle)
+	if err != nil {
+		return nil, false, err
+	}
+	changes, escalates, err := r.PermissionPreviewer.PermissionChanges(ctx, bundleFS, ext)
+	if err != nil {
+		return nil, false, fmt.Errorf("error computing the permission changes of the pending upgrade to bundle %q: %w", bundleMetadata.Name, err)
+	}
+	changes.Bundle = bundleMetadata
+	return changes, escalates, nil
+}
+
+// unpackPendingBundle unpacks the bundle of the pending upgrade of ext apart
+// from the installed bundle and returns its contents.
+func (r *ClusterExtensionReconciler) unpackPendingBundle(ctx context.Context, ext *ocv1.ClusterExtension, bundle *declcfg.Bundle) (fs.FS, error) {
+	imageSource, err := r.bundleImageSource(ctx, ext, bundle.Image)
+	if err != nil {
+		return nil, err
+	}
+	unpackResult, err := r.Unpacker.Unpack(ctx, &rukpaksource.BundleSource{
+		Name:  resolvedBundleUnpackName(ext, PendingUpgradeUnpackName(ext.GetName())),
+		Type:  rukpaksource.SourceTypeImage,
+		Image: imageSource,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error unpacking bundle image %q of the pending upgrade: %w", bundle.Image, err)
+	}
+	if unpackResult.State != rukpaksource.StateUnpacked {
+		panic(fmt.Sprintf("unexpected unpack state %q", unpackResult.State))
+	}
+	return unpackResult.Bundle, nil
+}
+
+// resolvedBundleUnpackName returns the name the resolved bundle of ext is
+// unpacked with, or name if it has not been unpacked yet. The bundle image of
+// an Image source is unpacked by the resolver to read its metadata, so it is
+// reused rather than pulled and cached a second time.
+func resolvedBundleUnpackName(ext *ocv1.ClusterExtension, name string) string {
+	if ext.Spec.Source.SourceType == ocv1.SourceTypeImage {
+		return resolve.ResolveUnpackName(ext.GetName())
+	}
+	return name
+}
+
+// reconcileDependencies creates a ClusterExtension for each missing dependency
+// reported in the status of ext that can be satisfied from the catalogs when the
+// dependency policy of ext is Install. It returns an error describing the
+// dependencies of the bundle with the given name that are not satisfied yet.
+func (r *ClusterExtensionReconciler) reconcileDependencies(ctx context.Context, ext *ocv1.ClusterExtension, bundleName string) error {
+	var unsatisfied []string
+	for i := range ext.Status.Dependencies {
+		dep := &ext.Status.Dependencies[i]
+		// Nothing is installed in Plan mode, including dependencies.
+		if dep.State == ocv1.DependencyStateMissing && dep.Candidate != nil && dependencyPolicy(ext) == ocv1.DependencyPolicyInstall && !planMode(ext) {
+			if err := r.createDependency(ctx, ext, dep); err != nil {
+				return err
+			}
+		}
+		if dep.State != ocv1.DependencyStateSatisfied {
+			unsatisfied = append(unsatisfied, fmt.Sprintf("%s: %s", dep.Requirement, dep.Message))
+		}
+	}
+	if len(unsatisfied) == 0 {
+		return nil
+	}
+	err := fmt.Errorf("bundle %q has unsatisfied dependencies: %s", bundleName, strings.Join(unsatisfied, "; "))
+	r.Recorder.Eventf(ext, corev1.EventTypeWarning, EventReasonMissingDependencies, "%v", err)
+	return err
+}
+
+// createDependency creates the ClusterExtension installing the candidate of the
+// missing dependency dep, and updates dep accordingly. The ClusterExtension is
+// owned by every ClusterExtension it was created for, so that it is garbage
+// collected once none of them exists anymore.
+func (r *ClusterExtensionReconciler) createDependency(ctx context.Context, ext *ocv1.ClusterExtension, dep *ocv1.DependencyStatus) error {
+	depExt := dependencyClusterExtension(ext, dep.Candidate)
+	if err := controllerutil.SetOwnerReference(ext, depExt, r.Client.Scheme()); err != nil {
+		return fmt.Errorf("error setting the owner of ClusterExtension %q for dependency %s: %w", depExt.Name, dep.Requirement, err)
+	}
+	err := r.Client.Create(ctx, depExt)
+	switch {
+	case err == nil:
+		r.Recorder.Eventf(ext, corev1.EventTypeNormal, EventReasonDependencyCreated, "Created ClusterExtension %q for dependency %s", depExt.Name, dep.Requirement)
+	case apierrors.IsAlreadyExists(err):
+		// The ClusterExtension was created by a previous reconcile, unless it
+		// installs another package.
+		existing := &ocv1.ClusterExtension{}
+		if err := r.Client.Get(ctx, client.ObjectKeyFromObject(depExt), existing); err != nil {
+			return fmt.Errorf("error getting ClusterExtension %q for dependency %s: %w", depExt.Name, dep.Requirement, err)
+		}
+		if existing.Spec.Source.Catalog == nil || existing.Spec.Source.Catalog.PackageName != dep.Candidate.PackageName {
+			dep.State = ocv1.DependencyStateUnsatisfiable
+			dep.Message = fmt.Sprintf("ClusterExtension %q cannot be created to install package %q because it already exists for another package", depExt.Name, dep.Candidate.PackageName)
+			return nil
+		}
+		// A ClusterExtension that was not created for a dependency is not
+		// taken over, so that it is not deleted along with ext.
+		if isDependencyClusterExtension(existing) && !isOwnedBy(existing, ext) {
+			patch := client.MergeFromWithOptions(existing.DeepCopy(), client.MergeFromWithOptimisticLock{})
+			if err := controllerutil.SetOwnerReference(ext, existing, r.Client.Scheme()); err != nil {
+				return fmt.Errorf("error setting the owner of ClusterExtension %q for dependency %s: %w", depExt.Name, dep.Requirement, err)
+			}
+			if err := r.Client.Patch(ctx, existing, patch); err != nil {
+				return fmt.Errorf("error adding an owner to ClusterExtension %q for dependency %s: %w", depExt.Name, dep.Requirement, err)
+			}
+		}
+	default:
+		return fmt.Errorf("error creating ClusterExtension %q for dependency %s: %w", depExt.Name, dep.Requirement, err)
+	}
+	dep.ClusterExtensionName = depExt.Name
+	dep.Message = fmt.Sprintf("ClusterExtension %q was created to install bundle %q from catalog %q", depExt.Name, dep.Candidate.Bundle.Name, dep.Candidate.Catalog)
+	return nil
+}
+
+// dependencyClusterExtension returns the ClusterExtension installing the given
+// candidate for a dependency of ext. It is installed with the same namespace
+// and ServiceAccount as ext, and pinned to the version and catalog of the
+// candidate. The ClusterExtensions depending on it may require different
+// version ranges of its package, so it is not upgraded automatically.
+func dependencyClusterExtension(ext *ocv1.ClusterExtension, candidate *ocv1.DependencyCandidate) *ocv1.ClusterExtension {
+	return &ocv1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: candidate.PackageName,
+		},
+		Spec: ocv1.ClusterExtensionSpec{
+			Namespace:      ext.Spec.Namespace,
+			ServiceAccount: ext.Spec.ServiceAccount,
+			Source: ocv1.SourceConfig{
+				SourceType: ocv1.SourceTypeCatalog,
+				Catalog: &ocv1.CatalogSource{
+					PackageName: candidate.PackageName,
+					Version:     candidate.Bundle.Version,
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{catalogd.MetadataNameLabel: candidate.Catalog},
+					},
+				},
+			},
+			Install: &ocv1.ClusterExtensionInstallConfig{
+				DependencyPolicy: ocv1.DependencyPolicyInstall,
+			},
+		},
+	}
+}
+
+// isDependencyClusterExtension returns whether ext was created for the
+// dependency of another ClusterExtension, which then owns it.
+func isDependencyClusterExtension(ext *ocv1.ClusterExtension) bool {
+	for _, ref := range ext.GetOwnerReferences() {
+		if ref.APIVersion == ocv1.GroupVersion.String() && ref.Kind == ocv1.ClusterExtensionKind {
+			return true
+		}
+	}
+	return false
+}
+
+// isOwnedBy returns whether obj has an owner reference to owner.
+func isOwnedBy(obj, owner client.Object) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == owner.GetUID() {
+			return true
+		}
+	}
+	return false
+}
+
+// permissionEscalationRequiresApproval returns whether the permissions granted
+// by an upgrade of ext to the given bundle must be approved before it is performed.
+func permissionEscalationRequiresApproval(ext *ocv1.ClusterExtension, bundle ocv1.BundleMetadata) bool {
+	return ext.Spec.Install != nil &&
+		ext.Spec.Install.PermissionEscalation == ocv1.PermissionEscalationRequireApproval &&
+		ext.Spec.Install.ApprovedPermissionEscalationVersion != bundle.Version
+}
+
+func dependencyPolicy(ext *ocv1.ClusterExtension) ocv1.DependencyPolicy {
+	if ext.Spec.Install == nil || ext.Spec.Install.DependencyPolicy == "" {
+		return ocv1.DependencyPolicyReport
+	}
+	return ext.Spec.Install.DependencyPolicy
+}
+
+func planMode(ext *ocv1.ClusterExtension) bool {
+	return ext.Spec.Install != nil && ext.Spec.Install.Mode == ocv1.ApplyModePlan
+}
+
+// reconcileRollbackTo rolls the installed content back to the revision selected
+// by spec.install.rollbackTo. Resolution is skipped while rolling back.
+func (r *ClusterExtensionReconciler) reconcileRollbackTo(ctx context.Context, ext *ocv1.ClusterExtension, installedBundle *InstalledBundle) (ctrl.Result, error) {
+	l := log.FromContext(ctx)
+
+	// No catalog is consulted while rolling back, so there is no upgrade to
+	// approve and the deprecation status of the last resolution is kept.
+	setAvailableUpgrade(ext, nil)
+	preserveDeprecationStatus(ext)
+
+	l.Info("rolling back", "revision", ext.Spec.Install.RollbackTo.Revision)
+	applyStart := time.Now()
+	managedObjs, storeLbls, err := r.Applier.RollbackTo(ctx, ext, int(ext.Spec.Install.RollbackTo.Revision))
+	metrics.ObserveReconcilePhase(metrics.PhaseApply, applyStart, err)
+	var preflightErr *applier.PreflightError
+	if errors.As(err, &preflightErr) {
+		r.Recorder.Eventf(ext, corev1.EventTypeWarning, EventReasonPreflightFailed, "Preflight checks failed for rollback: %v", err)
+	} else if err != nil {
+		r.Recorder.Eventf(ext, corev1.EventTypeWarning, EventReasonRollbackFailed, "Error rolling back: %v", err)
+	}
+	if err != nil {
+		setStatusProgressingFailed(ext, applyFailureReason(err), fmt.Errorf("error rolling back: %w", err))
+		setInstalledStatusFromBundle(ext, installedBundle)
+		return ctrl.Result{}, err
+	}
+	rolledBackBundle := installedBundleFromLabels(storeLbls)
+	rolledBackBundle.ManagedObjects = managedObjectReferences(managedObjs)
+	setInstalledStatusFromBundle(ext, rolledBackBundle)
+	r.Recorder.Eventf(ext, corev1.EventTypeNormal, EventReasonRolledBack, "Rolled back to bundle %q with version %q", rolledBackBundle.Name, rolledBackBundle.Version)
+	metrics.SetInstalledExtension(ext.GetName(), storeLbls[labels.PackageNameKey], rolledBackBundle.Version,
+		apimeta.IsStatusConditionTrue(ext.Status.Conditions, ocv1.TypeDeprecated))
+
+	l.Info("watching managed objects")
+	cache, err := r.watchManagedObjects(ctx, ext, managedObjs)
+	if err != nil {
+		setStatusProgressingFailed(ext, ocv1.ReasonWatchFailed, err)
+		return ctrl.Result{}, err
+	}
+	unhealthy, err := r.checkHealth(ctx, ext, cache, managedObjs)
+	setHealthyStatus(ext, unhealthy, err)
+
+	setStatusProgressing(ext, nil)
+	return ctrl.Result{}, nil
+}
+
+// watchManagedObjects watches the objects managed by ext for changes and
+// returns the cache the watched objects are stored in.
+func (r *ClusterExtensionReconciler) watchManagedObjects(ctx context.Context, ext *ocv1.ClusterExtension, managedObjs []client.Object) (_ cmcache.Cache, err error) {
+	defer func(start time.Time) {
+		metrics.ObserveReconcilePhase(metrics.PhaseWatch, start, err)
+	}(time.Now())
+
+	cache, err := r.Manager.Get(ctx, ext)
+	if err != nil {
+		return nil, err
+	}
+	if err = cache.Watch(ctx, r.controller, managedObjs...); err != nil {
+		return nil, err
+	}
+	return cache, nil
+}
+
+// applyFailureReason returns the reason of the Progressing condition when
+// applying the bundle failed with err.
+func applyFailureReason(err error) string {
+	var preflightErr *applier.PreflightError
+	if errors.As(err, &preflightErr) {
+		return ocv1.ReasonPreflightFailed
+	}
+	return ocv1.ReasonApplyFailed
+}
+
+// recordApplyResult records an Event describing the outcome of applying the
+// bundle to the cluster, based on the state of its release. That the release
+// is unchanged is only recorded when the bundle differs from the installed one.
+func (r *ClusterExtensionReconciler) recordApplyResult(ext *ocv1.ClusterExtension, bundle ocv1.BundleMetadata, state string, bundleChanged bool, err error) {
+	var (
+		preflightErr *applier.PreflightError
+		rolledBack   *applier.RolledBackError
+	)
+	switch {
+	case errors.As(err, &preflightErr):
+		r.Recorder.Eventf(ext, corev1.EventTypeWarning, EventReasonPreflightFailed, "Preflight checks failed for bundle %q with version %q: %v", bundle.Name, bundle.Version, err)
+	case errors.As(err, &rolledBack):
+		r.Recorder.Eventf(ext, corev1.EventTypeWarning, EventReasonRolledBack, "Upgrade to bundle %q with version %q was rolled back: %v", bundle.Name, bundle.Version, rolledBack.Err)
+	case err != nil:
+		r.Recorder.Eventf(ext, corev1.EventTypeWarning, EventReasonApplyFailed, "Error applying bundle %q with version %q: %v", bundle.Name, bundle.Version, err)
+	case state == applier.StateNeedsInstall:
+		r.Recorder.Eventf(ext, corev1.EventTypeNormal, EventReasonInstalled, "Installed bundle %q with version %q", bundle.Name, bundle.Version)
+	case state == applier.StateNeedsUpgrade:
+		r.Recorder.Eventf(ext, corev1.EventTypeNormal, EventReasonUpgraded, "Upgraded to bundle %q with version %q", bundle.Name, bundle.Version)
+	case bundleChanged:
+		r.Recorder.Eventf(ext, corev1.EventTypeNormal, EventReasonUnchanged, "Bundle %q with version %q is already installed", bundle.Name, bundle.Version)
+	}
+}
+
+// recordDeprecationChange records an Event when the Deprecated condition of ext
+// changed from previous.
+func (r *ClusterExtensionReconciler) recordDeprecationChange(ext *ocv1.ClusterExtension, previous *metav1.Condition) {
+	current := apimeta.FindStatusCondition(ext.Status.Conditions, ocv1.TypeDeprecated)
+	wasDeprecated := previous != nil && previous.Status == metav1.ConditionTrue
+	switch {
+	case current.Status == metav1.ConditionTrue && (!wasDeprecated || previous.Message != current.Message):
+		r.Recorder.Event(ext, corev1.EventTypeWarning, EventReasonDeprecated, current.Message)
+	case current.Status != metav1.ConditionTrue && wasDeprecated:
+		r.Recorder.Event(ext, corev1.EventTypeNormal, EventReasonNoLongerDeprecated, "The package, channels and bundle are no longer deprecated")
+	}
+}
+
+// checkHealth returns messages describing the managed objects that are not
+// healthy and the health rules of ext that are not satisfied, as last observed
+// by the watches of the content manager.
+func (r *ClusterExtensionReconciler) checkHealth(ctx context.Context, ext *ocv1.ClusterExtension, managedCache cmcache.Cache, managedObjs []client.Object) ([]string, error) {
+	var unhealthy []string
+	for _, obj := range managedObjs {
+		if !health.HasHealth(obj.GetObjectKind().GroupVersionKind().GroupKind()) {
+			continue
+		}
+		observed, found, err := managedCache.Get(obj)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			unhealthy = append(unhealthy, fmt.Sprintf("%s not found", describeObject(obj)))
+			continue
+		}
+		if err := health.Check(observed); err != nil {
+			unhealthy = append(unhealthy, fmt.Sprintf("%s: %v", describeObject(obj), err))
+		}
+	}
+
+	var rules health.Rules
+	if ext.Spec.Health != nil {
+		var err error
+		if rules, err = health.NewRules(ext.Spec.Health.Rules); err != nil {
+			return nil, err
+		}
+	}
+	ruleObjs := make([]client.Object, 0, len(rules))
+	for _, rule := range rules {
+		ruleObjs = append(ruleObjs, rule.Object())
+	}
+
+	// Watching no objects stops the watches of rules that were removed.
+	refCache, err := r.Manager.GetReferenced(ctx, ext)
+	if err != nil {
+		return nil, err
+	}
+	if err := refCache.Watch(ctx, r.controller, ruleObjs...); err != nil {
+		return nil, fmt.Errorf("watching objects referenced by health rules: %w", err)
+	}
+	for _, rule := range rules {
+		observed, found, err := refCache.Get(rule.Object())
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			unhealthy = append(unhealthy, fmt.Sprintf("health rule %q: %s not found", rule.Name(), describeObject(rule.Object())))
+			continue
+		}
+		if err := rule.Evaluate(observed); err != nil {
+			unhealthy = append(unhealthy, fmt.Sprintf("health rule %q: %v", rule.Name(), err))
+		}
+	}
+	return unhealthy, nil
+}
+
+// describeObject returns the kind and the namespaced name of obj.
+func describeObject(obj client.Object) string {
+	name := obj.GetName()
+	if obj.GetNamespace() != "" {
+		name = obj.GetNamespace() + "/" + name
+	}
+	return fmt.Sprintf("%s %q", obj.GetObjectKind().GroupVersionKind().Kind, name)
+}
+
+// bundleForInstalledBundle returns the bundle and version to apply in order to
+// keep the installed bundle in place instead of the resolved one.
+func bundleForInstalledBundle(installedBundle *InstalledBundle, packageName string) (*declcfg.Bundle, *bsemver.Version, error) {
+	installedBundleVersion, err := bsemver.Parse(installedBundle.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &declcfg.Bundle{
+		Name:    installedBundle.Name,
+		Package: packageName,
+		Image:   installedBundle.Image,
+	}, &installedBundleVersion, nil
+}
+
+// upgradeRequiresApproval returns true when the ClusterExtension requires manual
+// approval of upgrades and the resolved bundle is an upgrade of the installed bundle
+// whose version has not been approved.
+func upgradeRequiresApproval(ext *ocv1.ClusterExtension, installedBundle *InstalledBundle, resolvedBundle ocv1.BundleMetadata) bool {
+	if ext.Spec.Source.Catalog == nil || ext.Spec.Source.Catalog.UpgradeApproval != ocv1.UpgradeApprovalManual {
+		return false
+	}
+	if installedBundle == nil || installedBundle.BundleMetadata == resolvedBundle {
+		return false
+	}
+	return ext.Spec.Source.Catalog.ApprovedVersion != resolvedBundle.Version
+}
+
 // SetDeprecationStatus will set the appropriate deprecation statuses for a ClusterExtension
 // based on the provided bundle
 func SetDeprecationStatus(ext *ocv1.ClusterExtension, bundleName string, deprecation *declcfg.Deprecation) {
@@ -405,6 +1178,23 @@ func SetDeprecationStatus(ext *ocv1.ClusterExtension, bundleName string, depreca
 	}
 }
 
+// preserveDeprecationStatus keeps the deprecation conditions set by the last
+// resolution, marking them as observed for the current generation.
+func preserveDeprecationStatus(ext *ocv1.ClusterExtension) {
+	for _, conditionType := range []string{
+		ocv1.TypeDeprecated,
+		ocv1.TypePackageDeprecated,
+		ocv1.TypeChannelDeprecated,
+		ocv1.TypeBundleDeprecated,
+	} {
+		keepCondition(ext, metav1.Condition{
+			Type:   conditionType,
+			Status: metav1.ConditionFalse,
+			Reason: ocv1.ReasonDeprecated,
+		})
+	}
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ClusterExtensionReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	controller, err := ctrl.NewControllerManagedBy(mgr).
@@ -473,6 +1263,21 @@ type DefaultInstalledBundleGetter struct {
 type InstalledBundle struct {
 	ocv1.BundleMetadata
 	Image string
+	// Catalog and Channels describe where the bundle was resolved from.
+	// They are empty when the bundle was not resolved from a catalog.
+	Catalog  string
+	Channels []string
+	// ManagedObjects is the inventory of the objects installed from the bundle.
+	ManagedObjects []ocv1.ManagedObjectReference
+}
+
+// origin returns where the installed bundle was resolved from, or nil if it
+// was not resolved from a catalog.
+func (b *InstalledBundle) origin() *resolve.Origin {
+	if b.Catalog == "" {
+		return nil
+	}
+	return &resolve.Origin{Catalog: b.Catalog, Channels: b.Channels}
 }
 
 func (d *DefaultInstalledBundleGetter) GetInstalledBundle(ctx context.Context, ext *ocv1.ClusterExtension) (*InstalledBundle, error) {
@@ -493,14 +1298,53 @@ func (d *DefaultInstalledBundleGetter) GetInstalledBundle(ctx context.Context, e
 	// But we need to look for the most-recent _Deployed_ release
 	for _, rel := range relhis {
 		if rel.Info != nil && rel.Info.Status == release.StatusDeployed {
-			return &InstalledBundle{
-				BundleMetadata: ocv1.BundleMetadata{
-					Name:    rel.Labels[labels.BundleNameKey],
-					Version: rel.Labels[labels.BundleVersionKey],
-				},
-				Image: rel.Labels[labels.BundleReferenceKey],
-			}, nil
+			installedBundle := installedBundleFromLabels(rel.Labels)
+			objs, err := util.ManifestObjects(strings.NewReader(rel.Manifest), fmt.Sprintf("%s-release-manifest", rel.Name))
+			if err != nil {
+				return nil, err
+			}
+			installedBundle.ManagedObjects = managedObjectReferences(objs)
+			return installedBundle, nil
 		}
 	}
 	return nil, nil
 }
+
+// installedBundleFromLabels returns the bundle described by the labels stored with a release.
+func installedBundleFromLabels(lbls map[string]string) *InstalledBundle {
+	installedBundle := &InstalledBundle{
+		BundleMetadata: ocv1.BundleMetadata{
+			Name:    lbls[labels.BundleNameKey],
+			Version: lbls[labels.BundleVersionKey],
+		},
+		Image:   lbls[labels.BundleReferenceKey],
+		Catalog: lbls[labels.CatalogNameKey],
+	}
+	if channels := lbls[labels.ChannelsKey]; channels != "" {
+		installedBundle.Channels = strings.Split(channels, ",")
+	}
+	return installedBundle
+}
+
+// managedObjectReferences returns the sorted references to the given objects.
+func managedObjectReferences(objs []client.Object) []ocv1.ManagedObjectReference {
+	refs := make([]ocv1.ManagedObjectReference, 0, len(objs))
+	for _, obj := range objs {
+		apiVersion, kind := obj.GetObjectKind().GroupVersionKind().ToAPIVersionAndKind()
+		refs = append(refs, ocv1.ManagedObjectReference{
+			APIVersion: apiVersion,
+			Kind:       kind,
+			Namespace:  obj.GetNamespace(),
+			Name:       obj.GetName(),
+		})
+	}
+	slices.SortFunc(refs, func(a, b ocv1.ManagedObjectReference) int {
+		return cmp.Or(
+			cmp.Compare(a.APIVersion, b.APIVersion),
+			cmp.Compare(a.Kind, b.Kind),
+			cmp.Compare(a.Namespace, b.Namespace),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+	return refs
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"testing"
 	"testing/fstest"
+	"time"
 
 	bsemver "github.com/blang/semver/v4"
 	"github.com/google/go-cmp/cmp"
@@ -15,10 +16,17 @@ import (
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/storage/driver"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	crfinalizer "sigs.k8s.io/controller-runtime/pkg/finalizer"
@@ -28,6 +36,7 @@ import (
 	"github.com/operator-framework/operator-registry/alpha/declcfg"
 
 	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+	"github.com/operator-framework/operator-controller/internal/applier"
 	"github.com/operator-framework/operator-controller/internal/conditionsets"
 	"github.com/operator-framework/operator-controller/internal/controllers"
 	"github.com/operator-framework/operator-controller/internal/finalizers"
@@ -50,8 +59,8 @@ func TestClusterExtensionDoesNotExist(t *testing.T) {
 func TestClusterExtensionResolutionFails(t *testing.T) {
 	pkgName := fmt.Sprintf("non-existent-%s", rand.String(6))
 	cl, reconciler := newClientAndReconciler(t)
-	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, error) {
-		return nil, nil, nil, fmt.Errorf("no package %q found", pkgName)
+	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *resolve.Origin, error) {
+		return nil, nil, nil, nil, fmt.Errorf("no package %q found", pkgName)
 	})
 	ctx := context.Background()
 	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
@@ -91,7 +100,7 @@ func TestClusterExtensionResolutionFails(t *testing.T) {
 	cond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
 	require.NotNil(t, cond)
 	require.Equal(t, metav1.ConditionTrue, cond.Status)
-	require.Equal(t, ocv1.ReasonRetrying, cond.Reason)
+	require.Equal(t, ocv1.ReasonResolutionFailed, cond.Reason)
 	require.Equal(t, fmt.Sprintf("no package %q found", pkgName), cond.Message)
 
 	verifyInvariants(ctx, t, reconciler.Client, clusterExtension)
@@ -154,13 +163,13 @@ func TestClusterExtensionResolutionSuccessfulUnpackFails(t *testing.T) {
 
 			t.Log("It sets resolution success status")
 			t.Log("By running reconcile")
-			reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, error) {
+			reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *resolve.Origin, error) {
 				v := bsemver.MustParse("1.0.0")
 				return &declcfg.Bundle{
 					Name:    "prometheus.v1.0.0",
 					Package: "prometheus",
 					Image:   "quay.io/operatorhubio/prometheus@fake1.0.0",
-				}, &v, nil, nil
+				}, &v, nil, nil, nil
 			})
 			res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
 			require.Equal(t, ctrl.Result{}, res)
@@ -179,15 +188,13 @@ func TestClusterExtensionResolutionSuccessfulUnpackFails(t *testing.T) {
 
 			t.Log("By checking the expected conditions")
 			expectStatus := metav1.ConditionTrue
-			expectReason := ocv1.ReasonRetrying
 			if tc.expectTerminal {
 				expectStatus = metav1.ConditionFalse
-				expectReason = ocv1.ReasonBlocked
 			}
 			progressingCond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
 			require.NotNil(t, progressingCond)
 			require.Equal(t, expectStatus, progressingCond.Status)
-			require.Equal(t, expectReason, progressingCond.Reason)
+			require.Equal(t, ocv1.ReasonUnpackFailed, progressingCond.Reason)
 			require.Contains(t, progressingCond.Message, fmt.Sprintf("for resolved bundle %q with version %q", expectedBundleMetadata.Name, expectedBundleMetadata.Version))
 
 			require.NoError(t, cl.DeleteAllOf(ctx, &ocv1.ClusterExtension{}))
@@ -236,13 +243,13 @@ func TestClusterExtensionUnpackUnexpectedState(t *testing.T) {
 
 	t.Log("It sets resolution success status")
 	t.Log("By running reconcile")
-	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, error) {
+	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *resolve.Origin, error) {
 		v := bsemver.MustParse("1.0.0")
 		return &declcfg.Bundle{
 			Name:    "prometheus.v1.0.0",
 			Package: "prometheus",
 			Image:   "quay.io/operatorhubio/prometheus@fake1.0.0",
-		}, &v, nil, nil
+		}, &v, nil, nil, nil
 	})
 
 	require.Panics(t, func() {
@@ -294,13 +301,13 @@ func TestClusterExtensionResolutionAndUnpackSuccessfulApplierFails(t *testing.T)
 
 	t.Log("It sets resolution success status")
 	t.Log("By running reconcile")
-	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, error) {
+	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *resolve.Origin, error) {
 		v := bsemver.MustParse("1.0.0")
 		return &declcfg.Bundle{
 			Name:    "prometheus.v1.0.0",
 			Package: "prometheus",
 			Image:   "quay.io/operatorhubio/prometheus@fake1.0.0",
-		}, &v, nil, nil
+		}, &v, nil, nil, nil
 	})
 	reconciler.Applier = &MockApplier{
 		err: errors.New("apply failure"),
@@ -326,7 +333,7 @@ func TestClusterExtensionResolutionAndUnpackSuccessfulApplierFails(t *testing.T)
 	progressingCond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
 	require.NotNil(t, progressingCond)
 	require.Equal(t, metav1.ConditionTrue, progressingCond.Status)
-	require.Equal(t, ocv1.ReasonRetrying, progressingCond.Reason)
+	require.Equal(t, ocv1.ReasonApplyFailed, progressingCond.Reason)
 	require.Contains(t, progressingCond.Message, fmt.Sprintf("for resolved bundle %q with version %q", expectedBundleMetadata.Name, expectedBundleMetadata.Version))
 
 	require.NoError(t, cl.DeleteAllOf(ctx, &ocv1.ClusterExtension{}))
@@ -374,13 +381,13 @@ func TestClusterExtensionApplierFailsWithBundleInstalled(t *testing.T) {
 
 	t.Log("It sets resolution success status")
 	t.Log("By running reconcile")
-	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, error) {
+	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *resolve.Origin, error) {
 		v := bsemver.MustParse("1.0.0")
 		return &declcfg.Bundle{
 			Name:    "prometheus.v1.0.0",
 			Package: "prometheus",
 			Image:   "quay.io/operatorhubio/prometheus@fake1.0.0",
-		}, &v, nil, nil
+		}, &v, nil, nil, nil
 	})
 
 	reconciler.Manager = &MockManagedContentCacheManager{
@@ -425,12 +432,269 @@ func TestClusterExtensionApplierFailsWithBundleInstalled(t *testing.T) {
 	progressingCond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
 	require.NotNil(t, progressingCond)
 	require.Equal(t, metav1.ConditionTrue, progressingCond.Status)
-	require.Equal(t, ocv1.ReasonRetrying, progressingCond.Reason)
+	require.Equal(t, ocv1.ReasonApplyFailed, progressingCond.Reason)
 	require.Contains(t, progressingCond.Message, fmt.Sprintf("for resolved bundle %q with version %q", expectedBundleMetadata.Name, expectedBundleMetadata.Version))
 
 	require.NoError(t, cl.DeleteAllOf(ctx, &ocv1.ClusterExtension{}))
 }
 
+func TestClusterExtensionHealthy(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	reconciler.Unpacker = &MockUnpacker{
+		result: &source.Result{
+			State:  source.StateUnpacked,
+			Bundle: fstest.MapFS{},
+		},
+	}
+
+	ctx := context.Background()
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	namespace := fmt.Sprintf("test-ns-%s", rand.String(8))
+
+	t.Log("When the cluster extension installs a Deployment and a CustomResourceDefinition")
+	t.Log("By initializing cluster state")
+	clusterExtension := &ocv1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec: ocv1.ClusterExtensionSpec{
+			Source: ocv1.SourceConfig{
+				SourceType: "Catalog",
+				Catalog: &ocv1.CatalogSource{
+					PackageName: "prometheus",
+				},
+			},
+			Namespace: namespace,
+			ServiceAccount: ocv1.ServiceAccountReference{
+				Name: fmt.Sprintf("test-sa-%s", rand.String(8)),
+			},
+		},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+
+	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *resolve.Origin, error) {
+		v := bsemver.MustParse("1.0.0")
+		return &declcfg.Bundle{
+			Name:    "prometheus.v1.0.0",
+			Package: "prometheus",
+			Image:   "quay.io/operatorhubio/prometheus@fake1.0.0",
+		}, &v, nil, nil, nil
+	})
+
+	dep := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "prometheus-operator", Namespace: namespace},
+	}
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition"},
+		ObjectMeta: metav1.ObjectMeta{Name: "prometheuses.monitoring.coreos.com"},
+	}
+	configMap := &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: "prometheus-config", Namespace: namespace},
+	}
+	reconciler.Applier = &MockApplier{
+		objs: []client.Object{dep, crd, configMap},
+	}
+
+	establishedCRD := crd.DeepCopy()
+	establishedCRD.Status.Conditions = []apiextensionsv1.CustomResourceDefinitionCondition{
+		{Type: apiextensionsv1.Established, Status: apiextensionsv1.ConditionTrue},
+	}
+	rollingOutDep := dep.DeepCopy()
+	rollingOutDep.Status.UpdatedReplicas = 1
+	availableDep := dep.DeepCopy()
+	availableDep.Status = appsv1.DeploymentStatus{
+		UpdatedReplicas:   1,
+		AvailableReplicas: 1,
+		Conditions: []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+		},
+	}
+
+	for _, tc := range []struct {
+		name          string
+		observed      []client.Object
+		cacheErr      error
+		expectStatus  metav1.ConditionStatus
+		expectReason  string
+		expectMessage string
+	}{
+		{
+			name:          "It reports the objects that have not been observed yet",
+			expectStatus:  metav1.ConditionFalse,
+			expectReason:  ocv1.ReasonUnhealthy,
+			expectMessage: fmt.Sprintf(`Deployment "%s/prometheus-operator" not found; CustomResourceDefinition "prometheuses.monitoring.coreos.com" not found`, namespace),
+		},
+		{
+			name:          "It reports a Deployment that is not available",
+			observed:      []client.Object{rollingOutDep, establishedCRD},
+			expectStatus:  metav1.ConditionFalse,
+			expectReason:  ocv1.ReasonUnhealthy,
+			expectMessage: fmt.Sprintf(`Deployment "%s/prometheus-operator": 0 of 1 replicas available`, namespace),
+		},
+		{
+			name:          "It reports healthy when the Deployment is available and the CRD is established",
+			observed:      []client.Object{availableDep, establishedCRD},
+			expectStatus:  metav1.ConditionTrue,
+			expectReason:  ocv1.ReasonHealthy,
+			expectMessage: "installed workloads are ready",
+		},
+	} {
+		t.Log(tc.name)
+		reconciler.Manager = &MockManagedContentCacheManager{
+			cache: &MockManagedContentCache{observed: tc.observed},
+		}
+		res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.Equal(t, ctrl.Result{}, res)
+		require.NoError(t, err)
+
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		verifyConditionsInvariants(t, clusterExtension)
+
+		healthyCond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeHealthy)
+		require.NotNil(t, healthyCond)
+		require.Equal(t, tc.expectStatus, healthyCond.Status)
+		require.Equal(t, tc.expectReason, healthyCond.Reason)
+		require.Equal(t, tc.expectMessage, healthyCond.Message)
+	}
+
+	t.Log("It keeps the last health check when the managed objects cannot be applied")
+	reconciler.Applier = &MockApplier{err: errors.New("apply failure")}
+	res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.Equal(t, ctrl.Result{}, res)
+	require.Error(t, err)
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	healthyCond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeHealthy)
+	require.NotNil(t, healthyCond)
+	require.Equal(t, metav1.ConditionTrue, healthyCond.Status)
+
+	require.NoError(t, cl.DeleteAllOf(ctx, &ocv1.ClusterExtension{}))
+}
+
+func TestClusterExtensionHealthRules(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	reconciler.Unpacker = &MockUnpacker{
+		result: &source.Result{
+			State:  source.StateUnpacked,
+			Bundle: fstest.MapFS{},
+		},
+	}
+	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *resolve.Origin, error) {
+		v := bsemver.MustParse("1.0.0")
+		return &declcfg.Bundle{
+			Name:    "prometheus.v1.0.0",
+			Package: "prometheus",
+			Image:   "quay.io/operatorhubio/prometheus@fake1.0.0",
+		}, &v, nil, nil, nil
+	})
+	reconciler.Applier = &MockApplier{objs: []client.Object{}}
+
+	ctx := context.Background()
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	namespace := fmt.Sprintf("test-ns-%s", rand.String(8))
+
+	t.Log("When the cluster extension has a health rule on a custom resource")
+	t.Log("By initializing cluster state")
+	clusterExtension := &ocv1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec: ocv1.ClusterExtensionSpec{
+			Source: ocv1.SourceConfig{
+				SourceType: "Catalog",
+				Catalog: &ocv1.CatalogSource{
+					PackageName: "prometheus",
+				},
+			},
+			Namespace: namespace,
+			ServiceAccount: ocv1.ServiceAccountReference{
+				Name: fmt.Sprintf("test-sa-%s", rand.String(8)),
+			},
+			Health: &ocv1.HealthConfig{
+				Rules: []ocv1.HealthRule{{
+					Name: "prometheus-ready",
+					Object: ocv1.HealthRuleObjectReference{
+						APIVersion: "monitoring.coreos.com/v1",
+						Kind:       "Prometheus",
+						Name:       "default",
+						Namespace:  namespace,
+					},
+					Expression: "has(self.status.phase) && self.status.phase == 'Ready'",
+					Message:    "Prometheus is not ready",
+				}},
+			},
+		},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+
+	prometheus := func(phase string) client.Object {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"status": map[string]interface{}{"phase": phase},
+		}}
+		obj.SetAPIVersion("monitoring.coreos.com/v1")
+		obj.SetKind("Prometheus")
+		obj.SetName("default")
+		obj.SetNamespace(namespace)
+		return obj
+	}
+
+	for _, tc := range []struct {
+		name          string
+		observed      []client.Object
+		expectStatus  metav1.ConditionStatus
+		expectMessage string
+	}{
+		{
+			name:          "It reports a rule whose object does not exist",
+			expectStatus:  metav1.ConditionFalse,
+			expectMessage: fmt.Sprintf(`health rule "prometheus-ready": Prometheus "%s/default" not found`, namespace),
+		},
+		{
+			name:          "It reports a rule that is not satisfied",
+			observed:      []client.Object{prometheus("Pending")},
+			expectStatus:  metav1.ConditionFalse,
+			expectMessage: `health rule "prometheus-ready": Prometheus is not ready`,
+		},
+		{
+			name:          "It reports healthy when the rule is satisfied",
+			observed:      []client.Object{prometheus("Ready")},
+			expectStatus:  metav1.ConditionTrue,
+			expectMessage: "installed workloads are ready",
+		},
+	} {
+		t.Log(tc.name)
+		reconciler.Manager = &MockManagedContentCacheManager{
+			cache:           &MockManagedContentCache{},
+			referencedCache: &MockManagedContentCache{observed: tc.observed},
+		}
+		res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.Equal(t, ctrl.Result{}, res)
+		require.NoError(t, err)
+
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		verifyConditionsInvariants(t, clusterExtension)
+
+		healthyCond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeHealthy)
+		require.NotNil(t, healthyCond)
+		require.Equal(t, tc.expectStatus, healthyCond.Status)
+		require.Equal(t, tc.expectMessage, healthyCond.Message)
+	}
+
+	t.Log("It reports an unknown health when a rule cannot be compiled")
+	clusterExtension.Spec.Health.Rules[0].Expression = "self.status.phase =="
+	require.NoError(t, cl.Update(ctx, clusterExtension))
+	res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.Equal(t, ctrl.Result{}, res)
+	require.NoError(t, err)
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	healthyCond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeHealthy)
+	require.NotNil(t, healthyCond)
+	require.Equal(t, metav1.ConditionUnknown, healthyCond.Status)
+	require.Equal(t, ocv1.ReasonFailed, healthyCond.Reason)
+	require.Contains(t, healthyCond.Message, `error checking health: health rule "prometheus-ready": invalid expression`)
+
+	require.NoError(t, cl.DeleteAllOf(ctx, &ocv1.ClusterExtension{}))
+}
+
 func TestClusterExtensionManagerFailed(t *testing.T) {
 	cl, reconciler := newClientAndReconciler(t)
 	reconciler.Unpacker = &MockUnpacker{
@@ -473,13 +737,13 @@ func TestClusterExtensionManagerFailed(t *testing.T) {
 
 	t.Log("It sets resolution success status")
 	t.Log("By running reconcile")
-	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, error) {
+	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *resolve.Origin, error) {
 		v := bsemver.MustParse("1.0.0")
 		return &declcfg.Bundle{
 			Name:    "prometheus.v1.0.0",
 			Package: "prometheus",
 			Image:   "quay.io/operatorhubio/prometheus@fake1.0.0",
-		}, &v, nil, nil
+		}, &v, nil, nil, nil
 	})
 	reconciler.Applier = &MockApplier{
 		objs: []client.Object{},
@@ -507,7 +771,7 @@ func TestClusterExtensionManagerFailed(t *testing.T) {
 	progressingCond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
 	require.NotNil(t, progressingCond)
 	require.Equal(t, metav1.ConditionTrue, progressingCond.Status)
-	require.Equal(t, ocv1.ReasonRetrying, progressingCond.Reason)
+	require.Equal(t, ocv1.ReasonWatchFailed, progressingCond.Reason)
 
 	require.NoError(t, cl.DeleteAllOf(ctx, &ocv1.ClusterExtension{}))
 }
@@ -555,13 +819,13 @@ func TestClusterExtensionManagedContentCacheWatchFail(t *testing.T) {
 
 	t.Log("It sets resolution success status")
 	t.Log("By running reconcile")
-	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, error) {
+	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *resolve.Origin, error) {
 		v := bsemver.MustParse("1.0.0")
 		return &declcfg.Bundle{
 			Name:    "prometheus.v1.0.0",
 			Package: "prometheus",
 			Image:   "quay.io/operatorhubio/prometheus@fake1.0.0",
-		}, &v, nil, nil
+		}, &v, nil, nil, nil
 	})
 	reconciler.Applier = &MockApplier{
 		objs: []client.Object{},
@@ -591,7 +855,7 @@ func TestClusterExtensionManagedContentCacheWatchFail(t *testing.T) {
 	progressingCond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
 	require.NotNil(t, progressingCond)
 	require.Equal(t, metav1.ConditionTrue, progressingCond.Status)
-	require.Equal(t, ocv1.ReasonRetrying, progressingCond.Reason)
+	require.Equal(t, ocv1.ReasonWatchFailed, progressingCond.Reason)
 
 	require.NoError(t, cl.DeleteAllOf(ctx, &ocv1.ClusterExtension{}))
 }
@@ -638,16 +902,25 @@ func TestClusterExtensionInstallationSucceeds(t *testing.T) {
 
 	t.Log("It sets resolution success status")
 	t.Log("By running reconcile")
-	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, error) {
+	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *resolve.Origin, error) {
 		v := bsemver.MustParse("1.0.0")
 		return &declcfg.Bundle{
 			Name:    "prometheus.v1.0.0",
 			Package: "prometheus",
 			Image:   "quay.io/operatorhubio/prometheus@fake1.0.0",
-		}, &v, nil, nil
+		}, &v, nil, &resolve.Origin{Catalog: "operatorhubio", Channels: []string{"beta"}}, nil
 	})
 	reconciler.Applier = &MockApplier{
-		objs: []client.Object{},
+		objs: []client.Object{
+			&corev1.ConfigMap{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+				ObjectMeta: metav1.ObjectMeta{Name: "prometheus-config", Namespace: namespace},
+			},
+			&corev1.Namespace{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+				ObjectMeta: metav1.ObjectMeta{Name: namespace},
+			},
+		},
 	}
 	reconciler.Manager = &MockManagedContentCacheManager{
 		cache: &MockManagedContentCache{},
@@ -660,7 +933,16 @@ func TestClusterExtensionInstallationSucceeds(t *testing.T) {
 	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
 
 	t.Log("By checking the status fields")
-	require.Equal(t, ocv1.BundleMetadata{Name: "prometheus.v1.0.0", Version: "1.0.0"}, clusterExtension.Status.Install.Bundle)
+	require.Equal(t, &ocv1.ClusterExtensionInstallStatus{
+		Bundle:           ocv1.BundleMetadata{Name: "prometheus.v1.0.0", Version: "1.0.0"},
+		ResolvedImageRef: "quay.io/operatorhubio/prometheus@fake1.0.0",
+		Catalog:          "operatorhubio",
+		Channels:         []string{"beta"},
+		ManagedObjects: []ocv1.ManagedObjectReference{
+			{APIVersion: "v1", Kind: "ConfigMap", Namespace: namespace, Name: "prometheus-config"},
+			{APIVersion: "v1", Kind: "Namespace", Name: namespace},
+		},
+	}, clusterExtension.Status.Install)
 
 	t.Log("By checking the expected installed conditions")
 	installedCond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeInstalled)
@@ -718,13 +1000,13 @@ func TestClusterExtensionDeleteFinalizerFails(t *testing.T) {
 	require.NoError(t, err)
 	t.Log("It sets resolution success status")
 	t.Log("By running reconcile")
-	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, error) {
+	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *resolve.Origin, error) {
 		v := bsemver.MustParse("1.0.0")
 		return &declcfg.Bundle{
 			Name:    "prometheus.v1.0.0",
 			Package: "prometheus",
 			Image:   "quay.io/operatorhubio/prometheus@fake1.0.0",
-		}, &v, nil, nil
+		}, &v, nil, nil, nil
 	})
 	fakeFinalizer := "fake.testfinalizer.io"
 	finalizersMessage := "still have finalizers"
@@ -777,6 +1059,160 @@ func TestClusterExtensionDeleteFinalizerFails(t *testing.T) {
 	require.NotNil(t, cond)
 	require.Equal(t, metav1.ConditionTrue, cond.Status)
 	require.Contains(t, cond.Message, finalizersMessage)
+	cond = apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeTerminating)
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionTrue, cond.Status)
+	require.Equal(t, ocv1.ReasonFailed, cond.Reason)
+	require.Contains(t, cond.Message, finalizersMessage)
+}
+
+func TestClusterExtensionEvents(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	recorder := record.NewFakeRecorder(100)
+	reconciler.Recorder = recorder
+	reconciler.Unpacker = &MockUnpacker{
+		result: &source.Result{
+			State:  source.StateUnpacked,
+			Bundle: fstest.MapFS{},
+			ResolvedSource: &source.BundleSource{
+				Type:  source.SourceTypeImage,
+				Image: &source.ImageSource{Ref: "quay.io/operatorhubio/prometheus@sha256:abc"},
+			},
+		},
+	}
+
+	ctx := context.Background()
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+
+	t.Log("When a cluster extension is installed from a catalog")
+	clusterExtension := &ocv1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec: ocv1.ClusterExtensionSpec{
+			Source: ocv1.SourceConfig{
+				SourceType: "Catalog",
+				Catalog: &ocv1.CatalogSource{
+					PackageName: "prometheus",
+				},
+			},
+			Namespace: fmt.Sprintf("test-ns-%s", rand.String(8)),
+			ServiceAccount: ocv1.ServiceAccountReference{
+				Name: fmt.Sprintf("test-sa-%s", rand.String(8)),
+			},
+		},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+
+	var deprecation *declcfg.Deprecation
+	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *resolve.Origin, error) {
+		v := bsemver.MustParse("1.0.0")
+		return &declcfg.Bundle{
+			Name:    "prometheus.v1.0.0",
+			Package: "prometheus",
+			Image:   "quay.io/operatorhubio/prometheus:v1.0.0",
+		}, &v, deprecation, &resolve.Origin{Catalog: "operatorhubio"}, nil
+	})
+	mockApplier := &MockApplier{state: applier.StateNeedsInstall}
+	reconciler.Applier = mockApplier
+	reconciler.Manager = &MockManagedContentCacheManager{
+		cache: &MockManagedContentCache{},
+	}
+	fakeFinalizer := "fake.testfinalizer.io"
+	require.NoError(t, reconciler.Finalizers.Register(fakeFinalizer, finalizers.FinalizerFunc(func(ctx context.Context, obj client.Object) (crfinalizer.Result, error) {
+		return crfinalizer.Result{}, nil
+	})))
+
+	// Reconcile twice to add the finalizer and install the ClusterExtension
+	for range 2 {
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+	}
+
+	t.Log("It records the resolution, unpack and install steps")
+	require.Equal(t, []string{
+		`Normal Resolved Resolved bundle "prometheus.v1.0.0" with version "1.0.0" from catalog "operatorhubio"`,
+		`Normal Unpacking Unpacking bundle image "quay.io/operatorhubio/prometheus:v1.0.0"`,
+		`Normal Unpacked Unpacked bundle image "quay.io/operatorhubio/prometheus@sha256:abc"`,
+		`Normal Installed Installed bundle "prometheus.v1.0.0" with version "1.0.0"`,
+	}, receivedEvents(recorder))
+
+	t.Log("When the bundle is installed, the package is deprecated and a preflight check fails")
+	reconciler.InstalledBundleGetter = &MockInstalledBundleGetter{
+		bundle: &controllers.InstalledBundle{
+			BundleMetadata: ocv1.BundleMetadata{Name: "prometheus.v1.0.0", Version: "1.0.0"},
+			Image:          "quay.io/operatorhubio/prometheus@sha256:abc",
+		},
+	}
+	deprecation = &declcfg.Deprecation{
+		Entries: []declcfg.DeprecationEntry{{
+			Reference: declcfg.PackageScopedReference{Schema: declcfg.SchemaPackage},
+			Message:   "package prometheus is deprecated",
+		}},
+	}
+	mockApplier.state = applier.StateNeedsUpgrade
+	mockApplier.err = &applier.PreflightError{Err: errors.New("stored version removed")}
+	_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.Error(t, err)
+
+	t.Log("It records the deprecation and the preflight failure, but not the resolution and unpack of the installed bundle")
+	require.Equal(t, []string{
+		`Warning Deprecated package prometheus is deprecated`,
+		`Warning PreflightFailed Preflight checks failed for bundle "prometheus.v1.0.0" with version "1.0.0": stored version removed`,
+	}, receivedEvents(recorder))
+
+	t.Log("When the deprecation is removed and the release is unchanged")
+	deprecation = nil
+	mockApplier.state = applier.StateUnchanged
+	mockApplier.err = nil
+	_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.NoError(t, err)
+
+	t.Log("It records that the bundle is no longer deprecated, but not that the installed bundle was unchanged")
+	require.Equal(t, []string{
+		`Normal NoLongerDeprecated The package, channels and bundle are no longer deprecated`,
+	}, receivedEvents(recorder))
+
+	t.Log("When a different bundle is resolved and its release is unchanged")
+	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *resolve.Origin, error) {
+		v := bsemver.MustParse("1.0.1")
+		return &declcfg.Bundle{
+			Name:    "prometheus.v1.0.1",
+			Package: "prometheus",
+			Image:   "quay.io/operatorhubio/prometheus:v1.0.1",
+		}, &v, nil, &resolve.Origin{Catalog: "operatorhubio"}, nil
+	})
+	_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.NoError(t, err)
+
+	t.Log("It records the resolution, unpack and apply steps")
+	require.Equal(t, []string{
+		`Normal Resolved Resolved bundle "prometheus.v1.0.1" with version "1.0.1" from catalog "operatorhubio"`,
+		`Normal Unpacking Unpacking bundle image "quay.io/operatorhubio/prometheus:v1.0.1"`,
+		`Normal Unpacked Unpacked bundle image "quay.io/operatorhubio/prometheus@sha256:abc"`,
+		`Normal Unchanged Bundle "prometheus.v1.0.1" with version "1.0.1" is already installed`,
+	}, receivedEvents(recorder))
+
+	t.Log("When the cluster extension is deleted")
+	require.NoError(t, cl.Delete(ctx, clusterExtension))
+	_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.NoError(t, err)
+
+	t.Log("It records the finalizers that were run")
+	require.Equal(t, []string{
+		`Normal Finalized Ran finalizer "fake.testfinalizer.io"`,
+	}, receivedEvents(recorder))
+}
+
+// receivedEvents returns the events recorded by recorder since the last call.
+func receivedEvents(recorder *record.FakeRecorder) []string {
+	var events []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
 }
 
 func verifyInvariants(ctx context.Context, t *testing.T, c client.Client, ext *ocv1.ClusterExtension) {
@@ -1525,3 +1961,1112 @@ func TestGetInstalledBundleHistory(t *testing.T) {
 		require.Equal(t, tst.expectedBundle, md)
 	}
 }
+
+func TestClusterExtensionManualUpgradeApproval(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	reconciler.Unpacker = &MockUnpacker{
+		result: &source.Result{
+			State:  source.StateUnpacked,
+			Bundle: fstest.MapFS{},
+		},
+	}
+
+	ctx := context.Background()
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+
+	t.Log("When the cluster extension requires manual approval of upgrades")
+	t.Log("By initializing cluster state")
+	clusterExtension := &ocv1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec: ocv1.ClusterExtensionSpec{
+			Source: ocv1.SourceConfig{
+				SourceType: "Catalog",
+				Catalog: &ocv1.CatalogSource{
+					PackageName:     "prometheus",
+					UpgradeApproval: ocv1.UpgradeApprovalManual,
+				},
+			},
+			Namespace: fmt.Sprintf("test-ns-%s", rand.String(8)),
+			ServiceAccount: ocv1.ServiceAccountReference{
+				Name: fmt.Sprintf("test-sa-%s", rand.String(8)),
+			},
+		},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+
+	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *resolve.Origin, error) {
+		v := bsemver.MustParse("1.0.1")
+		return &declcfg.Bundle{
+			Name:    "prometheus.v1.0.1",
+			Package: "prometheus",
+			Image:   "quay.io/operatorhubio/prometheus@fake1.0.1",
+		}, &v, nil, nil, nil
+	})
+	reconciler.Manager = &MockManagedContentCacheManager{
+		cache: &MockManagedContentCache{},
+	}
+	reconciler.InstalledBundleGetter = &MockInstalledBundleGetter{
+		bundle: &controllers.InstalledBundle{
+			BundleMetadata: ocv1.BundleMetadata{Name: "prometheus.v1.0.0", Version: "1.0.0"},
+			Image:          "quay.io/operatorhubio/prometheus@fake1.0.0",
+		},
+	}
+	reconciler.Applier = &MockApplier{
+		objs: []client.Object{},
+	}
+
+	t.Log("It keeps the installed bundle and reports the available upgrade")
+	res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.Equal(t, ctrl.Result{}, res)
+	require.NoError(t, err)
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	require.Equal(t, ocv1.BundleMetadata{Name: "prometheus.v1.0.0", Version: "1.0.0"}, clusterExtension.Status.Install.Bundle)
+	require.Equal(t, &ocv1.BundleMetadata{Name: "prometheus.v1.0.1", Version: "1.0.1"}, clusterExtension.Status.AvailableUpgrade)
+
+	upgradeCond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeUpgradeAvailable)
+	require.NotNil(t, upgradeCond)
+	require.Equal(t, metav1.ConditionTrue, upgradeCond.Status)
+	require.Equal(t, ocv1.ReasonApprovalRequired, upgradeCond.Reason)
+	require.Contains(t, upgradeCond.Message, `set spec.source.catalog.approvedVersion to "1.0.1"`)
+
+	progressingCond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
+	require.NotNil(t, progressingCond)
+	require.Equal(t, metav1.ConditionTrue, progressingCond.Status)
+	require.Equal(t, ocv1.ReasonSucceeded, progressingCond.Reason)
+
+	t.Log("It keeps reporting the resolved upgrade, not the installed bundle, as available")
+	// The available upgrade must not alias the resolved bundle metadata, which is
+	// replaced by the installed bundle while the upgrade waits for approval.
+	res, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.Equal(t, ctrl.Result{}, res)
+	require.NoError(t, err)
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	require.Equal(t, ocv1.BundleMetadata{Name: "prometheus.v1.0.0", Version: "1.0.0"}, clusterExtension.Status.Install.Bundle)
+	require.NotNil(t, clusterExtension.Status.AvailableUpgrade)
+	require.NotEqual(t, clusterExtension.Status.Install.Bundle, *clusterExtension.Status.AvailableUpgrade)
+	require.Equal(t, &ocv1.BundleMetadata{Name: "prometheus.v1.0.1", Version: "1.0.1"}, clusterExtension.Status.AvailableUpgrade)
+
+	t.Log("It applies the upgrade once its version is approved")
+	clusterExtension.Spec.Source.Catalog.ApprovedVersion = "1.0.1"
+	require.NoError(t, cl.Update(ctx, clusterExtension))
+
+	res, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.Equal(t, ctrl.Result{}, res)
+	require.NoError(t, err)
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	require.Equal(t, ocv1.BundleMetadata{Name: "prometheus.v1.0.1", Version: "1.0.1"}, clusterExtension.Status.Install.Bundle)
+	require.Nil(t, clusterExtension.Status.AvailableUpgrade)
+
+	upgradeCond = apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeUpgradeAvailable)
+	require.NotNil(t, upgradeCond)
+	require.Equal(t, metav1.ConditionFalse, upgradeCond.Status)
+	require.Equal(t, ocv1.ReasonUpToDate, upgradeCond.Reason)
+
+	require.NoError(t, cl.DeleteAllOf(ctx, &ocv1.ClusterExtension{}))
+}
+
+func TestClusterExtensionPendingPermissionChanges(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	unpacker := &MockUnpacker{
+		result: &source.Result{
+			State:  source.StateUnpacked,
+			Bundle: fstest.MapFS{},
+		},
+	}
+	reconciler.Unpacker = unpacker
+	addedRule := ocv1.PermissionRule{Rule: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}}}
+	removedRule := ocv1.PermissionRule{Namespace: "ns-a", Rule: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}}
+	reconciler.PermissionPreviewer = &MockPermissionPreviewer{
+		added:   []ocv1.PermissionRule{addedRule},
+		removed: []ocv1.PermissionRule{removedRule},
+	}
+
+	ctx := context.Background()
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+
+	t.Log("When an upgrade of the cluster extension is waiting for approval")
+	clusterExtension := &ocv1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec: ocv1.ClusterExtensionSpec{
+			Source: ocv1.SourceConfig{
+				SourceType: "Catalog",
+				Catalog: &ocv1.CatalogSource{
+					PackageName:     "prometheus",
+					UpgradeApproval: ocv1.UpgradeApprovalManual,
+				},
+			},
+			Namespace: fmt.Sprintf("test-ns-%s", rand.String(8)),
+			ServiceAccount: ocv1.ServiceAccountReference{
+				Name: fmt.Sprintf("test-sa-%s", rand.String(8)),
+			},
+		},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+
+	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *resolve.Origin, error) {
+		v := bsemver.MustParse("1.0.1")
+		return &declcfg.Bundle{
+			Name:    "prometheus.v1.0.1",
+			Package: "prometheus",
+			Image:   "quay.io/operatorhubio/prometheus@fake1.0.1",
+		}, &v, nil, nil, nil
+	})
+	reconciler.Manager = &MockManagedContentCacheManager{
+		cache: &MockManagedContentCache{},
+	}
+	reconciler.InstalledBundleGetter = &MockInstalledBundleGetter{
+		bundle: &controllers.InstalledBundle{
+			BundleMetadata: ocv1.BundleMetadata{Name: "prometheus.v1.0.0", Version: "1.0.0"},
+			Image:          "quay.io/operatorhubio/prometheus@fake1.0.0",
+		},
+	}
+	reconciler.Applier = &MockApplier{
+		objs: []client.Object{},
+	}
+
+	t.Log("It reports the permission changes of the pending upgrade")
+	res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.Equal(t, ctrl.Result{}, res)
+	require.NoError(t, err)
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	require.Equal(t, ocv1.BundleMetadata{Name: "prometheus.v1.0.0", Version: "1.0.0"}, clusterExtension.Status.Install.Bundle)
+	require.Equal(t, &ocv1.PermissionChanges{
+		Bundle:  ocv1.BundleMetadata{Name: "prometheus.v1.0.1", Version: "1.0.1"},
+		Added:   []ocv1.PermissionRule{addedRule},
+		Removed: []ocv1.PermissionRule{removedRule},
+	}, clusterExtension.Status.Install.PendingPermissionChanges)
+	require.Equal(t, []string{extKey.Name, controllers.PendingUpgradeUnpackName(extKey.Name)}, unpacker.unpacked)
+
+	t.Log("It retries when the permission changes cannot be computed")
+	reconciler.PermissionPreviewer = &MockPermissionPreviewer{err: errors.New("fake error")}
+	_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.EqualError(t, err, `error computing the permission changes of the pending upgrade to bundle "prometheus.v1.0.1": fake error`)
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	progressingCond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
+	require.NotNil(t, progressingCond)
+	require.Equal(t, metav1.ConditionTrue, progressingCond.Status)
+	require.Equal(t, ocv1.ReasonRetrying, progressingCond.Reason)
+	require.Nil(t, clusterExtension.Status.Install.PendingPermissionChanges)
+
+	t.Log("It stops reporting them once the upgrade is approved")
+	reconciler.PermissionPreviewer = &MockPermissionPreviewer{}
+	clusterExtension.Spec.Source.Catalog.ApprovedVersion = "1.0.1"
+	require.NoError(t, cl.Update(ctx, clusterExtension))
+
+	unpacker.unpacked, unpacker.cleaned = nil, nil
+	res, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.Equal(t, ctrl.Result{}, res)
+	require.NoError(t, err)
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	require.Equal(t, ocv1.BundleMetadata{Name: "prometheus.v1.0.1", Version: "1.0.1"}, clusterExtension.Status.Install.Bundle)
+	require.Nil(t, clusterExtension.Status.Install.PendingPermissionChanges)
+	require.Equal(t, []string{extKey.Name}, unpacker.unpacked)
+	require.Equal(t, []string{controllers.PendingUpgradeUnpackName(extKey.Name)}, unpacker.cleaned)
+
+	require.NoError(t, cl.DeleteAllOf(ctx, &ocv1.ClusterExtension{}))
+}
+
+func TestClusterExtensionPermissionEscalation(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	reconciler.Unpacker = &MockUnpacker{
+		result: &source.Result{
+			State:  source.StateUnpacked,
+			Bundle: fstest.MapFS{},
+		},
+	}
+	addedRule := ocv1.PermissionRule{Rule: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}}}
+	reconciler.PermissionPreviewer = &MockPermissionPreviewer{
+		added:     []ocv1.PermissionRule{addedRule},
+		escalates: true,
+	}
+
+	ctx := context.Background()
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+
+	t.Log("When the cluster extension requires approval of permission escalations")
+	clusterExtension := &ocv1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec: ocv1.ClusterExtensionSpec{
+			Source: ocv1.SourceConfig{
+				SourceType: "Catalog",
+				Catalog: &ocv1.CatalogSource{
+					PackageName: "prometheus",
+				},
+			},
+			Namespace: fmt.Sprintf("test-ns-%s", rand.String(8)),
+			ServiceAccount: ocv1.ServiceAccountReference{
+				Name: fmt.Sprintf("test-sa-%s", rand.String(8)),
+			},
+			Install: &ocv1.ClusterExtensionInstallConfig{
+				PermissionEscalation: ocv1.PermissionEscalationRequireApproval,
+			},
+		},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+
+	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *resolve.Origin, error) {
+		v := bsemver.MustParse("1.0.1")
+		return &declcfg.Bundle{
+			Name:    "prometheus.v1.0.1",
+			Package: "prometheus",
+			Image:   "quay.io/operatorhubio/prometheus@fake1.0.1",
+		}, &v, nil, nil, nil
+	})
+	reconciler.Manager = &MockManagedContentCacheManager{
+		cache: &MockManagedContentCache{},
+	}
+	reconciler.InstalledBundleGetter = &MockInstalledBundleGetter{
+		bundle: &controllers.InstalledBundle{
+			BundleMetadata: ocv1.BundleMetadata{Name: "prometheus.v1.0.0", Version: "1.0.0"},
+			Image:          "quay.io/operatorhubio/prometheus@fake1.0.0",
+		},
+	}
+	reconciler.Applier = &MockApplier{
+		objs: []client.Object{},
+	}
+
+	t.Log("It keeps the installed bundle when the upgrade grants additional permissions")
+	res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.Equal(t, ctrl.Result{}, res)
+	require.NoError(t, err)
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	require.Equal(t, ocv1.BundleMetadata{Name: "prometheus.v1.0.0", Version: "1.0.0"}, clusterExtension.Status.Install.Bundle)
+	require.Equal(t, &ocv1.PermissionChanges{
+		Bundle: ocv1.BundleMetadata{Name: "prometheus.v1.0.1", Version: "1.0.1"},
+		Added:  []ocv1.PermissionRule{addedRule},
+	}, clusterExtension.Status.Install.PendingPermissionChanges)
+
+	progressingCond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
+	require.NotNil(t, progressingCond)
+	require.Equal(t, metav1.ConditionFalse, progressingCond.Status)
+	require.Equal(t, ocv1.ReasonPermissionEscalation, progressingCond.Reason)
+	require.Contains(t, progressingCond.Message, `set spec.install.approvedPermissionEscalationVersion to "1.0.1"`)
+
+	t.Log("It upgrades once the permission escalation is approved")
+	clusterExtension.Spec.Install.ApprovedPermissionEscalationVersion = "1.0.1"
+	require.NoError(t, cl.Update(ctx, clusterExtension))
+
+	res, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.Equal(t, ctrl.Result{}, res)
+	require.NoError(t, err)
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	require.Equal(t, ocv1.BundleMetadata{Name: "prometheus.v1.0.1", Version: "1.0.1"}, clusterExtension.Status.Install.Bundle)
+	require.Nil(t, clusterExtension.Status.Install.PendingPermissionChanges)
+
+	progressingCond = apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
+	require.NotNil(t, progressingCond)
+	require.Equal(t, metav1.ConditionTrue, progressingCond.Status)
+	require.Equal(t, ocv1.ReasonSucceeded, progressingCond.Reason)
+
+	t.Log("It upgrades without approval when the upgrade grants no additional permissions, even if its rules change")
+	clusterExtension.Spec.Install.ApprovedPermissionEscalationVersion = ""
+	require.NoError(t, cl.Update(ctx, clusterExtension))
+	reconciler.PermissionPreviewer = &MockPermissionPreviewer{
+		added:   []ocv1.PermissionRule{{Rule: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}, ResourceNames: []string{"foo"}}}},
+		removed: []ocv1.PermissionRule{addedRule},
+	}
+
+	res, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.Equal(t, ctrl.Result{}, res)
+	require.NoError(t, err)
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	require.Equal(t, ocv1.BundleMetadata{Name: "prometheus.v1.0.1", Version: "1.0.1"}, clusterExtension.Status.Install.Bundle)
+	require.Nil(t, clusterExtension.Status.Install.PendingPermissionChanges)
+
+	t.Log("It requires approval when the upgrade binds a role to an additional subject")
+	addedBinding := ocv1.PermissionBinding{
+		RoleRef: rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "cluster-admin"},
+		Subject: rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: "default", Name: "default"},
+	}
+	reconciler.PermissionPreviewer = &MockPermissionPreviewer{
+		addedBindings: []ocv1.PermissionBinding{addedBinding},
+		escalates:     true,
+	}
+
+	res, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.Equal(t, ctrl.Result{}, res)
+	require.NoError(t, err)
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	require.Equal(t, ocv1.BundleMetadata{Name: "prometheus.v1.0.0", Version: "1.0.0"}, clusterExtension.Status.Install.Bundle)
+	require.Equal(t, &ocv1.PermissionChanges{
+		Bundle:        ocv1.BundleMetadata{Name: "prometheus.v1.0.1", Version: "1.0.1"},
+		AddedBindings: []ocv1.PermissionBinding{addedBinding},
+	}, clusterExtension.Status.Install.PendingPermissionChanges)
+
+	progressingCond = apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
+	require.NotNil(t, progressingCond)
+	require.Equal(t, ocv1.ReasonPermissionEscalation, progressingCond.Reason)
+
+	require.NoError(t, cl.DeleteAllOf(ctx, &ocv1.ClusterExtension{}))
+}
+
+func TestClusterExtensionPlanMode(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	unpacker := &MockUnpacker{
+		result: &source.Result{
+			State:  source.StateUnpacked,
+			Bundle: fstest.MapFS{},
+		},
+	}
+	reconciler.Unpacker = unpacker
+	plan := &ocv1.ReleasePlan{
+		Changed: []ocv1.ObjectChange{{
+			ManagedObjectReference: ocv1.ManagedObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "ns", Name: "manager"},
+			Fields:                 []string{".spec.template.spec.containers"},
+		}},
+	}
+	reconciler.Planner = &MockPlanner{plan: plan}
+
+	ctx := context.Background()
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+
+	t.Log("When the cluster extension is in Plan mode")
+	clusterExtension := &ocv1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec: ocv1.ClusterExtensionSpec{
+			Source: ocv1.SourceConfig{
+				SourceType: "Catalog",
+				Catalog: &ocv1.CatalogSource{
+					PackageName: "prometheus",
+				},
+			},
+			Namespace: fmt.Sprintf("test-ns-%s", rand.String(8)),
+			ServiceAccount: ocv1.ServiceAccountReference{
+				Name: fmt.Sprintf("test-sa-%s", rand.String(8)),
+			},
+			Install: &ocv1.ClusterExtensionInstallConfig{
+				Mode: ocv1.ApplyModePlan,
+			},
+		},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+
+	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *resolve.Origin, error) {
+		v := bsemver.MustParse("1.0.1")
+		return &declcfg.Bundle{
+			Name:    "prometheus.v1.0.1",
+			Package: "prometheus",
+			Image:   "quay.io/operatorhubio/prometheus@fake1.0.1",
+		}, &v, nil, nil, nil
+	})
+	reconciler.Manager = &MockManagedContentCacheManager{
+		cache: &MockManagedContentCache{},
+	}
+	reconciler.InstalledBundleGetter = &MockInstalledBundleGetter{
+		bundle: &controllers.InstalledBundle{
+			BundleMetadata: ocv1.BundleMetadata{Name: "prometheus.v1.0.0", Version: "1.0.0"},
+			Image:          "quay.io/operatorhubio/prometheus@fake1.0.0",
+		},
+	}
+	reconciler.Applier = &MockApplier{
+		objs: []client.Object{},
+	}
+
+	t.Log("It reports the plan of the upgrade and keeps the installed bundle")
+	res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.Equal(t, ctrl.Result{}, res)
+	require.NoError(t, err)
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	require.Equal(t, ocv1.BundleMetadata{Name: "prometheus.v1.0.0", Version: "1.0.0"}, clusterExtension.Status.Install.Bundle)
+	require.Equal(t, &ocv1.ReleasePlan{
+		Bundle:  ocv1.BundleMetadata{Name: "prometheus.v1.0.1", Version: "1.0.1"},
+		Changed: plan.Changed,
+	}, clusterExtension.Status.Plan)
+
+	progressingCond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
+	require.NotNil(t, progressingCond)
+	require.Equal(t, metav1.ConditionFalse, progressingCond.Status)
+	require.Equal(t, ocv1.ReasonPlanned, progressingCond.Reason)
+	require.Contains(t, progressingCond.Message, `set spec.install.mode to "Apply"`)
+
+	t.Log("It fails when planning fails")
+	reconciler.Planner = &MockPlanner{err: errors.New("dry run failed")}
+	_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.Error(t, err)
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	require.Nil(t, clusterExtension.Status.Plan)
+	progressingCond = apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
+	require.NotNil(t, progressingCond)
+	require.Equal(t, ocv1.ReasonApplyFailed, progressingCond.Reason)
+	require.Contains(t, progressingCond.Message, "dry run failed")
+
+	t.Log("It plans the upgrade that requires approval")
+	reconciler.Planner = &MockPlanner{plan: plan}
+	clusterExtension.Spec.Source.Catalog.UpgradeApproval = ocv1.UpgradeApprovalManual
+	require.NoError(t, cl.Update(ctx, clusterExtension))
+	unpacker.unpacked = nil
+
+	res, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.Equal(t, ctrl.Result{}, res)
+	require.NoError(t, err)
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	require.Equal(t, ocv1.BundleMetadata{Name: "prometheus.v1.0.0", Version: "1.0.0"}, clusterExtension.Status.Install.Bundle)
+	require.Equal(t, &ocv1.ReleasePlan{
+		Bundle:  ocv1.BundleMetadata{Name: "prometheus.v1.0.1", Version: "1.0.1"},
+		Changed: plan.Changed,
+	}, clusterExtension.Status.Plan)
+	require.Equal(t, []string{extKey.Name, controllers.PendingUpgradeUnpackName(extKey.Name)}, unpacker.unpacked)
+
+	progressingCond = apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
+	require.NotNil(t, progressingCond)
+	require.Equal(t, ocv1.ReasonPlanned, progressingCond.Reason)
+
+	t.Log("It applies the upgrade and clears the plan in Apply mode")
+	clusterExtension.Spec.Source.Catalog.UpgradeApproval = ocv1.UpgradeApprovalAutomatic
+	clusterExtension.Spec.Install.Mode = ocv1.ApplyModeApply
+	require.NoError(t, cl.Update(ctx, clusterExtension))
+
+	res, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.Equal(t, ctrl.Result{}, res)
+	require.NoError(t, err)
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	require.Equal(t, ocv1.BundleMetadata{Name: "prometheus.v1.0.1", Version: "1.0.1"}, clusterExtension.Status.Install.Bundle)
+	require.Nil(t, clusterExtension.Status.Plan)
+
+	progressingCond = apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
+	require.NotNil(t, progressingCond)
+	require.Equal(t, metav1.ConditionTrue, progressingCond.Status)
+	require.Equal(t, ocv1.ReasonSucceeded, progressingCond.Reason)
+
+	require.NoError(t, cl.DeleteAllOf(ctx, &ocv1.ClusterExtension{}))
+}
+
+func TestClusterExtensionUpgradeMaintenanceWindows(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	reconciler.Unpacker = &MockUnpacker{
+		result: &source.Result{
+			State:  source.StateUnpacked,
+			Bundle: fstest.MapFS{},
+		},
+	}
+
+	ctx := context.Background()
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+
+	// A window opening in two hours, which is therefore closed now.
+	opensAt := time.Now().UTC().Add(2 * time.Hour)
+	closedWindow := ocv1.MaintenanceWindow{
+		Schedule: fmt.Sprintf("%d %d * * *", opensAt.Minute(), opensAt.Hour()),
+		Duration: metav1.Duration{Duration: time.Minute},
+	}
+
+	t.Log("When the cluster extension only allows upgrades during maintenance windows")
+	t.Log("By initializing cluster state")
+	clusterExtension := &ocv1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec: ocv1.ClusterExtensionSpec{
+			Source: ocv1.SourceConfig{
+				SourceType: "Catalog",
+				Catalog: &ocv1.CatalogSource{
+					PackageName: "prometheus",
+				},
+			},
+			Namespace: fmt.Sprintf("test-ns-%s", rand.String(8)),
+			ServiceAccount: ocv1.ServiceAccountReference{
+				Name: fmt.Sprintf("test-sa-%s", rand.String(8)),
+			},
+			Install: &ocv1.ClusterExtensionInstallConfig{
+				MaintenanceWindows: []ocv1.MaintenanceWindow{closedWindow},
+			},
+		},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+
+	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *resolve.Origin, error) {
+		v := bsemver.MustParse("1.0.1")
+		return &declcfg.Bundle{
+			Name:    "prometheus.v1.0.1",
+			Package: "prometheus",
+			Image:   "quay.io/operatorhubio/prometheus@fake1.0.1",
+		}, &v, nil, nil, nil
+	})
+	reconciler.Manager = &MockManagedContentCacheManager{
+		cache: &MockManagedContentCache{},
+	}
+	reconciler.Applier = &MockApplier{
+		objs: []client.Object{},
+	}
+
+	t.Log("It does not defer the initial installation")
+	reconciler.InstalledBundleGetter = &MockInstalledBundleGetter{}
+	res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.Equal(t, ctrl.Result{}, res)
+	require.NoError(t, err)
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	require.Equal(t, ocv1.BundleMetadata{Name: "prometheus.v1.0.1", Version: "1.0.1"}, clusterExtension.Status.Install.Bundle)
+
+	t.Log("It keeps the installed bundle and requeues for the next window when an upgrade is found")
+	reconciler.InstalledBundleGetter = &MockInstalledBundleGetter{
+		bundle: &controllers.InstalledBundle{
+			BundleMetadata: ocv1.BundleMetadata{Name: "prometheus.v1.0.0", Version: "1.0.0"},
+			Image:          "quay.io/operatorhubio/prometheus@fake1.0.0",
+		},
+	}
+	res, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.NoError(t, err)
+	require.Greater(t, res.RequeueAfter, time.Hour)
+	require.LessOrEqual(t, res.RequeueAfter, 2*time.Hour)
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	require.Equal(t, ocv1.BundleMetadata{Name: "prometheus.v1.0.0", Version: "1.0.0"}, clusterExtension.Status.Install.Bundle)
+
+	progressingCond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
+	require.NotNil(t, progressingCond)
+	require.Equal(t, metav1.ConditionTrue, progressingCond.Status)
+	require.Equal(t, ocv1.ReasonUpgradeDeferred, progressingCond.Reason)
+	require.Contains(t, progressingCond.Message, `upgrade to bundle "prometheus.v1.0.1" with version "1.0.1" is deferred until the next maintenance window opens at`)
+
+	t.Log("It applies the upgrade once a window is open")
+	clusterExtension.Spec.Install.MaintenanceWindows = append(clusterExtension.Spec.Install.MaintenanceWindows, ocv1.MaintenanceWindow{
+		Schedule: "* * * * *",
+		Duration: metav1.Duration{Duration: time.Hour},
+	})
+	require.NoError(t, cl.Update(ctx, clusterExtension))
+
+	res, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.Equal(t, ctrl.Result{}, res)
+	require.NoError(t, err)
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	require.Equal(t, ocv1.BundleMetadata{Name: "prometheus.v1.0.1", Version: "1.0.1"}, clusterExtension.Status.Install.Bundle)
+
+	progressingCond = apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
+	require.NotNil(t, progressingCond)
+	require.Equal(t, metav1.ConditionTrue, progressingCond.Status)
+	require.Equal(t, ocv1.ReasonSucceeded, progressingCond.Reason)
+
+	require.NoError(t, cl.DeleteAllOf(ctx, &ocv1.ClusterExtension{}))
+}
+
+func TestClusterExtensionUpgradeRolledBack(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	reconciler.Unpacker = &MockUnpacker{
+		result: &source.Result{
+			State:  source.StateUnpacked,
+			Bundle: fstest.MapFS{},
+		},
+	}
+
+	ctx := context.Background()
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+
+	t.Log("When the cluster extension rolls back failed upgrades")
+	t.Log("By initializing cluster state")
+	clusterExtension := &ocv1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec: ocv1.ClusterExtensionSpec{
+			Source: ocv1.SourceConfig{
+				SourceType: "Catalog",
+				Catalog: &ocv1.CatalogSource{
+					PackageName: "prometheus",
+				},
+			},
+			Namespace: fmt.Sprintf("test-ns-%s", rand.String(8)),
+			ServiceAccount: ocv1.ServiceAccountReference{
+				Name: fmt.Sprintf("test-sa-%s", rand.String(8)),
+			},
+			Install: &ocv1.ClusterExtensionInstallConfig{
+				Rollback: &ocv1.RollbackConfig{Policy: ocv1.RollbackPolicyAutomatic},
+			},
+		},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+
+	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *resolve.Origin, error) {
+		v := bsemver.MustParse("1.0.1")
+		return &declcfg.Bundle{
+			Name:    "prometheus.v1.0.1",
+			Package: "prometheus",
+			Image:   "quay.io/operatorhubio/prometheus@fake1.0.1",
+		}, &v, nil, nil, nil
+	})
+	reconciler.InstalledBundleGetter = &MockInstalledBundleGetter{
+		bundle: &controllers.InstalledBundle{
+			BundleMetadata: ocv1.BundleMetadata{Name: "prometheus.v1.0.0", Version: "1.0.0"},
+			Image:          "quay.io/operatorhubio/prometheus@fake1.0.0",
+		},
+	}
+	reconciler.Applier = &MockApplier{
+		err: &applier.RolledBackError{FailedRevision: 2, Err: errors.New("deployment is not ready")},
+	}
+
+	t.Log("It keeps the installed bundle and reports the rollback without retrying")
+	res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.Equal(t, ctrl.Result{}, res)
+	require.ErrorIs(t, err, reconcile.TerminalError(nil))
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	require.Equal(t, ocv1.BundleMetadata{Name: "prometheus.v1.0.0", Version: "1.0.0"}, clusterExtension.Status.Install.Bundle)
+
+	progressingCond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
+	require.NotNil(t, progressingCond)
+	require.Equal(t, metav1.ConditionFalse, progressingCond.Status)
+	require.Equal(t, ocv1.ReasonRolledBack, progressingCond.Reason)
+	require.Equal(t, `upgrade to revision 2 failed and was rolled back: deployment is not ready for resolved bundle "prometheus.v1.0.1" with version "1.0.1"`, progressingCond.Message)
+
+	installedCond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeInstalled)
+	require.NotNil(t, installedCond)
+	require.Equal(t, metav1.ConditionTrue, installedCond.Status)
+
+	require.NoError(t, cl.DeleteAllOf(ctx, &ocv1.ClusterExtension{}))
+}
+
+func TestClusterExtensionUpgradeHealthCheckPending(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	reconciler.Unpacker = &MockUnpacker{
+		result: &source.Result{
+			State:  source.StateUnpacked,
+			Bundle: fstest.MapFS{},
+		},
+	}
+	reconciler.Manager = &MockManagedContentCacheManager{
+		cache: &MockManagedContentCache{},
+	}
+
+	ctx := context.Background()
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+
+	t.Log("When the cluster extension health checks upgrades")
+	t.Log("By initializing cluster state")
+	clusterExtension := &ocv1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec: ocv1.ClusterExtensionSpec{
+			Source: ocv1.SourceConfig{
+				SourceType: "Catalog",
+				Catalog: &ocv1.CatalogSource{
+					PackageName: "prometheus",
+				},
+			},
+			Namespace: fmt.Sprintf("test-ns-%s", rand.String(8)),
+			ServiceAccount: ocv1.ServiceAccountReference{
+				Name: fmt.Sprintf("test-sa-%s", rand.String(8)),
+			},
+			Install: &ocv1.ClusterExtensionInstallConfig{
+				Rollback: &ocv1.RollbackConfig{
+					Policy:             ocv1.RollbackPolicyAutomatic,
+					HealthCheckTimeout: &metav1.Duration{Duration: time.Minute},
+				},
+			},
+		},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+
+	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *resolve.Origin, error) {
+		v := bsemver.MustParse("1.0.1")
+		return &declcfg.Bundle{
+			Name:    "prometheus.v1.0.1",
+			Package: "prometheus",
+			Image:   "quay.io/operatorhubio/prometheus@fake1.0.1",
+		}, &v, nil, nil, nil
+	})
+	reconciler.InstalledBundleGetter = &MockInstalledBundleGetter{
+		bundle: &controllers.InstalledBundle{
+			BundleMetadata: ocv1.BundleMetadata{Name: "prometheus.v1.0.0", Version: "1.0.0"},
+			Image:          "quay.io/operatorhubio/prometheus@fake1.0.0",
+		},
+	}
+	deadline := time.Now().Add(time.Minute)
+	reconciler.Applier = &MockApplier{
+		state: applier.StateNeedsUpgrade,
+		err:   &applier.HealthCheckPendingError{Deadline: deadline},
+	}
+
+	t.Log("It installs the upgrade and checks it again before the deadline without blocking")
+	res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.NoError(t, err)
+	require.Positive(t, res.RequeueAfter)
+	require.LessOrEqual(t, res.RequeueAfter, time.Until(deadline))
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	require.Equal(t, ocv1.BundleMetadata{Name: "prometheus.v1.0.1", Version: "1.0.1"}, clusterExtension.Status.Install.Bundle)
+
+	progressingCond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
+	require.NotNil(t, progressingCond)
+	require.Equal(t, metav1.ConditionTrue, progressingCond.Status)
+	require.Equal(t, ocv1.ReasonRetrying, progressingCond.Reason)
+	require.Contains(t, progressingCond.Message, "for the upgraded workloads to become ready")
+
+	require.NoError(t, cl.DeleteAllOf(ctx, &ocv1.ClusterExtension{}))
+}
+
+func TestClusterExtensionRollbackTo(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	reconciler.Manager = &MockManagedContentCacheManager{
+		cache: &MockManagedContentCache{},
+	}
+
+	ctx := context.Background()
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+
+	t.Log("When the cluster extension rolls back to a previous revision")
+	t.Log("By initializing cluster state")
+	clusterExtension := &ocv1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec: ocv1.ClusterExtensionSpec{
+			Source: ocv1.SourceConfig{
+				SourceType: "Catalog",
+				Catalog: &ocv1.CatalogSource{
+					PackageName: "prometheus",
+				},
+			},
+			Namespace: fmt.Sprintf("test-ns-%s", rand.String(8)),
+			ServiceAccount: ocv1.ServiceAccountReference{
+				Name: fmt.Sprintf("test-sa-%s", rand.String(8)),
+			},
+			Install: &ocv1.ClusterExtensionInstallConfig{
+				RollbackTo: &ocv1.RollbackTarget{Revision: 1},
+			},
+		},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+
+	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *resolve.Origin, error) {
+		return nil, nil, nil, nil, errors.New("resolution must be skipped while rolling back")
+	})
+	reconciler.InstalledBundleGetter = &MockInstalledBundleGetter{
+		bundle: &controllers.InstalledBundle{
+			BundleMetadata: ocv1.BundleMetadata{Name: "prometheus.v1.0.1", Version: "1.0.1"},
+			Image:          "quay.io/operatorhubio/prometheus@fake1.0.1",
+		},
+	}
+	mockApplier := &MockApplier{
+		rollbackLabels: map[string]string{
+			labels.BundleNameKey:      "prometheus.v1.0.0",
+			labels.PackageNameKey:     "prometheus",
+			labels.BundleVersionKey:   "1.0.0",
+			labels.BundleReferenceKey: "quay.io/operatorhubio/prometheus@fake1.0.0",
+		},
+	}
+	reconciler.Applier = mockApplier
+
+	t.Log("It re-applies the selected revision without resolving a bundle")
+	res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.Equal(t, ctrl.Result{}, res)
+	require.NoError(t, err)
+	require.Equal(t, 1, mockApplier.rollbackRevision)
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	require.Equal(t, ocv1.BundleMetadata{Name: "prometheus.v1.0.0", Version: "1.0.0"}, clusterExtension.Status.Install.Bundle)
+	require.Equal(t, "quay.io/operatorhubio/prometheus@fake1.0.0", clusterExtension.Status.Install.ResolvedImageRef)
+	verifyConditionsInvariants(t, clusterExtension)
+
+	progressingCond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
+	require.NotNil(t, progressingCond)
+	require.Equal(t, metav1.ConditionTrue, progressingCond.Status)
+	require.Equal(t, ocv1.ReasonSucceeded, progressingCond.Reason)
+
+	t.Log("It keeps the installed bundle and reports an error when the revision cannot be rolled back to")
+	mockApplier.err = reconcile.TerminalError(errors.New("revision 1 not found in the release history"))
+	res, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.Equal(t, ctrl.Result{}, res)
+	require.ErrorIs(t, err, reconcile.TerminalError(nil))
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	require.Equal(t, ocv1.BundleMetadata{Name: "prometheus.v1.0.1", Version: "1.0.1"}, clusterExtension.Status.Install.Bundle)
+	verifyConditionsInvariants(t, clusterExtension)
+
+	progressingCond = apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
+	require.NotNil(t, progressingCond)
+	require.Equal(t, metav1.ConditionFalse, progressingCond.Status)
+	require.Equal(t, ocv1.ReasonApplyFailed, progressingCond.Reason)
+	require.Equal(t, "error rolling back: terminal error: revision 1 not found in the release history", progressingCond.Message)
+
+	require.NoError(t, cl.DeleteAllOf(ctx, &ocv1.ClusterExtension{}))
+}
+
+func TestClusterExtensionInvalidMaintenanceWindow(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	ctx := context.Background()
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+
+	t.Log("When the cluster extension has a maintenance window with an unknown time zone")
+	clusterExtension := &ocv1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec: ocv1.ClusterExtensionSpec{
+			Source: ocv1.SourceConfig{
+				SourceType: "Catalog",
+				Catalog: &ocv1.CatalogSource{
+					PackageName: "prometheus",
+				},
+			},
+			Namespace: fmt.Sprintf("test-ns-%s", rand.String(8)),
+			ServiceAccount: ocv1.ServiceAccountReference{
+				Name: fmt.Sprintf("test-sa-%s", rand.String(8)),
+			},
+			Install: &ocv1.ClusterExtensionInstallConfig{
+				MaintenanceWindows: []ocv1.MaintenanceWindow{{
+					Schedule: "@daily",
+					Duration: metav1.Duration{Duration: time.Hour},
+					TimeZone: "Mars/Olympus_Mons",
+				}},
+			},
+		},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+
+	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *resolve.Origin, error) {
+		v := bsemver.MustParse("1.0.1")
+		return &declcfg.Bundle{
+			Name:    "prometheus.v1.0.1",
+			Package: "prometheus",
+			Image:   "quay.io/operatorhubio/prometheus@fake1.0.1",
+		}, &v, nil, nil, nil
+	})
+	reconciler.InstalledBundleGetter = &MockInstalledBundleGetter{
+		bundle: &controllers.InstalledBundle{
+			BundleMetadata: ocv1.BundleMetadata{Name: "prometheus.v1.0.0", Version: "1.0.0"},
+			Image:          "quay.io/operatorhubio/prometheus@fake1.0.0",
+		},
+	}
+
+	t.Log("It blocks the upgrade with a terminal error")
+	res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.Equal(t, ctrl.Result{}, res)
+	require.Error(t, err)
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	progressingCond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
+	require.NotNil(t, progressingCond)
+	require.Equal(t, metav1.ConditionFalse, progressingCond.Status)
+	require.Equal(t, ocv1.ReasonBlocked, progressingCond.Reason)
+	require.Contains(t, progressingCond.Message, `invalid time zone "Mars/Olympus_Mons"`)
+
+	require.NoError(t, cl.DeleteAllOf(ctx, &ocv1.ClusterExtension{}))
+}
+
+func TestClusterExtensionMissingDependencies(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	ctx := context.Background()
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	depName := fmt.Sprintf("dependency-%s", rand.String(8))
+
+	t.Log("When the resolved bundle has missing dependencies and the dependency policy is Install")
+	clusterExtension := &ocv1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec: ocv1.ClusterExtensionSpec{
+			Source: ocv1.SourceConfig{
+				SourceType: "Catalog",
+				Catalog: &ocv1.CatalogSource{
+					PackageName: "prometheus",
+				},
+			},
+			Namespace: fmt.Sprintf("test-ns-%s", rand.String(8)),
+			ServiceAccount: ocv1.ServiceAccountReference{
+				Name: fmt.Sprintf("test-sa-%s", rand.String(8)),
+			},
+			Install: &ocv1.ClusterExtensionInstallConfig{
+				DependencyPolicy: ocv1.DependencyPolicyInstall,
+			},
+		},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+
+	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *resolve.Origin, error) {
+		v := bsemver.MustParse("1.0.0")
+		return &declcfg.Bundle{
+			Name:    "prometheus.v1.0.0",
+			Package: "prometheus",
+			Image:   "quay.io/operatorhubio/prometheus@fake1.0.0",
+		}, &v, nil, nil, nil
+	})
+	reconciler.DependencyResolver = resolve.DependencyResolverFunc(func(context.Context, *ocv1.ClusterExtension, *declcfg.Bundle) ([]ocv1.DependencyStatus, error) {
+		return []ocv1.DependencyStatus{
+			{
+				Requirement: "API example.com/v1, Kind=Foo",
+				State:       ocv1.DependencyStateSatisfied,
+				Message:     "the API is served by the cluster",
+			},
+			{
+				Requirement: fmt.Sprintf("package %q in range \">=1.0.0\"", depName),
+				State:       ocv1.DependencyStateMissing,
+				Candidate: &ocv1.DependencyCandidate{
+					PackageName: depName,
+					Bundle:      ocv1.BundleMetadata{Name: depName + ".v1.2.0", Version: "1.2.0"},
+					Catalog:     "operatorhubio",
+				},
+				Message: fmt.Sprintf("bundle %q from catalog \"operatorhubio\" satisfies the dependency", depName+".v1.2.0"),
+			},
+		}, nil
+	})
+
+	t.Log("It only reports the missing dependency in Plan mode")
+	clusterExtension.Spec.Install.Mode = ocv1.ApplyModePlan
+	require.NoError(t, cl.Update(ctx, clusterExtension))
+	res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.Equal(t, ctrl.Result{}, res)
+	require.ErrorContains(t, err, `bundle "prometheus.v1.0.0" has unsatisfied dependencies`)
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	require.Len(t, clusterExtension.Status.Dependencies, 2)
+	require.Empty(t, clusterExtension.Status.Dependencies[1].ClusterExtensionName)
+	progressingCond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
+	require.NotNil(t, progressingCond)
+	require.Equal(t, ocv1.ReasonMissingDependencies, progressingCond.Reason)
+	require.True(t, apierrors.IsNotFound(cl.Get(ctx, types.NamespacedName{Name: depName}, &ocv1.ClusterExtension{})))
+
+	t.Log("It creates a ClusterExtension for the missing dependency and waits for it")
+	clusterExtension.Spec.Install.Mode = ocv1.ApplyModeApply
+	require.NoError(t, cl.Update(ctx, clusterExtension))
+	res, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.Equal(t, ctrl.Result{}, res)
+	require.ErrorContains(t, err, `bundle "prometheus.v1.0.0" has unsatisfied dependencies`)
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	verifyInvariants(ctx, t, reconciler.Client, clusterExtension)
+	require.Nil(t, clusterExtension.Status.Install)
+	require.Len(t, clusterExtension.Status.Dependencies, 2)
+	missing := clusterExtension.Status.Dependencies[1]
+	require.Equal(t, ocv1.DependencyStateMissing, missing.State)
+	require.Equal(t, depName, missing.ClusterExtensionName)
+	require.Equal(t, fmt.Sprintf("ClusterExtension %q was created to install bundle %q from catalog \"operatorhubio\"", depName, depName+".v1.2.0"), missing.Message)
+
+	progressingCond = apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
+	require.NotNil(t, progressingCond)
+	require.Equal(t, metav1.ConditionTrue, progressingCond.Status)
+	require.Equal(t, ocv1.ReasonMissingDependencies, progressingCond.Reason)
+	require.Contains(t, progressingCond.Message, missing.Requirement)
+
+	dependency := &ocv1.ClusterExtension{}
+	require.NoError(t, cl.Get(ctx, types.NamespacedName{Name: depName}, dependency))
+	require.Len(t, dependency.OwnerReferences, 1)
+	require.Equal(t, clusterExtension.UID, dependency.OwnerReferences[0].UID)
+	require.Nil(t, dependency.OwnerReferences[0].Controller)
+	require.Equal(t, clusterExtension.Spec.Namespace, dependency.Spec.Namespace)
+	require.Equal(t, clusterExtension.Spec.ServiceAccount, dependency.Spec.ServiceAccount)
+	require.Equal(t, depName, dependency.Spec.Source.Catalog.PackageName)
+	require.Equal(t, "1.2.0", dependency.Spec.Source.Catalog.Version)
+	require.Equal(t, map[string]string{"olm.operatorframework.io/metadata.name": "operatorhubio"}, dependency.Spec.Source.Catalog.Selector.MatchLabels)
+	require.Equal(t, ocv1.DependencyPolicyInstall, dependency.Spec.Install.DependencyPolicy)
+
+	t.Log("It does not fail when the ClusterExtension for the dependency already exists")
+	_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.ErrorContains(t, err, `bundle "prometheus.v1.0.0" has unsatisfied dependencies`)
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	require.Equal(t, depName, clusterExtension.Status.Dependencies[1].ClusterExtensionName)
+	require.NoError(t, cl.Get(ctx, types.NamespacedName{Name: depName}, dependency))
+	require.Len(t, dependency.OwnerReferences, 1)
+
+	t.Log("When another cluster extension has the same missing dependency")
+	otherKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	other := clusterExtension.DeepCopy()
+	other.ObjectMeta = metav1.ObjectMeta{Name: otherKey.Name}
+	other.Status = ocv1.ClusterExtensionStatus{}
+	require.NoError(t, cl.Create(ctx, other))
+	_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: otherKey})
+	require.ErrorContains(t, err, `bundle "prometheus.v1.0.0" has unsatisfied dependencies`)
+
+	t.Log("It records both cluster extensions as owners of the ClusterExtension for the dependency")
+	require.NoError(t, cl.Get(ctx, otherKey, other))
+	require.Equal(t, depName, other.Status.Dependencies[1].ClusterExtensionName)
+	require.NoError(t, cl.Get(ctx, types.NamespacedName{Name: depName}, dependency))
+	ownerUIDs := []types.UID{}
+	for _, ref := range dependency.OwnerReferences {
+		ownerUIDs = append(ownerUIDs, ref.UID)
+	}
+	require.ElementsMatch(t, []types.UID{clusterExtension.UID, other.UID}, ownerUIDs)
+
+	t.Log("It does not take over a ClusterExtension of the package that was not created for a dependency")
+	require.NoError(t, cl.DeleteAllOf(ctx, &ocv1.ClusterExtension{}))
+	clusterExtension = clusterExtension.DeepCopy()
+	clusterExtension.ObjectMeta = metav1.ObjectMeta{Name: extKey.Name}
+	clusterExtension.Status = ocv1.ClusterExtensionStatus{}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+	standalone := dependency.DeepCopy()
+	standalone.ObjectMeta = metav1.ObjectMeta{Name: depName}
+	standalone.Status = ocv1.ClusterExtensionStatus{}
+	require.NoError(t, cl.Create(ctx, standalone))
+	_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.ErrorContains(t, err, `bundle "prometheus.v1.0.0" has unsatisfied dependencies`)
+	require.NoError(t, cl.Get(ctx, types.NamespacedName{Name: depName}, standalone))
+	require.Empty(t, standalone.OwnerReferences)
+
+	require.NoError(t, cl.DeleteAllOf(ctx, &ocv1.ClusterExtension{}))
+}
+
+func TestClusterExtensionUnpacksWithPullSecret(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	unpacker := &MockUnpacker{
+		result: &source.Result{
+			State:  source.StateUnpacked,
+			Bundle: fstest.MapFS{},
+		},
+	}
+	reconciler.Unpacker = unpacker
+	reconciler.PullSecretGetter = func(_ context.Context, ext *ocv1.ClusterExtension) ([]byte, error) {
+		require.Equal(t, "my-pull-secret", ext.Spec.Source.Image.PullSecret)
+		return []byte(`{"auths":{}}`), nil
+	}
+
+	ctx := context.Background()
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+
+	t.Log("When a cluster extension is installed from a private bundle image")
+	clusterExtension := &ocv1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec: ocv1.ClusterExtensionSpec{
+			Source: ocv1.SourceConfig{
+				SourceType: ocv1.SourceTypeImage,
+				Image: &ocv1.ImageSource{
+					Ref:        "quay.io/example/prometheus-bundle:v1.0.0",
+					PullSecret: "my-pull-secret",
+				},
+			},
+			Namespace: fmt.Sprintf("test-ns-%s", rand.String(8)),
+			ServiceAccount: ocv1.ServiceAccountReference{
+				Name: fmt.Sprintf("test-sa-%s", rand.String(8)),
+			},
+		},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+
+	reconciler.Resolver = resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *resolve.Origin, error) {
+		v := bsemver.MustParse("1.0.0")
+		return &declcfg.Bundle{
+			Name:    "prometheus.v1.0.0",
+			Package: "prometheus",
+			Image:   "quay.io/example/prometheus-bundle@sha256:abc",
+		}, &v, nil, nil, nil
+	})
+	reconciler.Applier = &MockApplier{
+		objs: []client.Object{},
+	}
+	reconciler.Manager = &MockManagedContentCacheManager{
+		cache: &MockManagedContentCache{},
+	}
+
+	_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.NoError(t, err)
+
+	t.Log("It unpacks the bundle image with the pull secret of the image source, under the name the resolver unpacked it with")
+	require.Equal(t, []string{resolve.ResolveUnpackName(extKey.Name)}, unpacker.unpacked)
+	require.Equal(t, []string{`{"auths":{}}`}, unpacker.pullSecrets)
+
+	t.Log("It reports getting the pull secret as an unpack failure")
+	reconciler.PullSecretGetter = func(context.Context, *ocv1.ClusterExtension) ([]byte, error) {
+		return nil, errors.New("fake error")
+	}
+	_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.ErrorContains(t, err, `error getting pull secret "my-pull-secret": fake error`)
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	cond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
+	require.NotNil(t, cond)
+	require.Equal(t, ocv1.ReasonUnpackFailed, cond.Reason)
+
+	require.NoError(t, cl.DeleteAllOf(ctx, &ocv1.ClusterExtension{}))
+}
@@ -19,12 +19,17 @@ package controllers
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+	olmv1error "github.com/operator-framework/operator-controller/internal/action/error"
+	"github.com/operator-framework/operator-controller/internal/uninstall"
 )
 
 // setInstalledStatusFromBundle sets the installed status based on the given installedBundle.
@@ -37,7 +42,11 @@ func setInstalledStatusFromBundle(ext *ocv1.ClusterExtension, installedBundle *I
 	}
 	// Something is installed
 	installStatus := &ocv1.ClusterExtensionInstallStatus{
-		Bundle: installedBundle.BundleMetadata,
+		Bundle:           installedBundle.BundleMetadata,
+		ResolvedImageRef: installedBundle.Image,
+		Catalog:          installedBundle.Catalog,
+		Channels:         installedBundle.Channels,
+		ManagedObjects:   installedBundle.ManagedObjects,
 	}
 	setInstallStatus(ext, installStatus)
 	setInstalledStatusConditionSuccess(ext, fmt.Sprintf("Installed bundle %s successfully", installedBundle.Image))
@@ -80,6 +89,63 @@ func setInstallStatus(ext *ocv1.ClusterExtension, installStatus *ocv1.ClusterExt
 	ext.Status.Install = installStatus
 }
 
+// setAvailableUpgrade records the upgrade that is waiting for approval, if any,
+// and sets the UpgradeAvailable condition accordingly.
+func setAvailableUpgrade(ext *ocv1.ClusterExtension, availableUpgrade *ocv1.BundleMetadata) {
+	ext.Status.AvailableUpgrade = availableUpgrade
+
+	cond := metav1.Condition{
+		Type:               ocv1.TypeUpgradeAvailable,
+		Status:             metav1.ConditionFalse,
+		Reason:             ocv1.ReasonUpToDate,
+		Message:            "no upgrade is waiting for approval",
+		ObservedGeneration: ext.GetGeneration(),
+	}
+	if availableUpgrade != nil {
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = ocv1.ReasonApprovalRequired
+		cond.Message = fmt.Sprintf("upgrade to bundle %q with version %q requires approval: set spec.source.catalog.approvedVersion to %q to approve it",
+			availableUpgrade.Name, availableUpgrade.Version, availableUpgrade.Version)
+	}
+	apimeta.SetStatusCondition(&ext.Status.Conditions, cond)
+}
+
+// setHealthyStatus sets the Healthy condition from the messages describing the
+// managed objects that are not healthy, or from the error that prevented
+// checking their health.
+func setHealthyStatus(ext *ocv1.ClusterExtension, unhealthy []string, err error) {
+	cond := metav1.Condition{
+		Type:               ocv1.TypeHealthy,
+		Status:             metav1.ConditionTrue,
+		Reason:             ocv1.ReasonHealthy,
+		Message:            "installed workloads are ready",
+		ObservedGeneration: ext.GetGeneration(),
+	}
+	switch {
+	case err != nil:
+		cond.Status = metav1.ConditionUnknown
+		cond.Reason = ocv1.ReasonFailed
+		cond.Message = fmt.Sprintf("error checking health: %v", err)
+	case len(unhealthy) > 0:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = ocv1.ReasonUnhealthy
+		cond.Message = strings.Join(unhealthy, "; ")
+	}
+	apimeta.SetStatusCondition(&ext.Status.Conditions, cond)
+}
+
+// keepCondition marks the condition of the same type as initial as observed for
+// the current generation without re-evaluating it, or sets it to initial if the
+// ClusterExtension does not have it yet.
+func keepCondition(ext *ocv1.ClusterExtension, initial metav1.Condition) {
+	cond := initial
+	if existing := apimeta.FindStatusCondition(ext.Status.Conditions, initial.Type); existing != nil {
+		cond = *existing
+	}
+	cond.ObservedGeneration = ext.GetGeneration()
+	apimeta.SetStatusCondition(&ext.Status.Conditions, cond)
+}
+
 func setStatusProgressing(ext *ocv1.ClusterExtension, err error) {
 	progressingCond := metav1.Condition{
 		Type:               ocv1.TypeProgressing,
@@ -101,3 +167,113 @@ func setStatusProgressing(ext *ocv1.ClusterExtension, err error) {
 
 	apimeta.SetStatusCondition(&ext.Status.Conditions, progressingCond)
 }
+
+// setStatusTerminating reports in the Terminating condition whether ext is being
+// deleted and whether its finalizers, which uninstall its content, failed with err.
+func setStatusTerminating(ext *ocv1.ClusterExtension, err error) {
+	cond := metav1.Condition{
+		Type:               ocv1.TypeTerminating,
+		Status:             metav1.ConditionFalse,
+		Reason:             ocv1.ReasonActive,
+		Message:            "deletion has not been requested",
+		ObservedGeneration: ext.GetGeneration(),
+	}
+	switch {
+	case ext.GetDeletionTimestamp() == nil:
+	case err == nil:
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = ocv1.ReasonUninstalling
+		cond.Message = fmt.Sprintf("uninstalled with policy %s", uninstall.Policy(ext))
+	case errors.Is(err, uninstall.ErrWaiting):
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = ocv1.ReasonUninstalling
+		cond.Message = err.Error()
+	default:
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = ocv1.ReasonFailed
+		cond.Message = err.Error()
+	}
+	apimeta.SetStatusCondition(&ext.Status.Conditions, cond)
+}
+
+// setStatusProgressingFailed reports in the Progressing condition that a stage
+// of the reconciliation failed with err, using reason to identify the stage.
+// Install conflicts and forbidden requests are reported as such whatever the stage.
+func setStatusProgressingFailed(ext *ocv1.ClusterExtension, reason string, err error) {
+	var installConflict *olmv1error.InstallConflictError
+	switch {
+	case errors.As(err, &installConflict):
+		reason = ocv1.ReasonInstallConflict
+	case apierrors.IsForbidden(err):
+		reason = ocv1.ReasonPermissionDenied
+	}
+
+	status := metav1.ConditionTrue
+	if errors.Is(err, reconcile.TerminalError(nil)) {
+		status = metav1.ConditionFalse
+	}
+
+	apimeta.SetStatusCondition(&ext.Status.Conditions, metav1.Condition{
+		Type:               ocv1.TypeProgressing,
+		Status:             status,
+		Reason:             reason,
+		Message:            err.Error(),
+		ObservedGeneration: ext.GetGeneration(),
+	})
+}
+
+// setStatusProgressingUpgradeDeferred reports in the Progressing condition that the
+// given upgrade waits for the maintenance window that opens at opensAt.
+func setStatusProgressingUpgradeDeferred(ext *ocv1.ClusterExtension, upgrade ocv1.BundleMetadata, opensAt time.Time) {
+	message := fmt.Sprintf("upgrade to bundle %q with version %q is deferred: no maintenance window is scheduled to open", upgrade.Name, upgrade.Version)
+	if !opensAt.IsZero() {
+		message = fmt.Sprintf("upgrade to bundle %q with version %q is deferred until the next maintenance window opens at %s",
+			upgrade.Name, upgrade.Version, opensAt.UTC().Format(time.RFC3339))
+	}
+	apimeta.SetStatusCondition(&ext.Status.Conditions, metav1.Condition{
+		Type:               ocv1.TypeProgressing,
+		Status:             metav1.ConditionTrue,
+		Reason:             ocv1.ReasonUpgradeDeferred,
+		Message:            message,
+		ObservedGeneration: ext.GetGeneration(),
+	})
+}
+
+// setStatusProgressingPermissionEscalation reports in the Progressing condition
+// that the upgrade to the given bundle grants additional permissions and is
+// blocked until they are approved.
+func setStatusProgressingPermissionEscalation(ext *ocv1.ClusterExtension, upgrade ocv1.BundleMetadata) {
+	apimeta.SetStatusCondition(&ext.Status.Conditions, metav1.Condition{
+		Type:   ocv1.TypeProgressing,
+		Status: metav1.ConditionFalse,
+		Reason: ocv1.ReasonPermissionEscalation,
+		Message: fmt.Sprintf("upgrade to bundle %q with version %q grants additional permissions, which are reported in status.install.pendingPermissionChanges: set spec.install.approvedPermissionEscalationVersion to %q to approve them",
+			upgrade.Name, upgrade.Version, upgrade.Version),
+		ObservedGeneration: ext.GetGeneration(),
+	})
+}
+
+// setStatusProgressingPlanned reports in the Progressing condition that the
+// changes applying the given bundle would make are reported instead of applied.
+func setStatusProgressingPlanned(ext *ocv1.ClusterExtension, bundle ocv1.BundleMetadata) {
+	apimeta.SetStatusCondition(&ext.Status.Conditions, metav1.Condition{
+		Type:   ocv1.TypeProgressing,
+		Status: metav1.ConditionFalse,
+		Reason: ocv1.ReasonPlanned,
+		Message: fmt.Sprintf("the changes applying bundle %q with version %q would make are reported in status.plan: set spec.install.mode to %q to apply them",
+			bundle.Name, bundle.Version, ocv1.ApplyModeApply),
+		ObservedGeneration: ext.GetGeneration(),
+	})
+}
+
+// setStatusProgressingRolledBack reports in the Progressing condition that an
+// upgrade failed with err and was rolled back.
+func setStatusProgressingRolledBack(ext *ocv1.ClusterExtension, err error) {
+	apimeta.SetStatusCondition(&ext.Status.Conditions, metav1.Condition{
+		Type:               ocv1.TypeProgressing,
+		Status:             metav1.ConditionFalse,
+		Reason:             ocv1.ReasonRolledBack,
+		Message:            err.Error(),
+		ObservedGeneration: ext.GetGeneration(),
+	})
+}
@@ -2,16 +2,22 @@ package controllers
 
 import (
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+	olmv1error "github.com/operator-framework/operator-controller/internal/action/error"
+	"github.com/operator-framework/operator-controller/internal/uninstall"
 )
 
 func TestSetStatusProgressing(t *testing.T) {
@@ -64,3 +70,129 @@ func TestSetStatusProgressing(t *testing.T) {
 		})
 	}
 }
+
+func TestSetStatusProgressingFailed(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		reason   string
+		err      error
+		expected metav1.Condition
+	}{
+		{
+			name:   "non-terminal error, Progressing condition has status True with the reason of the stage",
+			reason: ocv1.ReasonUnpackFailed,
+			err:    errors.New("boom"),
+			expected: metav1.Condition{
+				Type:    ocv1.TypeProgressing,
+				Status:  metav1.ConditionTrue,
+				Reason:  ocv1.ReasonUnpackFailed,
+				Message: "boom",
+			},
+		},
+		{
+			name:   "terminal error, Progressing condition has status False with the reason of the stage",
+			reason: ocv1.ReasonResolutionFailed,
+			err:    reconcile.TerminalError(errors.New("boom")),
+			expected: metav1.Condition{
+				Type:    ocv1.TypeProgressing,
+				Status:  metav1.ConditionFalse,
+				Reason:  ocv1.ReasonResolutionFailed,
+				Message: "terminal error: boom",
+			},
+		},
+		{
+			name:   "install conflict, Progressing condition has reason InstallConflict",
+			reason: ocv1.ReasonApplyFailed,
+			err:    fmt.Errorf("install failed: %w", &olmv1error.InstallConflictError{}),
+			expected: metav1.Condition{
+				Type:    ocv1.TypeProgressing,
+				Status:  metav1.ConditionTrue,
+				Reason:  ocv1.ReasonInstallConflict,
+				Message: "install failed: ",
+			},
+		},
+		{
+			name:   "forbidden error, Progressing condition has reason PermissionDenied",
+			reason: ocv1.ReasonWatchFailed,
+			err:    apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "foo", errors.New("boom")),
+			expected: metav1.Condition{
+				Type:    ocv1.TypeProgressing,
+				Status:  metav1.ConditionTrue,
+				Reason:  ocv1.ReasonPermissionDenied,
+				Message: `secrets "foo" is forbidden: boom`,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ext := &ocv1.ClusterExtension{}
+			setStatusProgressingFailed(ext, tc.reason, tc.err)
+			progressingCond := meta.FindStatusCondition(ext.Status.Conditions, ocv1.TypeProgressing)
+			require.NotNil(t, progressingCond, "progressing condition should be set but was not")
+			diff := cmp.Diff(*progressingCond, tc.expected, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime", "ObservedGeneration"))
+			require.Empty(t, diff, "difference between actual and expected Progressing conditions")
+		})
+	}
+}
+
+func TestSetStatusTerminating(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		deleted  bool
+		err      error
+		expected metav1.Condition
+	}{
+		{
+			name: "not deleted, Terminating condition has status False with reason Active",
+			expected: metav1.Condition{
+				Type:    ocv1.TypeTerminating,
+				Status:  metav1.ConditionFalse,
+				Reason:  ocv1.ReasonActive,
+				Message: "deletion has not been requested",
+			},
+		},
+		{
+			name:    "deleted and uninstalled, Terminating condition has status True with reason Uninstalling",
+			deleted: true,
+			expected: metav1.Condition{
+				Type:    ocv1.TypeTerminating,
+				Status:  metav1.ConditionTrue,
+				Reason:  ocv1.ReasonUninstalling,
+				Message: "uninstalled with policy KeepCRDs",
+			},
+		},
+		{
+			name:    "deleted and waiting for objects, Terminating condition has status True with reason Uninstalling",
+			deleted: true,
+			err:     fmt.Errorf("%w: 1 custom resources of foos.example.com", uninstall.ErrWaiting),
+			expected: metav1.Condition{
+				Type:    ocv1.TypeTerminating,
+				Status:  metav1.ConditionTrue,
+				Reason:  ocv1.ReasonUninstalling,
+				Message: "waiting for objects to be deleted: 1 custom resources of foos.example.com",
+			},
+		},
+		{
+			name:    "deleted and failed, Terminating condition has status True with reason Failed",
+			deleted: true,
+			err:     errors.New("boom"),
+			expected: metav1.Condition{
+				Type:    ocv1.TypeTerminating,
+				Status:  metav1.ConditionTrue,
+				Reason:  ocv1.ReasonFailed,
+				Message: "boom",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ext := &ocv1.ClusterExtension{}
+			if tc.deleted {
+				ext.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+			}
+			setStatusTerminating(ext, tc.err)
+			terminatingCond := meta.FindStatusCondition(ext.Status.Conditions, ocv1.TypeTerminating)
+			require.NotNil(t, terminatingCond, "terminating condition should be set but was not")
+			diff := cmp.Diff(*terminatingCond, tc.expected, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime", "ObservedGeneration"))
+			require.Empty(t, diff, "difference between actual and expected Terminating conditions")
+		})
+	}
+}
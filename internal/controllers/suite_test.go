@@ -26,9 +26,11 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	crfinalizer "sigs.k8s.io/controller-runtime/pkg/finalizer"
@@ -46,19 +48,25 @@ import (
 type MockUnpacker struct {
 	err    error
 	result *source.Result
+
+	unpacked    []string
+	pullSecrets []string
+	cleaned     []string
 }
 
 // Unpack mocks the Unpack method
-func (m *MockUnpacker) Unpack(_ context.Context, _ *source.BundleSource) (*source.Result, error) {
+func (m *MockUnpacker) Unpack(_ context.Context, bundle *source.BundleSource) (*source.Result, error) {
+	m.unpacked = append(m.unpacked, bundle.Name)
+	m.pullSecrets = append(m.pullSecrets, string(bundle.Image.PullSecret))
 	if m.err != nil {
 		return nil, m.err
 	}
 	return m.result, nil
 }
 
-func (m *MockUnpacker) Cleanup(_ context.Context, _ *source.BundleSource) error {
-	// TODO implement me
-	panic("implement me")
+func (m *MockUnpacker) Cleanup(_ context.Context, bundle *source.BundleSource) error {
+	m.cleaned = append(m.cleaned, bundle.Name)
+	return nil
 }
 
 func newClient(t *testing.T) client.Client {
@@ -90,21 +98,58 @@ type MockApplier struct {
 	err   error
 	objs  []client.Object
 	state string
+
+	rollbackRevision int
+	rollbackLabels   map[string]string
 }
 
 func (m *MockApplier) Apply(_ context.Context, _ fs.FS, _ *ocv1.ClusterExtension, _ map[string]string, _ map[string]string) ([]client.Object, string, error) {
+	return m.objs, m.state, m.err
+}
+
+func (m *MockApplier) RollbackTo(_ context.Context, _ *ocv1.ClusterExtension, revision int) ([]client.Object, map[string]string, error) {
+	m.rollbackRevision = revision
 	if m.err != nil {
-		return nil, m.state, m.err
+		return nil, nil, m.err
 	}
 
-	return m.objs, m.state, nil
+	return m.objs, m.rollbackLabels, nil
+}
+
+var _ controllers.PermissionPreviewer = (*MockPermissionPreviewer)(nil)
+
+type MockPermissionPreviewer struct {
+	added         []ocv1.PermissionRule
+	removed       []ocv1.PermissionRule
+	addedBindings []ocv1.PermissionBinding
+	escalates     bool
+	err           error
+}
+
+func (m *MockPermissionPreviewer) PermissionChanges(_ context.Context, _ fs.FS, _ *ocv1.ClusterExtension) (*ocv1.PermissionChanges, bool, error) {
+	if m.err != nil {
+		return nil, false, m.err
+	}
+	return &ocv1.PermissionChanges{Added: m.added, Removed: m.removed, AddedBindings: m.addedBindings}, m.escalates, nil
+}
+
+var _ controllers.Planner = (*MockPlanner)(nil)
+
+type MockPlanner struct {
+	plan *ocv1.ReleasePlan
+	err  error
+}
+
+func (m *MockPlanner) Plan(_ context.Context, _ fs.FS, _ *ocv1.ClusterExtension, _ map[string]string) (*ocv1.ReleasePlan, error) {
+	return m.plan, m.err
 }
 
 var _ contentmanager.Manager = (*MockManagedContentCacheManager)(nil)
 
 type MockManagedContentCacheManager struct {
-	err   error
-	cache cmcache.Cache
+	err             error
+	cache           cmcache.Cache
+	referencedCache cmcache.Cache
 }
 
 func (m *MockManagedContentCacheManager) Get(_ context.Context, _ *ocv1.ClusterExtension) (cmcache.Cache, error) {
@@ -114,12 +159,23 @@ func (m *MockManagedContentCacheManager) Get(_ context.Context, _ *ocv1.ClusterE
 	return m.cache, nil
 }
 
+func (m *MockManagedContentCacheManager) GetReferenced(_ context.Context, _ *ocv1.ClusterExtension) (cmcache.Cache, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if m.referencedCache != nil {
+		return m.referencedCache, nil
+	}
+	return m.cache, nil
+}
+
 func (m *MockManagedContentCacheManager) Delete(_ *ocv1.ClusterExtension) error {
 	return m.err
 }
 
 type MockManagedContentCache struct {
-	err error
+	err      error
+	observed []client.Object
 }
 
 var _ cmcache.Cache = (*MockManagedContentCache)(nil)
@@ -138,6 +194,23 @@ func (m *MockManagedContentCache) Watch(_ context.Context, _ cmcache.Watcher, _
 	return nil
 }
 
+func (m *MockManagedContentCache) Get(obj client.Object) (*unstructured.Unstructured, bool, error) {
+	if m.err != nil {
+		return nil, false, m.err
+	}
+	for _, observed := range m.observed {
+		if observed.GetObjectKind().GroupVersionKind() == obj.GetObjectKind().GroupVersionKind() &&
+			client.ObjectKeyFromObject(observed) == client.ObjectKeyFromObject(obj) {
+			u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(observed)
+			if err != nil {
+				return nil, false, err
+			}
+			return &unstructured.Unstructured{Object: u}, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
 func newClientAndReconciler(t *testing.T) (client.Client, *controllers.ClusterExtensionReconciler) {
 	cl := newClient(t)
 
@@ -145,6 +218,7 @@ func newClientAndReconciler(t *testing.T) (client.Client, *controllers.ClusterEx
 		Client:                cl,
 		InstalledBundleGetter: &MockInstalledBundleGetter{},
 		Finalizers:            crfinalizer.NewFinalizers(),
+		Recorder:              &record.FakeRecorder{},
 	}
 	return cl, reconciler
 }
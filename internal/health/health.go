@@ -0,0 +1,82 @@
+// Package health determines whether the objects installed for a
+// ClusterExtension are ready to serve.
+package health
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	deploymentGroupKind = appsv1.SchemeGroupVersion.WithKind("Deployment").GroupKind()
+	crdGroupKind        = apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinition").GroupKind()
+)
+
+// HasHealth returns true when the health of objects of the given kind is checked.
+func HasHealth(gk schema.GroupKind) bool {
+	return gk == deploymentGroupKind || gk == crdGroupKind
+}
+
+// Check returns an error describing why obj is not healthy, or nil if it is.
+// Objects whose kind has no notion of health are always healthy.
+func Check(obj *unstructured.Unstructured) error {
+	switch obj.GroupVersionKind().GroupKind() {
+	case deploymentGroupKind:
+		dep := appsv1.Deployment{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &dep); err != nil {
+			return err
+		}
+		return checkDeployment(&dep)
+	case crdGroupKind:
+		crd := apiextensionsv1.CustomResourceDefinition{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &crd); err != nil {
+			return err
+		}
+		return checkCRD(&crd)
+	}
+	return nil
+}
+
+// checkDeployment requires the latest generation of dep to be fully rolled out and available.
+func checkDeployment(dep *appsv1.Deployment) error {
+	if dep.Status.ObservedGeneration < dep.Generation {
+		return fmt.Errorf("generation %d has not been observed yet", dep.Generation)
+	}
+
+	for _, cond := range dep.Status.Conditions {
+		if cond.Type == appsv1.DeploymentAvailable && cond.Status != corev1.ConditionTrue {
+			return fmt.Errorf("not available: %s", cond.Message)
+		}
+	}
+
+	replicas := int32(1)
+	if dep.Spec.Replicas != nil {
+		replicas = *dep.Spec.Replicas
+	}
+	if dep.Status.UpdatedReplicas < replicas {
+		return fmt.Errorf("%d of %d replicas updated", dep.Status.UpdatedReplicas, replicas)
+	}
+	if dep.Status.AvailableReplicas < replicas {
+		return fmt.Errorf("%d of %d replicas available", dep.Status.AvailableReplicas, replicas)
+	}
+	return nil
+}
+
+// checkCRD requires crd to be established.
+func checkCRD(crd *apiextensionsv1.CustomResourceDefinition) error {
+	for _, cond := range crd.Status.Conditions {
+		if cond.Type == apiextensionsv1.Established {
+			if cond.Status != apiextensionsv1.ConditionTrue {
+				return fmt.Errorf("not established: %s", cond.Message)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("not established")
+}
@@ -0,0 +1,129 @@
+package health_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+
+	"github.com/operator-framework/operator-controller/internal/health"
+)
+
+func toUnstructured(t *testing.T, obj runtime.Object) *unstructured.Unstructured {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	require.NoError(t, err)
+	return &unstructured.Unstructured{Object: u}
+}
+
+func testDeployment(mutate func(*appsv1.Deployment)) *appsv1.Deployment {
+	dep := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test-ns", Generation: 2},
+		Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(int32(2))},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 2,
+			UpdatedReplicas:    2,
+			AvailableReplicas:  2,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+	if mutate != nil {
+		mutate(dep)
+	}
+	return dep
+}
+
+func testCRD(conditions ...apiextensionsv1.CustomResourceDefinitionCondition) *apiextensionsv1.CustomResourceDefinition {
+	return &apiextensionsv1.CustomResourceDefinition{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition"},
+		ObjectMeta: metav1.ObjectMeta{Name: "tests.example.com"},
+		Status:     apiextensionsv1.CustomResourceDefinitionStatus{Conditions: conditions},
+	}
+}
+
+func TestCheck(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		obj       runtime.Object
+		expectErr string
+	}{
+		{
+			name: "available deployment",
+			obj:  testDeployment(nil),
+		},
+		{
+			name: "deployment without replicas defaults to one",
+			obj: testDeployment(func(dep *appsv1.Deployment) {
+				dep.Spec.Replicas = nil
+			}),
+		},
+		{
+			name: "deployment generation not observed",
+			obj: testDeployment(func(dep *appsv1.Deployment) {
+				dep.Status.ObservedGeneration = 1
+			}),
+			expectErr: "generation 2 has not been observed yet",
+		},
+		{
+			name: "unavailable deployment",
+			obj: testDeployment(func(dep *appsv1.Deployment) {
+				dep.Status.Conditions[0].Status = corev1.ConditionFalse
+				dep.Status.Conditions[0].Message = "Deployment does not have minimum availability."
+			}),
+			expectErr: "not available: Deployment does not have minimum availability.",
+		},
+		{
+			name: "deployment rolling out",
+			obj: testDeployment(func(dep *appsv1.Deployment) {
+				dep.Status.UpdatedReplicas = 1
+			}),
+			expectErr: "1 of 2 replicas updated",
+		},
+		{
+			name: "deployment with unavailable replicas",
+			obj: testDeployment(func(dep *appsv1.Deployment) {
+				dep.Status.AvailableReplicas = 1
+			}),
+			expectErr: "1 of 2 replicas available",
+		},
+		{
+			name: "established CRD",
+			obj:  testCRD(apiextensionsv1.CustomResourceDefinitionCondition{Type: apiextensionsv1.Established, Status: apiextensionsv1.ConditionTrue}),
+		},
+		{
+			name:      "CRD not established",
+			obj:       testCRD(apiextensionsv1.CustomResourceDefinitionCondition{Type: apiextensionsv1.Established, Status: apiextensionsv1.ConditionFalse, Message: "not all names are accepted"}),
+			expectErr: "not established: not all names are accepted",
+		},
+		{
+			name:      "CRD without status",
+			obj:       testCRD(),
+			expectErr: "not established",
+		},
+		{
+			name: "other kinds are healthy",
+			obj: &corev1.ConfigMap{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test-ns"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := health.Check(toUnstructured(t, tc.obj))
+			if tc.expectErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.expectErr)
+		})
+	}
+}
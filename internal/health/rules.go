@@ -0,0 +1,101 @@
+package health
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+)
+
+// ruleCostLimit bounds the cost of evaluating a single rule, so that an
+// expensive expression cannot stall the reconciliation of its extension.
+const ruleCostLimit = 1000000
+
+// Rule is a compiled health rule.
+type Rule struct {
+	name    string
+	message string
+	object  *unstructured.Unstructured
+	program cel.Program
+}
+
+// NewRule compiles the expression of rule. The expression must evaluate to a boolean.
+func NewRule(rule ocv1.HealthRule) (*Rule, error) {
+	env, err := cel.NewEnv(cel.Variable("self", cel.DynType))
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(rule.Expression)
+	if issues.Err() != nil {
+		return nil, fmt.Errorf("invalid expression: %w", issues.Err())
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, fmt.Errorf("expression must evaluate to a boolean, not %s", ast.OutputType())
+	}
+	program, err := env.Program(ast, cel.CostLimit(ruleCostLimit))
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression: %w", err)
+	}
+
+	object := &unstructured.Unstructured{}
+	object.SetAPIVersion(rule.Object.APIVersion)
+	object.SetKind(rule.Object.Kind)
+	object.SetName(rule.Object.Name)
+	object.SetNamespace(rule.Object.Namespace)
+
+	return &Rule{
+		name:    rule.Name,
+		message: rule.Message,
+		object:  object,
+		program: program,
+	}, nil
+}
+
+// Name returns the name of the rule.
+func (r *Rule) Name() string {
+	return r.name
+}
+
+// Object returns a reference to the object the rule is evaluated against.
+func (r *Rule) Object() *unstructured.Unstructured {
+	return r.object
+}
+
+// Evaluate returns an error describing why obj does not satisfy the rule,
+// or nil if it does.
+func (r *Rule) Evaluate(obj *unstructured.Unstructured) error {
+	val, _, err := r.program.Eval(map[string]interface{}{"self": obj.Object})
+	if err != nil {
+		return err
+	}
+	satisfied, ok := val.Value().(bool)
+	if !ok {
+		return fmt.Errorf("expression evaluated to %v instead of a boolean", val.Value())
+	}
+	if satisfied {
+		return nil
+	}
+	if r.message != "" {
+		return errors.New(r.message)
+	}
+	return errors.New("expression evaluated to false")
+}
+
+// Rules is a set of health rules.
+type Rules []*Rule
+
+// NewRules compiles the given health rules.
+func NewRules(rules []ocv1.HealthRule) (Rules, error) {
+	compiled := make(Rules, 0, len(rules))
+	for _, rule := range rules {
+		r, err := NewRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("health rule %q: %w", rule.Name, err)
+		}
+		compiled = append(compiled, r)
+	}
+	return compiled, nil
+}
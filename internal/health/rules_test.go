@@ -0,0 +1,130 @@
+package health_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+	"github.com/operator-framework/operator-controller/internal/health"
+)
+
+func testFoo(phase string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Foo",
+		"metadata":   map[string]interface{}{"name": "default", "namespace": "test-ns"},
+	}}
+	if phase != "" {
+		obj.Object["status"] = map[string]interface{}{"phase": phase}
+	}
+	return obj
+}
+
+func testRule(expression, message string) ocv1.HealthRule {
+	return ocv1.HealthRule{
+		Name: "foo-ready",
+		Object: ocv1.HealthRuleObjectReference{
+			APIVersion: "example.com/v1",
+			Kind:       "Foo",
+			Name:       "default",
+			Namespace:  "test-ns",
+		},
+		Expression: expression,
+		Message:    message,
+	}
+}
+
+func TestRuleEvaluate(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		expression string
+		message    string
+		obj        *unstructured.Unstructured
+		expectErr  string
+	}{
+		{
+			name:       "satisfied",
+			expression: "self.status.phase == 'Ready'",
+			obj:        testFoo("Ready"),
+		},
+		{
+			name:       "not satisfied",
+			expression: "self.status.phase == 'Ready'",
+			obj:        testFoo("Pending"),
+			expectErr:  "expression evaluated to false",
+		},
+		{
+			name:       "not satisfied with message",
+			expression: "self.status.phase == 'Ready'",
+			message:    "Foo is not ready",
+			obj:        testFoo("Pending"),
+			expectErr:  "Foo is not ready",
+		},
+		{
+			name:       "missing field",
+			expression: "self.status.phase == 'Ready'",
+			obj:        testFoo(""),
+			expectErr:  "no such key: status",
+		},
+		{
+			name:       "missing field guarded by has",
+			expression: "has(self.status) && self.status.phase == 'Ready'",
+			obj:        testFoo(""),
+			expectErr:  "expression evaluated to false",
+		},
+		{
+			name:       "dynamic result that is not a boolean",
+			expression: "self.status.phase",
+			obj:        testFoo("Ready"),
+			expectErr:  "expression evaluated to Ready instead of a boolean",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, err := health.NewRule(testRule(tc.expression, tc.message))
+			require.NoError(t, err)
+			err = rule.Evaluate(tc.obj)
+			if tc.expectErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.expectErr)
+		})
+	}
+}
+
+func TestRuleObject(t *testing.T) {
+	rule, err := health.NewRule(testRule("true", ""))
+	require.NoError(t, err)
+	assert.Equal(t, "foo-ready", rule.Name())
+	assert.Equal(t, "example.com/v1, Kind=Foo", rule.Object().GroupVersionKind().String())
+	assert.Equal(t, "test-ns", rule.Object().GetNamespace())
+	assert.Equal(t, "default", rule.Object().GetName())
+}
+
+func TestNewRulesErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		expression string
+		expectErr  string
+	}{
+		{
+			name:       "syntax error",
+			expression: "self.status.phase ==",
+			expectErr:  `health rule "foo-ready": invalid expression: `,
+		},
+		{
+			name:       "not a boolean",
+			expression: "'Ready'",
+			expectErr:  `health rule "foo-ready": expression must evaluate to a boolean, not string`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := health.NewRules([]ocv1.HealthRule{testRule(tc.expression, "")})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectErr)
+		})
+	}
+}
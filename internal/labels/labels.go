@@ -7,4 +7,6 @@ const (
 	BundleNameKey      = "olm.operatorframework.io/bundle-name"
 	BundleVersionKey   = "olm.operatorframework.io/bundle-version"
 	BundleReferenceKey = "olm.operatorframework.io/bundle-reference"
+	CatalogNameKey     = "olm.operatorframework.io/catalog-name"
+	ChannelsKey        = "olm.operatorframework.io/channels"
 )
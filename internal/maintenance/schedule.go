@@ -0,0 +1,45 @@
+package maintenance
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Schedule is a parsed cron expression made of the minute, hour, day of month,
+// month and day of week fields.
+type Schedule struct {
+	spec *cron.SpecSchedule
+}
+
+var scheduleParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ParseSchedule parses a standard five-field cron expression or one of the
+// @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly descriptors.
+func ParseSchedule(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	// The time zone of a maintenance window is set by its own field.
+	if strings.HasPrefix(spec, "TZ=") || strings.HasPrefix(spec, "CRON_TZ=") {
+		return nil, errors.New("time zones are not supported in schedules")
+	}
+	parsed, err := scheduleParser.Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	specSchedule, ok := parsed.(*cron.SpecSchedule)
+	if !ok {
+		// @every schedules are relative to the time they are started at,
+		// so they cannot define when a window opens.
+		return nil, errors.New("interval schedules are not supported")
+	}
+	return &Schedule{spec: specSchedule}, nil
+}
+
+// Next returns the first time after t, at a whole minute and in the location
+// of t, that matches the schedule. It returns the zero time if the schedule
+// does not match within the next five years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	return s.spec.Next(t)
+}
@@ -0,0 +1,96 @@
+package maintenance_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-controller/internal/maintenance"
+)
+
+func mustParseTime(t *testing.T, loc *time.Location, value string) time.Time {
+	t.Helper()
+	ts, err := time.ParseInLocation("2006-01-02 15:04", value, loc)
+	require.NoError(t, err)
+	return ts
+}
+
+func TestScheduleNext(t *testing.T) {
+	for _, tc := range []struct {
+		schedule string
+		from     string
+		expected string
+	}{
+		{schedule: "* * * * *", from: "2024-01-01 00:00", expected: "2024-01-01 00:01"},
+		{schedule: "0 2 * * *", from: "2024-01-01 00:00", expected: "2024-01-01 02:00"},
+		{schedule: "0 2 * * *", from: "2024-01-01 02:00", expected: "2024-01-02 02:00"},
+		{schedule: "*/15 * * * *", from: "2024-01-01 00:16", expected: "2024-01-01 00:30"},
+		{schedule: "5-10/5 * * * *", from: "2024-01-01 00:06", expected: "2024-01-01 00:10"},
+		{schedule: "30 22 * * SAT", from: "2024-01-01 00:00", expected: "2024-01-06 22:30"},
+		{schedule: "0 0 * * 0", from: "2024-01-01 00:00", expected: "2024-01-07 00:00"},
+		{schedule: "0 0 1,15 * *", from: "2024-01-02 00:00", expected: "2024-01-15 00:00"},
+		{schedule: "0 0 1 * MON", from: "2024-01-02 00:00", expected: "2024-01-08 00:00"},
+		{schedule: "0 0 29 feb *", from: "2024-03-01 00:00", expected: "2028-02-29 00:00"},
+		{schedule: "0 0 31 * *", from: "2024-04-01 00:00", expected: "2024-05-31 00:00"},
+		{schedule: "@weekly", from: "2024-01-01 00:00", expected: "2024-01-07 00:00"},
+		{schedule: "@monthly", from: "2024-01-01 00:00", expected: "2024-02-01 00:00"},
+		{schedule: "0 0 30 2 *", from: "2024-01-01 00:00", expected: ""},
+	} {
+		t.Run(tc.schedule+" from "+tc.from, func(t *testing.T) {
+			s, err := maintenance.ParseSchedule(tc.schedule)
+			require.NoError(t, err)
+			got := s.Next(mustParseTime(t, time.UTC, tc.from))
+			if tc.expected == "" {
+				assert.True(t, got.IsZero(), "expected no activation, got %s", got)
+				return
+			}
+			assert.Equal(t, mustParseTime(t, time.UTC, tc.expected), got)
+		})
+	}
+}
+
+func TestScheduleNextAcrossDaylightSavingTime(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	s, err := maintenance.ParseSchedule("30 2 * * *")
+	require.NoError(t, err)
+
+	// 02:30 does not exist on 2024-03-31 in Berlin, so the next activation is the day after.
+	got := s.Next(mustParseTime(t, berlin, "2024-03-31 00:00"))
+	assert.Equal(t, mustParseTime(t, berlin, "2024-04-01 02:30"), got)
+
+	// 02:30 happens twice on 2024-10-27 in Berlin.
+	first := s.Next(mustParseTime(t, berlin, "2024-10-27 00:00"))
+	assert.Equal(t, 2, first.Hour())
+	assert.Equal(t, 30, first.Minute())
+	assert.Equal(t, 27, first.Day())
+	second := s.Next(first)
+	assert.True(t, second.After(first))
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, tc := range []struct {
+		schedule string
+		errMsg   string
+	}{
+		{schedule: "", errMsg: "empty spec string"},
+		{schedule: "0 0 * *", errMsg: "expected exactly 5 fields, found 4: [0 0 * *]"},
+		{schedule: "60 * * * *", errMsg: "end of range (60) above maximum (59): 60"},
+		{schedule: "* 24 * * *", errMsg: "end of range (24) above maximum (23): 24"},
+		{schedule: "* * 0 * *", errMsg: "beginning of range (0) below minimum (1): 0"},
+		{schedule: "* * * * 7", errMsg: "end of range (7) above maximum (6): 7"},
+		{schedule: "*/0 * * * *", errMsg: "step of range should be a positive number: */0"},
+		{schedule: "10-5 * * * *", errMsg: "beginning of range (10) beyond end of range (5): 10-5"},
+		{schedule: "@sometimes", errMsg: "unrecognized descriptor: @sometimes"},
+		{schedule: "@every 1h", errMsg: "interval schedules are not supported"},
+		{schedule: "CRON_TZ=Europe/Berlin 0 2 * * *", errMsg: "time zones are not supported in schedules"},
+	} {
+		t.Run(tc.schedule, func(t *testing.T) {
+			_, err := maintenance.ParseSchedule(tc.schedule)
+			require.EqualError(t, err, tc.errMsg)
+		})
+	}
+}
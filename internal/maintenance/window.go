@@ -0,0 +1,94 @@
+package maintenance
+
+import (
+	"fmt"
+	"time"
+	// Embed the time zone database so that time zones can be loaded
+	// regardless of the files available in the container image.
+	_ "time/tzdata"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+)
+
+// Window is a recurring time window that opens according to a schedule and
+// stays open for a fixed duration.
+type Window struct {
+	schedule *Schedule
+	duration time.Duration
+	location *time.Location
+}
+
+// NewWindow parses the given maintenance window.
+func NewWindow(mw ocv1.MaintenanceWindow) (*Window, error) {
+	schedule, err := ParseSchedule(mw.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", mw.Schedule, err)
+	}
+	if mw.Duration.Duration < time.Minute {
+		return nil, fmt.Errorf("invalid duration %q: must be at least 1m", mw.Duration.Duration)
+	}
+	if mw.TimeZone == "Local" {
+		return nil, fmt.Errorf("invalid time zone %q", mw.TimeZone)
+	}
+	location, err := time.LoadLocation(mw.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %w", mw.TimeZone, err)
+	}
+	return &Window{
+		schedule: schedule,
+		duration: mw.Duration.Duration,
+		location: location,
+	}, nil
+}
+
+// IsOpen returns whether the window is open at now. When it is not, it also
+// returns the time at which the window opens next, which is the zero time if
+// the window never opens.
+func (w *Window) IsOpen(now time.Time) (bool, time.Time) {
+	// The window is open if it opened within the last duration. The first
+	// opening after now-duration is either such an opening or the next one.
+	opening := w.schedule.Next(now.In(w.location).Add(-w.duration))
+	if opening.IsZero() {
+		return false, time.Time{}
+	}
+	if !opening.After(now) {
+		return true, time.Time{}
+	}
+	return false, opening
+}
+
+// Windows is a set of maintenance windows. It is open when any of its windows is open.
+type Windows []*Window
+
+// NewWindows parses the given maintenance windows.
+func NewWindows(mws []ocv1.MaintenanceWindow) (Windows, error) {
+	windows := make(Windows, 0, len(mws))
+	for i, mw := range mws {
+		w, err := NewWindow(mw)
+		if err != nil {
+			return nil, fmt.Errorf("maintenance window %d: %w", i, err)
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+// IsOpen returns whether any of the windows is open at now. When none is,
+// it also returns the earliest time at which one of them opens next, which is
+// the zero time if none of them ever opens. An empty set of windows is always open.
+func (ws Windows) IsOpen(now time.Time) (bool, time.Time) {
+	if len(ws) == 0 {
+		return true, time.Time{}
+	}
+	var nextOpening time.Time
+	for _, w := range ws {
+		open, opening := w.IsOpen(now)
+		if open {
+			return true, time.Time{}
+		}
+		if !opening.IsZero() && (nextOpening.IsZero() || opening.Before(nextOpening)) {
+			nextOpening = opening
+		}
+	}
+	return false, nextOpening
+}
@@ -0,0 +1,98 @@
+package maintenance_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+	"github.com/operator-framework/operator-controller/internal/maintenance"
+)
+
+func TestWindowsIsOpen(t *testing.T) {
+	windows, err := maintenance.NewWindows([]ocv1.MaintenanceWindow{
+		// Every day from 02:00 to 04:00 in New York.
+		{Schedule: "0 2 * * *", Duration: metav1.Duration{Duration: 2 * time.Hour}, TimeZone: "America/New_York"},
+		// Every Saturday from 12:00 to 12:30 UTC.
+		{Schedule: "0 12 * * SAT", Duration: metav1.Duration{Duration: 30 * time.Minute}},
+	})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		now          string
+		expectOpen   bool
+		expectOpenAt string
+	}{
+		{now: "2024-01-01 06:59", expectOpen: false, expectOpenAt: "2024-01-01 07:00"},
+		{now: "2024-01-01 07:00", expectOpen: true},
+		{now: "2024-01-01 08:59", expectOpen: true},
+		{now: "2024-01-01 09:00", expectOpen: false, expectOpenAt: "2024-01-02 07:00"},
+		{now: "2024-01-06 11:00", expectOpen: false, expectOpenAt: "2024-01-06 12:00"},
+		{now: "2024-01-06 12:29", expectOpen: true},
+		{now: "2024-01-06 12:30", expectOpen: false, expectOpenAt: "2024-01-07 07:00"},
+	} {
+		t.Run(tc.now, func(t *testing.T) {
+			open, openAt := windows.IsOpen(mustParseTime(t, time.UTC, tc.now))
+			assert.Equal(t, tc.expectOpen, open)
+			if tc.expectOpenAt == "" {
+				assert.True(t, openAt.IsZero())
+				return
+			}
+			assert.True(t, mustParseTime(t, time.UTC, tc.expectOpenAt).Equal(openAt), "expected %s, got %s", tc.expectOpenAt, openAt.UTC())
+		})
+	}
+}
+
+func TestWindowsIsOpenEmpty(t *testing.T) {
+	open, openAt := maintenance.Windows{}.IsOpen(time.Now())
+	assert.True(t, open)
+	assert.True(t, openAt.IsZero())
+}
+
+func TestWindowsIsOpenNeverOpens(t *testing.T) {
+	windows, err := maintenance.NewWindows([]ocv1.MaintenanceWindow{
+		{Schedule: "0 0 30 2 *", Duration: metav1.Duration{Duration: time.Hour}},
+	})
+	require.NoError(t, err)
+
+	open, openAt := windows.IsOpen(time.Now())
+	assert.False(t, open)
+	assert.True(t, openAt.IsZero())
+}
+
+func TestNewWindowsErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		window ocv1.MaintenanceWindow
+		errMsg string
+	}{
+		{
+			name:   "invalid schedule",
+			window: ocv1.MaintenanceWindow{Schedule: "0 0 * *", Duration: metav1.Duration{Duration: time.Hour}},
+			errMsg: `maintenance window 0: invalid schedule "0 0 * *": expected exactly 5 fields, found 4: [0 0 * *]`,
+		},
+		{
+			name:   "too short duration",
+			window: ocv1.MaintenanceWindow{Schedule: "@daily", Duration: metav1.Duration{Duration: time.Second}},
+			errMsg: `maintenance window 0: invalid duration "1s": must be at least 1m`,
+		},
+		{
+			name:   "unknown time zone",
+			window: ocv1.MaintenanceWindow{Schedule: "@daily", Duration: metav1.Duration{Duration: time.Hour}, TimeZone: "Mars/Olympus_Mons"},
+			errMsg: `maintenance window 0: invalid time zone "Mars/Olympus_Mons": unknown time zone Mars/Olympus_Mons`,
+		},
+		{
+			name:   "local time zone",
+			window: ocv1.MaintenanceWindow{Schedule: "@daily", Duration: metav1.Duration{Duration: time.Hour}, TimeZone: "Local"},
+			errMsg: `maintenance window 0: invalid time zone "Local"`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := maintenance.NewWindows([]ocv1.MaintenanceWindow{tc.window})
+			require.EqualError(t, err, tc.errMsg)
+		})
+	}
+}
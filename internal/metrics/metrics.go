@@ -0,0 +1,178 @@
+// Package metrics defines the Prometheus metrics exposed by operator-controller.
+// All metrics are registered on the controller-runtime metrics registry, so they
+// are served by the metrics endpoint of the manager.
+package metrics
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const namespace = "operator_controller"
+
+// Phases of the reconciliation of a ClusterExtension.
+const (
+	PhaseFinalize     = "finalize"
+	PhaseGetInstalled = "get-installed"
+	PhaseResolve      = "resolve"
+	PhaseUnpack       = "unpack"
+	PhaseApply        = "apply"
+	PhaseWatch        = "watch"
+)
+
+// Outcomes of a reconcile phase or a request.
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+var (
+	reconcilePhaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "reconcile_phase_duration_seconds",
+		Help:      "Duration of each phase of the reconciliation of a ClusterExtension, by phase and outcome.",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"phase", "outcome"})
+
+	reconcilePhaseTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reconcile_phase_total",
+		Help:      "Number of times each phase of the reconciliation of a ClusterExtension ran, by phase and outcome.",
+	}, []string{"phase", "outcome"})
+
+	installedExtensions = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "installed_extensions",
+		Help:      "Number of installed ClusterExtensions, by package, version and deprecation state.",
+	}, []string{"package", "version", "deprecated"})
+
+	unpackCacheSize = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "unpack_cache_size_bytes",
+		Help:      "Size of the unpacked contents of the bundle cached for each ClusterExtension.",
+	}, []string{"bundle"})
+
+	catalogCachePopulationErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "catalog_cache_population_errors_total",
+		Help:      "Number of errors populating the cache of the contents of a ClusterCatalog, by catalog.",
+	}, []string{"catalog"})
+
+	tokenRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "token_requests_total",
+		Help:      "Number of ServiceAccount token requests made to the API server, by outcome.",
+	}, []string{"outcome"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		reconcilePhaseDuration,
+		reconcilePhaseTotal,
+		installedExtensions,
+		unpackCacheSize,
+		catalogCachePopulationErrors,
+		tokenRequests,
+	)
+}
+
+// outcome returns the outcome label for err.
+func outcome(err error) string {
+	if err != nil {
+		return OutcomeError
+	}
+	return OutcomeSuccess
+}
+
+// ObserveReconcilePhase records a run of the given reconcile phase that
+// started at start and failed with err, if not nil.
+func ObserveReconcilePhase(phase string, start time.Time, err error) {
+	reconcilePhaseDuration.WithLabelValues(phase, outcome(err)).Observe(time.Since(start).Seconds())
+	reconcilePhaseTotal.WithLabelValues(phase, outcome(err)).Inc()
+}
+
+// installedExtension is the state of an installed ClusterExtension as
+// reported by the installed_extensions gauge.
+type installedExtension struct {
+	pkg        string
+	version    string
+	deprecated bool
+}
+
+func (e installedExtension) labels() []string {
+	return []string{e.pkg, e.version, strconv.FormatBool(e.deprecated)}
+}
+
+var (
+	installedMu    sync.Mutex
+	installedByExt = map[string]installedExtension{}
+)
+
+// SetInstalledExtension records that the ClusterExtension with the given name
+// has the given version of the given package installed.
+func SetInstalledExtension(name, pkg, version string, deprecated bool) {
+	installedMu.Lock()
+	defer installedMu.Unlock()
+
+	installed := installedExtension{pkg: pkg, version: version, deprecated: deprecated}
+	previous, ok := installedByExt[name]
+	installedByExt[name] = installed
+	if ok && previous != installed {
+		updateInstalledExtensions(previous)
+	}
+	updateInstalledExtensions(installed)
+}
+
+// DeleteInstalledExtension records that the ClusterExtension with the given
+// name no longer has anything installed.
+func DeleteInstalledExtension(name string) {
+	installedMu.Lock()
+	defer installedMu.Unlock()
+
+	if previous, ok := installedByExt[name]; ok {
+		delete(installedByExt, name)
+		updateInstalledExtensions(previous)
+	}
+}
+
+// updateInstalledExtensions sets the installed_extensions gauge of the given
+// state to the number of ClusterExtensions in that state, removing it when
+// there are none. installedMu must be held.
+func updateInstalledExtensions(state installedExtension) {
+	count := 0
+	for _, installed := range installedByExt {
+		if installed == state {
+			count++
+		}
+	}
+	if count == 0 {
+		installedExtensions.DeleteLabelValues(state.labels()...)
+		return
+	}
+	installedExtensions.WithLabelValues(state.labels()...).Set(float64(count))
+}
+
+// SetUnpackCacheSize records the size in bytes of the unpacked contents
+// cached for the bundle with the given name.
+func SetUnpackCacheSize(bundle string, size int64) {
+	unpackCacheSize.WithLabelValues(bundle).Set(float64(size))
+}
+
+// DeleteUnpackCacheSize removes the unpack cache size of the bundle with the given name.
+func DeleteUnpackCacheSize(bundle string) {
+	unpackCacheSize.DeleteLabelValues(bundle)
+}
+
+// IncCatalogCachePopulationErrors records an error populating the cache of the given catalog.
+func IncCatalogCachePopulationErrors(catalog string) {
+	catalogCachePopulationErrors.WithLabelValues(catalog).Inc()
+}
+
+// IncTokenRequests records a ServiceAccount token request that failed with err, if not nil.
+func IncTokenRequests(err error) {
+	tokenRequests.WithLabelValues(outcome(err)).Inc()
+}
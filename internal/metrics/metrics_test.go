@@ -0,0 +1,142 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// metricValue returns the value of the metric with the given name and labels
+// gathered from the controller-runtime metrics registry. The value of a
+// histogram is its sample count.
+func metricValue(t *testing.T, name string, labels map[string]string) (float64, bool) {
+	families, err := ctrlmetrics.Registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			if !hasLabels(m, labels) {
+				continue
+			}
+			switch {
+			case m.GetGauge() != nil:
+				return m.GetGauge().GetValue(), true
+			case m.GetCounter() != nil:
+				return m.GetCounter().GetValue(), true
+			case m.GetHistogram() != nil:
+				return float64(m.GetHistogram().GetSampleCount()), true
+			}
+		}
+	}
+	return 0, false
+}
+
+func hasLabels(m *dto.Metric, labels map[string]string) bool {
+	if len(m.GetLabel()) != len(labels) {
+		return false
+	}
+	for _, label := range m.GetLabel() {
+		if labels[label.GetName()] != label.GetValue() {
+			return false
+		}
+	}
+	return true
+}
+
+func TestObserveReconcilePhase(t *testing.T) {
+	ObserveReconcilePhase(PhaseResolve, time.Now(), nil)
+	ObserveReconcilePhase(PhaseResolve, time.Now(), errors.New("fake error"))
+	ObserveReconcilePhase(PhaseResolve, time.Now(), errors.New("fake error"))
+
+	for _, tc := range []struct {
+		outcome string
+		count   float64
+	}{
+		{outcome: OutcomeSuccess, count: 1},
+		{outcome: OutcomeError, count: 2},
+	} {
+		labels := map[string]string{"phase": PhaseResolve, "outcome": tc.outcome}
+		total, ok := metricValue(t, "operator_controller_reconcile_phase_total", labels)
+		require.True(t, ok)
+		assert.InDelta(t, tc.count, total, 0)
+		observed, ok := metricValue(t, "operator_controller_reconcile_phase_duration_seconds", labels)
+		require.True(t, ok)
+		assert.InDelta(t, tc.count, observed, 0)
+	}
+}
+
+func TestInstalledExtensions(t *testing.T) {
+	v1 := map[string]string{"package": "prometheus", "version": "1.0.0", "deprecated": "false"}
+	v2 := map[string]string{"package": "prometheus", "version": "2.0.0", "deprecated": "false"}
+	v2Deprecated := map[string]string{"package": "prometheus", "version": "2.0.0", "deprecated": "true"}
+
+	t.Log("By installing version 1.0.0 for two extensions")
+	SetInstalledExtension("ext-a", "prometheus", "1.0.0", false)
+	SetInstalledExtension("ext-b", "prometheus", "1.0.0", false)
+	SetInstalledExtension("ext-b", "prometheus", "1.0.0", false)
+	count, ok := metricValue(t, "operator_controller_installed_extensions", v1)
+	require.True(t, ok)
+	assert.InDelta(t, 2, count, 0)
+
+	t.Log("By upgrading one extension to version 2.0.0")
+	SetInstalledExtension("ext-b", "prometheus", "2.0.0", false)
+	count, ok = metricValue(t, "operator_controller_installed_extensions", v1)
+	require.True(t, ok)
+	assert.InDelta(t, 1, count, 0)
+	count, ok = metricValue(t, "operator_controller_installed_extensions", v2)
+	require.True(t, ok)
+	assert.InDelta(t, 1, count, 0)
+
+	t.Log("By deprecating version 2.0.0")
+	SetInstalledExtension("ext-b", "prometheus", "2.0.0", true)
+	_, ok = metricValue(t, "operator_controller_installed_extensions", v2)
+	assert.False(t, ok)
+	count, ok = metricValue(t, "operator_controller_installed_extensions", v2Deprecated)
+	require.True(t, ok)
+	assert.InDelta(t, 1, count, 0)
+
+	t.Log("By deleting both extensions")
+	DeleteInstalledExtension("ext-a")
+	DeleteInstalledExtension("ext-b")
+	DeleteInstalledExtension("ext-b")
+	_, ok = metricValue(t, "operator_controller_installed_extensions", v1)
+	assert.False(t, ok)
+	_, ok = metricValue(t, "operator_controller_installed_extensions", v2Deprecated)
+	assert.False(t, ok)
+}
+
+func TestUnpackCacheSize(t *testing.T) {
+	labels := map[string]string{"bundle": "test-ext"}
+
+	SetUnpackCacheSize("test-ext", 1024)
+	size, ok := metricValue(t, "operator_controller_unpack_cache_size_bytes", labels)
+	require.True(t, ok)
+	assert.InDelta(t, 1024, size, 0)
+
+	DeleteUnpackCacheSize("test-ext")
+	_, ok = metricValue(t, "operator_controller_unpack_cache_size_bytes", labels)
+	assert.False(t, ok)
+}
+
+func TestCounters(t *testing.T) {
+	IncCatalogCachePopulationErrors("test-catalog")
+	count, ok := metricValue(t, "operator_controller_catalog_cache_population_errors_total", map[string]string{"catalog": "test-catalog"})
+	require.True(t, ok)
+	assert.InDelta(t, 1, count, 0)
+
+	IncTokenRequests(nil)
+	IncTokenRequests(errors.New("fake error"))
+	count, ok = metricValue(t, "operator_controller_token_requests_total", map[string]string{"outcome": OutcomeSuccess})
+	require.True(t, ok)
+	assert.InDelta(t, 1, count, 0)
+	count, ok = metricValue(t, "operator_controller_token_requests_total", map[string]string{"outcome": OutcomeError})
+	require.True(t, ok)
+	assert.InDelta(t, 1, count, 0)
+}
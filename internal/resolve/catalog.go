@@ -29,44 +29,43 @@ type ValidationFunc func(*declcfg.Bundle) error
 type CatalogResolver struct {
 	WalkCatalogsFunc func(context.Context, string, CatalogWalkFunc, ...client.ListOption) error
 	Validations      []ValidationFunc
+	// FactsFunc returns the facts that the olm.constraint properties of the
+	// candidate bundles are evaluated against. It may be nil, in which case
+	// no facts about the cluster are known.
+	FactsFunc FactsFunc
 }
 
 type foundBundle struct {
 	bundle   *declcfg.Bundle
 	catalog  string
+	channels []string
 	priority int32
 }
 
-// Resolve returns a Bundle from a catalog that needs to get installed on the cluster.
-func (r *CatalogResolver) Resolve(ctx context.Context, ext *ocv1.ClusterExtension, installedBundle *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, error) {
+// Resolve returns a Bundle from a catalog that needs to get installed on the cluster,
+// along with the catalog it was found in.
+func (r *CatalogResolver) Resolve(ctx context.Context, ext *ocv1.ClusterExtension, installedBundle *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *Origin, error) {
 	packageName := ext.Spec.Source.Catalog.PackageName
 	versionRange := ext.Spec.Source.Catalog.Version
 	channels := ext.Spec.Source.Catalog.Channels
 
-	// unless overridden, default to selecting all bundles
-	var selector = labels.Everything()
-	var err error
-	if ext.Spec.Source.Catalog != nil {
-		selector, err = metav1.LabelSelectorAsSelector(ext.Spec.Source.Catalog.Selector)
-		if err != nil {
-			return nil, nil, nil, fmt.Errorf("desired catalog selector is invalid: %w", err)
-		}
-		// A nothing (empty) selector selects everything
-		if selector == labels.Nothing() {
-			selector = labels.Everything()
-		}
+	selector, err := catalogSelector(ext)
+	if err != nil {
+		return nil, nil, nil, nil, err
 	}
 
 	var versionRangeConstraints *mmsemver.Constraints
 	if versionRange != "" {
 		versionRangeConstraints, err = mmsemver.NewConstraint(versionRange)
 		if err != nil {
-			return nil, nil, nil, fmt.Errorf("desired version range %q is invalid: %w", versionRange, err)
+			return nil, nil, nil, nil, fmt.Errorf("desired version range %q is invalid: %w", versionRange, err)
 		}
 	}
 
 	resolvedBundles := []foundBundle{}
 	var priorDeprecation *declcfg.Deprecation
+	var excludedBundles []string
+	constraints := &lazyConstraintEvaluator{factsFunc: r.FactsFunc, ext: ext}
 
 	listOptions := []client.ListOption{
 		client.MatchingLabelsSelector{Selector: selector},
@@ -77,12 +76,15 @@ func (r *CatalogResolver) Resolve(ctx context.Context, ext *ocv1.ClusterExtensio
 		}
 
 		var predicates []filter.Predicate[declcfg.Bundle]
+		// The channels reported for the resolved bundle are restricted to the requested ones.
+		packageChannels := packageFBC.Channels
 		if len(channels) > 0 {
 			channelSet := sets.New(channels...)
 			filteredChannels := slices.DeleteFunc(packageFBC.Channels, func(c declcfg.Channel) bool {
 				return !channelSet.Has(c.Name)
 			})
 			predicates = append(predicates, filter.InAnyChannel(filteredChannels...))
+			packageChannels = filteredChannels
 		}
 
 		if versionRangeConstraints != nil {
@@ -97,6 +99,14 @@ func (r *CatalogResolver) Resolve(ctx context.Context, ext *ocv1.ClusterExtensio
 			predicates = append(predicates, successorPredicate)
 		}
 
+		if installedBundle != nil {
+			upgradeClassPredicate, err := filter.InAutoUpgradeClass(*installedBundle, ext.Spec.Source.Catalog.AutoUpgrade)
+			if err != nil {
+				return fmt.Errorf("error applying auto upgrade policy: %w", err)
+			}
+			predicates = append(predicates, upgradeClassPredicate)
+		}
+
 		// Apply the predicates to get the candidate bundles
 		packageFBC.Bundles = filter.Filter(packageFBC.Bundles, filter.And(predicates...))
 		if len(packageFBC.Bundles) == 0 {
@@ -122,7 +132,23 @@ func (r *CatalogResolver) Resolve(ctx context.Context, ext *ocv1.ClusterExtensio
 			return compare.ByVersion(a, b)
 		})
 
-		thisBundle := packageFBC.Bundles[0]
+		// Pick the first bundle whose olm.constraint properties are satisfied
+		thisBundleIndex := -1
+		for i, b := range packageFBC.Bundles {
+			unsatisfied, err := constraints.unsatisfiedConstraints(ctx, b)
+			if err != nil {
+				return err
+			}
+			if len(unsatisfied) == 0 {
+				thisBundleIndex = i
+				break
+			}
+			excludedBundles = append(excludedBundles, fmt.Sprintf("bundle %q from catalog %q: %s", b.Name, cat.GetName(), strings.Join(unsatisfied, "; ")))
+		}
+		if thisBundleIndex < 0 {
+			return nil
+		}
+		thisBundle := packageFBC.Bundles[thisBundleIndex]
 
 		if len(resolvedBundles) != 0 {
 			// We've already found one or more package candidates
@@ -138,11 +164,11 @@ func (r *CatalogResolver) Resolve(ctx context.Context, ext *ocv1.ClusterExtensio
 		}
 		// The current bundle shares deprecation status with prior bundles or
 		// there are no prior bundles. Add it to the list.
-		resolvedBundles = append(resolvedBundles, foundBundle{&thisBundle, cat.GetName(), cat.Spec.Priority})
+		resolvedBundles = append(resolvedBundles, foundBundle{&thisBundle, cat.GetName(), channelsContaining(thisBundle.Name, packageChannels), cat.Spec.Priority})
 		priorDeprecation = thisDeprecation
 		return nil
 	}, listOptions...); err != nil {
-		return nil, nil, nil, fmt.Errorf("error walking catalogs: %w", err)
+		return nil, nil, nil, nil, fmt.Errorf("error walking catalogs: %w", err)
 	}
 
 	// Resolve for priority
@@ -158,29 +184,61 @@ func (r *CatalogResolver) Resolve(ctx context.Context, ext *ocv1.ClusterExtensio
 
 	// Check for ambiguity
 	if len(resolvedBundles) != 1 {
-		return nil, nil, nil, resolutionError{
+		return nil, nil, nil, nil, resolutionError{
 			PackageName:     packageName,
 			Version:         versionRange,
 			Channels:        channels,
 			InstalledBundle: installedBundle,
 			ResolvedBundles: resolvedBundles,
+			ExcludedBundles: excludedBundles,
 		}
 	}
 	resolvedBundle := resolvedBundles[0].bundle
+	origin := &Origin{Catalog: resolvedBundles[0].catalog, Channels: resolvedBundles[0].channels}
 	resolvedBundleVersion, err := bundleutil.GetVersion(*resolvedBundle)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("error getting resolved bundle version for bundle %q: %w", resolvedBundle.Name, err)
+		return nil, nil, nil, nil, fmt.Errorf("error getting resolved bundle version for bundle %q: %w", resolvedBundle.Name, err)
 	}
 
 	// Run validations against the resolved bundle to ensure only valid resolved bundles are being returned
 	// Open Question: Should we grab the first valid bundle earlier?
 	for _, validation := range r.Validations {
 		if err := validation(resolvedBundle); err != nil {
-			return nil, nil, nil, fmt.Errorf("validating bundle %q: %w", resolvedBundle.Name, err)
+			return nil, nil, nil, nil, fmt.Errorf("validating bundle %q: %w", resolvedBundle.Name, err)
 		}
 	}
 
-	return resolvedBundle, resolvedBundleVersion, priorDeprecation, nil
+	return resolvedBundle, resolvedBundleVersion, priorDeprecation, origin, nil
+}
+
+// catalogSelector returns the selector of the catalogs that ext is resolved
+// from. Unless overridden, all catalogs are selected.
+func catalogSelector(ext *ocv1.ClusterExtension) (labels.Selector, error) {
+	if ext.Spec.Source.Catalog == nil {
+		return labels.Everything(), nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(ext.Spec.Source.Catalog.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("desired catalog selector is invalid: %w", err)
+	}
+	// A nothing (empty) selector selects everything
+	if selector == labels.Nothing() {
+		selector = labels.Everything()
+	}
+	return selector, nil
+}
+
+// channelsContaining returns the sorted names of the given channels that have
+// an entry for the bundle with the given name.
+func channelsContaining(bundleName string, channels []declcfg.Channel) []string {
+	var names []string
+	for _, ch := range channels {
+		if slices.ContainsFunc(ch.Entries, func(e declcfg.ChannelEntry) bool { return e.Name == bundleName }) {
+			names = append(names, ch.Name)
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
 }
 
 type resolutionError struct {
@@ -189,6 +247,9 @@ type resolutionError struct {
 	Channels        []string
 	InstalledBundle *ocv1.BundleMetadata
 	ResolvedBundles []foundBundle
+	// ExcludedBundles describe the bundles that were excluded because their
+	// olm.constraint properties are not satisfied.
+	ExcludedBundles []string
 }
 
 func (rei resolutionError) Error() string {
@@ -220,6 +281,12 @@ func (rei resolutionError) Error() string {
 		sb.WriteString(fmt.Sprintf("in multiple catalogs with the same priority %v ", matchedCatalogs))
 	}
 
+	if len(rei.ResolvedBundles) == 0 && len(rei.ExcludedBundles) > 0 {
+		excluded := slices.Clone(rei.ExcludedBundles)
+		slices.Sort(excluded) // sort for consistent error message
+		sb.WriteString(fmt.Sprintf("(excluded because of unsatisfied constraints: %s)", strings.Join(excluded, ", ")))
+	}
+
 	return strings.TrimSpace(sb.String())
 }
 
@@ -2,6 +2,7 @@ package resolve
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"testing"
@@ -28,7 +29,7 @@ func TestInvalidClusterExtensionVersionRange(t *testing.T) {
 	r := CatalogResolver{}
 	pkgName := randPkg()
 	ce := buildFooClusterExtension(pkgName, []string{}, "foobar", ocv1.UpgradeConstraintPolicyCatalogProvided)
-	_, _, _, err := r.Resolve(context.Background(), ce, nil)
+	_, _, _, _, err := r.Resolve(context.Background(), ce, nil)
 	assert.EqualError(t, err, `desired version range "foobar" is invalid: improper constraint: foobar`)
 }
 
@@ -38,7 +39,7 @@ func TestErrorWalkingCatalogs(t *testing.T) {
 	}}
 	pkgName := randPkg()
 	ce := buildFooClusterExtension(pkgName, []string{}, "", ocv1.UpgradeConstraintPolicyCatalogProvided)
-	_, _, _, err := r.Resolve(context.Background(), ce, nil)
+	_, _, _, _, err := r.Resolve(context.Background(), ce, nil)
 	assert.EqualError(t, err, "error walking catalogs: fake error")
 }
 
@@ -51,7 +52,7 @@ func TestErrorGettingPackage(t *testing.T) {
 	r := CatalogResolver{WalkCatalogsFunc: w.WalkCatalogs}
 	pkgName := randPkg()
 	ce := buildFooClusterExtension(pkgName, []string{}, "", ocv1.UpgradeConstraintPolicyCatalogProvided)
-	_, _, _, err := r.Resolve(context.Background(), ce, nil)
+	_, _, _, _, err := r.Resolve(context.Background(), ce, nil)
 	assert.EqualError(t, err, fmt.Sprintf(`error walking catalogs: error getting package %q from catalog "a": fake error`, pkgName))
 }
 
@@ -70,7 +71,7 @@ func TestPackageDoesNotExist(t *testing.T) {
 	r := CatalogResolver{WalkCatalogsFunc: w.WalkCatalogs}
 	pkgName := randPkg()
 	ce := buildFooClusterExtension(pkgName, []string{}, "", ocv1.UpgradeConstraintPolicyCatalogProvided)
-	_, _, _, err := r.Resolve(context.Background(), ce, nil)
+	_, _, _, _, err := r.Resolve(context.Background(), ce, nil)
 	assert.EqualError(t, err, fmt.Sprintf(`no bundles found for package %q`, pkgName))
 }
 
@@ -89,11 +90,12 @@ func TestPackageExists(t *testing.T) {
 	}
 	r := CatalogResolver{WalkCatalogsFunc: w.WalkCatalogs}
 	ce := buildFooClusterExtension(pkgName, []string{}, "", ocv1.UpgradeConstraintPolicyCatalogProvided)
-	gotBundle, gotVersion, gotDeprecation, err := r.Resolve(context.Background(), ce, nil)
+	gotBundle, gotVersion, gotDeprecation, gotOrigin, err := r.Resolve(context.Background(), ce, nil)
 	require.NoError(t, err)
 	assert.Equal(t, genBundle(pkgName, "3.0.0"), *gotBundle)
 	assert.Equal(t, bsemver.MustParse("3.0.0"), *gotVersion)
 	assert.Equal(t, ptr.To(packageDeprecation(pkgName)), gotDeprecation)
+	assert.Equal(t, &Origin{Catalog: "c", Channels: []string{"gamma"}}, gotOrigin)
 }
 
 func TestValidationFailed(t *testing.T) {
@@ -118,10 +120,115 @@ func TestValidationFailed(t *testing.T) {
 		},
 	}
 	ce := buildFooClusterExtension(pkgName, []string{}, "", ocv1.UpgradeConstraintPolicyCatalogProvided)
-	_, _, _, err := r.Resolve(context.Background(), ce, nil)
+	_, _, _, _, err := r.Resolve(context.Background(), ce, nil)
 	require.Error(t, err)
 }
 
+// withKubernetesVersionConstraint returns the package of genPackage, whose
+// bundle of the given version requires Kubernetes 1.30 or later.
+func withKubernetesVersionConstraint(fbc *declcfg.DeclarativeConfig, version string) *declcfg.DeclarativeConfig {
+	for i, b := range fbc.Bundles {
+		if b.Name == bundleName(b.Package, version) {
+			fbc.Bundles[i].Properties = append(b.Properties, property.Property{
+				Type:  property.TypeConstraint,
+				Value: json.RawMessage(`{"failureMessage":"requires Kubernetes 1.30 or later","cel":{"rule":"semver_compare(cluster.kubernetesVersion, '1.30.0') >= 0"}}`),
+			})
+		}
+	}
+	return fbc
+}
+
+func TestConstraintsExcludeBundles(t *testing.T) {
+	pkgName := randPkg()
+	w := staticCatalogWalker{
+		"c": func() (*declcfg.DeclarativeConfig, *catalogd.ClusterCatalogSpec, error) {
+			return withKubernetesVersionConstraint(genPackage(pkgName), "3.0.0"), nil, nil
+		},
+	}
+	r := CatalogResolver{
+		WalkCatalogsFunc: w.WalkCatalogs,
+		FactsFunc: func(context.Context, *ocv1.ClusterExtension) (*Facts, error) {
+			return &Facts{KubernetesVersion: "1.29.4"}, nil
+		},
+	}
+	ce := buildFooClusterExtension(pkgName, []string{}, "", ocv1.UpgradeConstraintPolicyCatalogProvided)
+	gotBundle, gotVersion, _, gotOrigin, err := r.Resolve(context.Background(), ce, nil)
+	require.NoError(t, err)
+	assert.Equal(t, genBundle(pkgName, "2.0.0"), *gotBundle)
+	assert.Equal(t, bsemver.MustParse("2.0.0"), *gotVersion)
+	assert.Equal(t, &Origin{Catalog: "c", Channels: []string{"alpha"}}, gotOrigin)
+
+	r.FactsFunc = func(context.Context, *ocv1.ClusterExtension) (*Facts, error) {
+		return &Facts{KubernetesVersion: "1.30.0"}, nil
+	}
+	gotBundle, _, _, _, err = r.Resolve(context.Background(), ce, nil)
+	require.NoError(t, err)
+	assert.Equal(t, bundleName(pkgName, "3.0.0"), gotBundle.Name)
+}
+
+func TestConstraintsExcludeAllBundles(t *testing.T) {
+	pkgName := randPkg()
+	w := staticCatalogWalker{
+		"c": func() (*declcfg.DeclarativeConfig, *catalogd.ClusterCatalogSpec, error) {
+			return withKubernetesVersionConstraint(genPackage(pkgName), "3.0.0"), nil, nil
+		},
+		"d": func() (*declcfg.DeclarativeConfig, *catalogd.ClusterCatalogSpec, error) {
+			return withKubernetesVersionConstraint(genPackage(pkgName), "3.0.0"), nil, nil
+		},
+	}
+	r := CatalogResolver{WalkCatalogsFunc: w.WalkCatalogs}
+	ce := buildFooClusterExtension(pkgName, []string{}, "3.0.0", ocv1.UpgradeConstraintPolicyCatalogProvided)
+	_, _, _, _, err := r.Resolve(context.Background(), ce, nil)
+	assert.EqualError(t, err, fmt.Sprintf(`no bundles found for package %[1]q matching version "3.0.0" (excluded because of unsatisfied constraints: `+
+		`bundle "%[1]s.v3.0.0" from catalog "c": requires Kubernetes 1.30 or later, bundle "%[1]s.v3.0.0" from catalog "d": requires Kubernetes 1.30 or later)`, pkgName))
+
+	r.FactsFunc = func(context.Context, *ocv1.ClusterExtension) (*Facts, error) {
+		return nil, errors.New("fake error")
+	}
+	_, _, _, _, err = r.Resolve(context.Background(), ce, nil)
+	assert.EqualError(t, err, "error walking catalogs: error gathering facts to evaluate constraints: fake error")
+}
+
+func TestKubernetesVersionExcludesBundles(t *testing.T) {
+	pkgName := randPkg()
+	w := staticCatalogWalker{
+		"c": func() (*declcfg.DeclarativeConfig, *catalogd.ClusterCatalogSpec, error) {
+			fbc := genPackage(pkgName)
+			for i, b := range fbc.Bundles {
+				switch b.Name {
+				case bundleName(pkgName, "3.0.0"):
+					fbc.Bundles[i].Properties = append(b.Properties, property.Property{
+						Type:  property.TypeCSVMetadata,
+						Value: json.RawMessage(`{"minKubeVersion":"1.31.0"}`),
+					})
+				case bundleName(pkgName, "2.0.0"):
+					fbc.Bundles[i].Properties = append(b.Properties, property.Property{
+						Type:  "olm.maxKubeVersion",
+						Value: json.RawMessage(`"1.29"`),
+					})
+				}
+			}
+			return fbc, nil, nil
+		},
+	}
+	r := CatalogResolver{
+		WalkCatalogsFunc: w.WalkCatalogs,
+		FactsFunc: func(context.Context, *ocv1.ClusterExtension) (*Facts, error) {
+			return &Facts{KubernetesVersion: "1.30.2"}, nil
+		},
+	}
+	ce := buildFooClusterExtension(pkgName, []string{}, ">=2.0.0", ocv1.UpgradeConstraintPolicyCatalogProvided)
+	_, _, _, _, err := r.Resolve(context.Background(), ce, nil)
+	assert.EqualError(t, err, fmt.Sprintf(`no bundles found for package %[1]q matching version ">=2.0.0" (excluded because of unsatisfied constraints: `+
+		`bundle "%[1]s.v2.0.0" from catalog "c": requires Kubernetes 1.29 or earlier, but the cluster runs Kubernetes 1.30.2, `+
+		`bundle "%[1]s.v3.0.0" from catalog "c": requires Kubernetes 1.31.0 or later, but the cluster runs Kubernetes 1.30.2)`, pkgName))
+
+	ce = buildFooClusterExtension(pkgName, []string{}, "", ocv1.UpgradeConstraintPolicyCatalogProvided)
+	gotBundle, _, _, _, err := r.Resolve(context.Background(), ce, nil)
+	require.NoError(t, err)
+	assert.Equal(t, bundleName(pkgName, "1.0.2"), gotBundle.Name)
+}
+
 func TestVersionDoesNotExist(t *testing.T) {
 	pkgName := randPkg()
 	w := staticCatalogWalker{
@@ -137,7 +244,7 @@ func TestVersionDoesNotExist(t *testing.T) {
 	}
 	r := CatalogResolver{WalkCatalogsFunc: w.WalkCatalogs}
 	ce := buildFooClusterExtension(pkgName, []string{}, "4.0.0", ocv1.UpgradeConstraintPolicyCatalogProvided)
-	_, _, _, err := r.Resolve(context.Background(), ce, nil)
+	_, _, _, _, err := r.Resolve(context.Background(), ce, nil)
 	assert.EqualError(t, err, fmt.Sprintf(`no bundles found for package %q matching version "4.0.0"`, pkgName))
 }
 
@@ -156,11 +263,12 @@ func TestVersionExists(t *testing.T) {
 	}
 	r := CatalogResolver{WalkCatalogsFunc: w.WalkCatalogs}
 	ce := buildFooClusterExtension(pkgName, []string{}, ">=1.0.0 <2.0.0", ocv1.UpgradeConstraintPolicyCatalogProvided)
-	gotBundle, gotVersion, gotDeprecation, err := r.Resolve(context.Background(), ce, nil)
+	gotBundle, gotVersion, gotDeprecation, gotOrigin, err := r.Resolve(context.Background(), ce, nil)
 	require.NoError(t, err)
 	assert.Equal(t, genBundle(pkgName, "1.0.2"), *gotBundle)
 	assert.Equal(t, bsemver.MustParse("1.0.2"), *gotVersion)
 	assert.Equal(t, ptr.To(packageDeprecation(pkgName)), gotDeprecation)
+	assert.Equal(t, &Origin{Catalog: "c", Channels: []string{"alpha", "beta"}}, gotOrigin)
 }
 
 func TestChannelDoesNotExist(t *testing.T) {
@@ -178,7 +286,7 @@ func TestChannelDoesNotExist(t *testing.T) {
 	}
 	r := CatalogResolver{WalkCatalogsFunc: w.WalkCatalogs}
 	ce := buildFooClusterExtension(pkgName, []string{"stable"}, "", ocv1.UpgradeConstraintPolicyCatalogProvided)
-	_, _, _, err := r.Resolve(context.Background(), ce, nil)
+	_, _, _, _, err := r.Resolve(context.Background(), ce, nil)
 	assert.EqualError(t, err, fmt.Sprintf(`no bundles found for package %q in channels [stable]`, pkgName))
 }
 
@@ -197,11 +305,12 @@ func TestChannelExists(t *testing.T) {
 	}
 	r := CatalogResolver{WalkCatalogsFunc: w.WalkCatalogs}
 	ce := buildFooClusterExtension(pkgName, []string{"beta"}, "", ocv1.UpgradeConstraintPolicyCatalogProvided)
-	gotBundle, gotVersion, gotDeprecation, err := r.Resolve(context.Background(), ce, nil)
+	gotBundle, gotVersion, gotDeprecation, gotOrigin, err := r.Resolve(context.Background(), ce, nil)
 	require.NoError(t, err)
 	assert.Equal(t, genBundle(pkgName, "1.0.2"), *gotBundle)
 	assert.Equal(t, bsemver.MustParse("1.0.2"), *gotVersion)
 	assert.Equal(t, ptr.To(packageDeprecation(pkgName)), gotDeprecation)
+	assert.Equal(t, &Origin{Catalog: "c", Channels: []string{"beta"}}, gotOrigin)
 }
 
 func TestChannelExistsButNotVersion(t *testing.T) {
@@ -219,7 +328,7 @@ func TestChannelExistsButNotVersion(t *testing.T) {
 	}
 	r := CatalogResolver{WalkCatalogsFunc: w.WalkCatalogs}
 	ce := buildFooClusterExtension(pkgName, []string{"beta"}, "3.0.0", ocv1.UpgradeConstraintPolicyCatalogProvided)
-	_, _, _, err := r.Resolve(context.Background(), ce, nil)
+	_, _, _, _, err := r.Resolve(context.Background(), ce, nil)
 	assert.EqualError(t, err, fmt.Sprintf(`no bundles found for package %q matching version "3.0.0" in channels [beta]`, pkgName))
 }
 
@@ -238,7 +347,7 @@ func TestVersionExistsButNotChannel(t *testing.T) {
 	}
 	r := CatalogResolver{WalkCatalogsFunc: w.WalkCatalogs}
 	ce := buildFooClusterExtension(pkgName, []string{"stable"}, "1.0.0", ocv1.UpgradeConstraintPolicyCatalogProvided)
-	_, _, _, err := r.Resolve(context.Background(), ce, nil)
+	_, _, _, _, err := r.Resolve(context.Background(), ce, nil)
 	assert.EqualError(t, err, fmt.Sprintf(`no bundles found for package %q matching version "1.0.0" in channels [stable]`, pkgName))
 }
 
@@ -257,7 +366,7 @@ func TestChannelAndVersionExist(t *testing.T) {
 	}
 	r := CatalogResolver{WalkCatalogsFunc: w.WalkCatalogs}
 	ce := buildFooClusterExtension(pkgName, []string{"alpha"}, "0.1.0", ocv1.UpgradeConstraintPolicyCatalogProvided)
-	gotBundle, gotVersion, gotDeprecation, err := r.Resolve(context.Background(), ce, nil)
+	gotBundle, gotVersion, gotDeprecation, _, err := r.Resolve(context.Background(), ce, nil)
 	require.NoError(t, err)
 	assert.Equal(t, genBundle(pkgName, "0.1.0"), *gotBundle)
 	assert.Equal(t, bsemver.MustParse("0.1.0"), *gotVersion)
@@ -279,7 +388,7 @@ func TestPreferNonDeprecated(t *testing.T) {
 	}
 	r := CatalogResolver{WalkCatalogsFunc: w.WalkCatalogs}
 	ce := buildFooClusterExtension(pkgName, []string{}, ">=0.1.0 <=1.0.0", ocv1.UpgradeConstraintPolicyCatalogProvided)
-	gotBundle, gotVersion, gotDeprecation, err := r.Resolve(context.Background(), ce, nil)
+	gotBundle, gotVersion, gotDeprecation, _, err := r.Resolve(context.Background(), ce, nil)
 	require.NoError(t, err)
 	assert.Equal(t, genBundle(pkgName, "0.1.0"), *gotBundle)
 	assert.Equal(t, bsemver.MustParse("0.1.0"), *gotVersion)
@@ -301,7 +410,7 @@ func TestAcceptDeprecated(t *testing.T) {
 	}
 	r := CatalogResolver{WalkCatalogsFunc: w.WalkCatalogs}
 	ce := buildFooClusterExtension(pkgName, []string{}, ">=1.0.0 <=1.0.1", ocv1.UpgradeConstraintPolicyCatalogProvided)
-	gotBundle, gotVersion, gotDeprecation, err := r.Resolve(context.Background(), ce, nil)
+	gotBundle, gotVersion, gotDeprecation, _, err := r.Resolve(context.Background(), ce, nil)
 	require.NoError(t, err)
 	assert.Equal(t, genBundle(pkgName, "1.0.1"), *gotBundle)
 	assert.Equal(t, bsemver.MustParse("1.0.1"), *gotVersion)
@@ -384,7 +493,7 @@ func TestPackageVariationsBetweenCatalogs(t *testing.T) {
 
 	t.Run("when bundle candidates for a package are deprecated in all but one catalog", func(t *testing.T) {
 		ce := buildFooClusterExtension(pkgName, []string{}, ">=1.0.0 <=1.0.3", ocv1.UpgradeConstraintPolicyCatalogProvided)
-		gotBundle, gotVersion, gotDeprecation, err := r.Resolve(context.Background(), ce, nil)
+		gotBundle, gotVersion, gotDeprecation, _, err := r.Resolve(context.Background(), ce, nil)
 		require.NoError(t, err)
 		// We choose the only non-deprecated package
 		assert.Equal(t, genBundle(pkgName, "1.0.2").Name, gotBundle.Name)
@@ -394,7 +503,7 @@ func TestPackageVariationsBetweenCatalogs(t *testing.T) {
 
 	t.Run("when bundle candidates are found and deprecated in multiple catalogs", func(t *testing.T) {
 		ce := buildFooClusterExtension(pkgName, []string{}, ">=1.0.0 <=1.0.1", ocv1.UpgradeConstraintPolicyCatalogProvided)
-		gotBundle, gotVersion, gotDeprecation, err := r.Resolve(context.Background(), ce, nil)
+		gotBundle, gotVersion, gotDeprecation, _, err := r.Resolve(context.Background(), ce, nil)
 		require.Error(t, err)
 		// We will not make a decision on which catalog to use
 		require.ErrorContains(t, err, "in multiple catalogs with the same priority [b c]")
@@ -405,7 +514,7 @@ func TestPackageVariationsBetweenCatalogs(t *testing.T) {
 
 	t.Run("when bundle candidates are found and not deprecated in multiple catalogs", func(t *testing.T) {
 		ce := buildFooClusterExtension(pkgName, []string{}, ">=1.0.0 <=1.0.4", ocv1.UpgradeConstraintPolicyCatalogProvided)
-		gotBundle, gotVersion, gotDeprecation, err := r.Resolve(context.Background(), ce, nil)
+		gotBundle, gotVersion, gotDeprecation, _, err := r.Resolve(context.Background(), ce, nil)
 		require.Error(t, err)
 		// We will not make a decision on which catalog to use
 		require.ErrorContains(t, err, "in multiple catalogs with the same priority [d f]")
@@ -416,7 +525,7 @@ func TestPackageVariationsBetweenCatalogs(t *testing.T) {
 
 	t.Run("highest semver bundle is chosen when candidates are all from the same catalog", func(t *testing.T) {
 		ce := buildFooClusterExtension(pkgName, []string{}, ">=1.0.4 <=1.0.5", ocv1.UpgradeConstraintPolicyCatalogProvided)
-		gotBundle, gotVersion, gotDeprecation, err := r.Resolve(context.Background(), ce, nil)
+		gotBundle, gotVersion, gotDeprecation, _, err := r.Resolve(context.Background(), ce, nil)
 		require.NoError(t, err)
 		// Bundles within one catalog for a package will be sorted by semver and deprecation and the best is returned
 		assert.Equal(t, genBundle(pkgName, "1.0.5").Name, gotBundle.Name)
@@ -446,7 +555,7 @@ func TestUpgradeFoundLegacy(t *testing.T) {
 		Version: "0.1.0",
 	}
 	// 0.1.0 => 1.0.2 would not be allowed using semver semantics
-	gotBundle, gotVersion, gotDeprecation, err := r.Resolve(context.Background(), ce, installedBundle)
+	gotBundle, gotVersion, gotDeprecation, _, err := r.Resolve(context.Background(), ce, installedBundle)
 	require.NoError(t, err)
 	assert.Equal(t, genBundle(pkgName, "1.0.2"), *gotBundle)
 	assert.Equal(t, bsemver.MustParse("1.0.2"), *gotVersion)
@@ -474,7 +583,7 @@ func TestUpgradeNotFoundLegacy(t *testing.T) {
 		Version: "0.1.0",
 	}
 	// 0.1.0 only upgrades to 1.0.x with its legacy upgrade edges, so this fails.
-	_, _, _, err := r.Resolve(context.Background(), ce, installedBundle)
+	_, _, _, _, err := r.Resolve(context.Background(), ce, installedBundle)
 	assert.EqualError(t, err, fmt.Sprintf(`error upgrading from currently installed version "0.1.0": no bundles found for package %q matching version "<1.0.0 >=2.0.0"`, pkgName))
 }
 
@@ -501,7 +610,7 @@ func TestUpgradeFoundSemver(t *testing.T) {
 	// there is a legacy upgrade edge from 1.0.0 to 2.0.0, but we are using semver semantics here.
 	// therefore:
 	// 	 1.0.0 => 1.0.2 is what we expect
-	gotBundle, gotVersion, gotDeprecation, err := r.Resolve(context.Background(), ce, installedBundle)
+	gotBundle, gotVersion, gotDeprecation, _, err := r.Resolve(context.Background(), ce, installedBundle)
 	require.NoError(t, err)
 	assert.Equal(t, genBundle(pkgName, "1.0.2"), *gotBundle)
 	assert.Equal(t, bsemver.MustParse("1.0.2"), *gotVersion)
@@ -530,10 +639,45 @@ func TestUpgradeNotFoundSemver(t *testing.T) {
 	}
 	// there are legacy upgrade edges from 0.1.0 to 1.0.x, but we are using semver semantics here.
 	// therefore, we expect to fail because there are no semver-compatible upgrade edges from 0.1.0.
-	_, _, _, err := r.Resolve(context.Background(), ce, installedBundle)
+	_, _, _, _, err := r.Resolve(context.Background(), ce, installedBundle)
 	assert.EqualError(t, err, fmt.Sprintf(`error upgrading from currently installed version "0.1.0": no bundles found for package %q matching version "!=0.1.0"`, pkgName))
 }
 
+func TestUpgradeRestrictedByAutoUpgradePolicy(t *testing.T) {
+	featuregatetesting.SetFeatureGateDuringTest(t, features.OperatorControllerFeatureGate, features.ForceSemverUpgradeConstraints, false)
+	for _, tc := range []struct {
+		policy        ocv1.AutoUpgradePolicy
+		expectVersion string
+	}{
+		{policy: ocv1.AutoUpgradePolicyAll, expectVersion: "2.0.0"},
+		{policy: ocv1.AutoUpgradePolicyMinorAndPatch, expectVersion: "1.0.2"},
+		{policy: ocv1.AutoUpgradePolicyPatchOnly, expectVersion: "1.0.2"},
+		{policy: ocv1.AutoUpgradePolicyNone, expectVersion: "1.0.0"},
+	} {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			pkgName := randPkg()
+			w := staticCatalogWalker{
+				"a": func() (*declcfg.DeclarativeConfig, *catalogd.ClusterCatalogSpec, error) {
+					return genPackage(pkgName), nil, nil
+				},
+			}
+			r := CatalogResolver{WalkCatalogsFunc: w.WalkCatalogs}
+			ce := buildFooClusterExtension(pkgName, []string{"alpha"}, "", ocv1.UpgradeConstraintPolicyCatalogProvided)
+			ce.Spec.Source.Catalog.AutoUpgrade = tc.policy
+			installedBundle := &ocv1.BundleMetadata{
+				Name:    bundleName(pkgName, "1.0.0"),
+				Version: "1.0.0",
+			}
+			// 1.0.0 has legacy upgrade edges to 1.0.1, 1.0.2 and 2.0.0, which are
+			// further restricted by the auto upgrade policy.
+			gotBundle, gotVersion, _, _, err := r.Resolve(context.Background(), ce, installedBundle)
+			require.NoError(t, err)
+			assert.Equal(t, genBundle(pkgName, tc.expectVersion), *gotBundle)
+			assert.Equal(t, bsemver.MustParse(tc.expectVersion), *gotVersion)
+		})
+	}
+}
+
 func TestDowngradeFound(t *testing.T) {
 	pkgName := randPkg()
 	w := staticCatalogWalker{
@@ -555,7 +699,7 @@ func TestDowngradeFound(t *testing.T) {
 	}
 	// 1.0.2 => 0.1.0 is a downgrade, but it is allowed because of the upgrade constraint policy.
 	//   note: we chose 0.1.0 because 1.0.0 and 1.0.1 are deprecated.
-	gotBundle, gotVersion, gotDeprecation, err := r.Resolve(context.Background(), ce, installedBundle)
+	gotBundle, gotVersion, gotDeprecation, _, err := r.Resolve(context.Background(), ce, installedBundle)
 	require.NoError(t, err)
 	assert.Equal(t, genBundle(pkgName, "0.1.0"), *gotBundle)
 	assert.Equal(t, bsemver.MustParse("0.1.0"), *gotVersion)
@@ -582,7 +726,7 @@ func TestDowngradeNotFound(t *testing.T) {
 		Version: "1.0.2",
 	}
 	// Downgrades are allowed via the upgrade constraint policy, but there is no bundle in the specified range.
-	_, _, _, err := r.Resolve(context.Background(), ce, installedBundle)
+	_, _, _, _, err := r.Resolve(context.Background(), ce, installedBundle)
 	assert.EqualError(t, err, fmt.Sprintf(`error upgrading from currently installed version "1.0.2": no bundles found for package %q matching version ">0.1.0 <1.0.0"`, pkgName))
 }
 
@@ -781,7 +925,7 @@ func TestInvalidClusterExtensionCatalogMatchExpressions(t *testing.T) {
 			},
 		},
 	}
-	_, _, _, err := r.Resolve(context.Background(), ce, nil)
+	_, _, _, _, err := r.Resolve(context.Background(), ce, nil)
 	assert.EqualError(t, err, "desired catalog selector is invalid: \"bad\" is not a valid label selector operator")
 }
 
@@ -807,7 +951,7 @@ func TestInvalidClusterExtensionCatalogMatchLabelsName(t *testing.T) {
 			},
 		},
 	}
-	_, _, _, err := r.Resolve(context.Background(), ce, nil)
+	_, _, _, _, err := r.Resolve(context.Background(), ce, nil)
 	assert.ErrorContains(t, err, "desired catalog selector is invalid: key: Invalid value:")
 }
 
@@ -833,7 +977,7 @@ func TestInvalidClusterExtensionCatalogMatchLabelsValue(t *testing.T) {
 			},
 		},
 	}
-	_, _, _, err := r.Resolve(context.Background(), ce, nil)
+	_, _, _, _, err := r.Resolve(context.Background(), ce, nil)
 	assert.ErrorContains(t, err, "desired catalog selector is invalid: values[0][name]: Invalid value:")
 }
 
@@ -854,7 +998,7 @@ func TestClusterExtensionMatchLabel(t *testing.T) {
 		MatchLabels: map[string]string{"olm.operatorframework.io/metadata.name": "b"},
 	}
 
-	_, _, _, err := r.Resolve(context.Background(), ce, nil)
+	_, _, _, _, err := r.Resolve(context.Background(), ce, nil)
 	require.NoError(t, err)
 }
 
@@ -875,7 +1019,7 @@ func TestClusterExtensionNoMatchLabel(t *testing.T) {
 		MatchLabels: map[string]string{"olm.operatorframework.io/metadata.name": "a"},
 	}
 
-	_, _, _, err := r.Resolve(context.Background(), ce, nil)
+	_, _, _, _, err := r.Resolve(context.Background(), ce, nil)
 	require.Error(t, err)
 	require.ErrorContains(t, err, fmt.Sprintf("no bundles found for package %q", pkgName))
 }
@@ -915,7 +1059,7 @@ func TestUnequalPriority(t *testing.T) {
 	r := CatalogResolver{WalkCatalogsFunc: w.WalkCatalogs}
 
 	ce := buildFooClusterExtension(pkgName, []string{}, "", ocv1.UpgradeConstraintPolicyCatalogProvided)
-	_, gotVersion, _, err := r.Resolve(context.Background(), ce, nil)
+	_, gotVersion, _, _, err := r.Resolve(context.Background(), ce, nil)
 	require.NoError(t, err)
 	require.Equal(t, bsemver.MustParse("1.0.0"), *gotVersion)
 }
@@ -936,7 +1080,7 @@ func TestMultiplePriority(t *testing.T) {
 	r := CatalogResolver{WalkCatalogsFunc: w.WalkCatalogs}
 
 	ce := buildFooClusterExtension(pkgName, []string{}, ">=1.0.0 <=1.0.1", ocv1.UpgradeConstraintPolicyCatalogProvided)
-	gotBundle, gotVersion, gotDeprecation, err := r.Resolve(context.Background(), ce, nil)
+	gotBundle, gotVersion, gotDeprecation, _, err := r.Resolve(context.Background(), ce, nil)
 	require.Error(t, err)
 	require.ErrorContains(t, err, "in multiple catalogs with the same priority [a b c]")
 	assert.Nil(t, gotBundle)
@@ -959,7 +1103,7 @@ func TestMultipleChannels(t *testing.T) {
 	}
 	r := CatalogResolver{WalkCatalogsFunc: w.WalkCatalogs}
 	ce := buildFooClusterExtension(pkgName, []string{"beta", "alpha"}, "", ocv1.UpgradeConstraintPolicyCatalogProvided)
-	gotBundle, gotVersion, gotDeprecation, err := r.Resolve(context.Background(), ce, nil)
+	gotBundle, gotVersion, gotDeprecation, _, err := r.Resolve(context.Background(), ce, nil)
 	require.NoError(t, err)
 	assert.Equal(t, genBundle(pkgName, "2.0.0"), *gotBundle)
 	assert.Equal(t, bsemver.MustParse("2.0.0"), *gotVersion)
@@ -992,7 +1136,7 @@ func TestAllCatalogsDisabled(t *testing.T) {
 	}
 
 	ce := buildFooClusterExtension(pkgName, []string{}, ">=1.0.0", ocv1.UpgradeConstraintPolicyCatalogProvided)
-	_, _, _, err := r.Resolve(context.Background(), ce, nil)
+	_, _, _, _, err := r.Resolve(context.Background(), ce, nil)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "no bundles found for package")
 }
@@ -1032,7 +1176,7 @@ func TestSomeCatalogsDisabled(t *testing.T) {
 	}
 
 	ce := buildFooClusterExtension(pkgName, []string{}, ">=1.0.0", ocv1.UpgradeConstraintPolicyCatalogProvided)
-	gotBundle, gotVersion, _, err := r.Resolve(context.Background(), ce, nil)
+	gotBundle, gotVersion, _, _, err := r.Resolve(context.Background(), ce, nil)
 	require.NoError(t, err)
 	require.NotNil(t, gotBundle)
 	require.Equal(t, bsemver.MustParse("3.0.0"), *gotVersion)
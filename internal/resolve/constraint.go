@@ -0,0 +1,399 @@
+package resolve
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	mmsemver "github.com/Masterminds/semver/v3"
+	bsemver "github.com/blang/semver/v4"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+	"github.com/operator-framework/operator-controller/internal/rukpak/preflights/kubeversion"
+)
+
+// constraintCostLimit bounds the cost of evaluating a single CEL rule, so that
+// an expensive rule cannot stall resolution.
+const constraintCostLimit = 1000000
+
+// maxConstraintSize is the maximum size in bytes of the value of an
+// olm.constraint property, which bounds the nesting of compound constraints.
+const maxConstraintSize = 2 << 16
+
+// Facts are the facts about the cluster that the olm.constraint properties of
+// a bundle are evaluated against.
+type Facts struct {
+	// KubernetesVersion is the version of the Kubernetes API server, without
+	// the leading "v", for example "1.30.2".
+	KubernetesVersion string
+	// Extensions are the installed ClusterExtensions, other than the one
+	// being resolved.
+	Extensions []ExtensionFacts
+	// APIServed reports whether the cluster serves the given API.
+	APIServed func(schema.GroupVersionKind) (bool, error)
+}
+
+// ExtensionFacts describe an installed ClusterExtension.
+type ExtensionFacts struct {
+	Name        string
+	PackageName string
+	Version     string
+}
+
+// FactsFunc returns the facts that the olm.constraint properties of the bundles
+// resolved for ext are evaluated against.
+type FactsFunc func(ctx context.Context, ext *ocv1.ClusterExtension) (*Facts, error)
+
+// ClusterFacts gathers Facts from the cluster.
+type ClusterFacts struct {
+	// Client is used to list the ClusterExtensions.
+	Client client.Reader
+	// RESTMapper is used to determine whether an API is served by the cluster.
+	RESTMapper meta.RESTMapper
+	// ServerVersion returns the version of the Kubernetes API server.
+	ServerVersion func() (*version.Info, error)
+}
+
+// Facts returns the facts about the cluster for the resolution of ext.
+func (f *ClusterFacts) Facts(ctx context.Context, ext *ocv1.ClusterExtension) (*Facts, error) {
+	info, err := f.ServerVersion()
+	if err != nil {
+		return nil, fmt.Errorf("error getting Kubernetes version: %w", err)
+	}
+	exts := &ocv1.ClusterExtensionList{}
+	if err := f.Client.List(ctx, exts); err != nil {
+		return nil, fmt.Errorf("error listing ClusterExtensions: %w", err)
+	}
+
+	facts := &Facts{
+		KubernetesVersion: strings.TrimPrefix(info.GitVersion, "v"),
+		APIServed: func(gvk schema.GroupVersionKind) (bool, error) {
+			_, err := f.RESTMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+			if meta.IsNoMatchError(err) {
+				return false, nil
+			}
+			return err == nil, err
+		},
+	}
+	for _, e := range exts.Items {
+		if e.Name == ext.Name || e.Status.Install == nil {
+			continue
+		}
+		ef := ExtensionFacts{Name: e.Name, Version: e.Status.Install.Bundle.Version}
+		if e.Spec.Source.Catalog != nil {
+			ef.PackageName = e.Spec.Source.Catalog.PackageName
+		}
+		facts.Extensions = append(facts.Extensions, ef)
+	}
+	return facts, nil
+}
+
+// constraint is the value of an olm.constraint property. Exactly one of its
+// rules is expected to be set.
+type constraint struct {
+	FailureMessage string                    `json:"failureMessage,omitempty"`
+	Cel            *celConstraint            `json:"cel,omitempty"`
+	Package        *property.PackageRequired `json:"package,omitempty"`
+	GVK            *property.GVKRequired     `json:"gvk,omitempty"`
+	All            *compoundConstraint       `json:"all,omitempty"`
+	Any            *compoundConstraint       `json:"any,omitempty"`
+	Not            *compoundConstraint       `json:"not,omitempty"`
+}
+
+type celConstraint struct {
+	Rule string `json:"rule"`
+}
+
+type compoundConstraint struct {
+	Constraints []constraint `json:"constraints"`
+}
+
+// hasConstraints reports whether bundle has an olm.constraint property.
+func hasConstraints(bundle declcfg.Bundle) bool {
+	for _, prop := range bundle.Properties {
+		if prop.Type == property.TypeConstraint {
+			return true
+		}
+	}
+	return false
+}
+
+// lazyConstraintEvaluator gathers the facts and builds a constraintEvaluator
+// the first time a bundle with an olm.constraint property is evaluated, so
+// that resolving bundles without constraints does not query the cluster.
+type lazyConstraintEvaluator struct {
+	factsFunc FactsFunc
+	ext       *ocv1.ClusterExtension
+	evaluator *constraintEvaluator
+}
+
+// unsatisfiedConstraints returns a message for each olm.constraint property of
+// bundle that is not satisfied, preceded by a message if the bundle does not
+// support the version of Kubernetes of the cluster, unless the Kubernetes
+// Version preflight of the ClusterExtension is disabled. An error is only
+// returned when the facts cannot be gathered.
+func (l *lazyConstraintEvaluator) unsatisfiedConstraints(ctx context.Context, bundle declcfg.Bundle) ([]string, error) {
+	minKubeVersion, maxKubeVersion, rangeErr := kubeversion.BundleRange(bundle.Properties)
+	if !kubeVersionEnforced(l.ext) {
+		minKubeVersion, maxKubeVersion, rangeErr = "", "", nil
+	}
+	if rangeErr == nil && minKubeVersion == "" && maxKubeVersion == "" && !hasConstraints(bundle) {
+		return nil, nil
+	}
+	if l.evaluator == nil {
+		facts := &Facts{}
+		if l.factsFunc != nil {
+			var err error
+			if facts, err = l.factsFunc(ctx, l.ext); err != nil {
+				return nil, fmt.Errorf("error gathering facts to evaluate constraints: %w", err)
+			}
+		}
+		evaluator, err := newConstraintEvaluator(facts)
+		if err != nil {
+			return nil, fmt.Errorf("error creating constraint evaluator: %w", err)
+		}
+		l.evaluator = evaluator
+	}
+
+	var messages []string
+	if rangeErr == nil {
+		rangeErr = kubeversion.Check(l.evaluator.facts.KubernetesVersion, minKubeVersion, maxKubeVersion)
+	}
+	if rangeErr != nil {
+		messages = append(messages, rangeErr.Error())
+	}
+	return append(messages, l.evaluator.unsatisfiedConstraints(bundle)...), nil
+}
+
+// kubeVersionEnforced reports whether the versions of Kubernetes supported by
+// bundles are checked for ext, which is the case unless the enforcement of its
+// Kubernetes Version preflight is None.
+func kubeVersionEnforced(ext *ocv1.ClusterExtension) bool {
+	if ext == nil || ext.Spec.Install == nil || ext.Spec.Install.Preflight == nil || ext.Spec.Install.Preflight.KubeVersion == nil {
+		return true
+	}
+	return ext.Spec.Install.Preflight.KubeVersion.Enforcement != ocv1.KubeVersionEnforcementNone
+}
+
+// constraintEvaluator evaluates the olm.constraint properties of bundles
+// against a set of facts. The evaluator compiles each CEL rule only once.
+type constraintEvaluator struct {
+	facts    *Facts
+	vars     map[string]interface{}
+	env      *cel.Env
+	programs map[string]cel.Program
+}
+
+func newConstraintEvaluator(facts *Facts) (*constraintEvaluator, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("properties", cel.ListType(cel.DynType)),
+		cel.Variable("cluster", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("extensions", cel.ListType(cel.DynType)),
+		cel.Function("semver_compare",
+			cel.Overload("semver_compare_dyn_dyn", []*cel.Type{cel.DynType, cel.DynType}, cel.IntType,
+				cel.BinaryBinding(semverCompare),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	extensions := make([]interface{}, 0, len(facts.Extensions))
+	for _, e := range facts.Extensions {
+		extensions = append(extensions, map[string]interface{}{
+			"name":        e.Name,
+			"packageName": e.PackageName,
+			"version":     e.Version,
+		})
+	}
+	return &constraintEvaluator{
+		facts: facts,
+		vars: map[string]interface{}{
+			"cluster":    map[string]interface{}{"kubernetesVersion": facts.KubernetesVersion},
+			"extensions": extensions,
+		},
+		env:      env,
+		programs: map[string]cel.Program{},
+	}, nil
+}
+
+// semverCompare implements semver_compare(a, b), which returns -1, 0 or 1
+// when the version a is lower than, equal to or greater than the version b.
+func semverCompare(lhs, rhs ref.Val) ref.Val {
+	a, err := bsemver.ParseTolerant(fmt.Sprint(lhs.Value()))
+	if err != nil {
+		return types.NewErr("unable to parse %q as a semantic version", lhs.Value())
+	}
+	b, err := bsemver.ParseTolerant(fmt.Sprint(rhs.Value()))
+	if err != nil {
+		return types.NewErr("unable to parse %q as a semantic version", rhs.Value())
+	}
+	return types.Int(a.Compare(b))
+}
+
+// unsatisfiedConstraints returns a message for each olm.constraint property of
+// bundle that is not satisfied, in the order of the properties.
+func (e *constraintEvaluator) unsatisfiedConstraints(bundle declcfg.Bundle) []string {
+	var (
+		properties []interface{}
+		messages   []string
+	)
+	for _, prop := range bundle.Properties {
+		if prop.Type != property.TypeConstraint {
+			continue
+		}
+		if properties == nil {
+			properties = bundleProperties(bundle)
+		}
+		c, err := parseConstraint(prop.Value)
+		if err != nil {
+			messages = append(messages, fmt.Sprintf("invalid olm.constraint property: %v", err))
+			continue
+		}
+		if err := e.evaluate(c, properties); err != nil {
+			messages = append(messages, err.Error())
+		}
+	}
+	return messages
+}
+
+func parseConstraint(value json.RawMessage) (constraint, error) {
+	c := constraint{}
+	if len(value) > maxConstraintSize {
+		return c, fmt.Errorf("value is larger than %d bytes", maxConstraintSize)
+	}
+	d := json.NewDecoder(bytes.NewReader(value))
+	d.DisallowUnknownFields()
+	return c, d.Decode(&c)
+}
+
+// bundleProperties returns the properties of bundle with their values decoded,
+// as the properties variable of CEL rules.
+func bundleProperties(bundle declcfg.Bundle) []interface{} {
+	properties := make([]interface{}, 0, len(bundle.Properties))
+	for _, prop := range bundle.Properties {
+		var value interface{}
+		if err := json.Unmarshal(prop.Value, &value); err != nil {
+			value = string(prop.Value)
+		}
+		properties = append(properties, map[string]interface{}{"type": prop.Type, "value": value})
+	}
+	return properties
+}
+
+// evaluate returns an error describing why c is not satisfied, or nil if it is.
+// The failure message of c, if any, replaces the description.
+func (e *constraintEvaluator) evaluate(c constraint, properties []interface{}) error {
+	err := e.evaluateRule(c, properties)
+	if err != nil && c.FailureMessage != "" {
+		return errors.New(c.FailureMessage)
+	}
+	return err
+}
+
+func (e *constraintEvaluator) evaluateRule(c constraint, properties []interface{}) error {
+	switch {
+	case c.Cel != nil:
+		return e.evaluateCel(c.Cel.Rule, properties)
+	case c.Package != nil:
+		versionRange, err := mmsemver.NewConstraint(c.Package.VersionRange)
+		if err != nil {
+			return fmt.Errorf("invalid version range %q: %w", c.Package.VersionRange, err)
+		}
+		for _, ext := range e.facts.Extensions {
+			if v, err := mmsemver.NewVersion(ext.Version); ext.PackageName == c.Package.PackageName && err == nil && versionRange.Check(v) {
+				return nil
+			}
+		}
+		return fmt.Errorf("package %q in range %q is not installed", c.Package.PackageName, c.Package.VersionRange)
+	case c.GVK != nil:
+		gvk := schema.GroupVersionKind{Group: c.GVK.Group, Version: c.GVK.Version, Kind: c.GVK.Kind}
+		served := false
+		if e.facts.APIServed != nil {
+			var err error
+			if served, err = e.facts.APIServed(gvk); err != nil {
+				return fmt.Errorf("error checking whether API %s is served: %w", gvk, err)
+			}
+		}
+		if !served {
+			return fmt.Errorf("API %s is not served", gvk)
+		}
+		return nil
+	case c.All != nil:
+		for _, sub := range c.All.Constraints {
+			if err := e.evaluate(sub, properties); err != nil {
+				return err
+			}
+		}
+		return nil
+	case c.Any != nil:
+		var errs []string
+		for _, sub := range c.Any.Constraints {
+			err := e.evaluate(sub, properties)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, err.Error())
+		}
+		return fmt.Errorf("none of the constraints are satisfied: %s", strings.Join(errs, "; "))
+	case c.Not != nil:
+		for _, sub := range c.Not.Constraints {
+			if err := e.evaluate(sub, properties); err == nil {
+				return errors.New("a constraint that must not be satisfied is satisfied")
+			}
+		}
+		return nil
+	default:
+		return errors.New("invalid olm.constraint property: no rule is set")
+	}
+}
+
+func (e *constraintEvaluator) evaluateCel(rule string, properties []interface{}) error {
+	program, ok := e.programs[rule]
+	if !ok {
+		ast, issues := e.env.Compile(rule)
+		if issues.Err() != nil {
+			return fmt.Errorf("invalid CEL rule %q: %w", rule, issues.Err())
+		}
+		if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+			return fmt.Errorf("CEL rule %q must evaluate to a boolean, not %s", rule, ast.OutputType())
+		}
+		var err error
+		program, err = e.env.Program(ast, cel.CostLimit(constraintCostLimit))
+		if err != nil {
+			return fmt.Errorf("invalid CEL rule %q: %w", rule, err)
+		}
+		e.programs[rule] = program
+	}
+
+	vars := map[string]interface{}{"properties": properties}
+	for k, v := range e.vars {
+		vars[k] = v
+	}
+	val, _, err := program.Eval(vars)
+	if err != nil {
+		return fmt.Errorf("error evaluating CEL rule %q: %w", rule, err)
+	}
+	satisfied, ok := val.Value().(bool)
+	if !ok {
+		return fmt.Errorf("CEL rule %q evaluated to %v instead of a boolean", rule, val.Value())
+	}
+	if !satisfied {
+		return fmt.Errorf("CEL rule %q is not satisfied", rule)
+	}
+	return nil
+}
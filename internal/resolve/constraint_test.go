@@ -0,0 +1,254 @@
+package resolve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+)
+
+func constraintBundle(constraints ...string) declcfg.Bundle {
+	bundle := genBundle("foo", "1.0.0")
+	bundle.Properties = append(bundle.Properties, property.MustBuildGVK("example.com", "v1", "Foo"))
+	for _, c := range constraints {
+		bundle.Properties = append(bundle.Properties, property.Property{Type: property.TypeConstraint, Value: json.RawMessage(c)})
+	}
+	return bundle
+}
+
+func TestUnsatisfiedConstraints(t *testing.T) {
+	facts := &Facts{
+		KubernetesVersion: "1.29.4",
+		Extensions: []ExtensionFacts{
+			{Name: "bar-ext", PackageName: "bar", Version: "1.2.0"},
+		},
+		APIServed: func(gvk schema.GroupVersionKind) (bool, error) {
+			if gvk.Kind == "Error" {
+				return false, errors.New("fake error")
+			}
+			return gvk.Kind == "Served", nil
+		},
+	}
+
+	for _, tt := range []struct {
+		name       string
+		constraint string
+		want       []string
+	}{
+		{
+			name:       "cel rule on the Kubernetes version is satisfied",
+			constraint: `{"cel":{"rule":"semver_compare(cluster.kubernetesVersion, '1.28.0') >= 0"}}`,
+		},
+		{
+			name:       "cel rule on the Kubernetes version is not satisfied",
+			constraint: `{"cel":{"rule":"semver_compare(cluster.kubernetesVersion, '1.30.0') >= 0"}}`,
+			want:       []string{`CEL rule "semver_compare(cluster.kubernetesVersion, '1.30.0') >= 0" is not satisfied`},
+		},
+		{
+			name:       "cel rule on the bundle properties",
+			constraint: `{"cel":{"rule":"properties.exists(p, p.type == 'olm.gvk' && p.value.kind == 'Foo')"}}`,
+		},
+		{
+			name:       "cel rule on the installed extensions",
+			constraint: `{"failureMessage":"bar must not be installed","cel":{"rule":"!extensions.exists(e, e.packageName == 'bar')"}}`,
+			want:       []string{"bar must not be installed"},
+		},
+		{
+			name:       "cel rule that is not a boolean",
+			constraint: `{"cel":{"rule":"1 + 1"}}`,
+			want:       []string{`CEL rule "1 + 1" must evaluate to a boolean, not int`},
+		},
+		{
+			name:       "cel rule that does not evaluate to a boolean",
+			constraint: `{"cel":{"rule":"cluster.kubernetesVersion"}}`,
+			want:       []string{`CEL rule "cluster.kubernetesVersion" evaluated to 1.29.4 instead of a boolean`},
+		},
+		{
+			name:       "cel rule with an invalid version",
+			constraint: `{"cel":{"rule":"semver_compare('foo', '1.0.0') == 0"}}`,
+			want:       []string{`error evaluating CEL rule "semver_compare('foo', '1.0.0') == 0": unable to parse "foo" as a semantic version`},
+		},
+		{
+			name:       "package is installed in range",
+			constraint: `{"package":{"packageName":"bar","versionRange":">=1.0.0"}}`,
+		},
+		{
+			name:       "package is not installed in range",
+			constraint: `{"package":{"packageName":"bar","versionRange":">=2.0.0"}}`,
+			want:       []string{`package "bar" in range ">=2.0.0" is not installed`},
+		},
+		{
+			name:       "gvk is served",
+			constraint: `{"gvk":{"group":"example.com","version":"v1","kind":"Served"}}`,
+		},
+		{
+			name:       "gvk is not served",
+			constraint: `{"gvk":{"group":"example.com","version":"v1","kind":"Missing"}}`,
+			want:       []string{"API example.com/v1, Kind=Missing is not served"},
+		},
+		{
+			name:       "gvk cannot be checked",
+			constraint: `{"gvk":{"group":"example.com","version":"v1","kind":"Error"}}`,
+			want:       []string{"error checking whether API example.com/v1, Kind=Error is served: fake error"},
+		},
+		{
+			name: "all is satisfied",
+			constraint: `{"all":{"constraints":[
+				{"package":{"packageName":"bar","versionRange":">=1.0.0"}},
+				{"gvk":{"group":"example.com","version":"v1","kind":"Served"}}
+			]}}`,
+		},
+		{
+			name: "all is not satisfied",
+			constraint: `{"all":{"constraints":[
+				{"package":{"packageName":"bar","versionRange":">=1.0.0"}},
+				{"gvk":{"group":"example.com","version":"v1","kind":"Missing"}}
+			]}}`,
+			want: []string{"API example.com/v1, Kind=Missing is not served"},
+		},
+		{
+			name: "any is satisfied",
+			constraint: `{"any":{"constraints":[
+				{"gvk":{"group":"example.com","version":"v1","kind":"Missing"}},
+				{"gvk":{"group":"example.com","version":"v1","kind":"Served"}}
+			]}}`,
+		},
+		{
+			name: "any is not satisfied",
+			constraint: `{"failureMessage":"requires a Missing or Other API","any":{"constraints":[
+				{"gvk":{"group":"example.com","version":"v1","kind":"Missing"}},
+				{"gvk":{"group":"example.com","version":"v1","kind":"Other"}}
+			]}}`,
+			want: []string{"requires a Missing or Other API"},
+		},
+		{
+			name: "not is satisfied",
+			constraint: `{"not":{"constraints":[
+				{"package":{"packageName":"baz","versionRange":">=0.0.0"}}
+			]}}`,
+		},
+		{
+			name: "not is not satisfied",
+			constraint: `{"not":{"constraints":[
+				{"package":{"packageName":"bar","versionRange":">=0.0.0"}}
+			]}}`,
+			want: []string{"a constraint that must not be satisfied is satisfied"},
+		},
+		{
+			name:       "unknown field",
+			constraint: `{"foo":{}}`,
+			want:       []string{`invalid olm.constraint property: json: unknown field "foo"`},
+		},
+		{
+			name:       "no rule",
+			constraint: `{"failureMessage":"no rule"}`,
+			want:       []string{"no rule"},
+		},
+		{
+			name:       "too large",
+			constraint: `{"failureMessage":"` + strings.Repeat("x", maxConstraintSize) + `"}`,
+			want:       []string{"invalid olm.constraint property: value is larger than 131072 bytes"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := newConstraintEvaluator(facts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, e.unsatisfiedConstraints(constraintBundle(tt.constraint)))
+		})
+	}
+}
+
+func TestLazyConstraintEvaluator(t *testing.T) {
+	ctx := context.Background()
+	calls := 0
+	l := &lazyConstraintEvaluator{factsFunc: func(context.Context, *ocv1.ClusterExtension) (*Facts, error) {
+		calls++
+		return &Facts{KubernetesVersion: "1.30.0"}, nil
+	}}
+
+	t.Log("It does not gather facts for bundles without constraints")
+	unsatisfied, err := l.unsatisfiedConstraints(ctx, constraintBundle())
+	require.NoError(t, err)
+	assert.Empty(t, unsatisfied)
+	assert.Equal(t, 0, calls)
+
+	t.Log("It gathers the facts once")
+	for range 2 {
+		unsatisfied, err = l.unsatisfiedConstraints(ctx, constraintBundle(`{"cel":{"rule":"cluster.kubernetesVersion == '1.30.0'"}}`, `{"cel":{"rule":"false"}}`))
+		require.NoError(t, err)
+		assert.Equal(t, []string{`CEL rule "false" is not satisfied`}, unsatisfied)
+	}
+	assert.Equal(t, 1, calls)
+}
+
+func TestLazyConstraintEvaluatorKubeVersionEnforcement(t *testing.T) {
+	ctx := context.Background()
+	factsFunc := func(context.Context, *ocv1.ClusterExtension) (*Facts, error) {
+		return &Facts{KubernetesVersion: "1.30.0"}, nil
+	}
+	bundle := constraintBundle()
+	bundle.Properties = append(bundle.Properties, property.Property{Type: "olm.maxKubeVersion", Value: json.RawMessage(`"1.29"`)})
+
+	ext := buildFooClusterExtension("foo", nil, "", "")
+	l := &lazyConstraintEvaluator{factsFunc: factsFunc, ext: ext}
+	unsatisfied, err := l.unsatisfiedConstraints(ctx, bundle)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"requires Kubernetes 1.29 or earlier, but the cluster runs Kubernetes 1.30.0"}, unsatisfied)
+
+	t.Log("The Kubernetes version is not checked when the Kubernetes Version preflight is disabled")
+	ext.Spec.Install = &ocv1.ClusterExtensionInstallConfig{
+		Preflight: &ocv1.PreflightConfig{KubeVersion: &ocv1.KubeVersionPreflightConfig{Enforcement: ocv1.KubeVersionEnforcementNone}},
+	}
+	l = &lazyConstraintEvaluator{factsFunc: factsFunc, ext: ext}
+	unsatisfied, err = l.unsatisfiedConstraints(ctx, bundle)
+	require.NoError(t, err)
+	assert.Empty(t, unsatisfied)
+}
+
+func TestClusterFacts(t *testing.T) {
+	sch := runtime.NewScheme()
+	require.NoError(t, ocv1.AddToScheme(sch))
+	mapper := apimeta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Foo"}, apimeta.RESTScopeNamespace)
+
+	f := &ClusterFacts{
+		Client: fake.NewClientBuilder().WithScheme(sch).WithObjects(
+			installedClusterExtension("foo", "foo", "1.0.0"),
+			installedClusterExtension("bar-ext", "bar", "1.2.0"),
+			installedClusterExtension("baz-ext", "baz", ""),
+		).Build(),
+		RESTMapper: mapper,
+		ServerVersion: func() (*version.Info, error) {
+			return &version.Info{GitVersion: "v1.30.2"}, nil
+		},
+	}
+	facts, err := f.Facts(context.Background(), buildFooClusterExtension("foo", nil, "", ""))
+	require.NoError(t, err)
+	assert.Equal(t, "1.30.2", facts.KubernetesVersion)
+	assert.Equal(t, []ExtensionFacts{{Name: "bar-ext", PackageName: "bar", Version: "1.2.0"}}, facts.Extensions)
+
+	served, err := facts.APIServed(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Foo"})
+	require.NoError(t, err)
+	assert.True(t, served)
+	served, err = facts.APIServed(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Bar"})
+	require.NoError(t, err)
+	assert.False(t, served)
+
+	f.ServerVersion = func() (*version.Info, error) { return nil, errors.New("fake error") }
+	_, err = f.Facts(context.Background(), buildFooClusterExtension("foo", nil, "", ""))
+	require.EqualError(t, err, "error getting Kubernetes version: fake error")
+}
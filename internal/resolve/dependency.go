@@ -0,0 +1,325 @@
+package resolve
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	mmsemver "github.com/Masterminds/semver/v3"
+	bsemver "github.com/blang/semver/v4"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	catalogd "github.com/operator-framework/catalogd/api/v1"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+	"github.com/operator-framework/operator-controller/internal/bundleutil"
+)
+
+// DependencyResolver determines whether the dependencies declared by a bundle
+// are satisfied.
+type DependencyResolver interface {
+	// ResolveDependencies returns the dependencies declared by bundle, which
+	// was resolved for ext, sorted by requirement. It returns nil when bundle
+	// declares no dependencies.
+	ResolveDependencies(ctx context.Context, ext *ocv1.ClusterExtension, bundle *declcfg.Bundle) ([]ocv1.DependencyStatus, error)
+}
+
+type DependencyResolverFunc func(ctx context.Context, ext *ocv1.ClusterExtension, bundle *declcfg.Bundle) ([]ocv1.DependencyStatus, error)
+
+func (f DependencyResolverFunc) ResolveDependencies(ctx context.Context, ext *ocv1.ClusterExtension, bundle *declcfg.Bundle) ([]ocv1.DependencyStatus, error) {
+	return f(ctx, ext, bundle)
+}
+
+// CatalogDependencyResolver resolves the dependencies declared by a bundle
+// through olm.package.required and olm.gvk.required properties.
+//
+// A package dependency is satisfied by another ClusterExtension that has a
+// version of the package in the required range installed. An API dependency is
+// satisfied when the API is served by the cluster. The bundles that would
+// satisfy missing dependencies are looked up in the catalogs selected by the
+// ClusterExtension, preferring non-deprecated bundles, then catalogs with a
+// higher priority, then higher versions, so that the outcome is deterministic.
+type CatalogDependencyResolver struct {
+	// Client is used to list the ClusterExtensions.
+	Client client.Reader
+	// RESTMapper is used to determine whether a required API is served by the cluster.
+	RESTMapper meta.RESTMapper
+	// WalkCatalogsFunc walks the given package of the catalogs.
+	WalkCatalogsFunc func(context.Context, string, CatalogWalkFunc, ...client.ListOption) error
+	// WalkAllCatalogsFunc walks the full contents of the catalogs. It is only
+	// used to find the packages providing an API that is not served by the cluster.
+	WalkAllCatalogsFunc func(context.Context, CatalogWalkFunc, ...client.ListOption) error
+}
+
+// dependencyCandidate is a bundle from a catalog that satisfies a dependency.
+type dependencyCandidate struct {
+	bundle     declcfg.Bundle
+	version    bsemver.Version
+	catalog    string
+	priority   int32
+	deprecated bool
+}
+
+func (c dependencyCandidate) status() *ocv1.DependencyCandidate {
+	return &ocv1.DependencyCandidate{
+		PackageName: c.bundle.Package,
+		Bundle:      bundleutil.MetadataFor(c.bundle.Name, c.version),
+		Catalog:     c.catalog,
+	}
+}
+
+// compareCandidates orders the best candidate first.
+func compareCandidates(a, b dependencyCandidate) int {
+	deprecated := func(c dependencyCandidate) int {
+		if c.deprecated {
+			return 1
+		}
+		return 0
+	}
+	return cmp.Or(
+		cmp.Compare(deprecated(a), deprecated(b)),
+		cmp.Compare(b.priority, a.priority),
+		b.version.Compare(a.version),
+		cmp.Compare(a.bundle.Package, b.bundle.Package),
+		cmp.Compare(a.catalog, b.catalog),
+	)
+}
+
+func (r *CatalogDependencyResolver) ResolveDependencies(ctx context.Context, ext *ocv1.ClusterExtension, bundle *declcfg.Bundle) ([]ocv1.DependencyStatus, error) {
+	var (
+		packagesRequired []property.PackageRequired
+		gvksRequired     []property.GVKRequired
+	)
+	for _, prop := range bundle.Properties {
+		var err error
+		switch prop.Type {
+		case property.TypePackageRequired:
+			var p property.PackageRequired
+			err = json.Unmarshal(prop.Value, &p)
+			packagesRequired = append(packagesRequired, p)
+		case property.TypeGVKRequired:
+			var p property.GVKRequired
+			err = json.Unmarshal(prop.Value, &p)
+			gvksRequired = append(gvksRequired, p)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error parsing property %q of bundle %q: %w", prop.Type, bundle.Name, err)
+		}
+	}
+	// The APIs the bundle provides itself are served once it is installed.
+	gvksRequired = slices.DeleteFunc(gvksRequired, func(req property.GVKRequired) bool {
+		return providesGVK(*bundle, schema.GroupVersionKind{Group: req.Group, Version: req.Version, Kind: req.Kind})
+	})
+	if len(packagesRequired) == 0 && len(gvksRequired) == 0 {
+		return nil, nil
+	}
+
+	selector, err := catalogSelector(ext)
+	if err != nil {
+		return nil, err
+	}
+	listOptions := []client.ListOption{client.MatchingLabelsSelector{Selector: selector}}
+
+	exts := &ocv1.ClusterExtensionList{}
+	if err := r.Client.List(ctx, exts); err != nil {
+		return nil, fmt.Errorf("error listing ClusterExtensions: %w", err)
+	}
+	// Only ClusterExtensions sourced from a catalog declare their package.
+	others := slices.DeleteFunc(exts.Items, func(e ocv1.ClusterExtension) bool {
+		return e.Name == ext.Name || e.Spec.Source.Catalog == nil
+	})
+	slices.SortFunc(others, func(a, b ocv1.ClusterExtension) int { return cmp.Compare(a.Name, b.Name) })
+
+	deps := make([]ocv1.DependencyStatus, 0, len(packagesRequired)+len(gvksRequired))
+	for _, req := range packagesRequired {
+		dep, err := r.resolvePackageRequired(ctx, req, others, listOptions)
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, dep)
+	}
+	for _, req := range gvksRequired {
+		dep, err := r.resolveGVKRequired(ctx, req, others, listOptions)
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, dep)
+	}
+
+	slices.SortFunc(deps, func(a, b ocv1.DependencyStatus) int { return cmp.Compare(a.Requirement, b.Requirement) })
+	return slices.CompactFunc(deps, func(a, b ocv1.DependencyStatus) bool { return a.Requirement == b.Requirement }), nil
+}
+
+func (r *CatalogDependencyResolver) resolvePackageRequired(ctx context.Context, req property.PackageRequired, exts []ocv1.ClusterExtension, listOptions []client.ListOption) (ocv1.DependencyStatus, error) {
+	dep := ocv1.DependencyStatus{Requirement: fmt.Sprintf("package %q in range %q", req.PackageName, req.VersionRange)}
+	versionRange, err := mmsemver.NewConstraint(req.VersionRange)
+	if err != nil {
+		dep.State = ocv1.DependencyStateUnsatisfiable
+		dep.Message = fmt.Sprintf("invalid version range: %v", err)
+		return dep, nil
+	}
+
+	// Only a single ClusterExtension can install a package, so an existing
+	// ClusterExtension for the package either satisfies the dependency or
+	// prevents it from being satisfied.
+	for _, ext := range exts {
+		if ext.Spec.Source.Catalog.PackageName != req.PackageName {
+			continue
+		}
+		dep.ClusterExtensionName = ext.Name
+		if ext.Status.Install == nil {
+			dep.State = ocv1.DependencyStateMissing
+			dep.Message = fmt.Sprintf("ClusterExtension %q for package %q is not installed yet", ext.Name, req.PackageName)
+			return dep, nil
+		}
+		installedVersion := ext.Status.Install.Bundle.Version
+		if v, err := mmsemver.NewVersion(installedVersion); err == nil && versionRange.Check(v) {
+			dep.State = ocv1.DependencyStateSatisfied
+			dep.Message = fmt.Sprintf("ClusterExtension %q has version %s installed", ext.Name, installedVersion)
+			return dep, nil
+		}
+		dep.State = ocv1.DependencyStateUnsatisfiable
+		dep.Message = fmt.Sprintf("ClusterExtension %q has version %s of package %q installed, which is not in the required range", ext.Name, installedVersion, req.PackageName)
+		return dep, nil
+	}
+
+	var (
+		candidates []dependencyCandidate
+		available  []bsemver.Version
+	)
+	if err := r.WalkCatalogsFunc(ctx, req.PackageName, func(ctx context.Context, cat *catalogd.ClusterCatalog, packageFBC *declcfg.DeclarativeConfig, err error) error {
+		if err != nil {
+			return fmt.Errorf("error getting package %q from catalog %q: %w", req.PackageName, cat.Name, err)
+		}
+		candidates = append(candidates, bestCandidates(cat, packageFBC, func(_ declcfg.Bundle, v bsemver.Version) bool {
+			available = append(available, v)
+			mmv, err := mmsemver.NewVersion(v.String())
+			return err == nil && versionRange.Check(mmv)
+		})...)
+		return nil
+	}, listOptions...); err != nil {
+		return dep, fmt.Errorf("error walking catalogs: %w", err)
+	}
+
+	if len(candidates) == 0 {
+		dep.State = ocv1.DependencyStateUnsatisfiable
+		if len(available) == 0 {
+			dep.Message = fmt.Sprintf("package %q was not found in the catalogs", req.PackageName)
+			return dep, nil
+		}
+		dep.Message = fmt.Sprintf("no bundle of package %q in the required range was found in the catalogs, available versions are %s", req.PackageName, versionList(available))
+		return dep, nil
+	}
+	slices.SortFunc(candidates, compareCandidates)
+	dep.State = ocv1.DependencyStateMissing
+	dep.Candidate = candidates[0].status()
+	dep.Message = fmt.Sprintf("bundle %q from catalog %q satisfies the dependency", candidates[0].bundle.Name, candidates[0].catalog)
+	return dep, nil
+}
+
+func (r *CatalogDependencyResolver) resolveGVKRequired(ctx context.Context, req property.GVKRequired, exts []ocv1.ClusterExtension, listOptions []client.ListOption) (ocv1.DependencyStatus, error) {
+	gvk := schema.GroupVersionKind{Group: req.Group, Version: req.Version, Kind: req.Kind}
+	dep := ocv1.DependencyStatus{Requirement: fmt.Sprintf("API %s", gvk)}
+
+	if _, err := r.RESTMapper.RESTMapping(gvk.GroupKind(), gvk.Version); err == nil {
+		dep.State = ocv1.DependencyStateSatisfied
+		dep.Message = "the API is served by the cluster"
+		return dep, nil
+	} else if !meta.IsNoMatchError(err) {
+		return dep, fmt.Errorf("error checking whether API %s is served: %w", gvk, err)
+	}
+
+	var candidates []dependencyCandidate
+	if err := r.WalkAllCatalogsFunc(ctx, func(ctx context.Context, cat *catalogd.ClusterCatalog, fbc *declcfg.DeclarativeConfig, err error) error {
+		if err != nil {
+			return fmt.Errorf("error getting catalog %q: %w", cat.Name, err)
+		}
+		candidates = append(candidates, bestCandidates(cat, fbc, func(b declcfg.Bundle, _ bsemver.Version) bool {
+			return providesGVK(b, gvk)
+		})...)
+		return nil
+	}, listOptions...); err != nil {
+		return dep, fmt.Errorf("error walking catalogs: %w", err)
+	}
+	if len(candidates) == 0 {
+		dep.State = ocv1.DependencyStateUnsatisfiable
+		dep.Message = "no bundle in the catalogs provides the API"
+		return dep, nil
+	}
+	slices.SortFunc(candidates, compareCandidates)
+
+	// The API may be provided by a ClusterExtension that is being installed.
+	for _, ext := range exts {
+		if !slices.ContainsFunc(candidates, func(c dependencyCandidate) bool { return c.bundle.Package == ext.Spec.Source.Catalog.PackageName }) {
+			continue
+		}
+		dep.State = ocv1.DependencyStateMissing
+		dep.ClusterExtensionName = ext.Name
+		dep.Message = fmt.Sprintf("ClusterExtension %q for package %q does not serve the API yet", ext.Name, ext.Spec.Source.Catalog.PackageName)
+		return dep, nil
+	}
+
+	dep.State = ocv1.DependencyStateMissing
+	dep.Candidate = candidates[0].status()
+	dep.Message = fmt.Sprintf("bundle %q from catalog %q satisfies the dependency", candidates[0].bundle.Name, candidates[0].catalog)
+	return dep, nil
+}
+
+// bestCandidates returns, for each package of fbc, the best bundle for which
+// match returns true.
+func bestCandidates(cat *catalogd.ClusterCatalog, fbc *declcfg.DeclarativeConfig, match func(declcfg.Bundle, bsemver.Version) bool) []dependencyCandidate {
+	best := map[string]dependencyCandidate{}
+	for _, b := range fbc.Bundles {
+		v, err := bundleutil.GetVersion(b)
+		if err != nil || !match(b, *v) {
+			continue
+		}
+		c := dependencyCandidate{bundle: b, version: *v, catalog: cat.Name, priority: cat.Spec.Priority}
+		for i := range fbc.Deprecations {
+			if fbc.Deprecations[i].Package == b.Package && isDeprecated(b, &fbc.Deprecations[i]) {
+				c.deprecated = true
+			}
+		}
+		if prev, ok := best[b.Package]; !ok || compareCandidates(c, prev) < 0 {
+			best[b.Package] = c
+		}
+	}
+	candidates := make([]dependencyCandidate, 0, len(best))
+	for _, c := range best {
+		candidates = append(candidates, c)
+	}
+	return candidates
+}
+
+func providesGVK(b declcfg.Bundle, gvk schema.GroupVersionKind) bool {
+	for _, prop := range b.Properties {
+		if prop.Type != property.TypeGVK {
+			continue
+		}
+		var p property.GVK
+		if err := json.Unmarshal(prop.Value, &p); err != nil {
+			continue
+		}
+		if p.Group == gvk.Group && p.Version == gvk.Version && p.Kind == gvk.Kind {
+			return true
+		}
+	}
+	return false
+}
+
+// versionList returns the sorted, deduplicated list of versions.
+func versionList(versions []bsemver.Version) string {
+	bsemver.Sort(versions)
+	strs := make([]string, 0, len(versions))
+	for _, v := range versions {
+		strs = append(strs, v.String())
+	}
+	return strings.Join(slices.Compact(strs), ", ")
+}
@@ -0,0 +1,225 @@
+package resolve
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	catalogd "github.com/operator-framework/catalogd/api/v1"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+)
+
+func installedClusterExtension(name, pkg, version string) *ocv1.ClusterExtension {
+	ext := buildFooClusterExtension(pkg, nil, "", ocv1.UpgradeConstraintPolicyCatalogProvided)
+	ext.Name = name
+	if version != "" {
+		ext.Status.Install = &ocv1.ClusterExtensionInstallStatus{
+			Bundle: ocv1.BundleMetadata{Name: bundleName(pkg, version), Version: version},
+		}
+	}
+	return ext
+}
+
+// providerPackage returns a package whose 1.0.x bundles provide the Bar API.
+func providerPackage() *declcfg.DeclarativeConfig {
+	fbc := genPackage("provider")
+	for i, b := range fbc.Bundles {
+		if b.Name == bundleName("provider", "1.0.0") || b.Name == bundleName("provider", "1.0.1") || b.Name == bundleName("provider", "1.0.2") {
+			fbc.Bundles[i].Properties = append(b.Properties, property.MustBuildGVK("example.com", "v1", "Bar"))
+		}
+	}
+	return fbc
+}
+
+func newDependencyResolver(t *testing.T, exts ...client.Object) *CatalogDependencyResolver {
+	t.Helper()
+	sch := runtime.NewScheme()
+	require.NoError(t, ocv1.AddToScheme(sch))
+
+	mapper := apimeta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Foo"}, apimeta.RESTScopeNamespace)
+
+	cat := &catalogd.ClusterCatalog{ObjectMeta: metav1.ObjectMeta{Name: "a"}}
+	return &CatalogDependencyResolver{
+		Client:     fake.NewClientBuilder().WithScheme(sch).WithObjects(exts...).Build(),
+		RESTMapper: mapper,
+		WalkCatalogsFunc: func(ctx context.Context, pkg string, f CatalogWalkFunc, _ ...client.ListOption) error {
+			fbc := &declcfg.DeclarativeConfig{}
+			if pkg != "missing" {
+				fbc = genPackage(pkg)
+			}
+			return f(ctx, cat, fbc, nil)
+		},
+		WalkAllCatalogsFunc: func(ctx context.Context, f CatalogWalkFunc, _ ...client.ListOption) error {
+			return f(ctx, cat, providerPackage(), nil)
+		},
+	}
+}
+
+func TestResolveDependenciesNone(t *testing.T) {
+	r := newDependencyResolver(t)
+	bundle := genBundle("foo", "1.0.0")
+	deps, err := r.ResolveDependencies(context.Background(), buildFooClusterExtension("foo", nil, "", ""), &bundle)
+	require.NoError(t, err)
+	assert.Nil(t, deps)
+}
+
+func TestResolveDependenciesProvidedByBundle(t *testing.T) {
+	r := newDependencyResolver(t)
+	bundle := genBundle("foo", "1.0.0")
+	bundle.Properties = append(bundle.Properties,
+		property.MustBuildGVK("example.com", "v1", "Bar"),
+		property.MustBuildGVKRequired("example.com", "v1", "Bar"),
+	)
+	deps, err := r.ResolveDependencies(context.Background(), buildFooClusterExtension("foo", nil, "", ""), &bundle)
+	require.NoError(t, err)
+	assert.Nil(t, deps)
+}
+
+func TestResolveDependencies(t *testing.T) {
+	r := newDependencyResolver(t, installedClusterExtension("baz-ext", "baz", "1.2.0"))
+	bundle := genBundle("foo", "1.0.0")
+	bundle.Properties = append(bundle.Properties,
+		property.MustBuildPackageRequired("qux", ">=5.0.0"),
+		property.MustBuildPackageRequired("bar", ">=1.0.0 <2.0.0"),
+		property.MustBuildPackageRequired("baz", ">=1.0.0"),
+		property.MustBuildPackageRequired("missing", ">=1.0.0"),
+		property.MustBuildGVKRequired("example.com", "v1", "Foo"),
+		property.MustBuildGVKRequired("example.com", "v1", "Bar"),
+		property.MustBuildGVKRequired("example.com", "v1", "Baz"),
+		property.MustBuildGVKRequired("example.com", "v1", "Bar"),
+	)
+
+	deps, err := r.ResolveDependencies(context.Background(), buildFooClusterExtension("foo", nil, "", ""), &bundle)
+	require.NoError(t, err)
+	assert.Equal(t, []ocv1.DependencyStatus{
+		{
+			Requirement: "API example.com/v1, Kind=Bar",
+			State:       ocv1.DependencyStateMissing,
+			Candidate: &ocv1.DependencyCandidate{
+				PackageName: "provider",
+				Bundle:      ocv1.BundleMetadata{Name: "provider.v1.0.2", Version: "1.0.2"},
+				Catalog:     "a",
+			},
+			Message: `bundle "provider.v1.0.2" from catalog "a" satisfies the dependency`,
+		},
+		{
+			Requirement: "API example.com/v1, Kind=Baz",
+			State:       ocv1.DependencyStateUnsatisfiable,
+			Message:     "no bundle in the catalogs provides the API",
+		},
+		{
+			Requirement: "API example.com/v1, Kind=Foo",
+			State:       ocv1.DependencyStateSatisfied,
+			Message:     "the API is served by the cluster",
+		},
+		{
+			Requirement: `package "bar" in range ">=1.0.0 <2.0.0"`,
+			State:       ocv1.DependencyStateMissing,
+			Candidate: &ocv1.DependencyCandidate{
+				PackageName: "bar",
+				Bundle:      ocv1.BundleMetadata{Name: "bar.v1.0.2", Version: "1.0.2"},
+				Catalog:     "a",
+			},
+			Message: `bundle "bar.v1.0.2" from catalog "a" satisfies the dependency`,
+		},
+		{
+			Requirement:          `package "baz" in range ">=1.0.0"`,
+			State:                ocv1.DependencyStateSatisfied,
+			ClusterExtensionName: "baz-ext",
+			Message:              `ClusterExtension "baz-ext" has version 1.2.0 installed`,
+		},
+		{
+			Requirement: `package "missing" in range ">=1.0.0"`,
+			State:       ocv1.DependencyStateUnsatisfiable,
+			Message:     `package "missing" was not found in the catalogs`,
+		},
+		{
+			Requirement: `package "qux" in range ">=5.0.0"`,
+			State:       ocv1.DependencyStateUnsatisfiable,
+			Message:     `no bundle of package "qux" in the required range was found in the catalogs, available versions are 0.1.0, 1.0.0, 1.0.1, 1.0.2, 2.0.0, 3.0.0`,
+		},
+	}, deps)
+}
+
+func TestResolveDependenciesExistingClusterExtensions(t *testing.T) {
+	r := newDependencyResolver(t,
+		installedClusterExtension("bar-ext", "bar", "2.0.0"),
+		installedClusterExtension("provider-ext", "provider", ""),
+	)
+	bundle := genBundle("foo", "1.0.0")
+	bundle.Properties = append(bundle.Properties,
+		property.MustBuildPackageRequired("bar", "<2.0.0"),
+		property.MustBuildPackageRequired("provider", ">=1.0.0"),
+		property.MustBuildGVKRequired("example.com", "v1", "Bar"),
+		property.MustBuildPackageRequired("invalid", "foobar"),
+	)
+
+	deps, err := r.ResolveDependencies(context.Background(), buildFooClusterExtension("foo", nil, "", ""), &bundle)
+	require.NoError(t, err)
+	assert.Equal(t, []ocv1.DependencyStatus{
+		{
+			Requirement:          "API example.com/v1, Kind=Bar",
+			State:                ocv1.DependencyStateMissing,
+			ClusterExtensionName: "provider-ext",
+			Message:              `ClusterExtension "provider-ext" for package "provider" does not serve the API yet`,
+		},
+		{
+			Requirement:          `package "bar" in range "<2.0.0"`,
+			State:                ocv1.DependencyStateUnsatisfiable,
+			ClusterExtensionName: "bar-ext",
+			Message:              `ClusterExtension "bar-ext" has version 2.0.0 of package "bar" installed, which is not in the required range`,
+		},
+		{
+			Requirement: `package "invalid" in range "foobar"`,
+			State:       ocv1.DependencyStateUnsatisfiable,
+			Message:     "invalid version range: improper constraint: foobar",
+		},
+		{
+			Requirement:          `package "provider" in range ">=1.0.0"`,
+			State:                ocv1.DependencyStateMissing,
+			ClusterExtensionName: "provider-ext",
+			Message:              `ClusterExtension "provider-ext" for package "provider" is not installed yet`,
+		},
+	}, deps)
+}
+
+func TestResolveDependenciesErrors(t *testing.T) {
+	ctx := context.Background()
+	ext := buildFooClusterExtension("foo", nil, "", "")
+
+	bundle := genBundle("foo", "1.0.0")
+	bundle.Properties = append(bundle.Properties, property.Property{Type: property.TypePackageRequired, Value: []byte(`"not-an-object"`)})
+	_, err := newDependencyResolver(t).ResolveDependencies(ctx, ext, &bundle)
+	require.ErrorContains(t, err, `error parsing property "olm.package.required" of bundle "foo.v1.0.0"`)
+
+	bundle = genBundle("foo", "1.0.0")
+	bundle.Properties = append(bundle.Properties, property.MustBuildPackageRequired("bar", ">=1.0.0"))
+	r := newDependencyResolver(t)
+	r.WalkCatalogsFunc = func(ctx context.Context, _ string, f CatalogWalkFunc, _ ...client.ListOption) error {
+		return f(ctx, &catalogd.ClusterCatalog{ObjectMeta: metav1.ObjectMeta{Name: "a"}}, nil, errors.New("fake error"))
+	}
+	_, err = r.ResolveDependencies(ctx, ext, &bundle)
+	require.EqualError(t, err, `error walking catalogs: error getting package "bar" from catalog "a": fake error`)
+
+	bundle = genBundle("foo", "1.0.0")
+	bundle.Properties = append(bundle.Properties, property.MustBuildGVKRequired("example.com", "v1", "Bar"))
+	r = newDependencyResolver(t)
+	r.WalkAllCatalogsFunc = func(ctx context.Context, f CatalogWalkFunc, _ ...client.ListOption) error {
+		return f(ctx, &catalogd.ClusterCatalog{ObjectMeta: metav1.ObjectMeta{Name: "a"}}, nil, errors.New("fake error"))
+	}
+	_, err = r.ResolveDependencies(ctx, ext, &bundle)
+	require.EqualError(t, err, `error walking catalogs: error getting catalog "a": fake error`)
+}
@@ -0,0 +1,157 @@
+package resolve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	bsemver "github.com/blang/semver/v4"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+	"github.com/operator-framework/operator-controller/internal/rukpak/convert"
+	rukpaksource "github.com/operator-framework/operator-controller/internal/rukpak/source"
+)
+
+// ImageResolver resolves a ClusterExtension whose source is a bundle image
+// reference. The bundle image is unpacked in order to read its metadata, so
+// the returned bundle always references the image by digest.
+type ImageResolver struct {
+	Unpacker rukpaksource.Unpacker
+	// PullSecretGetter returns the contents of the docker config JSON to use
+	// when pulling the bundle image of the given ClusterExtension. It may be
+	// nil, in which case the unpacker's default credentials are used.
+	PullSecretGetter func(context.Context, *ocv1.ClusterExtension) ([]byte, error)
+	Validations      []ValidationFunc
+	// FactsFunc returns the facts that the olm.constraint properties of the
+	// bundle are evaluated against. It may be nil, in which case no facts
+	// about the cluster are known.
+	FactsFunc FactsFunc
+}
+
+// ResolveUnpackName returns the name the bundle image of the ClusterExtension
+// with the given name is unpacked with to be resolved. It is kept apart from
+// the installed bundle, which is unpacked with the name of the ClusterExtension,
+// and cannot be the name of another ClusterExtension. The resolved bundle is
+// applied from the image unpacked with this name, rather than unpacked again.
+func ResolveUnpackName(extName string) string {
+	return extName + "_resolve"
+}
+
+// Resolve returns a Bundle built from the metadata of the bundle image referenced by the ClusterExtension.
+func (r *ImageResolver) Resolve(ctx context.Context, ext *ocv1.ClusterExtension, _ *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *Origin, error) {
+	if ext.Spec.Source.Image == nil {
+		return nil, nil, nil, nil, reconcile.TerminalError(errors.New("image source is required when sourceType is Image"))
+	}
+
+	var pullSecret []byte
+	if ext.Spec.Source.Image.PullSecret != "" && r.PullSecretGetter != nil {
+		var err error
+		pullSecret, err = r.PullSecretGetter(ctx, ext)
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("error getting pull secret %q: %w", ext.Spec.Source.Image.PullSecret, err)
+		}
+	}
+
+	// The image is unpacked apart from the installed bundle, which unpacking
+	// another image under the same name would evict from the cache.
+	unpackResult, err := r.Unpacker.Unpack(ctx, &rukpaksource.BundleSource{
+		Name: ResolveUnpackName(ext.GetName()),
+		Type: rukpaksource.SourceTypeImage,
+		Image: &rukpaksource.ImageSource{
+			Ref:        ext.Spec.Source.Image.Ref,
+			PullSecret: pullSecret,
+		},
+	})
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("error unpacking bundle image %q: %w", ext.Spec.Source.Image.Ref, err)
+	}
+
+	imageRef := ext.Spec.Source.Image.Ref
+	if unpackResult.ResolvedSource != nil && unpackResult.ResolvedSource.Image != nil {
+		imageRef = unpackResult.ResolvedSource.Image.Ref
+	}
+
+	reg, err := convert.ParseFS(ctx, unpackResult.Bundle)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("error reading bundle image %q: %w", imageRef, err)
+	}
+
+	bundle, err := bundleFromRegistryV1(reg, imageRef)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	bundleVersion := reg.CSV.Spec.Version.Version
+
+	for _, validation := range r.Validations {
+		if err := validation(bundle); err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("validating bundle %q: %w", bundle.Name, err)
+		}
+	}
+
+	constraints := &lazyConstraintEvaluator{factsFunc: r.FactsFunc, ext: ext}
+	unsatisfied, err := constraints.unsatisfiedConstraints(ctx, *bundle)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if len(unsatisfied) > 0 {
+		return nil, nil, nil, nil, fmt.Errorf("bundle %q has unsatisfied constraints: %s", bundle.Name, strings.Join(unsatisfied, "; "))
+	}
+
+	return bundle, &bundleVersion, nil, nil, nil
+}
+
+// bundleFromRegistryV1 builds a declcfg.Bundle for the registry+v1 bundle reg,
+// which was unpacked from imageRef.
+func bundleFromRegistryV1(reg convert.RegistryV1, imageRef string) (*declcfg.Bundle, error) {
+	if reg.PackageName == "" {
+		return nil, fmt.Errorf("bundle image %q does not declare a package name", imageRef)
+	}
+	if reg.CSV.Name == "" {
+		return nil, fmt.Errorf("bundle image %q does not contain a ClusterServiceVersion", imageRef)
+	}
+
+	properties := []property.Property{
+		property.MustBuildPackage(reg.PackageName, reg.CSV.Spec.Version.String()),
+		property.MustBuildCSVMetadata(reg.CSV),
+	}
+	if csvPropertiesJSON, ok := reg.CSV.Annotations["olm.properties"]; ok {
+		var csvProperties []property.Property
+		if err := json.Unmarshal([]byte(csvPropertiesJSON), &csvProperties); err != nil {
+			return nil, fmt.Errorf("error unmarshalling properties of bundle %q: %w", reg.CSV.Name, err)
+		}
+		for _, p := range csvProperties {
+			// The package property is derived from the bundle metadata above.
+			if p.Type == property.TypePackage {
+				continue
+			}
+			properties = append(properties, p)
+		}
+	}
+
+	return &declcfg.Bundle{
+		Schema:     declcfg.SchemaBundle,
+		Name:       reg.CSV.Name,
+		Package:    reg.PackageName,
+		Image:      imageRef,
+		Properties: properties,
+	}, nil
+}
+
+// MultiResolver dispatches resolution to a Resolver based on the
+// source type of the ClusterExtension.
+type MultiResolver map[string]Resolver
+
+// Resolve resolves the ClusterExtension with the Resolver registered for its source type.
+func (m MultiResolver) Resolve(ctx context.Context, ext *ocv1.ClusterExtension, installedBundle *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *Origin, error) {
+	r, ok := m[ext.Spec.Source.SourceType]
+	if !ok {
+		return nil, nil, nil, nil, reconcile.TerminalError(fmt.Errorf("unsupported source type %q", ext.Spec.Source.SourceType))
+	}
+	return r.Resolve(ctx, ext, installedBundle)
+}
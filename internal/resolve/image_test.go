@@ -0,0 +1,257 @@
+package resolve
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	bsemver "github.com/blang/semver/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+	rukpaksource "github.com/operator-framework/operator-controller/internal/rukpak/source"
+)
+
+const (
+	testBundleImageTagRef    = "quay.io/example/foo-bundle:latest"
+	testBundleImageDigestRef = "quay.io/example/foo-bundle@sha256:f0e1d2c3b4a5968778695a4b3c2d1e0ff0e1d2c3b4a5968778695a4b3c2d1e0f"
+)
+
+type fakeUnpacker struct {
+	bundle fstest.MapFS
+	err    error
+
+	unpacked *rukpaksource.BundleSource
+}
+
+func (f *fakeUnpacker) Unpack(_ context.Context, bundle *rukpaksource.BundleSource) (*rukpaksource.Result, error) {
+	f.unpacked = bundle
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &rukpaksource.Result{
+		Bundle: f.bundle,
+		ResolvedSource: &rukpaksource.BundleSource{
+			Name:  bundle.Name,
+			Type:  rukpaksource.SourceTypeImage,
+			Image: &rukpaksource.ImageSource{Ref: testBundleImageDigestRef},
+		},
+		State: rukpaksource.StateUnpacked,
+	}, nil
+}
+
+func (f *fakeUnpacker) Cleanup(context.Context, *rukpaksource.BundleSource) error {
+	return nil
+}
+
+func fooBundleFS(csvAnnotations string) fstest.MapFS {
+	return fstest.MapFS{
+		"metadata/annotations.yaml": &fstest.MapFile{Data: []byte(`annotations:
+  operators.operatorframework.io.bundle.mediatype.v1: registry+v1
+  operators.operatorframework.io.bundle.package.v1: foo
+`)},
+		"manifests/csv.yaml": &fstest.MapFile{Data: []byte(`apiVersion: operators.operatorframework.io/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: foo.v1.2.3
+  annotations:
+    olm.properties: '` + csvAnnotations + `'
+spec:
+  version: 1.2.3
+  installModes:
+    - type: AllNamespaces
+      supported: true
+`)},
+	}
+}
+
+func buildFooImageClusterExtension(pullSecret string) *ocv1.ClusterExtension {
+	return &ocv1.ClusterExtension{
+		Spec: ocv1.ClusterExtensionSpec{
+			Namespace:      "default",
+			ServiceAccount: ocv1.ServiceAccountReference{Name: "default"},
+			Source: ocv1.SourceConfig{
+				SourceType: ocv1.SourceTypeImage,
+				Image: &ocv1.ImageSource{
+					Ref:        testBundleImageTagRef,
+					PullSecret: pullSecret,
+				},
+			},
+		},
+	}
+}
+
+func TestImageResolverResolvesBundleFromImage(t *testing.T) {
+	u := &fakeUnpacker{bundle: fooBundleFS(`[{"type":"from-csv-key","value":"from-csv-value"}]`)}
+	r := ImageResolver{Unpacker: u}
+	ce := buildFooImageClusterExtension("")
+	ce.Name = "foo"
+
+	gotBundle, gotVersion, gotDeprecation, gotOrigin, err := r.Resolve(context.Background(), ce, nil)
+	require.NoError(t, err)
+
+	require.Len(t, gotBundle.Properties, 3)
+	assert.Equal(t, property.TypeCSVMetadata, gotBundle.Properties[1].Type)
+	gotBundle.Properties = slices.Delete(gotBundle.Properties, 1, 2)
+	assert.Equal(t, &declcfg.Bundle{
+		Schema:  declcfg.SchemaBundle,
+		Name:    "foo.v1.2.3",
+		Package: "foo",
+		Image:   testBundleImageDigestRef,
+		Properties: []property.Property{
+			property.MustBuildPackage("foo", "1.2.3"),
+			{Type: "from-csv-key", Value: []byte(`"from-csv-value"`)},
+		},
+	}, gotBundle)
+	assert.Equal(t, bsemver.MustParse("1.2.3"), *gotVersion)
+	assert.Nil(t, gotDeprecation)
+	assert.Nil(t, gotOrigin)
+
+	require.NotNil(t, u.unpacked)
+	assert.Equal(t, ResolveUnpackName("foo"), u.unpacked.Name)
+	assert.Equal(t, testBundleImageTagRef, u.unpacked.Image.Ref)
+	assert.Empty(t, u.unpacked.Image.PullSecret)
+}
+
+func TestImageResolverUsesPullSecret(t *testing.T) {
+	u := &fakeUnpacker{bundle: fooBundleFS(`[]`)}
+	r := ImageResolver{
+		Unpacker: u,
+		PullSecretGetter: func(_ context.Context, ext *ocv1.ClusterExtension) ([]byte, error) {
+			assert.Equal(t, "my-pull-secret", ext.Spec.Source.Image.PullSecret)
+			return []byte(`{"auths":{}}`), nil
+		},
+	}
+
+	_, _, _, _, err := r.Resolve(context.Background(), buildFooImageClusterExtension("my-pull-secret"), nil)
+	require.NoError(t, err)
+	require.NotNil(t, u.unpacked)
+	assert.Equal(t, []byte(`{"auths":{}}`), u.unpacked.Image.PullSecret)
+}
+
+func TestImageResolverPullSecretError(t *testing.T) {
+	r := ImageResolver{
+		Unpacker: &fakeUnpacker{bundle: fooBundleFS(`[]`)},
+		PullSecretGetter: func(context.Context, *ocv1.ClusterExtension) ([]byte, error) {
+			return nil, errors.New("fake error")
+		},
+	}
+
+	_, _, _, _, err := r.Resolve(context.Background(), buildFooImageClusterExtension("my-pull-secret"), nil)
+	assert.EqualError(t, err, `error getting pull secret "my-pull-secret": fake error`)
+}
+
+func TestImageResolverUnpackError(t *testing.T) {
+	r := ImageResolver{Unpacker: &fakeUnpacker{err: errors.New("fake error")}}
+
+	_, _, _, _, err := r.Resolve(context.Background(), buildFooImageClusterExtension(""), nil)
+	assert.EqualError(t, err, `error unpacking bundle image "quay.io/example/foo-bundle:latest": fake error`)
+}
+
+func TestImageResolverValidationError(t *testing.T) {
+	r := ImageResolver{
+		Unpacker: &fakeUnpacker{bundle: fooBundleFS(`[]`)},
+		Validations: []ValidationFunc{
+			func(*declcfg.Bundle) error { return errors.New("fake error") },
+		},
+	}
+
+	_, _, _, _, err := r.Resolve(context.Background(), buildFooImageClusterExtension(""), nil)
+	assert.EqualError(t, err, `validating bundle "foo.v1.2.3": fake error`)
+}
+
+func TestImageResolverUnsatisfiedConstraints(t *testing.T) {
+	r := ImageResolver{
+		Unpacker: &fakeUnpacker{bundle: fooBundleFS(`[{"type":"olm.constraint","value":{"failureMessage":"requires the bar package","package":{"packageName":"bar","versionRange":">=1.0.0"}}}]`)},
+		FactsFunc: func(context.Context, *ocv1.ClusterExtension) (*Facts, error) {
+			return &Facts{Extensions: []ExtensionFacts{{Name: "bar", PackageName: "bar", Version: "0.9.0"}}}, nil
+		},
+	}
+
+	_, _, _, _, err := r.Resolve(context.Background(), buildFooImageClusterExtension(""), nil)
+	assert.EqualError(t, err, `bundle "foo.v1.2.3" has unsatisfied constraints: requires the bar package`)
+
+	r.FactsFunc = func(context.Context, *ocv1.ClusterExtension) (*Facts, error) {
+		return &Facts{Extensions: []ExtensionFacts{{Name: "bar", PackageName: "bar", Version: "1.0.0"}}}, nil
+	}
+	bundle, _, _, _, err := r.Resolve(context.Background(), buildFooImageClusterExtension(""), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "foo.v1.2.3", bundle.Name)
+}
+
+func TestImageResolverIncompatibleKubernetesVersion(t *testing.T) {
+	bundleFS := fooBundleFS(`[{"type":"olm.maxKubeVersion","value":"1.29"}]`)
+	csv := bundleFS["manifests/csv.yaml"]
+	csv.Data = []byte(strings.Replace(string(csv.Data), "spec:\n", "spec:\n  minKubeVersion: 1.28.0\n", 1))
+	r := ImageResolver{
+		Unpacker: &fakeUnpacker{bundle: bundleFS},
+		FactsFunc: func(context.Context, *ocv1.ClusterExtension) (*Facts, error) {
+			return &Facts{KubernetesVersion: "1.27.3"}, nil
+		},
+	}
+
+	_, _, _, _, err := r.Resolve(context.Background(), buildFooImageClusterExtension(""), nil)
+	assert.EqualError(t, err, `bundle "foo.v1.2.3" has unsatisfied constraints: requires Kubernetes 1.28.0 or later, but the cluster runs Kubernetes 1.27.3`)
+
+	r.FactsFunc = func(context.Context, *ocv1.ClusterExtension) (*Facts, error) {
+		return &Facts{KubernetesVersion: "1.30.0"}, nil
+	}
+	_, _, _, _, err = r.Resolve(context.Background(), buildFooImageClusterExtension(""), nil)
+	assert.EqualError(t, err, `bundle "foo.v1.2.3" has unsatisfied constraints: requires Kubernetes 1.29 or earlier, but the cluster runs Kubernetes 1.30.0`)
+
+	r.FactsFunc = func(context.Context, *ocv1.ClusterExtension) (*Facts, error) {
+		return &Facts{KubernetesVersion: "1.29.7"}, nil
+	}
+	_, _, _, _, err = r.Resolve(context.Background(), buildFooImageClusterExtension(""), nil)
+	require.NoError(t, err)
+}
+
+func TestImageResolverMissingImageSource(t *testing.T) {
+	r := ImageResolver{Unpacker: &fakeUnpacker{}}
+	ce := buildFooImageClusterExtension("")
+	ce.Spec.Source.Image = nil
+
+	_, _, _, _, err := r.Resolve(context.Background(), ce, nil)
+	assert.EqualError(t, err, "terminal error: image source is required when sourceType is Image")
+}
+
+func TestMultiResolver(t *testing.T) {
+	catalogResolver := Func(func(context.Context, *ocv1.ClusterExtension, *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *Origin, error) {
+		return &declcfg.Bundle{Name: "from-catalog"}, nil, nil, nil, nil
+	})
+	imageResolver := Func(func(context.Context, *ocv1.ClusterExtension, *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *Origin, error) {
+		return &declcfg.Bundle{Name: "from-image"}, nil, nil, nil, nil
+	})
+	r := MultiResolver{
+		ocv1.SourceTypeCatalog: catalogResolver,
+		ocv1.SourceTypeImage:   imageResolver,
+	}
+
+	for _, tc := range []struct {
+		sourceType   string
+		expectBundle string
+		expectErr    string
+	}{
+		{sourceType: ocv1.SourceTypeCatalog, expectBundle: "from-catalog"},
+		{sourceType: ocv1.SourceTypeImage, expectBundle: "from-image"},
+		{sourceType: "Unknown", expectErr: `terminal error: unsupported source type "Unknown"`},
+	} {
+		t.Run(tc.sourceType, func(t *testing.T) {
+			ce := &ocv1.ClusterExtension{Spec: ocv1.ClusterExtensionSpec{Source: ocv1.SourceConfig{SourceType: tc.sourceType}}}
+			gotBundle, _, _, _, err := r.Resolve(context.Background(), ce, nil)
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectBundle, gotBundle.Name)
+		})
+	}
+}
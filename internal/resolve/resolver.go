@@ -10,12 +10,23 @@ import (
 	ocv1 "github.com/operator-framework/operator-controller/api/v1"
 )
 
+// Origin describes where a resolved bundle was found.
+type Origin struct {
+	// Catalog is the name of the ClusterCatalog the bundle was resolved from.
+	Catalog string
+	// Channels are the sorted names of the channels of the catalog that
+	// contain the bundle, restricted to the requested channels if any.
+	Channels []string
+}
+
+// Resolver resolves the bundle to install for a ClusterExtension. The returned
+// Origin is nil when the bundle was not resolved from a catalog.
 type Resolver interface {
-	Resolve(ctx context.Context, ext *ocv1.ClusterExtension, installedBundle *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, error)
+	Resolve(ctx context.Context, ext *ocv1.ClusterExtension, installedBundle *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *Origin, error)
 }
 
-type Func func(ctx context.Context, ext *ocv1.ClusterExtension, installedBundle *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, error)
+type Func func(ctx context.Context, ext *ocv1.ClusterExtension, installedBundle *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *Origin, error)
 
-func (f Func) Resolve(ctx context.Context, ext *ocv1.ClusterExtension, installedBundle *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, error) {
+func (f Func) Resolve(ctx context.Context, ext *ocv1.ClusterExtension, installedBundle *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *Origin, error) {
 	return f(ctx, ext, installedBundle)
 }
@@ -0,0 +1,72 @@
+package certprovider
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/operator-framework/operator-controller/internal/rukpak/convert"
+)
+
+// certManagerInjectCAFromAnnotation tells the cert-manager CA injector which
+// Certificate to set the CA bundle of an object from.
+const certManagerInjectCAFromAnnotation = "cert-manager.io/inject-ca-from"
+
+// CertManager provisions serving certificates with cert-manager. Each
+// certificate is issued by its own self-signed Issuer, and cert-manager's CA
+// injector sets the CA bundle of the objects that use it. cert-manager must be
+// installed on the cluster.
+type CertManager struct{}
+
+var _ convert.CertificateProvider = CertManager{}
+
+func (CertManager) Provision(cfg convert.CertificateConfig) (*convert.Certificate, error) {
+	issuerName := fmt.Sprintf("%s-selfsigned-issuer", cfg.Name)
+
+	issuer := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "Issuer",
+		"metadata": map[string]interface{}{
+			"namespace": cfg.Namespace,
+			"name":      issuerName,
+		},
+		"spec": map[string]interface{}{
+			"selfSigned": map[string]interface{}{},
+		},
+	}}
+
+	dnsNames := []interface{}{}
+	for _, name := range cfg.DNSNames() {
+		dnsNames = append(dnsNames, name)
+	}
+	certificate := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "Certificate",
+		"metadata": map[string]interface{}{
+			"namespace": cfg.Namespace,
+			"name":      cfg.Name,
+		},
+		"spec": map[string]interface{}{
+			"secretName": cfg.Name,
+			"dnsNames":   dnsNames,
+			"usages":     []interface{}{"server auth"},
+			"issuerRef": map[string]interface{}{
+				"group": "cert-manager.io",
+				"kind":  "Issuer",
+				"name":  issuerName,
+			},
+			"privateKey": map[string]interface{}{
+				"rotationPolicy": "Always",
+			},
+		},
+	}}
+
+	return &convert.Certificate{
+		SecretName: cfg.Name,
+		Objects:    []client.Object{issuer, certificate},
+		Annotations: map[string]string{
+			certManagerInjectCAFromAnnotation: fmt.Sprintf("%s/%s", cfg.Namespace, cfg.Name),
+		},
+	}, nil
+}
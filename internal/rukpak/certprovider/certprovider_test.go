@@ -0,0 +1,149 @@
+package certprovider_test
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/operator-framework/operator-controller/internal/rukpak/certprovider"
+	"github.com/operator-framework/operator-controller/internal/rukpak/convert"
+)
+
+var certConfig = convert.CertificateConfig{Namespace: "test-ns", ServiceName: "test-service", Name: "test-service-cert"}
+
+func TestCertManager(t *testing.T) {
+	cert, err := certprovider.CertManager{}.Provision(certConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "test-service-cert", cert.SecretName)
+	assert.Empty(t, cert.CABundle)
+	assert.Equal(t, map[string]string{"cert-manager.io/inject-ca-from": "test-ns/test-service-cert"}, cert.Annotations)
+
+	require.Len(t, cert.Objects, 2)
+	issuer := cert.Objects[0].(*unstructured.Unstructured)
+	assert.Equal(t, "Issuer", issuer.GetKind())
+	assert.Equal(t, "test-ns", issuer.GetNamespace())
+	assert.Equal(t, "test-service-cert-selfsigned-issuer", issuer.GetName())
+
+	certificate := cert.Objects[1].(*unstructured.Unstructured)
+	assert.Equal(t, "Certificate", certificate.GetKind())
+	assert.Equal(t, "test-service-cert", certificate.GetName())
+	secretName, _, _ := unstructured.NestedString(certificate.Object, "spec", "secretName")
+	assert.Equal(t, "test-service-cert", secretName)
+	dnsNames, _, _ := unstructured.NestedStringSlice(certificate.Object, "spec", "dnsNames")
+	assert.Equal(t, []string{"test-service.test-ns.svc", "test-service.test-ns.svc.cluster.local"}, dnsNames)
+	issuerName, _, _ := unstructured.NestedString(certificate.Object, "spec", "issuerRef", "name")
+	assert.Equal(t, "test-service-cert-selfsigned-issuer", issuerName)
+}
+
+func TestSelfSigned(t *testing.T) {
+	p := certprovider.NewSelfSigned()
+	cert, err := p.Provision(certConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "test-service-cert", cert.SecretName)
+	assert.Empty(t, cert.Annotations)
+
+	require.Len(t, cert.Objects, 1)
+	secret := cert.Objects[0].(*corev1.Secret)
+	assert.Equal(t, "test-ns", secret.Namespace)
+	assert.Equal(t, "test-service-cert", secret.Name)
+	assert.Equal(t, corev1.SecretTypeTLS, secret.Type)
+	assert.Equal(t, cert.CABundle, secret.Data["ca.crt"])
+
+	t.Log("The certificate is valid for the service and signed by the CA bundle")
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(cert.CABundle))
+	block, _ := pem.Decode(secret.Data[corev1.TLSCertKey])
+	require.NotNil(t, block)
+	serving, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	_, err = serving.Verify(x509.VerifyOptions{
+		DNSName:   "test-service.test-ns.svc",
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	require.NoError(t, err)
+
+	t.Log("The certificate is reused")
+	again, err := p.Provision(certConfig)
+	require.NoError(t, err)
+	assert.Equal(t, cert.CABundle, again.CABundle)
+	assert.Equal(t, secret.Data, again.Objects[0].(*corev1.Secret).Data)
+
+	t.Log("Other certificates are generated separately")
+	other, err := p.Provision(convert.CertificateConfig{Namespace: "test-ns", ServiceName: "other-service", Name: "other-service-cert"})
+	require.NoError(t, err)
+	assert.NotEqual(t, cert.CABundle, other.CABundle)
+}
+
+func TestSelfSignedSecretStore(t *testing.T) {
+	secrets := fake.NewSimpleClientset().CoreV1().Secrets("olmv1-system")
+	cert, err := certprovider.NewSelfSigned(certprovider.WithSecretStore(secrets)).Provision(certConfig)
+	require.NoError(t, err)
+
+	t.Log("The certificate is stored")
+	stored, err := secrets.List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, stored.Items, 1)
+	assert.Equal(t, cert.CABundle, stored.Items[0].Data["ca.crt"])
+
+	t.Log("The stored certificate is reused by another provider, as after a restart")
+	again, err := certprovider.NewSelfSigned(certprovider.WithSecretStore(secrets)).Provision(certConfig)
+	require.NoError(t, err)
+	assert.Equal(t, cert.CABundle, again.CABundle)
+	assert.Equal(t, cert.Objects[0].(*corev1.Secret).Data, again.Objects[0].(*corev1.Secret).Data)
+
+	t.Log("A stored certificate that cannot be used is replaced")
+	storedSecret := stored.Items[0]
+	storedSecret.Data[corev1.TLSPrivateKeyKey] = []byte("invalid")
+	_, err = secrets.Update(context.Background(), &storedSecret, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	replaced, err := certprovider.NewSelfSigned(certprovider.WithSecretStore(secrets)).Provision(certConfig)
+	require.NoError(t, err)
+	assert.NotEqual(t, cert.CABundle, replaced.CABundle)
+	stored, err = secrets.List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, stored.Items, 1)
+	assert.Equal(t, replaced.CABundle, stored.Items[0].Data["ca.crt"])
+}
+
+func TestSelfSignedDelete(t *testing.T) {
+	secrets := fake.NewSimpleClientset().CoreV1().Secrets("olmv1-system")
+	p := certprovider.NewSelfSigned(certprovider.WithSecretStore(secrets))
+	ownedConfig := certConfig
+	ownedConfig.Owner = "test-ext"
+	cert, err := p.Provision(ownedConfig)
+	require.NoError(t, err)
+	otherConfig := convert.CertificateConfig{Namespace: "other-ns", ServiceName: "test-service", Name: "test-service-cert", Owner: "other-ext"}
+	other, err := p.Provision(otherConfig)
+	require.NoError(t, err)
+
+	t.Log("The stored certificates are labeled with their owner")
+	stored, err := secrets.List(context.Background(), metav1.ListOptions{LabelSelector: "olm.operatorframework.io/owner-name=test-ext"})
+	require.NoError(t, err)
+	require.Len(t, stored.Items, 1)
+	assert.Equal(t, "ClusterExtension", stored.Items[0].Labels["olm.operatorframework.io/owner-kind"])
+	assert.Equal(t, cert.CABundle, stored.Items[0].Data["ca.crt"])
+
+	t.Log("Only the certificates of the deleted owner are deleted")
+	require.NoError(t, p.Delete(context.Background(), "test-ext"))
+	stored, err = secrets.List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, stored.Items, 1)
+	assert.Equal(t, other.CABundle, stored.Items[0].Data["ca.crt"])
+
+	t.Log("A deleted certificate is not reused")
+	again, err := p.Provision(ownedConfig)
+	require.NoError(t, err)
+	assert.NotEqual(t, cert.CABundle, again.CABundle)
+	again, err = p.Provision(otherConfig)
+	require.NoError(t, err)
+	assert.Equal(t, other.CABundle, again.CABundle)
+}
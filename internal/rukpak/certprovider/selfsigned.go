@@ -0,0 +1,311 @@
+package certprovider
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"slices"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+	"github.com/operator-framework/operator-controller/internal/labels"
+	"github.com/operator-framework/operator-controller/internal/rukpak/convert"
+)
+
+const (
+	// selfSignedValidity is how long the generated certificates are valid.
+	selfSignedValidity = 365 * 24 * time.Hour
+	// selfSignedRenewBefore is how long before they expire the generated
+	// certificates are replaced.
+	selfSignedRenewBefore = 30 * 24 * time.Hour
+	// selfSignedStoreTimeout bounds the requests made to the Secret store.
+	selfSignedStoreTimeout = 10 * time.Second
+	// selfSignedCertificateAnnotation records the certificate a Secret of
+	// the Secret store holds.
+	selfSignedCertificateAnnotation = "olm.operatorframework.io/certificate"
+)
+
+// SelfSigned provisions serving certificates in-process: it generates a CA and
+// a certificate signed by it, stores them in a Secret installed with the
+// bundle, and sets the CA bundle of the objects that use it.
+//
+// Unless a Secret store is configured with WithSecretStore, the generated
+// certificates are only kept in memory, so they are replaced, and the bundles
+// using them upgraded, when operator-controller restarts.
+type SelfSigned struct {
+	mu      sync.Mutex
+	certs   map[string]*selfSignedCertificate
+	now     func() time.Time
+	secrets corev1client.SecretInterface
+}
+
+type SelfSignedOption func(*SelfSigned)
+
+// WithSecretStore persists the generated certificates in Secrets managed
+// through secrets, so that they are reused while they are valid instead of
+// being replaced when operator-controller restarts.
+func WithSecretStore(secrets corev1client.SecretInterface) SelfSignedOption {
+	return func(s *SelfSigned) {
+		s.secrets = secrets
+	}
+}
+
+type selfSignedCertificate struct {
+	owner    string
+	dnsNames []string
+	notAfter time.Time
+	caPEM    []byte
+	certPEM  []byte
+	keyPEM   []byte
+}
+
+var _ convert.CertificateProvider = &SelfSigned{}
+
+func NewSelfSigned(opts ...SelfSignedOption) *SelfSigned {
+	s := &SelfSigned{
+		certs: map[string]*selfSignedCertificate{},
+		now:   time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *SelfSigned) Provision(cfg convert.CertificateConfig) (*convert.Certificate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := fmt.Sprintf("%s/%s", cfg.Namespace, cfg.Name)
+	cert, ok := s.certs[key]
+	if !ok && s.secrets != nil {
+		var err error
+		if cert, err = s.load(key); err != nil {
+			return nil, fmt.Errorf("error loading certificate %q: %w", key, err)
+		}
+	}
+	regenerate := cert == nil || !slices.Equal(cert.dnsNames, cfg.DNSNames()) || s.now().Add(selfSignedRenewBefore).After(cert.notAfter)
+	if regenerate {
+		var err error
+		if cert, err = s.generate(cfg.DNSNames()); err != nil {
+			return nil, fmt.Errorf("error generating certificate %q: %w", key, err)
+		}
+	}
+	// A certificate stored before its owner was recorded is stored again, so
+	// that it is deleted along with its owner.
+	if regenerate || cert.owner != cfg.Owner {
+		cert.owner = cfg.Owner
+		if s.secrets != nil {
+			if err := s.store(key, cert); err != nil {
+				return nil, fmt.Errorf("error storing certificate %q: %w", key, err)
+			}
+		}
+	}
+	s.certs[key] = cert
+
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: cfg.Namespace,
+			Name:      cfg.Name,
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			"ca.crt":                cert.caPEM,
+			corev1.TLSCertKey:       cert.certPEM,
+			corev1.TLSPrivateKeyKey: cert.keyPEM,
+		},
+	}
+	return &convert.Certificate{
+		SecretName: cfg.Name,
+		Objects:    []client.Object{secret},
+		CABundle:   cert.caPEM,
+	}, nil
+}
+
+// generate returns a new CA and a certificate for dnsNames signed by it.
+func (s *SelfSigned) generate(dnsNames []string) (*selfSignedCertificate, error) {
+	notBefore := s.now().Add(-time.Hour)
+	notAfter := notBefore.Add(selfSignedValidity)
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: fmt.Sprintf("%s-ca", dnsNames[0])},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+
+	certKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	certTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:     dnsNames,
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, certTemplate, caTemplate, &certKey.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(certKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &selfSignedCertificate{
+		dnsNames: dnsNames,
+		notAfter: notAfter,
+		caPEM:    pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+		certPEM:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}),
+		keyPEM:   pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}, nil
+}
+
+// storeSecretName returns the name of the Secret of the Secret store that holds
+// the certificate with the given key, which may be too long for a name.
+func storeSecretName(key string) string {
+	return fmt.Sprintf("selfsigned-%x", sha256.Sum256([]byte(key)))[:len("selfsigned-")+32]
+}
+
+// load returns the certificate with the given key from the Secret store, or
+// nil if it is not stored or cannot be used anymore.
+func (s *SelfSigned) load(key string) (*selfSignedCertificate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), selfSignedStoreTimeout)
+	defer cancel()
+	secret, err := s.secrets.Get(ctx, storeSecretName(key), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cert := &selfSignedCertificate{
+		owner:   secret.Labels[labels.OwnerNameKey],
+		caPEM:   secret.Data["ca.crt"],
+		certPEM: secret.Data[corev1.TLSCertKey],
+		keyPEM:  secret.Data[corev1.TLSPrivateKeyKey],
+	}
+	if err := cert.parse(); err != nil {
+		// The certificate is generated again.
+		return nil, nil
+	}
+	return cert, nil
+}
+
+// parse sets the DNS names and the expiration of c from its certificate,
+// after checking that it matches its key and is signed by its CA.
+func (c *selfSignedCertificate) parse() error {
+	keyPair, err := tls.X509KeyPair(c.certPEM, c.keyPEM)
+	if err != nil {
+		return err
+	}
+	leaf, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return err
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(c.caPEM) {
+		return errors.New("invalid CA certificate")
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{Roots: roots, CurrentTime: leaf.NotBefore, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}}); err != nil {
+		return err
+	}
+	c.dnsNames = leaf.DNSNames
+	c.notAfter = leaf.NotAfter
+	return nil
+}
+
+// store saves the certificate with the given key in the Secret store.
+func (s *SelfSigned) store(key string, cert *selfSignedCertificate) error {
+	ctx, cancel := context.WithTimeout(context.Background(), selfSignedStoreTimeout)
+	defer cancel()
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        storeSecretName(key),
+			Annotations: map[string]string{selfSignedCertificateAnnotation: key},
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			"ca.crt":                cert.caPEM,
+			corev1.TLSCertKey:       cert.certPEM,
+			corev1.TLSPrivateKeyKey: cert.keyPEM,
+		},
+	}
+	if cert.owner != "" {
+		secret.Labels = map[string]string{
+			labels.OwnerKindKey: ocv1.ClusterExtensionKind,
+			labels.OwnerNameKey: cert.owner,
+		}
+	}
+	_, err := s.secrets.Update(ctx, secret, metav1.UpdateOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = s.secrets.Create(ctx, secret, metav1.CreateOptions{})
+	}
+	return err
+}
+
+// Delete forgets the certificates provisioned for the ClusterExtension with
+// the given name and deletes them from the Secret store.
+func (s *SelfSigned) Delete(ctx context.Context, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	maps.DeleteFunc(s.certs, func(_ string, cert *selfSignedCertificate) bool {
+		return cert.owner == owner
+	})
+	if s.secrets == nil {
+		return nil
+	}
+
+	stored, err := s.secrets.List(ctx, metav1.ListOptions{LabelSelector: k8slabels.SelectorFromSet(k8slabels.Set{
+		labels.OwnerKindKey: ocv1.ClusterExtensionKind,
+		labels.OwnerNameKey: owner,
+	}).String()})
+	if err != nil {
+		return fmt.Errorf("error listing stored certificates: %w", err)
+	}
+	var errs []error
+	for _, secret := range stored.Items {
+		if err := s.secrets.Delete(ctx, secret.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("error deleting stored certificate %q: %w", secret.Annotations[selfSignedCertificateAnnotation], err))
+		}
+	}
+	return errors.Join(errs...)
+}
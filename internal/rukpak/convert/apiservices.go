@@ -0,0 +1,115 @@
+package convert
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// apiServicePort is the port of the Services of APIServices.
+	apiServicePort = 443
+	// defaultAPIServiceContainerPort is the container port of an APIService
+	// that does not declare one.
+	defaultAPIServiceContainerPort = 443
+
+	// The priorities legacy OLM sets on the APIServices it creates.
+	apiServiceGroupPriorityMinimum = 2000
+	apiServiceVersionPriority      = 15
+
+	// The roles extension API servers are bound to, to delegate the
+	// authentication and authorization of their requests to the cluster.
+	authDelegatorClusterRoleName = "system:auth-delegator"
+	authReaderRoleName           = "extension-apiserver-authentication-reader"
+)
+
+// convertAPIServices returns the APIServices of the owned APIService
+// definitions of the CSV of in, and the bindings of the service accounts of
+// the deployments serving them to the roles of extension API servers. The
+// APIServices are served by the Services of services.
+func convertAPIServices(in RegistryV1, services *serviceSet) ([]client.Object, error) {
+	descs := in.CSV.Spec.APIServiceDefinitions.Owned
+	if len(descs) == 0 {
+		return nil, nil
+	}
+	if services.certProvider == nil {
+		return nil, errors.New("apiServiceDefinitions are not supported without a certificate provider")
+	}
+
+	var (
+		objs []client.Object
+		// A group version is served by a single deployment, whatever the
+		// number of kinds it has.
+		servedBy      = map[string]string{}
+		boundServices = map[string]struct{}{}
+	)
+	for _, desc := range descs {
+		name := fmt.Sprintf("%s.%s", desc.Version, desc.Group)
+		if deploymentName, ok := servedBy[name]; ok {
+			if deploymentName != desc.DeploymentName {
+				return nil, fmt.Errorf("APIService %q is served by both deployment %q and deployment %q", name, deploymentName, desc.DeploymentName)
+			}
+			continue
+		}
+		servedBy[name] = desc.DeploymentName
+
+		containerPort := desc.ContainerPort
+		if containerPort == 0 {
+			containerPort = defaultAPIServiceContainerPort
+		}
+		targetPort := intstr.FromInt32(containerPort)
+		svc, cert, err := services.serve(fmt.Sprintf("APIService %q", name), desc.DeploymentName, apiServicePort, &targetPort)
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, newAPIService(name, desc.Group, desc.Version, svc.Namespace, svc.Name, cert))
+
+		if _, ok := boundServices[svc.Name]; ok {
+			continue
+		}
+		boundServices[svc.Name] = struct{}{}
+		i := slices.IndexFunc(services.deployments, func(d appsv1.Deployment) bool { return d.Name == desc.DeploymentName })
+		saName := saNameOrDefault(services.deployments[i].Spec.Template.Spec.ServiceAccountName)
+		authDelegator := newClusterRoleBinding(svc.Name+"-"+authDelegatorClusterRoleName, authDelegatorClusterRoleName, svc.Namespace, saName)
+		authReader := newRoleBinding(metav1.NamespaceSystem, svc.Name+"-auth-reader", authReaderRoleName, svc.Namespace, saName)
+		objs = append(objs, &authDelegator, &authReader)
+	}
+	return objs, nil
+}
+
+// newAPIService returns the APIService name of the group version served by the
+// Service serviceName, with the serving certificate cert. APIServices are
+// unstructured, so as not to depend on the kube-aggregator module.
+func newAPIService(name, group, version, serviceNamespace, serviceName string, cert *Certificate) *unstructured.Unstructured {
+	spec := map[string]interface{}{
+		"group":                group,
+		"version":              version,
+		"groupPriorityMinimum": int64(apiServiceGroupPriorityMinimum),
+		"versionPriority":      int64(apiServiceVersionPriority),
+		"service": map[string]interface{}{
+			"namespace": serviceNamespace,
+			"name":      serviceName,
+			"port":      int64(apiServicePort),
+		},
+	}
+	if len(cert.CABundle) > 0 {
+		spec["caBundle"] = base64.StdEncoding.EncodeToString(cert.CABundle)
+	}
+	apiService := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiregistration.k8s.io/v1",
+		"kind":       "APIService",
+		"spec":       spec,
+	}}
+	apiService.SetName(name)
+	if len(cert.Annotations) > 0 {
+		apiService.SetAnnotations(cert.Annotations)
+	}
+	return apiService
+}
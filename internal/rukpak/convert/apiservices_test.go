@@ -0,0 +1,115 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+)
+
+func TestConvertAPIServices(t *testing.T) {
+	bundle := webhookBundle(t)
+	bundle.CSV.Spec.APIServiceDefinitions.Owned = []v1alpha1.APIServiceDescription{
+		{Group: "metrics.example.com", Version: "v1beta1", Kind: "NodeMetrics", DeploymentName: "test.manager", ContainerPort: 6443},
+		{Group: "metrics.example.com", Version: "v1beta1", Kind: "PodMetrics", DeploymentName: "test.manager", ContainerPort: 6443},
+		{Group: "metrics.example.com", Version: "v1", Kind: "PodMetrics", DeploymentName: "test.manager", ContainerPort: 6443},
+	}
+	certProvider := &fakeCertificateProvider{}
+
+	plain, err := Convert(bundle, installNamespace, []string{metav1.NamespaceAll}, WithCertificateProvider(certProvider))
+	require.NoError(t, err)
+
+	t.Log("It creates a Service for the deployment, with a certificate mounted into it")
+	assert.Len(t, certProvider.provisioned, 1)
+	svc := findObject[*corev1.Service](t, plain.Objects, "test-manager-service")
+	assert.Equal(t, []corev1.ServicePort{{Name: "443", Port: 443, TargetPort: intstr.FromInt32(6443)}}, svc.Spec.Ports)
+	findObject[*corev1.Secret](t, plain.Objects, "test-manager-service-cert")
+
+	t.Log("It creates an APIService per group version")
+	var apiServices []string
+	for _, obj := range plain.Objects {
+		if u, ok := obj.(*unstructured.Unstructured); ok && u.GetKind() == "APIService" {
+			apiServices = append(apiServices, u.GetName())
+		}
+	}
+	assert.Equal(t, []string{"v1beta1.metrics.example.com", "v1.metrics.example.com"}, apiServices)
+	apiService := findObject[*unstructured.Unstructured](t, plain.Objects, "v1beta1.metrics.example.com")
+	assert.Equal(t, map[string]interface{}{
+		"apiVersion": "apiregistration.k8s.io/v1",
+		"kind":       "APIService",
+		"metadata": map[string]interface{}{
+			"name":        "v1beta1.metrics.example.com",
+			"annotations": map[string]interface{}{"example.com/inject": "test-manager-service-cert"},
+		},
+		"spec": map[string]interface{}{
+			"group":                "metrics.example.com",
+			"version":              "v1beta1",
+			"groupPriorityMinimum": int64(2000),
+			"versionPriority":      int64(15),
+			"service": map[string]interface{}{
+				"namespace": installNamespace,
+				"name":      "test-manager-service",
+				"port":      int64(443),
+			},
+			"caBundle": "ZmFrZS1jYQ==",
+		},
+	}, apiService.Object)
+
+	t.Log("It binds the service account of the deployment to the roles of extension API servers")
+	authDelegator := findObject[*rbacv1.ClusterRoleBinding](t, plain.Objects, "test-manager-service-system:auth-delegator")
+	assert.Equal(t, rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "system:auth-delegator"}, authDelegator.RoleRef)
+	assert.Equal(t, []rbacv1.Subject{{Kind: "ServiceAccount", Namespace: installNamespace, Name: "default"}}, authDelegator.Subjects)
+	authReader := findObject[*rbacv1.RoleBinding](t, plain.Objects, "test-manager-service-auth-reader")
+	assert.Equal(t, metav1.NamespaceSystem, authReader.Namespace)
+	assert.Equal(t, rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "extension-apiserver-authentication-reader"}, authReader.RoleRef)
+	assert.Equal(t, []rbacv1.Subject{{Kind: "ServiceAccount", Namespace: installNamespace, Name: "default"}}, authReader.Subjects)
+}
+
+func TestConvertAPIServicesErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		webhooks  []v1alpha1.WebhookDescription
+		descs     []v1alpha1.APIServiceDescription
+		expectErr string
+	}{
+		{
+			name:      "missing deployment",
+			descs:     []v1alpha1.APIServiceDescription{{Group: "example.com", Version: "v1", DeploymentName: "missing"}},
+			expectErr: `APIService "v1.example.com" is served by deployment "missing", which is not in the bundle`,
+		},
+		{
+			name: "group version served by several deployments",
+			descs: []v1alpha1.APIServiceDescription{
+				{Group: "example.com", Version: "v1", Kind: "Foo", DeploymentName: "test.manager"},
+				{Group: "example.com", Version: "v1", Kind: "Bar", DeploymentName: "other"},
+			},
+			expectErr: `APIService "v1.example.com" is served by both deployment "test.manager" and deployment "other"`,
+		},
+		{
+			name: "port shared with a webhook served on another port",
+			webhooks: []v1alpha1.WebhookDescription{{
+				GenerateName:   "vfoo.example.com",
+				Type:           v1alpha1.ValidatingAdmissionWebhook,
+				DeploymentName: "test.manager",
+				TargetPort:     ptr.To(intstr.FromInt32(9443)),
+			}},
+			descs:     []v1alpha1.APIServiceDescription{{Group: "example.com", Version: "v1", DeploymentName: "test.manager", ContainerPort: 6443}},
+			expectErr: `APIService "v1.example.com": port 443 of service "test-manager-service" targets both 9443 and 6443`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			bundle := webhookBundle(t, tc.webhooks...)
+			bundle.CSV.Spec.APIServiceDefinitions.Owned = tc.descs
+			_, err := Convert(bundle, installNamespace, nil, WithCertificateProvider(&fakeCertificateProvider{}))
+			require.EqualError(t, err, tc.expectErr)
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package convert
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// installerCollectionVerbs are the verbs the installer needs on the
+	// resources of a bundle in the namespaces of its objects, which cannot be
+	// restricted to resource names.
+	installerCollectionVerbs = []string{"create"}
+	// installerWatchVerbs are the verbs the installer needs on the resources of
+	// a bundle in all namespaces, since the installed objects are watched with
+	// an informer per resource that is not restricted to a namespace.
+	installerWatchVerbs = []string{"list", "watch"}
+	// installerObjectVerbs are the verbs the installer needs on the objects of
+	// a bundle, restricted to their names.
+	installerObjectVerbs = []string{"delete", "get", "patch", "update"}
+)
+
+// Installer describes the ServiceAccount that installs a bundle as a
+// ClusterExtension.
+type Installer struct {
+	// ClusterExtensionName is the name of the ClusterExtension, which also
+	// names the generated roles and bindings.
+	ClusterExtensionName string
+	// ServiceAccountName is the name of the ServiceAccount, in the install
+	// namespace.
+	ServiceAccountName string
+}
+
+// InstallerRBAC returns the ClusterRole, Roles and bindings granting installer
+// the permissions needed to install and manage the objects that in converts
+// to, with the given install namespace and target namespaces. The installer is
+// allowed to escalate the Roles and ClusterRoles of the bundle and to bind
+// them, and to bind the roles outside of the bundle its bindings refer to, so
+// it does not need the permissions these roles grant.
+func InstallerRBAC(in RegistryV1, installNamespace string, targetNamespaces []string, installer Installer, opts ...Option) ([]client.Object, error) {
+	installNamespace = defaultInstallNamespace(in, installNamespace)
+	plain, err := Convert(in, installNamespace, targetNamespaces, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return installerRBAC(plain.Objects, installNamespace, installer)
+}
+
+// installerRBAC returns the roles and bindings granting installer, in
+// installNamespace, the permissions needed to manage objs.
+func installerRBAC(objs []client.Object, installNamespace string, installer Installer) ([]client.Object, error) {
+	// The objects to manage, by namespace, group, resource and name. Cluster
+	// scoped objects are in the empty namespace.
+	managed := map[string]map[string]map[string]sets.Set[string]{}
+	add := func(namespace, group, resource, name string) {
+		if managed[namespace] == nil {
+			managed[namespace] = map[string]map[string]sets.Set[string]{}
+		}
+		if managed[namespace][group] == nil {
+			managed[namespace][group] = map[string]sets.Set[string]{}
+		}
+		if managed[namespace][group][resource] == nil {
+			managed[namespace][group][resource] = sets.New[string]()
+		}
+		managed[namespace][group][resource].Insert(name)
+	}
+	// The resources of the objects to manage, by group.
+	watched := map[string]sets.Set[string]{}
+	// The verbs to escalate or bind roles, by namespace and name.
+	roleVerbs := map[string]map[rbacv1.RoleRef]sets.Set[string]{}
+	addRoleVerbs := func(namespace string, roleRef rbacv1.RoleRef, verbs ...string) {
+		roleRef.APIGroup = rbacv1.GroupName
+		if roleVerbs[namespace] == nil {
+			roleVerbs[namespace] = map[rbacv1.RoleRef]sets.Set[string]{}
+		}
+		if roleVerbs[namespace][roleRef] == nil {
+			roleVerbs[namespace][roleRef] = sets.New[string]()
+		}
+		roleVerbs[namespace][roleRef].Insert(verbs...)
+	}
+
+	for _, obj := range objs {
+		gvk := obj.GetObjectKind().GroupVersionKind()
+		gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+		add(obj.GetNamespace(), gvk.Group, gvr.Resource, obj.GetName())
+		if watched[gvk.Group] == nil {
+			watched[gvk.Group] = sets.New[string]()
+		}
+		watched[gvk.Group].Insert(gvr.Resource)
+
+		switch gvk.GroupKind() {
+		case rbacv1.SchemeGroupVersion.WithKind("Role").GroupKind(), rbacv1.SchemeGroupVersion.WithKind("ClusterRole").GroupKind():
+			addRoleVerbs(obj.GetNamespace(), rbacv1.RoleRef{Kind: gvk.Kind, Name: obj.GetName()}, "bind", "escalate")
+		case rbacv1.SchemeGroupVersion.WithKind("RoleBinding").GroupKind(), rbacv1.SchemeGroupVersion.WithKind("ClusterRoleBinding").GroupKind():
+			roleRef, err := roleRefOf(obj)
+			if err != nil {
+				return nil, err
+			}
+			// The roles of RoleBindings are bound in their namespace, even ClusterRoles.
+			addRoleVerbs(obj.GetNamespace(), roleRef, "bind")
+		}
+	}
+
+	name := installer.ClusterExtensionName + "-installer"
+	clusterRules := append(managedRules(managed[""]), watchRules(watched)...)
+	clusterRules = append(clusterRules, roleRules(roleVerbs[""])...)
+	// With the OwnerReferencesPermissionEnforcement admission plugin, setting
+	// the ClusterExtension as the owner of objects requires updating its finalizers.
+	clusterRules = append(clusterRules, rbacv1.PolicyRule{
+		APIGroups:     []string{"olm.operatorframework.io"},
+		Resources:     []string{"clusterextensions/finalizers"},
+		ResourceNames: []string{installer.ClusterExtensionName},
+		Verbs:         []string{"update"},
+	})
+	clusterRole := newClusterRole(name, clusterRules)
+	clusterRoleBinding := newClusterRoleBinding(name, name, installNamespace, installer.ServiceAccountName)
+	rbacObjs := []client.Object{&clusterRole, &clusterRoleBinding}
+
+	namespaces := sets.KeySet(managed).Union(sets.KeySet(roleVerbs))
+	namespaces.Delete("")
+	for _, namespace := range sets.List(namespaces) {
+		role := newRole(namespace, name, append(managedRules(managed[namespace]), roleRules(roleVerbs[namespace])...))
+		roleBinding := newRoleBinding(namespace, name, name, installNamespace, installer.ServiceAccountName)
+		rbacObjs = append(rbacObjs, &role, &roleBinding)
+	}
+	return rbacObjs, nil
+}
+
+// managedRules returns the rules allowing to manage the objects of the given
+// resources, by group, resource and name.
+func managedRules(resources map[string]map[string]sets.Set[string]) []rbacv1.PolicyRule {
+	var rules []rbacv1.PolicyRule
+	for _, group := range sets.List(sets.KeySet(resources)) {
+		groupResources := sets.List(sets.KeySet(resources[group]))
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{group},
+			Resources: groupResources,
+			Verbs:     installerCollectionVerbs,
+		})
+		for _, resource := range groupResources {
+			rules = append(rules, rbacv1.PolicyRule{
+				APIGroups:     []string{group},
+				Resources:     []string{resource},
+				ResourceNames: sets.List(resources[group][resource]),
+				Verbs:         installerObjectVerbs,
+			})
+		}
+	}
+	return rules
+}
+
+// watchRules returns the rules allowing to list and watch the given resources,
+// by group, in all namespaces.
+func watchRules(resources map[string]sets.Set[string]) []rbacv1.PolicyRule {
+	var rules []rbacv1.PolicyRule
+	for _, group := range sets.List(sets.KeySet(resources)) {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{group},
+			Resources: sets.List(resources[group]),
+			Verbs:     installerWatchVerbs,
+		})
+	}
+	return rules
+}
+
+// roleRules returns the rules allowing the given verbs on the given roles.
+func roleRules(roleVerbs map[rbacv1.RoleRef]sets.Set[string]) []rbacv1.PolicyRule {
+	refs := make([]rbacv1.RoleRef, 0, len(roleVerbs))
+	for ref := range roleVerbs {
+		refs = append(refs, ref)
+	}
+	slices.SortFunc(refs, func(a, b rbacv1.RoleRef) int {
+		return cmp.Or(cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Name, b.Name))
+	})
+	var rules []rbacv1.PolicyRule
+	for _, ref := range refs {
+		resource := "roles"
+		if ref.Kind == "ClusterRole" {
+			resource = "clusterroles"
+		}
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups:     []string{rbacv1.GroupName},
+			Resources:     []string{resource},
+			ResourceNames: []string{ref.Name},
+			Verbs:         sets.List(roleVerbs[ref]),
+		})
+	}
+	return rules
+}
+
+// roleRefOf returns the role referenced by the RoleBinding or
+// ClusterRoleBinding obj, which is either typed or unstructured.
+func roleRefOf(obj client.Object) (rbacv1.RoleRef, error) {
+	var binding struct {
+		RoleRef rbacv1.RoleRef `json:"roleRef"`
+	}
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err == nil {
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(u, &binding)
+	}
+	if err != nil {
+		return rbacv1.RoleRef{}, fmt.Errorf("error converting %s %q: %w", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName(), err)
+	}
+	return binding.RoleRef, nil
+}
@@ -0,0 +1,150 @@
+package convert
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+)
+
+// requireRule requires a rule of rules that allows exactly the given verbs on
+// the given resource names of resource. Several rules may apply to the same
+// resource names with different verbs.
+func requireRule(t *testing.T, rules []rbacv1.PolicyRule, verbs []string, group, resource string, resourceNames ...string) {
+	t.Helper()
+	for _, rule := range rules {
+		if assert.ObjectsAreEqual([]string{group}, rule.APIGroups) &&
+			assert.ObjectsAreEqual([]string{resource}, rule.Resources) &&
+			assert.ObjectsAreEqual(resourceNames, rule.ResourceNames) &&
+			assert.ObjectsAreEqual(verbs, rule.Verbs) {
+			return
+		}
+	}
+	require.Failf(t, "rule not found", "no rule allowing %v on %s.%s %v", verbs, resource, group, resourceNames)
+}
+
+func TestInstallerRBAC(t *testing.T) {
+	bundle := webhookBundle(t)
+	bundle.CSV.Spec.InstallModes = append(bundle.CSV.Spec.InstallModes, v1alpha1.InstallMode{Type: v1alpha1.InstallModeTypeSingleNamespace, Supported: true})
+	bundle.CSV.Spec.InstallStrategy.StrategySpec.Permissions = []v1alpha1.StrategyDeploymentPermissions{{
+		Rules: []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"}}},
+	}}
+	bundle.CSV.Spec.InstallStrategy.StrategySpec.ClusterPermissions = []v1alpha1.StrategyDeploymentPermissions{{
+		Rules: []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}}},
+	}}
+	bundle.CSV.Spec.APIServiceDefinitions.Owned = []v1alpha1.APIServiceDescription{
+		{Group: "metrics.example.com", Version: "v1", Kind: "PodMetrics", DeploymentName: "test.manager"},
+	}
+	opts := []Option{WithCertificateProvider(&fakeCertificateProvider{})}
+	installer := Installer{ClusterExtensionName: "test-ext", ServiceAccountName: "test-installer"}
+
+	plain, err := Convert(bundle, installNamespace, []string{"ns-a"}, opts...)
+	require.NoError(t, err)
+	var bundleRoles, bundleClusterRoles []string
+	for _, obj := range plain.Objects {
+		switch obj.(type) {
+		case *rbacv1.Role:
+			bundleRoles = append(bundleRoles, obj.GetName())
+		case *rbacv1.ClusterRole:
+			bundleClusterRoles = append(bundleClusterRoles, obj.GetName())
+		}
+	}
+	require.Len(t, bundleRoles, 1)
+	require.Len(t, bundleClusterRoles, 1)
+
+	objs, err := InstallerRBAC(bundle, installNamespace, []string{"ns-a"}, installer, opts...)
+	require.NoError(t, err)
+
+	t.Log("It binds every role to the installer service account")
+	var roleNamespaces []string
+	for _, obj := range objs {
+		var subjects []rbacv1.Subject
+		switch o := obj.(type) {
+		case *rbacv1.Role:
+			roleNamespaces = append(roleNamespaces, o.Namespace)
+		case *rbacv1.RoleBinding:
+			subjects = o.Subjects
+		case *rbacv1.ClusterRoleBinding:
+			subjects = o.Subjects
+		default:
+			continue
+		}
+		assert.Equal(t, "test-ext-installer", obj.GetName())
+		if subjects != nil {
+			assert.Equal(t, []rbacv1.Subject{{Kind: "ServiceAccount", Namespace: installNamespace, Name: "test-installer"}}, subjects)
+		}
+	}
+	assert.Equal(t, []string{"kube-system", "ns-a", installNamespace}, roleNamespaces)
+
+	t.Log("It allows managing the cluster-scoped objects of the bundle")
+	clusterRole := findObject[*rbacv1.ClusterRole](t, objs, "test-ext-installer")
+	requireRule(t, clusterRole.Rules, []string{"create"}, "apiextensions.k8s.io", "customresourcedefinitions")
+	requireRule(t, clusterRole.Rules, []string{"delete", "get", "patch", "update"}, "apiextensions.k8s.io", "customresourcedefinitions", "foos.example.com")
+	requireRule(t, clusterRole.Rules, []string{"delete", "get", "patch", "update"}, "apiregistration.k8s.io", "apiservices", "v1.metrics.example.com")
+	requireRule(t, clusterRole.Rules, []string{"update"}, "olm.operatorframework.io", "clusterextensions/finalizers", "test-ext")
+
+	t.Log("It allows escalating and binding the roles of the bundle, and binding the other roles")
+	requireRule(t, clusterRole.Rules, []string{"bind", "escalate"}, rbacv1.GroupName, "clusterroles", bundleClusterRoles[0])
+	requireRule(t, clusterRole.Rules, []string{"delete", "get", "patch", "update"}, rbacv1.GroupName, "clusterroles", bundleClusterRoles[0])
+	requireRule(t, clusterRole.Rules, []string{"bind"}, rbacv1.GroupName, "clusterroles", "system:auth-delegator")
+
+	role := findRole(t, objs, "ns-a")
+	requireRule(t, role.Rules, []string{"bind", "escalate"}, rbacv1.GroupName, "roles", bundleRoles[0])
+	requireRule(t, role.Rules, []string{"delete", "get", "patch", "update"}, rbacv1.GroupName, "rolebindings", bundleRoles[0])
+
+	role = findRole(t, objs, metav1.NamespaceSystem)
+	requireRule(t, role.Rules, []string{"bind"}, rbacv1.GroupName, "roles", "extension-apiserver-authentication-reader")
+
+	t.Log("It allows managing the namespaced objects of the bundle in the install namespace")
+	role = findRole(t, objs, installNamespace)
+	requireRule(t, role.Rules, []string{"create"}, "apps", "deployments")
+	requireRule(t, role.Rules, []string{"delete", "get", "patch", "update"}, "apps", "deployments", "test.manager")
+	requireRule(t, role.Rules, []string{"delete", "get", "patch", "update"}, "", "services", "test-manager-service")
+	requireRule(t, role.Rules, []string{"delete", "get", "patch", "update"}, "", "secrets", "test-manager-service-cert")
+
+	t.Log("It allows listing and watching every resource of the bundle in all namespaces")
+	for _, gr := range []struct{ group, resource string }{
+		{"apiextensions.k8s.io", "customresourcedefinitions"},
+		{"apiregistration.k8s.io", "apiservices"},
+		{"apps", "deployments"},
+		{"", "secrets"},
+		{"", "services"},
+		{rbacv1.GroupName, "roles"},
+		{rbacv1.GroupName, "rolebindings"},
+		{rbacv1.GroupName, "clusterroles"},
+		{rbacv1.GroupName, "clusterrolebindings"},
+	} {
+		assert.True(t, slices.ContainsFunc(clusterRole.Rules, func(rule rbacv1.PolicyRule) bool {
+			return slices.Equal([]string{gr.group}, rule.APIGroups) && slices.Contains(rule.Resources, gr.resource) &&
+				len(rule.ResourceNames) == 0 && slices.Equal([]string{"list", "watch"}, rule.Verbs)
+		}), "no rule to list and watch %s.%s in the ClusterRole", gr.resource, gr.group)
+	}
+	for _, role := range []*rbacv1.Role{findRole(t, objs, installNamespace), findRole(t, objs, "ns-a")} {
+		for _, rule := range role.Rules {
+			assert.NotContains(t, rule.Verbs, "list")
+			assert.NotContains(t, rule.Verbs, "watch")
+		}
+	}
+}
+
+func TestInstallerRBACErrors(t *testing.T) {
+	_, err := InstallerRBAC(webhookBundle(t), installNamespace, []string{installNamespace}, Installer{ClusterExtensionName: "test-ext", ServiceAccountName: "test-installer"})
+	require.ErrorContains(t, err, "do not support target namespaces")
+}
+
+func findRole(t *testing.T, objs []client.Object, namespace string) *rbacv1.Role {
+	t.Helper()
+	for _, obj := range objs {
+		if role, ok := obj.(*rbacv1.Role); ok && role.Namespace == namespace {
+			return role
+		}
+	}
+	require.Failf(t, "role not found", "no Role in namespace %q", namespace)
+	return nil
+}
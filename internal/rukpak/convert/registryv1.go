@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"maps"
 	"path/filepath"
 	"strings"
 
@@ -21,6 +22,7 @@ import (
 	"k8s.io/cli-runtime/pkg/resource"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/yaml"
 
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
@@ -31,6 +33,10 @@ import (
 	"github.com/operator-framework/operator-controller/internal/rukpak/util"
 )
 
+// MinKubeVersionAnnotation is the annotation of the Helm chart of a registry+v1
+// bundle that holds the minKubeVersion of its ClusterServiceVersion.
+const MinKubeVersionAnnotation = "olm.operatorframework.io/min-kube-version"
+
 type RegistryV1 struct {
 	PackageName string
 	CSV         v1alpha1.ClusterServiceVersion
@@ -41,17 +47,52 @@ type Plain struct {
 	Objects []client.Object
 }
 
-func RegistryV1ToHelmChart(ctx context.Context, rv1 fs.FS, installNamespace string, watchNamespaces []string) (*chart.Chart, error) {
+// Option configures the conversion of a registry+v1 bundle.
+type Option func(o *options)
+
+type options struct {
+	certProvider CertificateProvider
+	certOwner    string
+}
+
+// WithCertificateProvider sets the provider of the serving certificates of the
+// webhooks of the bundle. Without one, bundles with webhooks are rejected.
+func WithCertificateProvider(p CertificateProvider) Option {
+	return func(o *options) {
+		o.certProvider = p
+	}
+}
+
+// WithCertificateOwner sets the name of the ClusterExtension the serving
+// certificates of the bundle are provisioned for.
+func WithCertificateOwner(owner string) Option {
+	return func(o *options) {
+		o.certOwner = owner
+	}
+}
+
+func RegistryV1ToHelmChart(ctx context.Context, rv1 fs.FS, installNamespace string, watchNamespaces []string, opts ...Option) (*chart.Chart, error) {
+	reg, err := ParseFS(ctx, rv1)
+	if err != nil {
+		return nil, err
+	}
+
+	return toChart(reg, installNamespace, watchNamespaces, opts...)
+}
+
+// ParseFS reads a registry+v1 bundle from the filesystem rv1, which is
+// expected to contain the bundle's metadata and manifests directories.
+func ParseFS(ctx context.Context, rv1 fs.FS) (RegistryV1, error) {
 	l := log.FromContext(ctx)
 
 	reg := RegistryV1{}
 	annotationsFileData, err := fs.ReadFile(rv1, filepath.Join("metadata", "annotations.yaml"))
 	if err != nil {
-		return nil, err
+		return reg, err
 	}
 	annotationsFile := registry.AnnotationsFile{}
 	if err := yaml.Unmarshal(annotationsFileData, &annotationsFile); err != nil {
-		return nil, err
+		return reg, err
 	}
 	reg.PackageName = annotationsFile.Annotations.PackageName
 
@@ -100,14 +141,14 @@ func RegistryV1ToHelmChart(ctx context.Context, rv1 fs.FS, installNamespace stri
 		}
 		return nil
 	}); err != nil {
-		return nil, err
+		return reg, err
 	}
 
 	if err := copyMetadataPropertiesToCSV(&reg.CSV, rv1); err != nil {
-		return nil, err
+		return reg, err
 	}
 
-	return toChart(reg, installNamespace, watchNamespaces)
+	return reg, nil
 }
 
 // copyMetadataPropertiesToCSV copies properties from `metadata/propeties.yaml` (in the filesystem fsys) into
@@ -158,14 +199,20 @@ func copyMetadataPropertiesToCSV(csv *v1alpha1.ClusterServiceVersion, fsys fs.FS
 	return nil
 }
 
-func toChart(in RegistryV1, installNamespace string, watchNamespaces []string) (*chart.Chart, error) {
-	plain, err := Convert(in, installNamespace, watchNamespaces)
+func toChart(in RegistryV1, installNamespace string, watchNamespaces []string, opts ...Option) (*chart.Chart, error) {
+	plain, err := Convert(in, installNamespace, watchNamespaces, opts...)
 	if err != nil {
 		return nil, err
 	}
 
 	chrt := &chart.Chart{Metadata: &chart.Metadata{}}
-	chrt.Metadata.Annotations = in.CSV.GetAnnotations()
+	chrt.Metadata.Annotations = maps.Clone(in.CSV.GetAnnotations())
+	if in.CSV.Spec.MinKubeVersion != "" {
+		if chrt.Metadata.Annotations == nil {
+			chrt.Metadata.Annotations = map[string]string{}
+		}
+		chrt.Metadata.Annotations[MinKubeVersionAnnotation] = in.CSV.Spec.MinKubeVersion
+	}
 	for _, obj := range plain.Objects {
 		jsonData, err := json.Marshal(obj)
 		if err != nil {
@@ -181,6 +228,32 @@ func toChart(in RegistryV1, installNamespace string, watchNamespaces []string) (
 	return chrt, nil
 }
 
+// TargetNamespaces returns the namespaces a bundle with the given install
+// modes, installed into installNamespace, targets when targetNamespaces are
+// requested. When none are requested, the bundle targets all namespaces if it
+// supports the AllNamespaces install mode, and otherwise its install namespace
+// if it supports the OwnNamespace install mode. An error is returned when the
+// install modes do not support the target namespaces.
+func TargetNamespaces(installModes []v1alpha1.InstallMode, installNamespace string, targetNamespaces []string) ([]string, error) {
+	supportedInstallModes := sets.New[string]()
+	for _, im := range installModes {
+		if im.Supported {
+			supportedInstallModes.Insert(string(im.Type))
+		}
+	}
+	if len(targetNamespaces) == 0 {
+		if supportedInstallModes.Has(string(v1alpha1.InstallModeTypeAllNamespaces)) {
+			targetNamespaces = []string{""}
+		} else if supportedInstallModes.Has(string(v1alpha1.InstallModeTypeOwnNamespace)) {
+			targetNamespaces = []string{installNamespace}
+		}
+	}
+	if err := validateTargetNamespaces(supportedInstallModes, installNamespace, targetNamespaces); err != nil {
+		return nil, err
+	}
+	return targetNamespaces, nil
+}
+
 func validateTargetNamespaces(supportedInstallModes sets.Set[string], installNamespace string, targetNamespaces []string) error {
 	set := sets.New[string](targetNamespaces...)
 	switch {
@@ -204,44 +277,37 @@ func validateTargetNamespaces(supportedInstallModes sets.Set[string], installNam
 	return fmt.Errorf("supported install modes %v do not support target namespaces %v", sets.List[string](supportedInstallModes), targetNamespaces)
 }
 
-func saNameOrDefault(saName string) string {
-	if saName == "" {
-		return "default"
-	}
-	return saName
-}
-
-func Convert(in RegistryV1, installNamespace string, targetNamespaces []string) (*Plain, error) {
+// defaultInstallNamespace returns installNamespace, or the namespace in is
+// installed to when it is empty.
+func defaultInstallNamespace(in RegistryV1, installNamespace string) string {
 	if installNamespace == "" {
 		installNamespace = in.CSV.Annotations["operatorframework.io/suggested-namespace"]
 	}
 	if installNamespace == "" {
 		installNamespace = fmt.Sprintf("%s-system", in.PackageName)
 	}
-	supportedInstallModes := sets.New[string]()
-	for _, im := range in.CSV.Spec.InstallModes {
-		if im.Supported {
-			supportedInstallModes.Insert(string(im.Type))
-		}
-	}
-	if len(targetNamespaces) == 0 {
-		if supportedInstallModes.Has(string(v1alpha1.InstallModeTypeAllNamespaces)) {
-			targetNamespaces = []string{""}
-		} else if supportedInstallModes.Has(string(v1alpha1.InstallModeTypeOwnNamespace)) {
-			targetNamespaces = []string{installNamespace}
-		}
-	}
+	return installNamespace
+}
 
-	if err := validateTargetNamespaces(supportedInstallModes, installNamespace, targetNamespaces); err != nil {
-		return nil, err
+func saNameOrDefault(saName string) string {
+	if saName == "" {
+		return "default"
 	}
+	return saName
+}
 
-	if len(in.CSV.Spec.APIServiceDefinitions.Owned) > 0 {
-		return nil, fmt.Errorf("apiServiceDefintions are not supported")
+func Convert(in RegistryV1, installNamespace string, targetNamespaces []string, opts ...Option) (*Plain, error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
 	}
 
-	if len(in.CSV.Spec.WebhookDefinitions) > 0 {
-		return nil, fmt.Errorf("webhookDefinitions are not supported")
+	installNamespace = defaultInstallNamespace(in, installNamespace)
+	targetNamespaces, err := TargetNamespaces(in.CSV.Spec.InstallModes, installNamespace, targetNamespaces)
+	if err != nil {
+		// The install modes of a bundle never change, so retrying cannot
+		// succeed until the requested target namespaces are changed.
+		return nil, reconcile.TerminalError(err)
 	}
 
 	deployments := []appsv1.Deployment{}
@@ -267,6 +333,16 @@ func Convert(in RegistryV1, installNamespace string, targetNamespaces []string)
 		serviceAccounts[saName] = newServiceAccount(installNamespace, saName)
 	}
 
+	services := newServiceSet(installNamespace, deployments, o.certProvider, o.certOwner)
+	webhookObjs, err := convertWebhooks(&in, targetNamespaces, services)
+	if err != nil {
+		return nil, err
+	}
+	apiServiceObjs, err := convertAPIServices(in, services)
+	if err != nil {
+		return nil, err
+	}
+
 	// NOTES:
 	//   1. There's an extra Role for OperatorConditions: get/update/patch; resourceName=csv.name
 	//        - This is managed by the OperatorConditions controller here: https://github.com/operator-framework/operator-lifecycle-manager/blob/9ced412f3e263b8827680dc0ad3477327cd9a508/pkg/controller/operators/operatorcondition_controller.go#L106-L109
@@ -362,6 +438,9 @@ func Convert(in RegistryV1, installNamespace string, targetNamespaces []string)
 		}
 		objs = append(objs, &obj)
 	}
+	objs = append(objs, services.objects()...)
+	objs = append(objs, webhookObjs...)
+	objs = append(objs, apiServiceObjs...)
 	for _, obj := range deployments {
 		obj := obj
 		objs = append(objs, &obj)
@@ -17,6 +17,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
 	"github.com/operator-framework/operator-registry/alpha/property"
@@ -373,6 +374,37 @@ func TestRegistryV1SuiteGenerateOwnNamespace(t *testing.T) {
 	require.Equal(t, strings.Join(watchNamespaces, ","), dep.(*appsv1.Deployment).Spec.Template.Annotations[olmNamespaces])
 }
 
+func TestRegistryV1SuiteGenerateDefaultOwnNamespace(t *testing.T) {
+	t.Log("RegistryV1 Suite Convert")
+	t.Log("It should generate objects successfully based on target namespaces")
+
+	t.Log("It should default to own namespace when no target namespaces are given and all namespaces is not supported")
+	baseCSV, svc := getBaseCsvAndService()
+	csv := baseCSV.DeepCopy()
+	csv.Spec.InstallModes = []v1alpha1.InstallMode{
+		{Type: v1alpha1.InstallModeTypeAllNamespaces, Supported: false},
+		{Type: v1alpha1.InstallModeTypeOwnNamespace, Supported: true},
+	}
+
+	t.Log("By creating a registry v1 bundle")
+	unstructuredSvc := convertToUnstructured(t, svc)
+	registryv1Bundle := RegistryV1{
+		PackageName: "testPkg",
+		CSV:         *csv,
+		Others:      []unstructured.Unstructured{unstructuredSvc},
+	}
+
+	t.Log("By converting to plain")
+	plainBundle, err := Convert(registryv1Bundle, installNamespace, nil)
+	require.NoError(t, err)
+	require.NotNil(t, plainBundle)
+
+	t.Log("By verifying olm.targetNamespaces annotation in the deployment's pod template")
+	dep := findObjectByName("testDeployment", plainBundle.Objects)
+	require.NotNil(t, dep)
+	require.Equal(t, installNamespace, dep.(*appsv1.Deployment).Spec.Template.Annotations[olmNamespaces])
+}
+
 func TestRegistryV1SuiteGenerateErrorMultiNamespaceEmpty(t *testing.T) {
 	t.Log("RegistryV1 Suite Convert")
 	t.Log("It should generate objects successfully based on target namespaces")
@@ -448,6 +480,9 @@ func TestRegistryV1SuiteGenerateErrorAllNamespaceDisabled(t *testing.T) {
 	plainBundle, err := Convert(registryv1Bundle, installNamespace, watchNamespaces)
 	require.Error(t, err)
 	require.Nil(t, plainBundle)
+
+	t.Log("By verifying the error is terminal")
+	require.ErrorIs(t, err, reconcile.TerminalError(nil))
 }
 
 func TestRegistryV1SuiteGeneratePropagateCsvAnnotations(t *testing.T) {
@@ -494,7 +529,7 @@ func TestRegistryV1SuiteGenerateNoWebhooks(t *testing.T) {
 	t.Log("It should generate objects successfully based on target namespaces")
 
 	t.Log("It should enforce limitations")
-	t.Log("It should not allow bundles with webhooks")
+	t.Log("It should not allow bundles with webhooks without a certificate provider")
 	t.Log("By creating a registry v1 bundle")
 	csv := v1alpha1.ClusterServiceVersion{
 		ObjectMeta: metav1.ObjectMeta{
@@ -514,7 +549,7 @@ func TestRegistryV1SuiteGenerateNoWebhooks(t *testing.T) {
 	t.Log("By converting to plain")
 	plainBundle, err := Convert(registryv1Bundle, installNamespace, watchNamespaces)
 	require.Error(t, err)
-	require.ErrorContains(t, err, "webhookDefinitions are not supported")
+	require.ErrorContains(t, err, "webhookDefinitions are not supported without a certificate provider")
 	require.Nil(t, plainBundle)
 }
 
@@ -523,7 +558,7 @@ func TestRegistryV1SuiteGenerateNoAPISerciceDefinitions(t *testing.T) {
 	t.Log("It should generate objects successfully based on target namespaces")
 
 	t.Log("It should enforce limitations")
-	t.Log("It should not allow bundles with API service definitions")
+	t.Log("It should not allow bundles with API service definitions without a certificate provider")
 	t.Log("By creating a registry v1 bundle")
 	csv := v1alpha1.ClusterServiceVersion{
 		ObjectMeta: metav1.ObjectMeta{
@@ -545,7 +580,7 @@ func TestRegistryV1SuiteGenerateNoAPISerciceDefinitions(t *testing.T) {
 	t.Log("By converting to plain")
 	plainBundle, err := Convert(registryv1Bundle, installNamespace, watchNamespaces)
 	require.Error(t, err)
-	require.ErrorContains(t, err, "apiServiceDefintions are not supported")
+	require.ErrorContains(t, err, "apiServiceDefinitions are not supported without a certificate provider")
 	require.Nil(t, plainBundle)
 }
 
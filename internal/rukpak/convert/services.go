@@ -0,0 +1,211 @@
+package convert
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// The serving certificate is mounted where operators built with
+	// operator-sdk or controller-runtime, and legacy OLM, expect to find it.
+	webhookCertVolumeName    = "webhook-cert"
+	webhookCertMountPath     = "/tmp/k8s-webhook-server/serving-certs"
+	apiServiceCertVolumeName = "apiservice-cert"
+	apiServiceCertMountPath  = "/apiserver.local.config/certificates"
+)
+
+// CertificateProvider provisions the serving certificates of the webhooks and
+// APIServices of registry+v1 bundles.
+type CertificateProvider interface {
+	// Provision returns how the serving certificate described by cfg is
+	// provisioned.
+	Provision(cfg CertificateConfig) (*Certificate, error)
+}
+
+// CertificateConfig describes a serving certificate.
+type CertificateConfig struct {
+	// Namespace is the namespace of the Service the certificate is for.
+	Namespace string
+	// ServiceName is the name of the Service the certificate is for.
+	ServiceName string
+	// Name is the name of the certificate.
+	Name string
+	// Owner is the name of the ClusterExtension the certificate is provisioned
+	// for, if known. Providers that store certificates outside of the bundle
+	// use it to delete them once the ClusterExtension is gone.
+	Owner string
+}
+
+// DNSNames returns the DNS names the certificate must be valid for.
+func (c CertificateConfig) DNSNames() []string {
+	return []string{
+		fmt.Sprintf("%s.%s.svc", c.ServiceName, c.Namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", c.ServiceName, c.Namespace),
+	}
+}
+
+// Certificate describes how a serving certificate is provisioned.
+type Certificate struct {
+	// SecretName is the name of the Secret, in the namespace of the Service,
+	// that holds the certificate and its private key under the tls.crt and
+	// tls.key keys.
+	SecretName string
+	// Objects are installed along with the bundle to provision the certificate.
+	Objects []client.Object
+	// CABundle is set in the webhook client configurations and APIServices
+	// when the provider knows the CA the certificate is signed by.
+	CABundle []byte
+	// Annotations are set on the webhook configurations, the CRDs using a
+	// conversion webhook and the APIServices, for a CA injector to set their
+	// CA bundle.
+	Annotations map[string]string
+}
+
+// serviceSet builds the Services of the deployments of a bundle that serve
+// webhooks or APIServices, and provisions their serving certificates.
+type serviceSet struct {
+	namespace    string
+	deployments  []appsv1.Deployment
+	certProvider CertificateProvider
+	certOwner    string
+
+	services     map[string]*corev1.Service
+	certificates map[string]*Certificate
+	objs         []client.Object
+}
+
+func newServiceSet(namespace string, deployments []appsv1.Deployment, certProvider CertificateProvider, certOwner string) *serviceSet {
+	return &serviceSet{
+		namespace:    namespace,
+		deployments:  deployments,
+		certProvider: certProvider,
+		certOwner:    certOwner,
+		services:     map[string]*corev1.Service{},
+		certificates: map[string]*Certificate{},
+	}
+}
+
+// serviceName returns the name of the Service of the deployment deploymentName.
+func serviceName(deploymentName string) string {
+	return strings.ReplaceAll(deploymentName, ".", "-") + "-service"
+}
+
+// serve exposes port of the Service of the deployment deploymentName, which
+// serves what is described by description. It returns the Service and its
+// serving certificate, which is mounted into the deployment.
+func (s *serviceSet) serve(description, deploymentName string, port int32, targetPort *intstr.IntOrString) (*corev1.Service, *Certificate, error) {
+	i := slices.IndexFunc(s.deployments, func(d appsv1.Deployment) bool { return d.Name == deploymentName })
+	if i < 0 {
+		return nil, nil, fmt.Errorf("%s is served by deployment %q, which is not in the bundle", description, deploymentName)
+	}
+
+	name := serviceName(deploymentName)
+	svc, ok := s.services[name]
+	if !ok {
+		svc = newService(s.namespace, name, s.deployments[i].Spec.Selector)
+		cert, err := s.certProvider.Provision(CertificateConfig{
+			Namespace:   s.namespace,
+			ServiceName: name,
+			Name:        name + "-cert",
+			Owner:       s.certOwner,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("error provisioning certificate of service %q: %w", name, err)
+		}
+		s.services[name] = svc
+		s.certificates[name] = cert
+		s.objs = append(s.objs, svc)
+		s.objs = append(s.objs, cert.Objects...)
+		mountCertificate(&s.deployments[i], cert.SecretName)
+	}
+	if err := addServicePort(svc, port, targetPort); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", description, err)
+	}
+	return svc, s.certificates[name], nil
+}
+
+// objects returns the Services and the objects provisioning their certificates.
+func (s *serviceSet) objects() []client.Object {
+	return s.objs
+}
+
+func newService(namespace, name string, selector *metav1.LabelSelector) *corev1.Service {
+	svc := &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+	}
+	if selector != nil {
+		svc.Spec.Selector = selector.MatchLabels
+	}
+	return svc
+}
+
+// addServicePort adds port to svc, unless it already has it. A port can only
+// be shared by webhooks and APIServices served on the same target port.
+func addServicePort(svc *corev1.Service, port int32, targetPort *intstr.IntOrString) error {
+	servicePort := corev1.ServicePort{
+		Name:       fmt.Sprintf("%d", port),
+		Port:       port,
+		TargetPort: intstr.FromInt32(port),
+	}
+	if targetPort != nil {
+		servicePort.TargetPort = *targetPort
+	}
+	if i := slices.IndexFunc(svc.Spec.Ports, func(p corev1.ServicePort) bool { return p.Port == port }); i >= 0 {
+		if existing := svc.Spec.Ports[i].TargetPort; existing != servicePort.TargetPort {
+			return fmt.Errorf("port %d of service %q targets both %s and %s", port, svc.Name, existing.String(), servicePort.TargetPort.String())
+		}
+		return nil
+	}
+	svc.Spec.Ports = append(svc.Spec.Ports, servicePort)
+	return nil
+}
+
+// mountCertificate mounts the serving certificate stored in the Secret
+// secretName into every container of dep.
+func mountCertificate(dep *appsv1.Deployment, secretName string) {
+	// The pod spec shares its slices with the CSV, which must not be modified.
+	podSpec := &dep.Spec.Template.Spec
+	podSpec.Containers = slices.Clone(podSpec.Containers)
+	podSpec.Volumes = append(slices.Clip(podSpec.Volumes),
+		corev1.Volume{
+			Name: apiServiceCertVolumeName,
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+				SecretName: secretName,
+				Items: []corev1.KeyToPath{
+					{Key: corev1.TLSCertKey, Path: "apiserver.crt"},
+					{Key: corev1.TLSPrivateKeyKey, Path: "apiserver.key"},
+				},
+			}},
+		},
+		corev1.Volume{
+			Name: webhookCertVolumeName,
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+				SecretName: secretName,
+				Items: []corev1.KeyToPath{
+					{Key: corev1.TLSCertKey, Path: corev1.TLSCertKey},
+					{Key: corev1.TLSPrivateKeyKey, Path: corev1.TLSPrivateKeyKey},
+				},
+			}},
+		},
+	)
+	for i := range podSpec.Containers {
+		podSpec.Containers[i].VolumeMounts = append(slices.Clip(podSpec.Containers[i].VolumeMounts),
+			corev1.VolumeMount{Name: apiServiceCertVolumeName, MountPath: apiServiceCertMountPath},
+			corev1.VolumeMount{Name: webhookCertVolumeName, MountPath: webhookCertMountPath},
+		)
+	}
+}
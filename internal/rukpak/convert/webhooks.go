@@ -0,0 +1,137 @@
+package convert
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+
+	"github.com/operator-framework/operator-controller/internal/rukpak/util"
+)
+
+// defaultWebhookPort is the port of a webhook that does not declare one.
+const defaultWebhookPort = 443
+
+// convertWebhooks returns the webhook configurations of the webhooks of the
+// CSV of in. The webhooks are served by the Services of services, and
+// conversion webhooks are configured in the CRDs of in.Others.
+func convertWebhooks(in *RegistryV1, targetNamespaces []string, services *serviceSet) ([]client.Object, error) {
+	webhooks := in.CSV.Spec.WebhookDefinitions
+	if len(webhooks) == 0 {
+		return nil, nil
+	}
+	if services.certProvider == nil {
+		return nil, errors.New("webhookDefinitions are not supported without a certificate provider")
+	}
+	// The CRDs with a conversion webhook are replaced, not modified in place.
+	in.Others = slices.Clone(in.Others)
+
+	var namespaceSelector *metav1.LabelSelector
+	if len(targetNamespaces) != 1 || targetNamespaces[0] != "" {
+		namespaceSelector = &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      corev1.LabelMetadataName,
+				Operator: metav1.LabelSelectorOpIn,
+				Values:   targetNamespaces,
+			}},
+		}
+	}
+
+	installNamespace := services.namespace
+	var objs []client.Object
+	for _, wh := range webhooks {
+		if wh.ContainerPort == 0 {
+			wh.ContainerPort = defaultWebhookPort
+		}
+		svc, cert, err := services.serve(fmt.Sprintf("webhook %q", wh.GenerateName), wh.DeploymentName, wh.ContainerPort, wh.TargetPort)
+		if err != nil {
+			return nil, err
+		}
+
+		switch wh.Type {
+		case v1alpha1.ValidatingAdmissionWebhook:
+			objs = append(objs, &admissionregistrationv1.ValidatingWebhookConfiguration{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "ValidatingWebhookConfiguration",
+					APIVersion: admissionregistrationv1.SchemeGroupVersion.String(),
+				},
+				ObjectMeta: metav1.ObjectMeta{Name: wh.GenerateName, Annotations: cert.Annotations},
+				Webhooks:   []admissionregistrationv1.ValidatingWebhook{wh.GetValidatingWebhook(installNamespace, namespaceSelector, cert.CABundle)},
+			})
+		case v1alpha1.MutatingAdmissionWebhook:
+			objs = append(objs, &admissionregistrationv1.MutatingWebhookConfiguration{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "MutatingWebhookConfiguration",
+					APIVersion: admissionregistrationv1.SchemeGroupVersion.String(),
+				},
+				ObjectMeta: metav1.ObjectMeta{Name: wh.GenerateName, Annotations: cert.Annotations},
+				Webhooks:   []admissionregistrationv1.MutatingWebhook{wh.GetMutatingWebhook(installNamespace, namespaceSelector, cert.CABundle)},
+			})
+		case v1alpha1.ConversionWebhook:
+			// CRDs are cluster-scoped, so their conversion webhook must serve
+			// all namespaces.
+			if namespaceSelector != nil {
+				return nil, reconcile.TerminalError(fmt.Errorf("conversion webhook %q is only supported when watching all namespaces", wh.GenerateName))
+			}
+			for _, crdName := range wh.ConversionCRDs {
+				if err := setConversionWebhook(in.Others, crdName, wh, installNamespace, svc.Name, cert); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			return nil, fmt.Errorf("webhook %q has unsupported type %q", wh.GenerateName, wh.Type)
+		}
+	}
+	return objs, nil
+}
+
+// setConversionWebhook configures the CRD named crdName among objs to convert
+// its versions with the webhook wh.
+func setConversionWebhook(objs []unstructured.Unstructured, crdName string, wh v1alpha1.WebhookDescription, namespace, serviceName string, cert *Certificate) error {
+	i := slices.IndexFunc(objs, func(obj unstructured.Unstructured) bool {
+		return obj.GetKind() == "CustomResourceDefinition" && obj.GetName() == crdName
+	})
+	if i < 0 {
+		return fmt.Errorf("conversion webhook %q is for CRD %q, which is not in the bundle", wh.GenerateName, crdName)
+	}
+
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(objs[i].Object, crd); err != nil {
+		return fmt.Errorf("error parsing CRD %q: %w", crdName, err)
+	}
+	crd.Spec.Conversion = &apiextensionsv1.CustomResourceConversion{
+		Strategy: apiextensionsv1.WebhookConverter,
+		Webhook: &apiextensionsv1.WebhookConversion{
+			ClientConfig: &apiextensionsv1.WebhookClientConfig{
+				Service: &apiextensionsv1.ServiceReference{
+					Namespace: namespace,
+					Name:      serviceName,
+					Path:      wh.WebhookPath,
+					Port:      &wh.ContainerPort,
+				},
+				CABundle: cert.CABundle,
+			},
+			ConversionReviewVersions: wh.AdmissionReviewVersions,
+		},
+	}
+	if len(cert.Annotations) > 0 {
+		crd.SetAnnotations(util.MergeMaps(crd.GetAnnotations(), cert.Annotations))
+	}
+
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(crd)
+	if err != nil {
+		return fmt.Errorf("error converting CRD %q: %w", crdName, err)
+	}
+	objs[i].Object = u
+	return nil
+}
@@ -0,0 +1,239 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+)
+
+type fakeCertificateProvider struct {
+	provisioned []CertificateConfig
+}
+
+func (f *fakeCertificateProvider) Provision(cfg CertificateConfig) (*Certificate, error) {
+	f.provisioned = append(f.provisioned, cfg)
+	return &Certificate{
+		SecretName: cfg.Name,
+		Objects: []client.Object{&corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: cfg.Namespace, Name: cfg.Name},
+		}},
+		CABundle:    []byte("fake-ca"),
+		Annotations: map[string]string{"example.com/inject": cfg.Name},
+	}, nil
+}
+
+func webhookBundle(t *testing.T, webhooks ...v1alpha1.WebhookDescription) RegistryV1 {
+	t.Helper()
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		TypeMeta:   metav1.TypeMeta{Kind: "CustomResourceDefinition", APIVersion: apiextensionsv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "foos.example.com"},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: "example.com",
+			Names: apiextensionsv1.CustomResourceDefinitionNames{Kind: "Foo", Plural: "foos"},
+			Scope: apiextensionsv1.NamespaceScoped,
+		},
+	}
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(crd)
+	require.NoError(t, err)
+
+	return RegistryV1{
+		PackageName: "testPkg",
+		CSV: v1alpha1.ClusterServiceVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: "testCSV"},
+			Spec: v1alpha1.ClusterServiceVersionSpec{
+				InstallModes: []v1alpha1.InstallMode{
+					{Type: v1alpha1.InstallModeTypeAllNamespaces, Supported: true},
+					{Type: v1alpha1.InstallModeTypeMultiNamespace, Supported: true},
+				},
+				InstallStrategy: v1alpha1.NamedInstallStrategy{
+					StrategySpec: v1alpha1.StrategyDetailsDeployment{
+						DeploymentSpecs: []v1alpha1.StrategyDeploymentSpec{{
+							Name: "test.manager",
+							Spec: appsv1.DeploymentSpec{
+								Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "manager"}},
+								Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+									Containers: []corev1.Container{{Name: "manager"}},
+								}},
+							},
+						}},
+					},
+				},
+				WebhookDefinitions: webhooks,
+			},
+		},
+		Others: []unstructured.Unstructured{{Object: u}},
+	}
+}
+
+func findObject[T client.Object](t *testing.T, objs []client.Object, name string) T {
+	t.Helper()
+	for _, obj := range objs {
+		if o, ok := obj.(T); ok && obj.GetName() == name {
+			return o
+		}
+	}
+	require.Failf(t, "object not found", "no %T named %q", *new(T), name)
+	return *new(T)
+}
+
+func TestConvertWebhooks(t *testing.T) {
+	bundle := webhookBundle(t,
+		v1alpha1.WebhookDescription{
+			GenerateName:            "vfoo.example.com",
+			Type:                    v1alpha1.ValidatingAdmissionWebhook,
+			DeploymentName:          "test.manager",
+			TargetPort:              ptr.To(intstr.FromInt32(9443)),
+			AdmissionReviewVersions: []string{"v1"},
+			SideEffects:             ptr.To(admissionregistrationv1.SideEffectClassNone),
+			WebhookPath:             ptr.To("/validate"),
+		},
+		v1alpha1.WebhookDescription{
+			GenerateName:            "mfoo.example.com",
+			Type:                    v1alpha1.MutatingAdmissionWebhook,
+			DeploymentName:          "test.manager",
+			ContainerPort:           8443,
+			AdmissionReviewVersions: []string{"v1"},
+			SideEffects:             ptr.To(admissionregistrationv1.SideEffectClassNone),
+			WebhookPath:             ptr.To("/mutate"),
+		},
+		v1alpha1.WebhookDescription{
+			GenerateName:            "cfoo.example.com",
+			Type:                    v1alpha1.ConversionWebhook,
+			DeploymentName:          "test.manager",
+			TargetPort:              ptr.To(intstr.FromInt32(9443)),
+			AdmissionReviewVersions: []string{"v1"},
+			WebhookPath:             ptr.To("/convert"),
+			ConversionCRDs:          []string{"foos.example.com"},
+		},
+	)
+	certProvider := &fakeCertificateProvider{}
+
+	plain, err := Convert(bundle, installNamespace, []string{metav1.NamespaceAll}, WithCertificateProvider(certProvider))
+	require.NoError(t, err)
+
+	t.Log("It provisions one certificate per deployment")
+	assert.Equal(t, []CertificateConfig{{Namespace: installNamespace, ServiceName: "test-manager-service", Name: "test-manager-service-cert"}}, certProvider.provisioned)
+	findObject[*corev1.Secret](t, plain.Objects, "test-manager-service-cert")
+
+	t.Log("It creates a Service for the deployment with a port per webhook port")
+	svc := findObject[*corev1.Service](t, plain.Objects, "test-manager-service")
+	assert.Equal(t, map[string]string{"app": "manager"}, svc.Spec.Selector)
+	assert.Equal(t, []corev1.ServicePort{
+		{Name: "443", Port: 443, TargetPort: intstr.FromInt32(9443)},
+		{Name: "8443", Port: 8443, TargetPort: intstr.FromInt32(8443)},
+	}, svc.Spec.Ports)
+
+	t.Log("It mounts the certificate into the deployment")
+	dep := findObject[*appsv1.Deployment](t, plain.Objects, "test.manager")
+	assert.Len(t, dep.Spec.Template.Spec.Volumes, 2)
+	assert.Equal(t, "test-manager-service-cert", dep.Spec.Template.Spec.Volumes[1].Secret.SecretName)
+	assert.Equal(t, []corev1.VolumeMount{
+		{Name: "apiservice-cert", MountPath: "/apiserver.local.config/certificates"},
+		{Name: "webhook-cert", MountPath: "/tmp/k8s-webhook-server/serving-certs"},
+	}, dep.Spec.Template.Spec.Containers[0].VolumeMounts)
+	assert.Empty(t, bundle.CSV.Spec.InstallStrategy.StrategySpec.DeploymentSpecs[0].Spec.Template.Spec.Containers[0].VolumeMounts)
+
+	t.Log("It creates the webhook configurations")
+	vwc := findObject[*admissionregistrationv1.ValidatingWebhookConfiguration](t, plain.Objects, "vfoo.example.com")
+	assert.Equal(t, map[string]string{"example.com/inject": "test-manager-service-cert"}, vwc.Annotations)
+	require.Len(t, vwc.Webhooks, 1)
+	assert.Nil(t, vwc.Webhooks[0].NamespaceSelector)
+	assert.Equal(t, admissionregistrationv1.WebhookClientConfig{
+		Service: &admissionregistrationv1.ServiceReference{
+			Namespace: installNamespace,
+			Name:      "test-manager-service",
+			Path:      ptr.To("/validate"),
+			Port:      ptr.To[int32](443),
+		},
+		CABundle: []byte("fake-ca"),
+	}, vwc.Webhooks[0].ClientConfig)
+	mwc := findObject[*admissionregistrationv1.MutatingWebhookConfiguration](t, plain.Objects, "mfoo.example.com")
+	require.Len(t, mwc.Webhooks, 1)
+	assert.Equal(t, ptr.To[int32](8443), mwc.Webhooks[0].ClientConfig.Service.Port)
+
+	t.Log("It configures the conversion webhook of the CRD")
+	u := findObject[*unstructured.Unstructured](t, plain.Objects, "foos.example.com")
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, crd))
+	assert.Equal(t, map[string]string{"example.com/inject": "test-manager-service-cert"}, crd.Annotations)
+	assert.Equal(t, &apiextensionsv1.CustomResourceConversion{
+		Strategy: apiextensionsv1.WebhookConverter,
+		Webhook: &apiextensionsv1.WebhookConversion{
+			ClientConfig: &apiextensionsv1.WebhookClientConfig{
+				Service: &apiextensionsv1.ServiceReference{
+					Namespace: installNamespace,
+					Name:      "test-manager-service",
+					Path:      ptr.To("/convert"),
+					Port:      ptr.To[int32](443),
+				},
+				CABundle: []byte("fake-ca"),
+			},
+			ConversionReviewVersions: []string{"v1"},
+		},
+	}, crd.Spec.Conversion)
+	assert.Nil(t, bundle.Others[0].Object["spec"].(map[string]interface{})["conversion"])
+}
+
+func TestConvertWebhooksTargetNamespaces(t *testing.T) {
+	bundle := webhookBundle(t, v1alpha1.WebhookDescription{
+		GenerateName:   "vfoo.example.com",
+		Type:           v1alpha1.ValidatingAdmissionWebhook,
+		DeploymentName: "test.manager",
+	})
+
+	plain, err := Convert(bundle, installNamespace, []string{"ns-a", "ns-b"}, WithCertificateProvider(&fakeCertificateProvider{}))
+	require.NoError(t, err)
+	vwc := findObject[*admissionregistrationv1.ValidatingWebhookConfiguration](t, plain.Objects, "vfoo.example.com")
+	assert.Equal(t, &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{
+			Key:      "kubernetes.io/metadata.name",
+			Operator: metav1.LabelSelectorOpIn,
+			Values:   []string{"ns-a", "ns-b"},
+		}},
+	}, vwc.Webhooks[0].NamespaceSelector)
+
+	t.Log("It rejects conversion webhooks when not watching all namespaces")
+	bundle = webhookBundle(t, v1alpha1.WebhookDescription{
+		GenerateName:   "cfoo.example.com",
+		Type:           v1alpha1.ConversionWebhook,
+		DeploymentName: "test.manager",
+		ConversionCRDs: []string{"foos.example.com"},
+	})
+	_, err = Convert(bundle, installNamespace, []string{"ns-a", "ns-b"}, WithCertificateProvider(&fakeCertificateProvider{}))
+	require.EqualError(t, err, `terminal error: conversion webhook "cfoo.example.com" is only supported when watching all namespaces`)
+	assert.ErrorIs(t, err, reconcile.TerminalError(nil))
+}
+
+func TestConvertWebhooksErrors(t *testing.T) {
+	bundle := webhookBundle(t, v1alpha1.WebhookDescription{
+		GenerateName:   "vfoo.example.com",
+		Type:           v1alpha1.ValidatingAdmissionWebhook,
+		DeploymentName: "missing",
+	})
+	_, err := Convert(bundle, installNamespace, nil, WithCertificateProvider(&fakeCertificateProvider{}))
+	require.EqualError(t, err, `webhook "vfoo.example.com" is served by deployment "missing", which is not in the bundle`)
+
+	bundle = webhookBundle(t, v1alpha1.WebhookDescription{
+		GenerateName:   "cfoo.example.com",
+		Type:           v1alpha1.ConversionWebhook,
+		DeploymentName: "test.manager",
+		ConversionCRDs: []string{"bars.example.com"},
+	})
+	_, err = Convert(bundle, installNamespace, nil, WithCertificateProvider(&fakeCertificateProvider{}))
+	require.EqualError(t, err, `conversion webhook "cfoo.example.com" is for CRD "bars.example.com", which is not in the bundle`)
+}
@@ -66,6 +66,18 @@ func NewPreflight(crdCli apiextensionsv1client.CustomResourceDefinitionInterface
 	return p
 }
 
+// NewStoredVersionPreflight returns a Preflight that only ensures that no version
+// stored in the cluster is removed from an existing CRD. It is meant to guard
+// rollbacks, which restore CRDs that already passed the full set of checks when
+// they were first installed.
+func NewStoredVersionPreflight(crdCli apiextensionsv1client.CustomResourceDefinitionInterface) *Preflight {
+	return NewPreflight(crdCli, WithValidator(&kappcus.Validator{
+		Validations: []kappcus.Validation{
+			kappcus.NewValidationFunc("NoStoredVersionRemoved", kappcus.NoStoredVersionRemoved),
+		},
+	}))
+}
+
 func (p *Preflight) Install(ctx context.Context, rel *release.Release) error {
 	return p.runPreflight(ctx, rel)
 }
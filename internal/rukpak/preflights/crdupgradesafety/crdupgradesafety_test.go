@@ -364,3 +364,26 @@ func TestUpgrade(t *testing.T) {
 		})
 	}
 }
+
+func TestStoredVersionPreflight(t *testing.T) {
+	oldCrd := getCrdFromManifestFile(t, "old-crd.json")
+
+	t.Run("allows changes that keep stored versions", func(t *testing.T) {
+		preflight := crdupgradesafety.NewStoredVersionPreflight(&MockCRDGetter{oldCrd: oldCrd})
+		err := preflight.Upgrade(context.Background(), &release.Release{
+			Name:     "test-release",
+			Manifest: getManifestString(t, "crd-field-removed.json"),
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("refuses changes that remove a stored version", func(t *testing.T) {
+		preflight := crdupgradesafety.NewStoredVersionPreflight(&MockCRDGetter{oldCrd: oldCrd})
+		err := preflight.Upgrade(context.Background(), &release.Release{
+			Name:     "test-release",
+			Manifest: getManifestString(t, "crd-invalid-upgrade.json"),
+		})
+		require.ErrorContains(t, err, `"NoStoredVersionRemoved"`)
+		require.NotContains(t, err.Error(), "enum constraints")
+	})
+}
@@ -0,0 +1,146 @@
+package kubeversion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	bsemver "github.com/blang/semver/v4"
+	"helm.sh/helm/v3/pkg/release"
+	"k8s.io/client-go/discovery"
+
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	"github.com/operator-framework/operator-controller/internal/rukpak/convert"
+)
+
+// PropertyTypeMaxKubeVersion is the type of the bundle property that declares the
+// highest minor version of Kubernetes the bundle supports, for example "1.31".
+const PropertyTypeMaxKubeVersion = "olm.maxKubeVersion"
+
+// IncompatibleError is returned when the version of Kubernetes the cluster runs
+// is outside of the range of versions supported by a bundle.
+type IncompatibleError struct {
+	KubernetesVersion string
+	MinKubeVersion    string
+	MaxKubeVersion    string
+}
+
+func (e *IncompatibleError) Error() string {
+	if e.MinKubeVersion != "" {
+		return fmt.Sprintf("requires Kubernetes %s or later, but the cluster runs Kubernetes %s", e.MinKubeVersion, e.KubernetesVersion)
+	}
+	return fmt.Sprintf("requires Kubernetes %s or earlier, but the cluster runs Kubernetes %s", e.MaxKubeVersion, e.KubernetesVersion)
+}
+
+// Check returns an *IncompatibleError if kubernetesVersion is lower than
+// minKubeVersion or if its minor version is greater than the one of
+// maxKubeVersion. Empty versions are not checked.
+func Check(kubernetesVersion, minKubeVersion, maxKubeVersion string) error {
+	if kubernetesVersion == "" || (minKubeVersion == "" && maxKubeVersion == "") {
+		return nil
+	}
+	current, err := bsemver.ParseTolerant(kubernetesVersion)
+	if err != nil {
+		return fmt.Errorf("invalid Kubernetes version %q: %w", kubernetesVersion, err)
+	}
+	// Distributions add pre-release and build metadata to the version, as in
+	// 1.30.2-gke.1 or 1.30.2+k3s1, which must not make it lower than 1.30.2.
+	current.Pre, current.Build = nil, nil
+
+	if minKubeVersion != "" {
+		minVersion, err := bsemver.ParseTolerant(minKubeVersion)
+		if err != nil {
+			return fmt.Errorf("invalid minimum Kubernetes version %q: %w", minKubeVersion, err)
+		}
+		if current.LT(minVersion) {
+			return &IncompatibleError{KubernetesVersion: current.String(), MinKubeVersion: minKubeVersion}
+		}
+	}
+	if maxKubeVersion != "" {
+		maxVersion, err := bsemver.ParseTolerant(maxKubeVersion)
+		if err != nil {
+			return fmt.Errorf("invalid maximum Kubernetes version %q: %w", maxKubeVersion, err)
+		}
+		if current.Major > maxVersion.Major || (current.Major == maxVersion.Major && current.Minor > maxVersion.Minor) {
+			return &IncompatibleError{KubernetesVersion: current.String(), MaxKubeVersion: maxKubeVersion}
+		}
+	}
+	return nil
+}
+
+// BundleRange returns the range of Kubernetes versions supported by a bundle
+// with the given properties: the minKubeVersion of its olm.csv.metadata
+// property and the value of its olm.maxKubeVersion property.
+func BundleRange(properties []property.Property) (string, string, error) {
+	var minKubeVersion, maxKubeVersion string
+	for _, prop := range properties {
+		switch prop.Type {
+		case property.TypeCSVMetadata:
+			var csvMetadata struct {
+				MinKubeVersion string `json:"minKubeVersion"`
+			}
+			if err := json.Unmarshal(prop.Value, &csvMetadata); err != nil {
+				return "", "", fmt.Errorf("error parsing property %q: %w", prop.Type, err)
+			}
+			minKubeVersion = csvMetadata.MinKubeVersion
+		case PropertyTypeMaxKubeVersion:
+			if err := json.Unmarshal(prop.Value, &maxKubeVersion); err != nil {
+				return "", "", fmt.Errorf("error parsing property %q: %w", prop.Type, err)
+			}
+		}
+	}
+	return minKubeVersion, maxKubeVersion, nil
+}
+
+// Preflight blocks installing or upgrading to a bundle that does not support
+// the version of Kubernetes the cluster runs.
+type Preflight struct {
+	versionGetter discovery.ServerVersionInterface
+}
+
+func NewPreflight(versionGetter discovery.ServerVersionInterface) *Preflight {
+	return &Preflight{versionGetter: versionGetter}
+}
+
+func (p *Preflight) Install(ctx context.Context, rel *release.Release) error {
+	return p.runPreflight(ctx, rel)
+}
+
+func (p *Preflight) Upgrade(ctx context.Context, rel *release.Release) error {
+	return p.runPreflight(ctx, rel)
+}
+
+func (p *Preflight) runPreflight(_ context.Context, rel *release.Release) error {
+	if rel == nil || rel.Chart == nil || rel.Chart.Metadata == nil {
+		return nil
+	}
+	annotations := rel.Chart.Metadata.Annotations
+
+	var properties []property.Property
+	if propertiesJSON, ok := annotations["olm.properties"]; ok {
+		if err := json.Unmarshal([]byte(propertiesJSON), &properties); err != nil {
+			return fmt.Errorf("error parsing properties of release %q: %w", rel.Name, err)
+		}
+	}
+	_, maxKubeVersion, err := BundleRange(properties)
+	if err != nil {
+		return err
+	}
+	minKubeVersion := annotations[convert.MinKubeVersionAnnotation]
+	if minKubeVersion == "" && maxKubeVersion == "" {
+		return nil
+	}
+
+	info, err := p.versionGetter.ServerVersion()
+	if err != nil {
+		return fmt.Errorf("error getting Kubernetes version: %w", err)
+	}
+	// The error is retried, so that the release is applied once the control
+	// plane is upgraded or downgraded into the supported range.
+	if err := Check(strings.TrimPrefix(info.GitVersion, "v"), minKubeVersion, maxKubeVersion); err != nil {
+		return fmt.Errorf("incompatible Kubernetes version: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package kubeversion_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	"k8s.io/apimachinery/pkg/version"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	"github.com/operator-framework/operator-controller/internal/rukpak/convert"
+	"github.com/operator-framework/operator-controller/internal/rukpak/preflights/kubeversion"
+)
+
+type fakeVersionGetter struct {
+	info *version.Info
+	err  error
+}
+
+func (f fakeVersionGetter) ServerVersion() (*version.Info, error) {
+	return f.info, f.err
+}
+
+func TestCheck(t *testing.T) {
+	for _, tc := range []struct {
+		name              string
+		kubernetesVersion string
+		minKubeVersion    string
+		maxKubeVersion    string
+		expectErr         string
+	}{
+		{name: "no range", kubernetesVersion: "1.30.2"},
+		{name: "unknown Kubernetes version", minKubeVersion: "1.31.0"},
+		{name: "above minimum", kubernetesVersion: "1.30.2", minKubeVersion: "1.30.0"},
+		{name: "minimum with distribution suffix", kubernetesVersion: "1.30.0-gke.1", minKubeVersion: "1.30.0"},
+		{
+			name:              "below minimum",
+			kubernetesVersion: "1.30.2+k3s1",
+			minKubeVersion:    "1.31.0",
+			expectErr:         "requires Kubernetes 1.31.0 or later, but the cluster runs Kubernetes 1.30.2",
+		},
+		{name: "patch above maximum minor", kubernetesVersion: "1.30.9", maxKubeVersion: "1.30"},
+		{
+			name:              "above maximum",
+			kubernetesVersion: "1.31.0",
+			maxKubeVersion:    "1.30",
+			expectErr:         "requires Kubernetes 1.30 or earlier, but the cluster runs Kubernetes 1.31.0",
+		},
+		{
+			name:              "invalid minimum",
+			kubernetesVersion: "1.31.0",
+			minKubeVersion:    "latest",
+			expectErr:         `invalid minimum Kubernetes version "latest"`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := kubeversion.Check(tc.kubernetesVersion, tc.minKubeVersion, tc.maxKubeVersion)
+			if tc.expectErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.expectErr)
+		})
+	}
+}
+
+func TestBundleRange(t *testing.T) {
+	minKubeVersion, maxKubeVersion, err := kubeversion.BundleRange([]property.Property{
+		property.MustBuildPackage("foo", "1.0.0"),
+		{Type: property.TypeCSVMetadata, Value: json.RawMessage(`{"displayName":"Foo","minKubeVersion":"1.28.0"}`)},
+		{Type: kubeversion.PropertyTypeMaxKubeVersion, Value: json.RawMessage(`"1.31"`)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "1.28.0", minKubeVersion)
+	assert.Equal(t, "1.31", maxKubeVersion)
+
+	_, _, err = kubeversion.BundleRange([]property.Property{
+		{Type: kubeversion.PropertyTypeMaxKubeVersion, Value: json.RawMessage(`1.31`)},
+	})
+	require.EqualError(t, err, `error parsing property "olm.maxKubeVersion": json: cannot unmarshal number into Go value of type string`)
+}
+
+func testRelease(annotations map[string]string) *release.Release {
+	return &release.Release{
+		Name:  "test-release",
+		Chart: &chart.Chart{Metadata: &chart.Metadata{Annotations: annotations}},
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	ctx := context.Background()
+	preflight := kubeversion.NewPreflight(fakeVersionGetter{info: &version.Info{GitVersion: "v1.30.2"}})
+
+	require.NoError(t, preflight.Install(ctx, testRelease(nil)))
+	require.NoError(t, preflight.Install(ctx, testRelease(map[string]string{convert.MinKubeVersionAnnotation: "1.30.0"})))
+	require.NoError(t, preflight.Upgrade(ctx, testRelease(map[string]string{"olm.properties": `[{"type":"olm.maxKubeVersion","value":"1.30"}]`})))
+
+	err := preflight.Install(ctx, testRelease(map[string]string{convert.MinKubeVersionAnnotation: "1.31.0"}))
+	require.EqualError(t, err, "incompatible Kubernetes version: requires Kubernetes 1.31.0 or later, but the cluster runs Kubernetes 1.30.2")
+	assert.NotErrorIs(t, err, reconcile.TerminalError(nil))
+	var incompatible *kubeversion.IncompatibleError
+	assert.ErrorAs(t, err, &incompatible)
+
+	err = preflight.Upgrade(ctx, testRelease(map[string]string{"olm.properties": `[{"type":"olm.maxKubeVersion","value":"1.29"}]`}))
+	require.EqualError(t, err, "incompatible Kubernetes version: requires Kubernetes 1.29 or earlier, but the cluster runs Kubernetes 1.30.2")
+
+	preflight = kubeversion.NewPreflight(fakeVersionGetter{err: errors.New("fake error")})
+	err = preflight.Install(ctx, testRelease(map[string]string{convert.MinKubeVersionAnnotation: "1.31.0"}))
+	require.EqualError(t, err, "error getting Kubernetes version: fake error")
+	assert.NotErrorIs(t, err, reconcile.TerminalError(nil))
+}
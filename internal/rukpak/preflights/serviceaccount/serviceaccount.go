@@ -0,0 +1,372 @@
+package serviceaccount
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/release"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+	"github.com/operator-framework/operator-controller/internal/rukpak/util"
+)
+
+var (
+	// objectVerbs are the verbs needed on every object of a release to install,
+	// upgrade and uninstall it.
+	objectVerbs = []string{"create", "update", "patch", "delete", "get"}
+	// collectionVerbs are the verbs of objectVerbs that cannot be restricted to
+	// the name of an object.
+	collectionVerbs = []string{"create"}
+	// watchVerbs are the verbs needed on the resource of every object of a
+	// release in all namespaces, since the installed objects are watched with
+	// an informer per resource that is not restricted to a namespace.
+	watchVerbs = []string{"list", "watch"}
+)
+
+// MissingPermissionsError is returned when the service account of a
+// ClusterExtension lacks permissions needed to install or upgrade it.
+type MissingPermissionsError struct {
+	Namespace      string
+	ServiceAccount string
+	// Missing describes every missing permission, in a stable order.
+	Missing []string
+}
+
+func (e *MissingPermissionsError) Error() string {
+	return fmt.Sprintf("service account %q in namespace %q is missing the following permissions: %s",
+		e.ServiceAccount, e.Namespace, strings.Join(e.Missing, "; "))
+}
+
+// Preflight checks with SubjectAccessReviews that the service account of a
+// ClusterExtension is allowed to manage every object of its release, and to
+// create the Roles, ClusterRoles and bindings among them, so that every missing
+// permission is reported at once instead of failing the release midway.
+type Preflight struct {
+	extReader  client.Reader
+	restMapper meta.RESTMapper
+	sarClient  authorizationv1client.SubjectAccessReviewInterface
+}
+
+func NewPreflight(extReader client.Reader, restMapper meta.RESTMapper, sarClient authorizationv1client.SubjectAccessReviewInterface) *Preflight {
+	return &Preflight{
+		extReader:  extReader,
+		restMapper: restMapper,
+		sarClient:  sarClient,
+	}
+}
+
+func (p *Preflight) Install(ctx context.Context, rel *release.Release) error {
+	return p.runPreflight(ctx, rel)
+}
+
+func (p *Preflight) Upgrade(ctx context.Context, rel *release.Release) error {
+	return p.runPreflight(ctx, rel)
+}
+
+func (p *Preflight) runPreflight(ctx context.Context, rel *release.Release) error {
+	if rel == nil {
+		return nil
+	}
+
+	// Releases are named after their ClusterExtension.
+	ext := &ocv1.ClusterExtension{}
+	if err := p.extReader.Get(ctx, client.ObjectKey{Name: rel.Name}, ext); err != nil {
+		return fmt.Errorf("error getting ClusterExtension %q: %w", rel.Name, err)
+	}
+	relObjects, err := util.ManifestObjects(strings.NewReader(rel.Manifest), fmt.Sprintf("%s-release-manifest", rel.Name))
+	if err != nil {
+		return fmt.Errorf("parsing release %q objects: %w", rel.Name, err)
+	}
+	return p.Check(ctx, ext.Spec.Namespace, ext.Spec.ServiceAccount.Name, rel.Namespace, relObjects)
+}
+
+// Check returns a *MissingPermissionsError if the service account name in
+// namespace is not allowed to manage objs, whose namespace defaults to
+// defaultNamespace. Creating a Role or ClusterRole requires either the escalate
+// verb on it or every permission it grants, and creating a binding requires
+// either the bind verb on its role or every permission the role grants, which
+// is only known for the roles among objs.
+func (p *Preflight) Check(ctx context.Context, namespace, name, defaultNamespace string, objs []client.Object) error {
+	c := &checker{
+		ctx:       ctx,
+		sarClient: p.sarClient,
+		user:      fmt.Sprintf("system:serviceaccount:%s:%s", namespace, name),
+		groups:    []string{"system:serviceaccounts", "system:serviceaccounts:" + namespace, "system:authenticated"},
+		allowed:   map[permission]bool{},
+		missing:   map[permission]struct{}{},
+	}
+
+	roles := map[roleKey][]rbacv1.PolicyRule{}
+	for _, obj := range objs {
+		gvk := obj.GetObjectKind().GroupVersionKind()
+		mapping, err := p.restMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return fmt.Errorf("error mapping %s %q to a resource: %w", gvk.Kind, obj.GetName(), err)
+		}
+		objNamespace := ""
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			objNamespace = obj.GetNamespace()
+			if objNamespace == "" {
+				objNamespace = defaultNamespace
+			}
+		}
+		for _, verb := range objectVerbs {
+			perm := permission{verb: verb, group: mapping.Resource.Group, resource: mapping.Resource.Resource, namespace: objNamespace}
+			if !slices.Contains(collectionVerbs, verb) {
+				perm.name = obj.GetName()
+			}
+			if err := c.require(perm); err != nil {
+				return err
+			}
+		}
+		for _, verb := range watchVerbs {
+			if err := c.require(permission{verb: verb, group: mapping.Resource.Group, resource: mapping.Resource.Resource}); err != nil {
+				return err
+			}
+		}
+
+		switch gvk.GroupKind() {
+		case rbacv1.SchemeGroupVersion.WithKind("Role").GroupKind():
+			role := &rbacv1.Role{}
+			if err := toTyped(obj, role); err != nil {
+				return err
+			}
+			roles[roleKey{kind: "Role", namespace: objNamespace, name: role.Name}] = role.Rules
+		case rbacv1.SchemeGroupVersion.WithKind("ClusterRole").GroupKind():
+			clusterRole := &rbacv1.ClusterRole{}
+			if err := toTyped(obj, clusterRole); err != nil {
+				return err
+			}
+			roles[roleKey{kind: "ClusterRole", name: clusterRole.Name}] = clusterRole.Rules
+		}
+	}
+
+	for key, rules := range roles {
+		escalate := permission{verb: "escalate", group: rbacv1.GroupName, resource: key.resource(), namespace: key.namespace, name: key.name}
+		if err := c.requireEither(escalate, rulePermissions(key.namespace, rules)); err != nil {
+			return err
+		}
+	}
+
+	for _, obj := range objs {
+		var (
+			bindingNamespace string
+			roleRef          rbacv1.RoleRef
+		)
+		switch obj.GetObjectKind().GroupVersionKind().GroupKind() {
+		case rbacv1.SchemeGroupVersion.WithKind("RoleBinding").GroupKind():
+			roleBinding := &rbacv1.RoleBinding{}
+			if err := toTyped(obj, roleBinding); err != nil {
+				return err
+			}
+			bindingNamespace, roleRef = roleBinding.Namespace, roleBinding.RoleRef
+			if bindingNamespace == "" {
+				bindingNamespace = defaultNamespace
+			}
+		case rbacv1.SchemeGroupVersion.WithKind("ClusterRoleBinding").GroupKind():
+			clusterRoleBinding := &rbacv1.ClusterRoleBinding{}
+			if err := toTyped(obj, clusterRoleBinding); err != nil {
+				return err
+			}
+			roleRef = clusterRoleBinding.RoleRef
+		default:
+			continue
+		}
+
+		key := roleKey{kind: roleRef.Kind, name: roleRef.Name}
+		if key.kind == "Role" {
+			key.namespace = bindingNamespace
+		}
+		bind := permission{verb: "bind", group: rbacv1.GroupName, resource: key.resource(), namespace: bindingNamespace, name: key.name}
+		rules, ok := roles[key]
+		if !ok {
+			// The permissions granted by roles outside of the release are unknown.
+			if err := c.require(bind); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := c.requireEither(bind, rulePermissions(bindingNamespace, rules)); err != nil {
+			return err
+		}
+	}
+
+	if len(c.missing) == 0 {
+		return nil
+	}
+	missing := make([]string, 0, len(c.missing))
+	for perm := range c.missing {
+		missing = append(missing, perm.String())
+	}
+	slices.Sort(missing)
+	return &MissingPermissionsError{Namespace: namespace, ServiceAccount: name, Missing: missing}
+}
+
+// permission is the subject of a SubjectAccessReview: either a verb on a
+// resource, or a verb on a non-resource URL.
+type permission struct {
+	verb           string
+	group          string
+	resource       string
+	subresource    string
+	namespace      string
+	name           string
+	nonResourceURL string
+}
+
+func (p permission) String() string {
+	if p.nonResourceURL != "" {
+		return fmt.Sprintf("%s non-resource URL %q", p.verb, p.nonResourceURL)
+	}
+	var sb strings.Builder
+	sb.WriteString(p.verb)
+	sb.WriteString(" ")
+	sb.WriteString(p.resource)
+	if p.group != "" {
+		sb.WriteString(".")
+		sb.WriteString(p.group)
+	}
+	if p.subresource != "" {
+		sb.WriteString("/")
+		sb.WriteString(p.subresource)
+	}
+	if p.name != "" {
+		fmt.Fprintf(&sb, " %q", p.name)
+	}
+	if p.namespace != "" {
+		fmt.Fprintf(&sb, " in namespace %q", p.namespace)
+	}
+	return sb.String()
+}
+
+type roleKey struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+func (k roleKey) resource() string {
+	if k.kind == "Role" {
+		return "roles"
+	}
+	return "clusterroles"
+}
+
+// rulePermissions returns the permissions granted by rules in namespace, which
+// is empty for cluster-wide rules.
+func rulePermissions(namespace string, rules []rbacv1.PolicyRule) []permission {
+	var perms []permission
+	for _, rule := range rules {
+		for _, verb := range rule.Verbs {
+			for _, url := range rule.NonResourceURLs {
+				perms = append(perms, permission{verb: verb, nonResourceURL: url})
+			}
+			for _, group := range rule.APIGroups {
+				for _, resource := range rule.Resources {
+					// Rules name subresources as resource/subresource.
+					resource, subresource, _ := strings.Cut(resource, "/")
+					perm := permission{verb: verb, group: group, resource: resource, subresource: subresource, namespace: namespace}
+					if len(rule.ResourceNames) == 0 {
+						perms = append(perms, perm)
+						continue
+					}
+					for _, name := range rule.ResourceNames {
+						perm.name = name
+						perms = append(perms, perm)
+					}
+				}
+			}
+		}
+	}
+	return perms
+}
+
+// checker records the permissions a service account is missing, reviewing
+// each permission only once.
+type checker struct {
+	ctx       context.Context
+	sarClient authorizationv1client.SubjectAccessReviewInterface
+	user      string
+	groups    []string
+
+	allowed map[permission]bool
+	missing map[permission]struct{}
+}
+
+// require records the permissions of perms that are not allowed.
+func (c *checker) require(perms ...permission) error {
+	for _, perm := range perms {
+		allowed, err := c.isAllowed(perm)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			c.missing[perm] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// requireEither records the missing permissions of alternative, unless perm
+// is allowed, in which case alternative is not needed.
+func (c *checker) requireEither(perm permission, alternative []permission) error {
+	allowed, err := c.isAllowed(perm)
+	if err != nil || allowed {
+		return err
+	}
+	return c.require(alternative...)
+}
+
+func (c *checker) isAllowed(perm permission) (bool, error) {
+	if allowed, ok := c.allowed[perm]; ok {
+		return allowed, nil
+	}
+	sar := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   c.user,
+			Groups: c.groups,
+		},
+	}
+	if perm.nonResourceURL != "" {
+		sar.Spec.NonResourceAttributes = &authorizationv1.NonResourceAttributes{
+			Path: perm.nonResourceURL,
+			Verb: perm.verb,
+		}
+	} else {
+		sar.Spec.ResourceAttributes = &authorizationv1.ResourceAttributes{
+			Verb:        perm.verb,
+			Group:       perm.group,
+			Resource:    perm.resource,
+			Subresource: perm.subresource,
+			Namespace:   perm.namespace,
+			Name:        perm.name,
+		}
+	}
+	res, err := c.sarClient.Create(c.ctx, sar, metav1.CreateOptions{})
+	if err != nil {
+		return false, fmt.Errorf("error reviewing permission to %s: %w", perm, err)
+	}
+	c.allowed[perm] = res.Status.Allowed
+	return res.Status.Allowed, nil
+}
+
+// toTyped converts obj, which is either typed or unstructured, into out.
+func toTyped(obj client.Object, out runtime.Object) error {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return fmt.Errorf("error converting %s %q: %w", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName(), err)
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u, out); err != nil {
+		return fmt.Errorf("error converting %s %q: %w", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName(), err)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package serviceaccount_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/release"
+	appsv1 "k8s.io/api/apps/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+	"github.com/operator-framework/operator-controller/internal/rukpak/preflights/serviceaccount"
+	"github.com/operator-framework/operator-controller/internal/scheme"
+)
+
+// fakeSARClient allows every access that is not denied. Denied accesses are
+// keyed by "<verb> <group>/<resource>[/<subresource>] <namespace>/<name>".
+type fakeSARClient struct {
+	denied map[string]bool
+	err    error
+	users  map[string]struct{}
+}
+
+func (f *fakeSARClient) Create(_ context.Context, sar *authorizationv1.SubjectAccessReview, _ metav1.CreateOptions) (*authorizationv1.SubjectAccessReview, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.users == nil {
+		f.users = map[string]struct{}{}
+	}
+	f.users[sar.Spec.User] = struct{}{}
+	a := sar.Spec.ResourceAttributes
+	resource := a.Resource
+	if a.Subresource != "" {
+		resource += "/" + a.Subresource
+	}
+	key := fmt.Sprintf("%s %s/%s %s/%s", a.Verb, a.Group, resource, a.Namespace, a.Name)
+	sar.Status.Allowed = !f.denied[key]
+	return sar, nil
+}
+
+func testRESTMapper() meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ServiceAccount"), meta.RESTScopeNamespace)
+	mapper.Add(appsv1.SchemeGroupVersion.WithKind("Deployment"), meta.RESTScopeNamespace)
+	mapper.Add(rbacv1.SchemeGroupVersion.WithKind("Role"), meta.RESTScopeNamespace)
+	mapper.Add(rbacv1.SchemeGroupVersion.WithKind("RoleBinding"), meta.RESTScopeNamespace)
+	mapper.Add(rbacv1.SchemeGroupVersion.WithKind("ClusterRole"), meta.RESTScopeRoot)
+	mapper.Add(rbacv1.SchemeGroupVersion.WithKind("ClusterRoleBinding"), meta.RESTScopeRoot)
+	return mapper
+}
+
+func testObjects() []client.Object {
+	return []client.Object{
+		&corev1.ServiceAccount{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "op"},
+		},
+		&appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{Name: "op"},
+		},
+		&rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole"},
+			ObjectMeta: metav1.ObjectMeta{Name: "op-role"},
+			Rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get", "list"}},
+				{APIGroups: []string{"apps"}, Resources: []string{"deployments/scale"}, Verbs: []string{"update"}},
+			},
+		},
+		&rbacv1.ClusterRoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{Name: "op-binding"},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "op-role"},
+		},
+		&rbacv1.RoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "RoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "op-auth-reader"},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "extension-apiserver-authentication-reader"},
+		},
+	}
+}
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		name          string
+		sarClient     *fakeSARClient
+		objs          []client.Object
+		expectMissing []string
+		expectErr     string
+	}{
+		{
+			name:      "all permissions",
+			sarClient: &fakeSARClient{},
+			objs:      testObjects(),
+		},
+		{
+			name: "role permissions held instead of escalate and bind",
+			sarClient: &fakeSARClient{denied: map[string]bool{
+				"escalate rbac.authorization.k8s.io/clusterroles /op-role": true,
+				"bind rbac.authorization.k8s.io/clusterroles /op-role":     true,
+			}},
+			objs: testObjects(),
+		},
+		{
+			name: "missing permissions",
+			sarClient: &fakeSARClient{denied: map[string]bool{
+				"delete apps/deployments ns/op":                            true,
+				"watch /serviceaccounts /":                                 true,
+				"escalate rbac.authorization.k8s.io/clusterroles /op-role": true,
+				"bind rbac.authorization.k8s.io/clusterroles /op-role":     true,
+				"list /secrets /":                                          true,
+				"update apps/deployments/scale /":                          true,
+				"bind rbac.authorization.k8s.io/roles kube-system/extension-apiserver-authentication-reader": true,
+			}},
+			objs: testObjects(),
+			expectMissing: []string{
+				`bind roles.rbac.authorization.k8s.io "extension-apiserver-authentication-reader" in namespace "kube-system"`,
+				`delete deployments.apps "op" in namespace "ns"`,
+				`list secrets`,
+				`update deployments.apps/scale`,
+				`watch serviceaccounts`,
+			},
+		},
+		{
+			name:      "unknown kind",
+			sarClient: &fakeSARClient{},
+			objs: []client.Object{&rbacv1.ClusterRole{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v2", Kind: "ClusterRole"},
+				ObjectMeta: metav1.ObjectMeta{Name: "op-role"},
+			}},
+			expectErr: `error mapping ClusterRole "op-role" to a resource`,
+		},
+		{
+			name:      "review error",
+			sarClient: &fakeSARClient{err: errors.New("fake error")},
+			objs:      testObjects(),
+			expectErr: `error reviewing permission to create serviceaccounts in namespace "ns": fake error`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			preflight := serviceaccount.NewPreflight(nil, testRESTMapper(), tc.sarClient)
+			err := preflight.Check(ctx, "olmv1-system", "installer", "ns", tc.objs)
+			switch {
+			case tc.expectErr != "":
+				require.ErrorContains(t, err, tc.expectErr)
+			case tc.expectMissing != nil:
+				var missing *serviceaccount.MissingPermissionsError
+				require.ErrorAs(t, err, &missing)
+				assert.Equal(t, tc.expectMissing, missing.Missing)
+				assert.Equal(t, "olmv1-system", missing.Namespace)
+				assert.Equal(t, "installer", missing.ServiceAccount)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, map[string]struct{}{"system:serviceaccount:olmv1-system:installer": {}}, tc.sarClient.users)
+			}
+		})
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	ctx := context.Background()
+	ext := &ocv1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-extension"},
+		Spec: ocv1.ClusterExtensionSpec{
+			Namespace:      "olmv1-system",
+			ServiceAccount: ocv1.ServiceAccountReference{Name: "installer"},
+		},
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(ext).Build()
+	rel := &release.Release{
+		Name:      "test-extension",
+		Namespace: "ns",
+		Manifest: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: op
+`,
+	}
+
+	preflight := serviceaccount.NewPreflight(cl, testRESTMapper(), &fakeSARClient{})
+	require.NoError(t, preflight.Install(ctx, nil))
+	require.NoError(t, preflight.Install(ctx, rel))
+
+	preflight = serviceaccount.NewPreflight(cl, testRESTMapper(), &fakeSARClient{denied: map[string]bool{
+		"create apps/deployments ns/":   true,
+		"update apps/deployments ns/op": true,
+	}})
+	err := preflight.Upgrade(ctx, rel)
+	require.EqualError(t, err, `service account "installer" in namespace "olmv1-system" is missing the following permissions: `+
+		`create deployments.apps in namespace "ns"; update deployments.apps "op" in namespace "ns"`)
+
+	rel.Name = "missing-extension"
+	err = preflight.Upgrade(ctx, rel)
+	require.ErrorContains(t, err, `error getting ClusterExtension "missing-extension"`)
+}
@@ -23,6 +23,8 @@ import (
 	"github.com/opencontainers/go-digest"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/operator-framework/operator-controller/internal/metrics"
 )
 
 type ContainersImageRegistry struct {
@@ -45,6 +47,18 @@ func (i *ContainersImageRegistry) Unpack(ctx context.Context, bundle *BundleSour
 	if err != nil {
 		return nil, err
 	}
+	if len(bundle.Image.PullSecret) > 0 {
+		authFilePath, err := writeAuthFile(bundle.Image.PullSecret)
+		if err != nil {
+			return nil, fmt.Errorf("error writing pull secret: %w", err)
+		}
+		defer func() {
+			if err := os.Remove(authFilePath); err != nil {
+				l.Error(err, "error removing temporary auth file", "path", authFilePath)
+			}
+		}()
+		srcCtx.AuthFilePath = authFilePath
+	}
 	//////////////////////////////////////////////////////
 	//
 	// Resolve a canonical reference for the image.
@@ -154,6 +168,7 @@ func (i *ContainersImageRegistry) Unpack(ctx context.Context, bundle *BundleSour
 	if err := i.deleteOtherImages(bundle.Name, canonicalRef.Digest()); err != nil {
 		return nil, fmt.Errorf("error deleting old images: %w", err)
 	}
+	i.observeCacheSize(l, bundle.Name)
 
 	return successResult(bundle.Name, unpackPath, canonicalRef), nil
 }
@@ -167,8 +182,54 @@ func successResult(bundleName, unpackPath string, canonicalRef reference.Canonic
 	}
 }
 
+// writeAuthFile writes the given docker config JSON to a temporary file
+// and returns its path. The caller is responsible for removing the file.
+func writeAuthFile(dockerConfigJSON []byte) (string, error) {
+	f, err := os.CreateTemp("", "bundle-auth-*.json")
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(dockerConfigJSON); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
 func (i *ContainersImageRegistry) Cleanup(_ context.Context, bundle *BundleSource) error {
-	return deleteRecursive(i.bundlePath(bundle.Name))
+	if err := deleteRecursive(i.bundlePath(bundle.Name)); err != nil {
+		return err
+	}
+	metrics.DeleteUnpackCacheSize(bundle.Name)
+	return nil
+}
+
+// observeCacheSize records the size of the unpacked images cached for the bundle.
+func (i *ContainersImageRegistry) observeCacheSize(l logr.Logger, bundleName string) {
+	var size int64
+	if err := filepath.WalkDir(i.bundlePath(bundleName), func(_ string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += fi.Size()
+		return nil
+	}); err != nil {
+		l.Error(err, "error computing size of unpacked bundle cache", "bundle", bundleName)
+		return
+	}
+	metrics.SetUnpackCacheSize(bundleName, size)
 }
 
 func (i *ContainersImageRegistry) bundlePath(bundleName string) string {
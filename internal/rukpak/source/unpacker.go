@@ -11,6 +11,10 @@ const SourceTypeImage SourceType = "image"
 type ImageSource struct {
 	// Ref contains the reference to a container image containing Bundle contents.
 	Ref string
+	// PullSecret optionally contains the contents of a docker config JSON file
+	// holding credentials for pulling Ref. When empty, the credentials
+	// configured for the unpacker are used.
+	PullSecret []byte
 }
 
 // Unpacker unpacks bundle content, either synchronously or asynchronously and
@@ -0,0 +1,234 @@
+// Package uninstall applies the uninstall policy of a ClusterExtension to the
+// objects installed for it when it is deleted.
+//
+// The installed objects are owned by the ClusterExtension, so the garbage
+// collector deletes the objects that are not orphaned here once the
+// ClusterExtension is gone.
+package uninstall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+	"github.com/operator-framework/operator-controller/internal/labels"
+)
+
+// ErrWaiting is wrapped by the errors returned while the uninstall waits for
+// objects to be deleted.
+var ErrWaiting = errors.New("waiting for objects to be deleted")
+
+// ErrServiceAccountNotFound is wrapped by the errors returned by ClientFor when
+// the ServiceAccount of the ClusterExtension or its namespace no longer exists.
+var ErrServiceAccountNotFound = errors.New("service account not found")
+
+// EventReasonPolicyNotApplied is the reason of the Warning Event recorded when
+// the uninstall policy cannot be applied because the ServiceAccount is gone.
+const EventReasonPolicyNotApplied = "UninstallPolicyNotApplied"
+
+var crdGroupKind = apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinition").GroupKind()
+
+// Uninstaller applies the uninstall policy of a ClusterExtension.
+type Uninstaller struct {
+	// ClientFor returns the client used to uninstall the objects of ext,
+	// which acts as the ServiceAccount of ext. It returns an error wrapping
+	// ErrServiceAccountNotFound when the ServiceAccount does not exist.
+	ClientFor func(ctx context.Context, ext *ocv1.ClusterExtension) (client.Client, error)
+	// ManagedObjectsFor returns the objects installed for ext.
+	ManagedObjectsFor func(ctx context.Context, ext *ocv1.ClusterExtension) ([]ocv1.ManagedObjectReference, error)
+	// Recorder records the Events of ext.
+	Recorder record.EventRecorder
+}
+
+// Policy returns the uninstall policy of ext.
+func Policy(ext *ocv1.ClusterExtension) ocv1.UninstallPolicy {
+	if ext.Spec.Uninstall == nil || ext.Spec.Uninstall.Policy == "" {
+		return ocv1.UninstallPolicyKeepCRDs
+	}
+	return ext.Spec.Uninstall.Policy
+}
+
+// Uninstall applies the uninstall policy of ext to the objects installed for
+// it. It returns an error wrapping ErrWaiting while custom resources that have
+// to be deleted first still exist.
+//
+// If the ServiceAccount of ext or its namespace was deleted first, nothing can
+// be orphaned or deleted on its behalf: a Warning Event is recorded and the
+// installed objects are left to the garbage collector.
+func (u *Uninstaller) Uninstall(ctx context.Context, ext *ocv1.ClusterExtension) error {
+	refs, err := u.ManagedObjectsFor(ctx, ext)
+	if err != nil {
+		return fmt.Errorf("error getting installed objects: %w", err)
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+	cl, err := u.ClientFor(ctx, ext)
+	if errors.Is(err, ErrServiceAccountNotFound) {
+		u.Recorder.Eventf(ext, corev1.EventTypeWarning, EventReasonPolicyNotApplied,
+			"Uninstall policy %q not applied, installed objects are left to the garbage collector: %v", Policy(ext), err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	crdRefs := slices.DeleteFunc(slices.Clone(refs), func(ref ocv1.ManagedObjectReference) bool {
+		return groupKind(ref) != crdGroupKind
+	})
+	switch Policy(ext) {
+	case ocv1.UninstallPolicyOrphan:
+		return orphan(ctx, cl, ext, refs)
+	case ocv1.UninstallPolicyCascade:
+		return cascade(ctx, cl, crdRefs)
+	default:
+		return orphan(ctx, cl, ext, crdRefs)
+	}
+}
+
+// orphan removes the owner references and labels that tie the referenced
+// objects to ext, so that they are kept when ext is deleted.
+func orphan(ctx context.Context, cl client.Client, ext *ocv1.ClusterExtension, refs []ocv1.ManagedObjectReference) error {
+	var errs []error
+	for _, ref := range refs {
+		obj, err := get(ctx, cl, ref)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if obj == nil {
+			continue
+		}
+
+		patch := client.MergeFrom(obj.DeepCopy())
+		ownerRefs := obj.GetOwnerReferences()
+		obj.SetOwnerReferences(slices.DeleteFunc(ownerRefs, func(ownerRef metav1.OwnerReference) bool {
+			return ownerRef.UID == ext.GetUID()
+		}))
+		objLabels := obj.GetLabels()
+		delete(objLabels, labels.OwnerKindKey)
+		delete(objLabels, labels.OwnerNameKey)
+		obj.SetLabels(objLabels)
+		if err := cl.Patch(ctx, obj, patch); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("error orphaning %s: %w", describe(ref), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// cascade deletes the custom resources of the referenced CustomResourceDefinitions
+// and, once none of them is left, the CustomResourceDefinitions.
+func cascade(ctx context.Context, cl client.Client, crdRefs []ocv1.ManagedObjectReference) error {
+	var (
+		errs    []error
+		waiting []string
+	)
+	for _, ref := range crdRefs {
+		obj, err := get(ctx, cl, ref)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if obj == nil {
+			continue
+		}
+		crd := apiextensionsv1.CustomResourceDefinition{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &crd); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		remaining, err := deleteCustomResources(ctx, cl, &crd)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error deleting custom resources of %s: %w", describe(ref), err))
+			continue
+		}
+		if remaining > 0 {
+			waiting = append(waiting, fmt.Sprintf("%d custom resources of %s", remaining, crd.Name))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if len(waiting) > 0 {
+		return fmt.Errorf("%w: %s", ErrWaiting, strings.Join(waiting, ", "))
+	}
+
+	for _, ref := range crdRefs {
+		crd := &unstructured.Unstructured{}
+		crd.SetAPIVersion(ref.APIVersion)
+		crd.SetKind(ref.Kind)
+		crd.SetName(ref.Name)
+		if err := cl.Delete(ctx, crd); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("error deleting %s: %w", describe(ref), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deleteCustomResources requests the deletion of the custom resources of crd
+// and returns how many of them still exist.
+func deleteCustomResources(ctx context.Context, cl client.Client, crd *apiextensionsv1.CustomResourceDefinition) (int, error) {
+	version := ""
+	for _, v := range crd.Spec.Versions {
+		if v.Storage {
+			version = v.Name
+		}
+	}
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{Group: crd.Spec.Group, Version: version, Kind: crd.Spec.Names.ListKind})
+	if err := cl.List(ctx, list); err != nil {
+		if apimeta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	for i := range list.Items {
+		cr := &list.Items[i]
+		if cr.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if err := cl.Delete(ctx, cr); err != nil && !apierrors.IsNotFound(err) {
+			return 0, err
+		}
+	}
+	return len(list.Items), nil
+}
+
+// get returns the referenced object, or nil if it does not exist.
+func get(ctx context.Context, cl client.Client, ref ocv1.ManagedObjectReference) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(ref.APIVersion)
+	obj.SetKind(ref.Kind)
+	if err := cl.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, obj); err != nil {
+		if apierrors.IsNotFound(err) || apimeta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting %s: %w", describe(ref), err)
+	}
+	return obj, nil
+}
+
+func groupKind(ref ocv1.ManagedObjectReference) schema.GroupKind {
+	return schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind).GroupKind()
+}
+
+func describe(ref ocv1.ManagedObjectReference) string {
+	if ref.Namespace == "" {
+		return fmt.Sprintf("%s %q", ref.Kind, ref.Name)
+	}
+	return fmt.Sprintf("%s %q in namespace %q", ref.Kind, ref.Name, ref.Namespace)
+}
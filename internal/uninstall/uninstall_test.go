@@ -0,0 +1,207 @@
+package uninstall_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+	"github.com/operator-framework/operator-controller/internal/labels"
+	"github.com/operator-framework/operator-controller/internal/uninstall"
+)
+
+const extUID = types.UID("ext-uid")
+
+var (
+	fooGVK = schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Foo"}
+
+	crdRef       = ocv1.ManagedObjectReference{APIVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition", Name: "foos.example.com"}
+	configMapRef = ocv1.ManagedObjectReference{APIVersion: "v1", Kind: "ConfigMap", Namespace: "test-ns", Name: "test-cm"}
+)
+
+func ownerMeta(name, namespace string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: namespace,
+		Labels: map[string]string{
+			labels.OwnerKindKey: ocv1.ClusterExtensionKind,
+			labels.OwnerNameKey: "test-ext",
+			"app":               "test",
+		},
+		OwnerReferences: []metav1.OwnerReference{{
+			APIVersion: ocv1.GroupVersion.String(),
+			Kind:       ocv1.ClusterExtensionKind,
+			Name:       "test-ext",
+			UID:        extUID,
+		}},
+	}
+}
+
+func testObjects() []client.Object {
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: ownerMeta("foos.example.com", ""),
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: fooGVK.Group,
+			Names: apiextensionsv1.CustomResourceDefinitionNames{Kind: "Foo", ListKind: "FooList", Plural: "foos"},
+			Scope: apiextensionsv1.NamespaceScoped,
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{Name: "v1beta1", Served: true},
+				{Name: "v1", Served: true, Storage: true},
+			},
+		},
+	}
+	cm := &corev1.ConfigMap{ObjectMeta: ownerMeta("test-cm", "test-ns")}
+	foo := &unstructured.Unstructured{}
+	foo.SetGroupVersionKind(fooGVK)
+	foo.SetNamespace("test-ns")
+	foo.SetName("test-foo")
+	return []client.Object{crd, cm, foo}
+}
+
+func newUninstaller(t *testing.T, policy ocv1.UninstallPolicy, funcs interceptor.Funcs) (*uninstall.Uninstaller, client.Client, *ocv1.ClusterExtension) {
+	t.Helper()
+	sch := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(sch))
+	require.NoError(t, apiextensionsv1.AddToScheme(sch))
+	mapper := apimeta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), apimeta.RESTScopeNamespace)
+	mapper.Add(apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinition"), apimeta.RESTScopeRoot)
+	mapper.Add(fooGVK, apimeta.RESTScopeNamespace)
+	cl := fake.NewClientBuilder().WithScheme(sch).WithRESTMapper(mapper).WithObjects(testObjects()...).WithInterceptorFuncs(funcs).Build()
+
+	ext := &ocv1.ClusterExtension{ObjectMeta: metav1.ObjectMeta{Name: "test-ext", UID: extUID}}
+	if policy != "" {
+		ext.Spec.Uninstall = &ocv1.ClusterExtensionUninstallConfig{Policy: policy}
+	}
+	u := &uninstall.Uninstaller{
+		ClientFor: func(context.Context, *ocv1.ClusterExtension) (client.Client, error) {
+			return cl, nil
+		},
+		ManagedObjectsFor: func(context.Context, *ocv1.ClusterExtension) ([]ocv1.ManagedObjectReference, error) {
+			return []ocv1.ManagedObjectReference{configMapRef, crdRef}, nil
+		},
+		Recorder: record.NewFakeRecorder(10),
+	}
+	return u, cl, ext
+}
+
+func requireOrphaned(t *testing.T, cl client.Client, obj client.Object, orphaned bool) {
+	t.Helper()
+	require.NoError(t, cl.Get(context.Background(), client.ObjectKeyFromObject(obj), obj))
+	if orphaned {
+		assert.Empty(t, obj.GetOwnerReferences())
+		assert.Equal(t, map[string]string{"app": "test"}, obj.GetLabels())
+		return
+	}
+	assert.Len(t, obj.GetOwnerReferences(), 1)
+	assert.Contains(t, obj.GetLabels(), labels.OwnerNameKey)
+}
+
+func TestPolicy(t *testing.T) {
+	ext := &ocv1.ClusterExtension{}
+	assert.Equal(t, ocv1.UninstallPolicyKeepCRDs, uninstall.Policy(ext))
+	ext.Spec.Uninstall = &ocv1.ClusterExtensionUninstallConfig{}
+	assert.Equal(t, ocv1.UninstallPolicyKeepCRDs, uninstall.Policy(ext))
+	ext.Spec.Uninstall.Policy = ocv1.UninstallPolicyCascade
+	assert.Equal(t, ocv1.UninstallPolicyCascade, uninstall.Policy(ext))
+}
+
+func TestUninstallOrphan(t *testing.T) {
+	u, cl, ext := newUninstaller(t, ocv1.UninstallPolicyOrphan, interceptor.Funcs{})
+	require.NoError(t, u.Uninstall(context.Background(), ext))
+
+	requireOrphaned(t, cl, &apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "foos.example.com"}}, true)
+	requireOrphaned(t, cl, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test-cm", Namespace: "test-ns"}}, true)
+}
+
+func TestUninstallKeepCRDs(t *testing.T) {
+	u, cl, ext := newUninstaller(t, "", interceptor.Funcs{})
+	require.NoError(t, u.Uninstall(context.Background(), ext))
+
+	requireOrphaned(t, cl, &apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "foos.example.com"}}, true)
+	requireOrphaned(t, cl, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test-cm", Namespace: "test-ns"}}, false)
+}
+
+func TestUninstallCascade(t *testing.T) {
+	ctx := context.Background()
+	// The custom resource has a finalizer, so it is only marked for deletion.
+	u, cl, ext := newUninstaller(t, ocv1.UninstallPolicyCascade, interceptor.Funcs{
+		Delete: func(ctx context.Context, cl client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			if obj.GetObjectKind().GroupVersionKind() != fooGVK {
+				return cl.Delete(ctx, obj, opts...)
+			}
+			obj.SetFinalizers([]string{"example.com/finalizer"})
+			if err := cl.Update(ctx, obj); err != nil {
+				return err
+			}
+			return cl.Delete(ctx, obj, opts...)
+		},
+	})
+
+	t.Log("It deletes the custom resources and waits for them to be gone")
+	err := u.Uninstall(ctx, ext)
+	require.ErrorIs(t, err, uninstall.ErrWaiting)
+	assert.EqualError(t, err, "waiting for objects to be deleted: 1 custom resources of foos.example.com")
+	foo := &unstructured.Unstructured{}
+	foo.SetGroupVersionKind(fooGVK)
+	require.NoError(t, cl.Get(ctx, client.ObjectKey{Namespace: "test-ns", Name: "test-foo"}, foo))
+	assert.NotNil(t, foo.GetDeletionTimestamp())
+	require.NoError(t, cl.Get(ctx, client.ObjectKey{Name: "foos.example.com"}, &apiextensionsv1.CustomResourceDefinition{}))
+
+	t.Log("It deletes the CustomResourceDefinitions once the custom resources are gone")
+	foo.SetFinalizers(nil)
+	require.NoError(t, cl.Update(ctx, foo))
+	require.NoError(t, u.Uninstall(ctx, ext))
+	err = cl.Get(ctx, client.ObjectKey{Name: "foos.example.com"}, &apiextensionsv1.CustomResourceDefinition{})
+	require.True(t, apierrors.IsNotFound(err))
+	requireOrphaned(t, cl, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test-cm", Namespace: "test-ns"}}, false)
+}
+
+func TestUninstallErrors(t *testing.T) {
+	u, _, ext := newUninstaller(t, ocv1.UninstallPolicyOrphan, interceptor.Funcs{
+		Patch: func(context.Context, client.WithWatch, client.Object, client.Patch, ...client.PatchOption) error {
+			return apierrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, "test-cm", errors.New("fake error"))
+		},
+	})
+	err := u.Uninstall(context.Background(), ext)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, uninstall.ErrWaiting)
+	assert.Contains(t, err.Error(), `error orphaning ConfigMap "test-cm" in namespace "test-ns"`)
+
+	u.ManagedObjectsFor = func(context.Context, *ocv1.ClusterExtension) ([]ocv1.ManagedObjectReference, error) {
+		return nil, errors.New("fake error")
+	}
+	assert.EqualError(t, u.Uninstall(context.Background(), ext), "error getting installed objects: fake error")
+}
+
+func TestUninstallServiceAccountNotFound(t *testing.T) {
+	u, cl, ext := newUninstaller(t, ocv1.UninstallPolicyCascade, interceptor.Funcs{})
+	recorder := record.NewFakeRecorder(10)
+	u.Recorder = recorder
+	u.ClientFor = func(context.Context, *ocv1.ClusterExtension) (client.Client, error) {
+		return nil, fmt.Errorf("%w: serviceaccounts %q not found", uninstall.ErrServiceAccountNotFound, "test-sa")
+	}
+	require.NoError(t, u.Uninstall(context.Background(), ext))
+
+	require.Len(t, recorder.Events, 1)
+	assert.Equal(t, `Warning UninstallPolicyNotApplied Uninstall policy "Cascade" not applied, installed objects are left to the garbage collector: service account not found: serviceaccounts "test-sa" not found`, <-recorder.Events)
+	require.NoError(t, cl.Get(context.Background(), client.ObjectKey{Name: "foos.example.com"}, &apiextensionsv1.CustomResourceDefinition{}))
+	requireOrphaned(t, cl, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test-cm", Namespace: "test-ns"}}, false)
+}
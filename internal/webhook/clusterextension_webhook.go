@@ -0,0 +1,147 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+	"github.com/operator-framework/operator-controller/internal/resolve"
+	"github.com/operator-framework/operator-controller/internal/rukpak/convert"
+)
+
+// ClusterExtension validates ClusterExtensions on admission against the
+// bundles they resolve to.
+type ClusterExtension struct {
+	// Resolver resolves the bundle of a ClusterExtension with a Catalog source.
+	Resolver resolve.Resolver
+}
+
+//+kubebuilder:webhook:admissionReviewVersions={v1},failurePolicy=Ignore,groups=olm.operatorframework.io,mutating=false,name=vclusterextension.olm.operatorframework.io,path=/validate-olm-operatorframework-io-v1-clusterextension,resources=clusterextensions,sideEffects=None,timeoutSeconds=10,verbs=create;update,versions=v1
+
+// SetupWebhookWithManager registers the webhook with the webhook server of mgr.
+func (w *ClusterExtension) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&ocv1.ClusterExtension{}).
+		WithValidator(w).
+		Complete()
+}
+
+var _ admission.CustomValidator = (*ClusterExtension)(nil)
+
+// ValidateCreate validates a created ClusterExtension.
+func (w *ClusterExtension) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	ext, ok := obj.(*ocv1.ClusterExtension)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterExtension but got a %T", obj)
+	}
+	return w.validateInstallModes(ctx, ext, nil)
+}
+
+// ValidateUpdate validates an updated ClusterExtension. Only changes to the
+// fields of its spec that select the bundle or the namespaces it watches are
+// validated, so that it can always be finalized, its status and metadata can
+// always be updated, and other updates do not wait for a resolution.
+func (w *ClusterExtension) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldExt, ok := oldObj.(*ocv1.ClusterExtension)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterExtension but got a %T", oldObj)
+	}
+	ext, ok := newObj.(*ocv1.ClusterExtension)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterExtension but got a %T", newObj)
+	}
+	if !installModesAffected(oldExt, ext) || !ext.GetDeletionTimestamp().IsZero() {
+		return nil, nil
+	}
+	var installedBundle *ocv1.BundleMetadata
+	if ext.Status.Install != nil {
+		installedBundle = &ext.Status.Install.Bundle
+	}
+	return w.validateInstallModes(ctx, ext, installedBundle)
+}
+
+// ValidateDelete allows every deletion.
+func (w *ClusterExtension) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// installModesAffected reports whether the update of oldExt to ext changes the
+// namespaces ext is installed into and watches, or the bundles it may resolve to.
+func installModesAffected(oldExt, ext *ocv1.ClusterExtension) bool {
+	if oldExt.Spec.Namespace != ext.Spec.Namespace ||
+		!equality.Semantic.DeepEqual(watchNamespaces(oldExt), watchNamespaces(ext)) ||
+		oldExt.Spec.Source.SourceType != ext.Spec.Source.SourceType {
+		return true
+	}
+	oldCatalog, catalog := oldExt.Spec.Source.Catalog, ext.Spec.Source.Catalog
+	if oldCatalog == nil || catalog == nil {
+		return oldCatalog != catalog
+	}
+	return oldCatalog.PackageName != catalog.PackageName ||
+		oldCatalog.Version != catalog.Version ||
+		!equality.Semantic.DeepEqual(oldCatalog.Channels, catalog.Channels) ||
+		!equality.Semantic.DeepEqual(oldCatalog.Selector, catalog.Selector)
+}
+
+// watchNamespaces returns the namespaces ext is configured to watch.
+func watchNamespaces(ext *ocv1.ClusterExtension) []string {
+	if ext.Spec.Install == nil {
+		return nil
+	}
+	return ext.Spec.Install.WatchNamespaces
+}
+
+// validateInstallModes rejects ext when the bundle it resolves to from a
+// catalog does not support the namespaces it watches. Bundle images are only
+// pulled once ext is reconciled, so their install modes are not validated on
+// admission. ext is admitted with a warning when it cannot be resolved, since
+// resolution may succeed later, for example once a catalog is available.
+func (w *ClusterExtension) validateInstallModes(ctx context.Context, ext *ocv1.ClusterExtension, installedBundle *ocv1.BundleMetadata) (admission.Warnings, error) {
+	if ext.Spec.Source.SourceType != ocv1.SourceTypeCatalog {
+		return nil, nil
+	}
+	bundle, _, _, _, err := w.Resolver.Resolve(ctx, ext, installedBundle)
+	var installModes []v1alpha1.InstallMode
+	if err == nil {
+		installModes, err = bundleInstallModes(bundle)
+	}
+	if err != nil {
+		return admission.Warnings{fmt.Sprintf("the install modes of the bundle could not be validated: %v", err)}, nil
+	}
+	if installModes == nil {
+		return nil, nil
+	}
+
+	watchNamespaces := watchNamespaces(ext)
+	if _, err := convert.TargetNamespaces(installModes, ext.Spec.Namespace, watchNamespaces); err != nil {
+		return nil, apierrors.NewInvalid(ocv1.GroupVersion.WithKind(ocv1.ClusterExtensionKind).GroupKind(), ext.GetName(), field.ErrorList{
+			field.Invalid(field.NewPath("spec", "install", "watchNamespaces"), watchNamespaces,
+				fmt.Sprintf("not supported by bundle %q: %v", bundle.Name, err)),
+		})
+	}
+	return nil, nil
+}
+
+// bundleInstallModes returns the install modes of bundle, or nil when its
+// properties do not include the metadata of its ClusterServiceVersion.
+func bundleInstallModes(bundle *declcfg.Bundle) ([]v1alpha1.InstallMode, error) {
+	props, err := property.Parse(bundle.Properties)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing properties of bundle %q: %w", bundle.Name, err)
+	}
+	if len(props.CSVMetadatas) == 0 {
+		return nil, nil
+	}
+	return props.CSVMetadatas[0].InstallModes, nil
+}
@@ -0,0 +1,196 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	bsemver "github.com/blang/semver/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	ocv1 "github.com/operator-framework/operator-controller/api/v1"
+	"github.com/operator-framework/operator-controller/internal/resolve"
+	"github.com/operator-framework/operator-controller/internal/webhook"
+)
+
+func bundleWithInstallModes(installModes ...v1alpha1.InstallModeType) *declcfg.Bundle {
+	csv := v1alpha1.ClusterServiceVersion{}
+	for _, installMode := range installModes {
+		csv.Spec.InstallModes = append(csv.Spec.InstallModes, v1alpha1.InstallMode{Type: installMode, Supported: true})
+	}
+	return &declcfg.Bundle{
+		Name:       "foo.v1.0.0",
+		Package:    "foo",
+		Properties: []property.Property{property.MustBuildCSVMetadata(csv)},
+	}
+}
+
+func resolverFor(bundle *declcfg.Bundle, err error) resolve.Func {
+	return func(context.Context, *ocv1.ClusterExtension, *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *resolve.Origin, error) {
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		v := bsemver.MustParse("1.0.0")
+		return bundle, &v, nil, nil, nil
+	}
+}
+
+func catalogExtension(watchNamespaces ...string) *ocv1.ClusterExtension {
+	ext := &ocv1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo"},
+		Spec: ocv1.ClusterExtensionSpec{
+			Namespace:      "foo-system",
+			ServiceAccount: ocv1.ServiceAccountReference{Name: "foo-installer"},
+			Source: ocv1.SourceConfig{
+				SourceType: ocv1.SourceTypeCatalog,
+				Catalog:    &ocv1.CatalogSource{PackageName: "foo"},
+			},
+		},
+	}
+	if len(watchNamespaces) > 0 {
+		ext.Spec.Install = &ocv1.ClusterExtensionInstallConfig{WatchNamespaces: watchNamespaces}
+	}
+	return ext
+}
+
+func TestClusterExtensionValidateCreate(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		name           string
+		ext            *ocv1.ClusterExtension
+		bundle         *declcfg.Bundle
+		resolveErr     error
+		expectErr      string
+		expectWarnings bool
+	}{
+		{
+			name:   "all namespaces supported",
+			ext:    catalogExtension(),
+			bundle: bundleWithInstallModes(v1alpha1.InstallModeTypeAllNamespaces),
+		},
+		{
+			name:   "install namespace by default",
+			ext:    catalogExtension(),
+			bundle: bundleWithInstallModes(v1alpha1.InstallModeTypeOwnNamespace),
+		},
+		{
+			name:   "install namespace supported",
+			ext:    catalogExtension("foo-system"),
+			bundle: bundleWithInstallModes(v1alpha1.InstallModeTypeOwnNamespace),
+		},
+		{
+			name:      "single namespace not supported",
+			ext:       catalogExtension("bar"),
+			bundle:    bundleWithInstallModes(v1alpha1.InstallModeTypeAllNamespaces, v1alpha1.InstallModeTypeOwnNamespace),
+			expectErr: `spec.install.watchNamespaces: Invalid value: []string{"bar"}: not supported by bundle "foo.v1.0.0"`,
+		},
+		{
+			name:      "all namespaces not supported",
+			ext:       catalogExtension(),
+			bundle:    bundleWithInstallModes(v1alpha1.InstallModeTypeSingleNamespace),
+			expectErr: `do not support targeting all namespaces`,
+		},
+		{
+			name:   "bundle without install modes metadata",
+			ext:    catalogExtension("bar"),
+			bundle: &declcfg.Bundle{Name: "foo.v1.0.0", Package: "foo"},
+		},
+		{
+			name:           "resolution error",
+			ext:            catalogExtension("bar"),
+			resolveErr:     errors.New("no catalogs"),
+			expectWarnings: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := &webhook.ClusterExtension{Resolver: resolverFor(tc.bundle, tc.resolveErr)}
+			warnings, err := w.ValidateCreate(ctx, tc.ext)
+			if tc.expectErr != "" {
+				require.True(t, apierrors.IsInvalid(err), "expected an invalid error, got %v", err)
+				assert.ErrorContains(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			if tc.expectWarnings {
+				assert.Equal(t, []string{"the install modes of the bundle could not be validated: no catalogs"}, []string(warnings))
+			} else {
+				assert.Empty(t, warnings)
+			}
+		})
+	}
+}
+
+func TestClusterExtensionValidateImageSource(t *testing.T) {
+	w := &webhook.ClusterExtension{Resolver: resolve.Func(func(context.Context, *ocv1.ClusterExtension, *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *resolve.Origin, error) {
+		require.Fail(t, "bundle images must not be resolved on admission")
+		return nil, nil, nil, nil, nil
+	})}
+	ext := catalogExtension("bar")
+	ext.Spec.Source = ocv1.SourceConfig{
+		SourceType: ocv1.SourceTypeImage,
+		Image:      &ocv1.ImageSource{Ref: "quay.io/example/foo-bundle:v1.0.0"},
+	}
+	warnings, err := w.ValidateCreate(context.Background(), ext)
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+}
+
+func TestClusterExtensionValidateUpdate(t *testing.T) {
+	ctx := context.Background()
+	var resolvedInstalledBundle *ocv1.BundleMetadata
+	resolved := 0
+	w := &webhook.ClusterExtension{Resolver: resolve.Func(func(_ context.Context, _ *ocv1.ClusterExtension, installedBundle *ocv1.BundleMetadata) (*declcfg.Bundle, *bsemver.Version, *declcfg.Deprecation, *resolve.Origin, error) {
+		resolved++
+		resolvedInstalledBundle = installedBundle
+		v := bsemver.MustParse("1.0.0")
+		return bundleWithInstallModes(v1alpha1.InstallModeTypeAllNamespaces), &v, nil, nil, nil
+	})}
+
+	oldExt := catalogExtension()
+	oldExt.Status.Install = &ocv1.ClusterExtensionInstallStatus{Bundle: ocv1.BundleMetadata{Name: "foo.v0.9.0", Version: "0.9.0"}}
+
+	t.Log("It does not validate updates that leave the spec unchanged")
+	newExt := oldExt.DeepCopy()
+	newExt.Finalizers = []string{"olm.operatorframework.io/cleanup-unpack-cache"}
+	_, err := w.ValidateUpdate(ctx, oldExt, newExt)
+	require.NoError(t, err)
+	assert.Equal(t, 0, resolved)
+
+	t.Log("It does not validate updates to spec fields that do not select the bundle or the watched namespaces")
+	newExt = oldExt.DeepCopy()
+	newExt.Spec.Source.Catalog.ApprovedVersion = "1.0.0"
+	newExt.Spec.Source.Catalog.UpgradeApproval = ocv1.UpgradeApprovalManual
+	newExt.Spec.Install = &ocv1.ClusterExtensionInstallConfig{}
+	_, err = w.ValidateUpdate(ctx, oldExt, newExt)
+	require.NoError(t, err)
+	assert.Equal(t, 0, resolved)
+
+	t.Log("It validates updates to the version of the package")
+	newExt = oldExt.DeepCopy()
+	newExt.Spec.Source.Catalog.Version = "1.0.0"
+	_, err = w.ValidateUpdate(ctx, oldExt, newExt)
+	require.NoError(t, err)
+	assert.Equal(t, 1, resolved)
+
+	t.Log("It validates the watched namespaces against the upgrade of the installed bundle")
+	newExt = catalogExtension("bar")
+	newExt.Status = oldExt.Status
+	_, err = w.ValidateUpdate(ctx, oldExt, newExt)
+	require.True(t, apierrors.IsInvalid(err), "expected an invalid error, got %v", err)
+	assert.Equal(t, 2, resolved)
+	assert.Equal(t, &oldExt.Status.Install.Bundle, resolvedInstalledBundle)
+
+	t.Log("It does not validate extensions being deleted")
+	newExt.DeletionTimestamp = ptr.To(metav1.Now())
+	_, err = w.ValidateUpdate(ctx, oldExt, newExt)
+	require.NoError(t, err)
+	assert.Equal(t, 2, resolved)
+}
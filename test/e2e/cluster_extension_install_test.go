@@ -258,13 +258,29 @@ func testCleanup(t *testing.T, cat *catalogd.ClusterCatalog, clusterExtension *o
 		return errors.IsNotFound(err)
 	}, pollDuration, pollInterval)
 
+	// The CustomResourceDefinitions are kept by the default uninstall policy,
+	// and no longer labeled once the ClusterExtension is deleted.
+	keptCRDs := &apiextensionsv1.CustomResourceDefinitionList{}
+	require.NoError(t, c.List(context.Background(), keptCRDs, client.MatchingLabels{"olm.operatorframework.io/owner-name": clusterExtension.Name}))
+
 	t.Logf("By deleting ClusterExtension %q", clusterExtension.Name)
-	require.NoError(t, c.Delete(context.Background(), clusterExtension))
+	require.NoError(t, client.IgnoreNotFound(c.Delete(context.Background(), clusterExtension)))
 	require.Eventually(t, func() bool {
 		err := c.Get(context.Background(), types.NamespacedName{Name: clusterExtension.Name}, &ocv1.ClusterExtension{})
 		return errors.IsNotFound(err)
 	}, pollDuration, pollInterval)
 
+	for i := range keptCRDs.Items {
+		t.Logf("By deleting the kept CustomResourceDefinition %q", keptCRDs.Items[i].Name)
+		require.NoError(t, client.IgnoreNotFound(c.Delete(context.Background(), &keptCRDs.Items[i])))
+	}
+	require.EventuallyWithT(t, func(ct *assert.CollectT) {
+		for _, crd := range keptCRDs.Items {
+			err := c.Get(context.Background(), types.NamespacedName{Name: crd.Name}, &apiextensionsv1.CustomResourceDefinition{})
+			assert.True(ct, errors.IsNotFound(err))
+		}
+	}, 5*pollDuration, pollInterval)
+
 	t.Logf("By deleting ServiceAccount %q", sa.Name)
 	require.NoError(t, c.Delete(context.Background(), sa))
 	require.Eventually(t, func() bool {
@@ -396,13 +412,13 @@ func TestClusterExtensionInstallRegistryMultipleBundles(t *testing.T) {
 		assert.NoError(ct, c.Get(context.Background(), types.NamespacedName{Name: clusterExtension.Name}, clusterExtension))
 	}, pollDuration, pollInterval)
 
-	t.Log("By eventually reporting Progressing == True and Reason Retrying")
+	t.Log("By eventually reporting Progressing == True and Reason ResolutionFailed")
 	require.EventuallyWithT(t, func(ct *assert.CollectT) {
 		assert.NoError(ct, c.Get(context.Background(), types.NamespacedName{Name: clusterExtension.Name}, clusterExtension))
 		cond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
 		if assert.NotNil(ct, cond) {
 			assert.Equal(ct, metav1.ConditionTrue, cond.Status)
-			assert.Equal(ct, ocv1.ReasonRetrying, cond.Reason)
+			assert.Equal(ct, ocv1.ReasonResolutionFailed, cond.Reason)
 			assert.Contains(ct, cond.Message, "in multiple catalogs with the same priority [extra-test-catalog test-catalog]")
 		}
 	}, pollDuration, pollInterval)
@@ -435,13 +451,16 @@ func TestClusterExtensionBlockInstallNonSuccessorVersion(t *testing.T) {
 	t.Log("By eventually reporting a successful installation")
 	require.EventuallyWithT(t, func(ct *assert.CollectT) {
 		assert.NoError(ct, c.Get(context.Background(), types.NamespacedName{Name: clusterExtension.Name}, clusterExtension))
-		assert.Equal(ct,
-			&ocv1.ClusterExtensionInstallStatus{Bundle: ocv1.BundleMetadata{
-				Name:    "test-operator.1.0.0",
-				Version: "1.0.0",
-			}},
-			clusterExtension.Status.Install,
-		)
+		if assert.NotNil(ct, clusterExtension.Status.Install) {
+			assert.Equal(ct,
+				ocv1.BundleMetadata{
+					Name:    "test-operator.1.0.0",
+					Version: "1.0.0",
+				},
+				clusterExtension.Status.Install.Bundle,
+			)
+			assert.Contains(ct, clusterExtension.Status.Install.ResolvedImageRef, "@sha256:")
+		}
 
 		cond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
 		if assert.NotNil(ct, cond) {
@@ -460,12 +479,12 @@ func TestClusterExtensionBlockInstallNonSuccessorVersion(t *testing.T) {
 		assert.NoError(ct, c.Get(context.Background(), types.NamespacedName{Name: clusterExtension.Name}, clusterExtension))
 	}, pollDuration, pollInterval)
 
-	t.Log("By eventually reporting Progressing == True and Reason Retrying")
+	t.Log("By eventually reporting Progressing == True and Reason ResolutionFailed")
 	require.EventuallyWithT(t, func(ct *assert.CollectT) {
 		assert.NoError(ct, c.Get(context.Background(), types.NamespacedName{Name: clusterExtension.Name}, clusterExtension))
 		cond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
 		if assert.NotNil(ct, cond) {
-			assert.Equal(ct, ocv1.ReasonRetrying, cond.Reason)
+			assert.Equal(ct, ocv1.ReasonResolutionFailed, cond.Reason)
 			assert.Equal(ct, "error upgrading from currently installed version \"1.0.0\": no bundles found for package \"test\" matching version \"1.2.0\"", cond.Message)
 		}
 	}, pollDuration, pollInterval)
@@ -811,13 +830,24 @@ func TestClusterExtensionRecoversFromInitialInstallFailedWhenFailureFixed(t *tes
 		assert.NoError(ct, c.Get(context.Background(), types.NamespacedName{Name: clusterExtension.Name}, clusterExtension))
 	}, pollDuration, pollInterval)
 
-	t.Log("By eventually reporting Progressing == True with Reason Retrying")
+	t.Log("By eventually reporting Progressing == True with Reason PreflightFailed and every missing permission")
 	require.EventuallyWithT(t, func(ct *assert.CollectT) {
 		assert.NoError(ct, c.Get(context.Background(), types.NamespacedName{Name: clusterExtension.Name}, clusterExtension))
 		cond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeProgressing)
 		if assert.NotNil(ct, cond) {
 			assert.Equal(ct, metav1.ConditionTrue, cond.Status)
-			assert.Equal(ct, ocv1.ReasonRetrying, cond.Reason)
+			assert.Equal(ct, ocv1.ReasonPreflightFailed, cond.Reason)
+			assert.Contains(ct, cond.Message, fmt.Sprintf("service account %q in namespace %q is missing the following permissions: ", sa.Name, ns.Name))
+			for _, missing := range []string{
+				`create customresourcedefinitions.apiextensions.k8s.io`,
+				`get customresourcedefinitions.apiextensions.k8s.io "olme2etests.olm.operatorframework.io"`,
+				`list customresourcedefinitions.apiextensions.k8s.io`,
+				fmt.Sprintf(`create configmaps in namespace %q`, ns.Name),
+				fmt.Sprintf(`update configmaps "test-configmap" in namespace %q`, ns.Name),
+				`watch configmaps`,
+			} {
+				assert.Contains(ct, cond.Message, missing)
+			}
 		}
 	}, pollDuration, pollInterval)
 
@@ -989,3 +1019,85 @@ func getArtifactsOutput(t *testing.T) {
 		}
 	}
 }
+
+func TestClusterExtensionUninstallCascade(t *testing.T) {
+	t.Log("When a cluster extension with the Cascade uninstall policy is deleted")
+
+	clusterExtension, extensionCatalog, sa, ns := testInit(t)
+	defer testCleanup(t, extensionCatalog, clusterExtension, sa, ns)
+	defer getArtifactsOutput(t)
+
+	t.Log("By permitting the installer service account to delete the custom resources")
+	cascadeName := fmt.Sprintf("%s-cascade", clusterExtension.Name)
+	cr := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: cascadeName},
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{"olm.operatorframework.io"},
+				Resources: []string{"olme2etests"},
+				Verbs:     []string{"delete", "get", "list"},
+			},
+		},
+	}
+	require.NoError(t, c.Create(context.Background(), cr))
+	defer func() { require.NoError(t, c.Delete(context.Background(), cr)) }()
+	crb := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: cascadeName},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      "ServiceAccount",
+				Name:      sa.Name,
+				Namespace: sa.Namespace,
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "ClusterRole",
+			Name:     cascadeName,
+		},
+	}
+	require.NoError(t, c.Create(context.Background(), crb))
+	defer func() { require.NoError(t, c.Delete(context.Background(), crb)) }()
+
+	clusterExtension.Spec = ocv1.ClusterExtensionSpec{
+		Source: ocv1.SourceConfig{
+			SourceType: "Catalog",
+			Catalog: &ocv1.CatalogSource{
+				PackageName: "test",
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"olm.operatorframework.io/metadata.name": extensionCatalog.Name},
+				},
+			},
+		},
+		Namespace: ns.Name,
+		ServiceAccount: ocv1.ServiceAccountReference{
+			Name: sa.Name,
+		},
+		Uninstall: &ocv1.ClusterExtensionUninstallConfig{Policy: ocv1.UninstallPolicyCascade},
+	}
+	t.Log("By creating the ClusterExtension resource")
+	require.NoError(t, c.Create(context.Background(), clusterExtension))
+
+	t.Log("By eventually installing the package successfully")
+	require.EventuallyWithT(t, func(ct *assert.CollectT) {
+		assert.NoError(ct, c.Get(context.Background(), types.NamespacedName{Name: clusterExtension.Name}, clusterExtension))
+		cond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1.TypeInstalled)
+		if assert.NotNil(ct, cond) {
+			assert.Equal(ct, metav1.ConditionTrue, cond.Status)
+			assert.Equal(ct, ocv1.ReasonSucceeded, cond.Reason)
+		}
+	}, pollDuration, pollInterval)
+
+	t.Log("By deleting the ClusterExtension resource")
+	require.NoError(t, c.Delete(context.Background(), clusterExtension))
+	require.Eventually(t, func() bool {
+		err := c.Get(context.Background(), types.NamespacedName{Name: clusterExtension.Name}, &ocv1.ClusterExtension{})
+		return errors.IsNotFound(err)
+	}, pollDuration, pollInterval)
+
+	t.Log("By eventually deleting the CustomResourceDefinition of the extension")
+	require.EventuallyWithT(t, func(ct *assert.CollectT) {
+		err := c.Get(context.Background(), types.NamespacedName{Name: "olme2etests.olm.operatorframework.io"}, &apiextensionsv1.CustomResourceDefinition{})
+		assert.True(ct, errors.IsNotFound(err))
+	}, 5*pollDuration, pollInterval)
+}